@@ -0,0 +1,23 @@
+// Package main is the entry point for the benchmarking tool
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benchmarking_go/pkg/api"
+)
+
+// runAPIServer serves the REST control API until interrupted
+func runAPIServer(flags *CLIFlags) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupSignalHandler(cancel, flags.QuietMode)
+
+	if !flags.QuietMode {
+		fmt.Printf("Control API listening on %s (POST /run, GET /stats, POST /stop, GET /results)\n", flags.Listen)
+	}
+	if err := api.NewServer().ListenAndServe(ctx, flags.Listen); err != nil {
+		exitWithError("%v", err)
+	}
+}
@@ -5,8 +5,13 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/benchmarking_go/pkg/config"
+	"github.com/benchmarking_go/pkg/cpuset"
 )
 
 // CLIFlags holds all command line flags
@@ -14,34 +19,110 @@ type CLIFlags struct {
 	URL             string
 	ConcurrentUsers int
 	RequestsPerUser int
-	DurationSeconds int
+	Duration        string // Seconds or a Go duration ("90s", "1h30m")
+	Preset          string // Built-in bundle of users, duration, ramp-up and thresholds
 	HTTPMethod      string
 	Headers         config.HeaderSliceFlag
+	HeaderFiles     config.HeaderFileFlag
 	RequestBody     string
 	ContentType     string
 	ShowHelp        bool
 	ShowVersion     bool
-	Timeout         int
+	Timeout         string // Seconds or a Go duration ("500ms")
 	ConfigFile      string
+	TargetsFile     string
 	OutputFormat    string
 	OutputFile      string
 	Insecure        bool
+	Metadata        config.MetadataFlag // Run metadata such as the git SHA or environment
+	Tags            config.TagsFlag     // Run tags
 
 	// Phase 2 features
 	RateLimit        int
-	RampUpSeconds    int
+	RampUp           string // Seconds or a Go duration
 	QuietMode        bool
 	VerboseMode      bool
 	DisableKeepAlive bool
-	Percentiles      config.IntSliceFlag
+	IdleTimeout      string // Close pooled connections idle for this long
+	MaxIdle          int    // Idle connections kept across all hosts
+	MaxIdlePerHost   int    // Idle connections kept per host
+	TCPKeepAlive     string // Interval of TCP keep-alive probes, or "off"
+	ConnPerUser      bool   // A dedicated connection for every user
+	UploadBW         string // Bytes sent per second per connection
+	DownloadBW       string // Bytes received per second per connection
+	Prewarm          bool   // Open the connection pool before measuring
+	Percentiles      config.FloatSliceFlag
 
 	// Phase 3 features
-	ShowHistogram bool
-	NoHdr         bool // Disable HdrHistogram (use legacy stats)
+	ShowHistogram    bool
+	HistogramBuckets string // Bucket upper bounds or "log"
+	NoHdr            bool   // Disable HdrHistogram (use legacy stats)
+	Mergeable        bool   // Embed the raw stats in JSON results for merge
+	HistoryDir       string // Directory run summaries are appended to
+	CacheStats       bool   // Report cache hits and misses from CDN headers
 
 	// Phase 4 features
 	HTTP2         bool
+	Engine        string // HTTP client engine
+	H2Connections int    // HTTP/2 connections per host to spread workers over
+	Goroutines    int    // Workers sending requests (0 = one per user)
+	MaxInFlight   int    // Requests in flight at once (0 = one per worker)
+	DNSCache      string // Resolve hosts once ("once") or for a TTL, and dial by IP
+	RoundRobinIPs bool   // Spread connections over all resolved IPs of a host
+	LocalAddrs    string // Local IPs to open connections from (comma-separated)
+	IPv4          bool   // Connect over IPv4 only
+	IPv6          bool   // Connect over IPv6 only
+	Interface     string // Network interface to send from
+	GoMaxProcs    int    // GOMAXPROCS override (0 = Go's default)
+	CPUs          string // CPUs to pin the process to (e.g. "0-3")
+	ReserveCore   bool   // Keep one of the CPUs for stats and progress
 	ShowLiveStats bool
+	Dashboard     string // Address to serve the live web dashboard on
+	GracePeriod   string // Time in-flight requests get to finish when stopping
+	TUI           bool   // Full-screen terminal dashboard
+	Interval      string // Print interim stats this often
+
+	// Long runs
+	Checkpoint         string // File to periodically save progress to
+	CheckpointInterval string // Time between checkpoints
+	Resume             string // Checkpoint file to resume from
+	StopAfterBytes     string // Stop once this much response data is received
+	MaxMemory          string // Memory budget; latency samples are downsampled near it
+	ExpectStatus       string // Response statuses counted as success (e.g. "404,5xx")
+	Until              string // Wall-clock time to stop by
+	Watch              bool   // Rerun whenever the config file changes
+
+	// Debugging
+	CaptureFailures     int
+	CaptureDir          string
+	CaptureResponses    float64 // Share of responses sampled to disk
+	CaptureResponsesDir string
+	ErrorSamples        int    // Example responses kept per error message for reports
+	RequestID           string // Header set to a unique ID on every request
+	VerifyRequestID     bool   // Fail responses that don't echo the request ID
+
+	// Plugins
+	PluginsDir    string // Directory of plugin manifests
+	AuthPlugin    string // Plugin supplying headers for every request
+	OutputPlugins string // Sink plugins receiving the results (comma-separated)
+	Sinks         string // Metrics sinks fed during the run (comma-separated)
+
+	// Scripting
+	Script     string   // wrk-style Lua script
+	ScriptArgs []string // Arguments after "--", passed to the script's init
+	JSScript   string   // JavaScript file with jsRequest, jsCheck and iteration functions
+
+	// CI gating
+	CheckBaseline string // JSON result of a previous run to compare against
+
+	// Distributed mode
+	Controller string // Address the controller listens on for workers
+	Workers    int    // Number of workers the controller waits for
+	Worker     bool   // Run as a worker instead of benchmarking locally
+	Join       string // Controller address a worker joins
+
+	// Control API
+	Listen string // Address the REST control API listens on
 }
 
 // parseFlags parses command line arguments and returns CLIFlags
@@ -55,11 +136,16 @@ func parseFlags() *CLIFlags {
 	flag.IntVar(&flags.ConcurrentUsers, "concurrent-users", 10, "Number of concurrent users")
 	flag.IntVar(&flags.ConcurrentUsers, "c", 10, "Number of concurrent users (shorthand)")
 
+	flag.IntVar(&flags.Goroutines, "goroutines", 0, "Number of workers sending requests (default: one per user)")
+	flag.IntVar(&flags.MaxInFlight, "max-in-flight", 0, "Most requests in flight at once across all workers (default: one per worker)")
+
 	flag.IntVar(&flags.RequestsPerUser, "requests-per-user", 100, "Number of requests per user")
 	flag.IntVar(&flags.RequestsPerUser, "r", 100, "Number of requests per user (shorthand)")
 
-	flag.IntVar(&flags.DurationSeconds, "duration", 0, "Duration in seconds for the benchmark")
-	flag.IntVar(&flags.DurationSeconds, "d", 0, "Duration in seconds for the benchmark (shorthand)")
+	flag.StringVar(&flags.Duration, "duration", "", "Duration of the benchmark in seconds or as a duration (e.g. '90s', '2m', '1h30m')")
+	flag.StringVar(&flags.Duration, "d", "", "Duration of the benchmark (shorthand)")
+
+	flag.StringVar(&flags.Preset, "preset", "", "Apply a built-in preset: "+strings.Join(config.PresetNames(), ", ")+" (explicit flags override it)")
 
 	flag.StringVar(&flags.HTTPMethod, "method", "GET", "HTTP method to use")
 	flag.StringVar(&flags.HTTPMethod, "m", "GET", "HTTP method to use (shorthand)")
@@ -67,15 +153,18 @@ func parseFlags() *CLIFlags {
 	flag.Var(&flags.Headers, "header", "Custom header to include in the request (format: 'key:value')")
 	flag.Var(&flags.Headers, "H", "Custom header to include in the request (shorthand) (format: 'key:value')")
 
-	flag.StringVar(&flags.RequestBody, "body", "", "Request body for POST/PUT")
+	flag.Var(&flags.HeaderFiles, "header-file", "Rotating header whose values are read from a file, one per line (format: 'key:path')")
+
+	flag.StringVar(&flags.RequestBody, "body", "", "Request body for POST/PUT ('-' reads from stdin)")
 	flag.StringVar(&flags.RequestBody, "b", "", "Request body for POST/PUT (shorthand)")
 
 	flag.StringVar(&flags.ContentType, "content-type", "", "Content-Type of the request body")
 	flag.StringVar(&flags.ContentType, "t", "", "Content-Type of the request body (shorthand)")
 
-	flag.IntVar(&flags.Timeout, "timeout", 30, "Timeout in seconds for each request")
+	flag.StringVar(&flags.Timeout, "timeout", "", "Timeout for each request in seconds or as a duration (e.g. '500ms'; default: 30s)")
 
 	flag.StringVar(&flags.ConfigFile, "config", "", "Path to JSON configuration file")
+	flag.StringVar(&flags.TargetsFile, "targets", "", "Path to vegeta-style plain-text targets file")
 
 	flag.StringVar(&flags.OutputFormat, "output", "", "Output format: json, csv, or empty for console")
 	flag.StringVar(&flags.OutputFormat, "o", "", "Output format (shorthand)")
@@ -89,7 +178,8 @@ func parseFlags() *CLIFlags {
 	flag.IntVar(&flags.RateLimit, "rate", 0, "Rate limit in requests per second (0 = unlimited)")
 	flag.IntVar(&flags.RateLimit, "R", 0, "Rate limit (shorthand)")
 
-	flag.IntVar(&flags.RampUpSeconds, "ramp-up", 0, "Ramp-up time in seconds to gradually start workers")
+	flag.StringVar(&flags.RampUp, "ramp-up", "", "Time to gradually start workers over, in seconds or as a duration (e.g. '30s')")
+	flag.StringVar(&flags.GracePeriod, "grace-period", "", "Time in-flight requests get to finish when stopping (e.g. '10s', '0' to cancel them; default: timeout)")
 
 	flag.BoolVar(&flags.QuietMode, "quiet", false, "Quiet mode - only show final summary")
 	flag.BoolVar(&flags.QuietMode, "q", false, "Quiet mode (shorthand)")
@@ -98,17 +188,81 @@ func parseFlags() *CLIFlags {
 	flag.BoolVar(&flags.VerboseMode, "V", false, "Verbose mode (shorthand)")
 
 	flag.BoolVar(&flags.DisableKeepAlive, "disable-keepalive", false, "Disable HTTP keep-alive connections")
-
-	flag.Var(&flags.Percentiles, "percentiles", "Custom percentiles to report (comma-separated, e.g., '50,90,95,99')")
+	flag.StringVar(&flags.IdleTimeout, "idle-timeout", "", "Close pooled connections idle for this long (default: keep them)")
+	flag.IntVar(&flags.MaxIdle, "max-idle", 0, "Idle connections kept across all hosts (default: one per in-flight request)")
+	flag.IntVar(&flags.MaxIdlePerHost, "max-idle-per-host", 0, "Idle connections kept per host (default: one per in-flight request)")
+	flag.StringVar(&flags.TCPKeepAlive, "tcp-keepalive", "", "Interval of TCP keep-alive probes, or 'off' (default: 30s)")
+	flag.BoolVar(&flags.ConnPerUser, "connection-per-user", false, "Give every user a dedicated connection instead of sharing a pool")
+	flag.StringVar(&flags.UploadBW, "upload-bandwidth", "", "Cap the bytes sent per second on each connection (e.g. '256KB')")
+	flag.StringVar(&flags.DownloadBW, "download-bandwidth", "", "Cap the bytes received per second on each connection (e.g. '1MB')")
+	flag.BoolVar(&flags.Prewarm, "prewarm", false, "Open every user's connection to each host before measuring starts")
+
+	flag.Var(&flags.Percentiles, "percentiles", "Custom percentiles to report (comma-separated, e.g., '50,90,99,99.9')")
 	flag.Var(&flags.Percentiles, "p", "Custom percentiles (shorthand)")
 
 	// Phase 3 flags
 	flag.BoolVar(&flags.ShowHistogram, "histogram", false, "Show ASCII latency histogram in output")
-	flag.BoolVar(&flags.NoHdr, "no-hdr", false, "Disable HdrHistogram (use legacy in-memory stats)")
+	flag.StringVar(&flags.HistogramBuckets, "histogram-buckets", "", "Upper bounds of the histogram buckets (e.g. '100us,500us,1ms,30s'), or 'log' for log-scale buckets fitted to the latencies")
+	flag.BoolVar(&flags.NoHdr, "no-hdr", false, "Disable HdrHistogram (use a bounded sample of raw latencies)")
+	flag.Var(&flags.Metadata, "meta", "Run metadata added to results and metric labels, repeatable (format: 'key=value', e.g. 'gitSha=abc123')")
+	flag.Var(&flags.Tags, "tag", "Run tag added to results and metric labels, repeatable or comma-separated")
+	flag.BoolVar(&flags.Mergeable, "mergeable", false, "Embed the raw stats in JSON results so the merge subcommand can combine them")
+	flag.StringVar(&flags.HistoryDir, "history-dir", "", "Append the run's summary to history.jsonl in this directory, for the trend subcommand")
 
 	// Phase 4 flags
 	flag.BoolVar(&flags.HTTP2, "http2", false, "Enable HTTP/2 protocol")
+	flag.IntVar(&flags.H2Connections, "h2-connections", 0, "Spread HTTP/2 workers over N connections per host instead of one")
+	flag.StringVar(&flags.DNSCache, "dns-cache", "", "Resolve hosts before measuring and dial by IP: 'once' or a TTL to re-resolve after (e.g. '30s')")
+	flag.BoolVar(&flags.RoundRobinIPs, "round-robin-ips", false, "Spread connections over all resolved IPs of a host and report per-IP stats")
+	flag.StringVar(&flags.LocalAddrs, "local-addresses", "", "Spread connections over these local IPs, round-robin (comma-separated)")
+	flag.BoolVar(&flags.IPv4, "4", false, "Connect over IPv4 only")
+	flag.BoolVar(&flags.IPv6, "6", false, "Connect over IPv6 only")
+	flag.StringVar(&flags.Interface, "interface", "", "Send traffic out through this network interface (Linux only, e.g. 'eth1')")
+	flag.StringVar(&flags.Engine, "engine", "", "HTTP client engine: nethttp (default) or fasthttp (HTTP/1.1 only)")
+	flag.IntVar(&flags.GoMaxProcs, "gomaxprocs", 0, "Number of OS threads running Go code at once (default: one per usable CPU)")
+	flag.StringVar(&flags.CPUs, "cpus", "", "Pin the process to these CPUs (Linux only, e.g. '0-3,6')")
+	flag.BoolVar(&flags.ReserveCore, "reserve-core", false, "Keep the last of the --cpus for the stats and progress goroutines")
 	flag.BoolVar(&flags.ShowLiveStats, "live", false, "Show real-time stats during benchmark")
+	flag.StringVar(&flags.Dashboard, "dashboard", "", "Serve a live web dashboard on this address during the run (e.g. ':9090')")
+	flag.BoolVar(&flags.TUI, "tui", false, "Show a full-screen terminal dashboard instead of the progress bar")
+	flag.StringVar(&flags.Interval, "interval", "", "Print requests/sec, p50/p95/p99 and errors of the last interval this often (e.g. '30s')")
+
+	// Long run flags
+	flag.StringVar(&flags.Checkpoint, "checkpoint", "", "Periodically save progress to this file so the run can be resumed")
+	flag.StringVar(&flags.CheckpointInterval, "checkpoint-interval", "", "Time between checkpoints (e.g. '1m'; default: 30s)")
+	flag.StringVar(&flags.Resume, "resume", "", "Resume an interrupted run from this checkpoint file")
+	flag.StringVar(&flags.StopAfterBytes, "stop-after-bytes", "", "Stop once this much response data is received (e.g. '50GB')")
+	flag.StringVar(&flags.ExpectStatus, "expect-status", "", "Response statuses counted as success instead of 2xx (e.g. '404' or '401,403,5xx')")
+	flag.StringVar(&flags.MaxMemory, "max-memory", "", "Memory budget; fewer latency samples are kept when the heap nears it (e.g. '512MB')")
+	flag.BoolVar(&flags.Watch, "watch", false, "Rerun the benchmark whenever the config file changes, comparing each run to the previous one")
+	flag.StringVar(&flags.Until, "until", "", "Stop by this wall-clock time (RFC 3339, e.g. '2024-07-01T06:00:00Z')")
+
+	flag.IntVar(&flags.CaptureFailures, "capture-failures", 0, "Save the first N failing requests/responses per error category")
+	flag.StringVar(&flags.CaptureDir, "capture-dir", "", "Directory for captured failures (default: failures)")
+	flag.Float64Var(&flags.CaptureResponses, "capture-responses", 0, "Save this share of request/response pairs to disk (e.g. 0.01 for 1%)")
+	flag.StringVar(&flags.CaptureResponsesDir, "capture-responses-dir", "", "Directory for sampled responses (default: captures)")
+	flag.IntVar(&flags.ErrorSamples, "error-samples", 0, "Keep N example responses (status, URL, start of body) per error in JSON/HTML reports")
+	flag.BoolVar(&flags.CacheStats, "cache-stats", false, "Report cache hit ratio and hit vs miss latencies from X-Cache, CF-Cache-Status, Age and Via")
+	flag.StringVar(&flags.RequestID, "request-id", "", "Set this header to a unique ID on every request (e.g. 'X-Request-ID')")
+	flag.BoolVar(&flags.VerifyRequestID, "verify-request-id", false, "Fail responses that don't echo the request ID in the same header")
+
+	flag.StringVar(&flags.PluginsDir, "plugins-dir", "", "Directory of plugin manifests (custom protocols, auth and output sinks)")
+	flag.StringVar(&flags.AuthPlugin, "auth-plugin", "", "Plugin that supplies headers (e.g. tokens) for every request")
+	flag.StringVar(&flags.OutputPlugins, "output-plugin", "", "Sink plugins that also receive the results (comma-separated)")
+	flag.StringVar(&flags.Sinks, "sink", "", "Metrics sinks fed during the run: console, json:<file>, statsd:<host:port>, prometheus:<addr> (comma-separated)")
+
+	flag.StringVar(&flags.Script, "script", "", "wrk-style Lua script (setup, init, delay, request, response, done); arguments after -- go to init")
+	flag.StringVar(&flags.Script, "s", "", "wrk-style Lua script (shorthand)")
+	flag.StringVar(&flags.JSScript, "js", "", "JavaScript file with the jsRequest, jsCheck and iteration functions")
+
+	flag.StringVar(&flags.CheckBaseline, "check-baseline", "", "Fail if results regress beyond baselineThresholds compared to this JSON result file")
+
+	flag.StringVar(&flags.Controller, "controller", "", "Coordinate --workers worker instances, listening on this address (e.g. ':7000')")
+	flag.IntVar(&flags.Workers, "workers", 0, "Number of workers the controller waits for")
+	flag.BoolVar(&flags.Worker, "worker", false, "Run as a worker for a controller (requires --join)")
+	flag.StringVar(&flags.Join, "join", "", "Controller address to join as a worker (host:port)")
+
+	flag.StringVar(&flags.Listen, "listen", "", "Serve the REST control API on this address (e.g. ':8080') instead of running a benchmark")
 
 	flag.BoolVar(&flags.ShowHelp, "help", false, "Display help message")
 	flag.BoolVar(&flags.ShowHelp, "h", false, "Display help message (shorthand)")
@@ -117,6 +271,7 @@ func parseFlags() *CLIFlags {
 	flag.BoolVar(&flags.ShowVersion, "v", false, "Display version (shorthand)")
 
 	flag.Parse()
+	flags.ScriptArgs = flag.Args()
 
 	return flags
 }
@@ -128,6 +283,98 @@ func validateFlags(flags *CLIFlags) error {
 		return fmt.Errorf("--verbose and --quiet cannot be used together")
 	}
 
+	if flags.IPv4 && flags.IPv6 {
+		return fmt.Errorf("-4 and -6 cannot be used together")
+	}
+
+	if flags.Worker && flags.Join == "" {
+		return fmt.Errorf("--worker requires --join <controller address>")
+	}
+	if flags.Worker && flags.Controller != "" {
+		return fmt.Errorf("--worker and --controller cannot be used together")
+	}
+	if flags.Controller != "" && flags.Workers < 1 {
+		return fmt.Errorf("--controller requires --workers <number>")
+	}
+	if flags.Listen != "" && (flags.Worker || flags.Controller != "") {
+		return fmt.Errorf("--listen cannot be combined with --worker or --controller")
+	}
+	if flags.Dashboard != "" && (flags.Worker || flags.Controller != "") {
+		return fmt.Errorf("--dashboard is only available for local runs")
+	}
+	if flags.TUI && (flags.Worker || flags.Controller != "") {
+		return fmt.Errorf("--tui is only available for local runs")
+	}
+	if (flags.Checkpoint != "" || flags.Resume != "") && (flags.Worker || flags.Controller != "") {
+		return fmt.Errorf("--checkpoint and --resume are only available for local runs")
+	}
+	if flags.Watch {
+		if flags.ConfigFile == "" {
+			return fmt.Errorf("--watch requires --config")
+		}
+		if flags.Worker || flags.Controller != "" || flags.Listen != "" || flags.Resume != "" {
+			return fmt.Errorf("--watch cannot be combined with --worker, --controller, --listen or --resume")
+		}
+	}
+	for _, d := range []struct {
+		name, value string
+		min         time.Duration
+	}{
+		{"--duration", flags.Duration, time.Second},
+		{"--timeout", flags.Timeout, time.Millisecond},
+		{"--ramp-up", flags.RampUp, 0},
+	} {
+		if d.value == "" {
+			continue
+		}
+		value, err := time.ParseDuration(flagDuration(d.value))
+		if err != nil || value < 0 {
+			return fmt.Errorf("invalid %s %q: expected seconds or a duration such as 90s, 2m or 1h30m", d.name, d.value)
+		}
+		if value < d.min && (value != 0 || d.name == "--timeout") {
+			return fmt.Errorf("%s must be at least %s", d.name, d.min)
+		}
+	}
+	if flags.StopAfterBytes != "" {
+		if _, err := config.ParseByteSize(flags.StopAfterBytes); err != nil {
+			return fmt.Errorf("invalid --stop-after-bytes: %w", err)
+		}
+	}
+	if flags.UploadBW != "" {
+		if _, err := config.ParseByteSize(flags.UploadBW); err != nil {
+			return fmt.Errorf("invalid --upload-bandwidth: %w", err)
+		}
+	}
+	if flags.DownloadBW != "" {
+		if _, err := config.ParseByteSize(flags.DownloadBW); err != nil {
+			return fmt.Errorf("invalid --download-bandwidth: %w", err)
+		}
+	}
+	if flags.MaxMemory != "" {
+		if _, err := config.ParseByteSize(flags.MaxMemory); err != nil {
+			return fmt.Errorf("invalid --max-memory: %w", err)
+		}
+	}
+	if flags.ExpectStatus != "" {
+		if _, err := config.ParseStatusList(flags.ExpectStatus); err != nil {
+			return fmt.Errorf("invalid --expect-status: %w", err)
+		}
+	}
+	if flags.TUI && flags.VerboseMode {
+		return fmt.Errorf("--tui and --verbose cannot be used together")
+	}
+	if flags.GoMaxProcs < 0 {
+		return fmt.Errorf("--gomaxprocs must not be negative")
+	}
+	if flags.CPUs != "" {
+		if _, err := cpuset.Parse(flags.CPUs); err != nil {
+			return fmt.Errorf("invalid --cpus: %w", err)
+		}
+	}
+	if flags.ReserveCore && flags.CPUs == "" {
+		return fmt.Errorf("--reserve-core requires --cpus")
+	}
+
 	return nil
 }
 
@@ -135,7 +382,7 @@ func validateFlags(flags *CLIFlags) error {
 func setDefaults(flags *CLIFlags) {
 	// Set default percentiles if none specified
 	if len(flags.Percentiles) == 0 {
-		flags.Percentiles = []int{50, 75, 90, 99}
+		flags.Percentiles = []float64{50, 75, 90, 99}
 	}
 }
 
@@ -150,11 +397,22 @@ func loadConfiguration(flags *CLIFlags) (*config.Config, error) {
 			return nil, err
 		}
 		applyConfigOverrides(cfg, flags)
+		if flags.TargetsFile != "" {
+			if cfg.Requests, err = config.LoadTargets(flags.TargetsFile); err != nil {
+				return nil, err
+			}
+			cfg.SetDefaults()
+		}
+	} else if flags.TargetsFile != "" {
+		cfg, err = loadTargetsConfiguration(flags)
+		if err != nil {
+			return nil, err
+		}
 	} else if flags.URL != "" {
 		cfg = config.NewFromCLI(
 			flags.URL, flags.HTTPMethod, flags.Headers, flags.RequestBody, flags.ContentType,
-			flags.ConcurrentUsers, flags.RequestsPerUser, flags.DurationSeconds, flags.Insecure,
-			flags.OutputFormat, flags.OutputFile, flags.RateLimit, flags.RampUpSeconds,
+			flags.ConcurrentUsers, flags.RequestsPerUser, flagDuration(flags.Duration), flags.Insecure,
+			flags.OutputFormat, flags.OutputFile, flags.RateLimit, flagDuration(flags.RampUp),
 			flags.DisableKeepAlive, flags.Percentiles, flags.ShowHistogram, flags.NoHdr,
 			flags.HTTP2, flags.ShowLiveStats,
 		)
@@ -162,6 +420,85 @@ func loadConfiguration(flags *CLIFlags) (*config.Config, error) {
 		return nil, nil
 	}
 
+	if flags.Preset != "" {
+		if err := applyPreset(cfg, flags); err != nil {
+			return nil, err
+		}
+	}
+	applyCommonOverrides(cfg, flags)
+
+	// Load rotating header pools from files
+	for _, h := range flags.HeaderFiles {
+		pool, err := config.LoadHeaderPool(h.Value)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.RotateHeaders == nil {
+			cfg.RotateHeaders = make(config.HeaderPools)
+		}
+		cfg.RotateHeaders[h.Key] = pool
+	}
+
+	// Read stdin body once at startup (--body - or bodyFile: "-")
+	if err := cfg.ReadStdinBody(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// applyPreset applies --preset, then the load flags given explicitly on the
+// command line again, so they override the preset
+func applyPreset(cfg *config.Config, flags *CLIFlags) error {
+	if err := cfg.ApplyPreset(flags.Preset); err != nil {
+		return err
+	}
+	if flagWasSet("c", "concurrent-users") {
+		cfg.Settings.ConcurrentUsers = flags.ConcurrentUsers
+	}
+	if flagWasSet("d", "duration") {
+		cfg.Settings.Duration = flagDuration(flags.Duration)
+	} else if flagWasSet("r", "requests-per-user") {
+		// A request count instead of the preset's duration
+		cfg.Settings.Duration = ""
+		cfg.Settings.RequestsPerUser = flags.RequestsPerUser
+	}
+	if flags.RampUp != "" {
+		cfg.Settings.RampUp = flagDuration(flags.RampUp)
+	}
+	return nil
+}
+
+// flagWasSet reports whether any of the named flags was given on the command line
+func flagWasSet(names ...string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if slices.Contains(names, f.Name) {
+			set = true
+		}
+	})
+	return set
+}
+
+// loadTargetsConfiguration creates a configuration from a targets file and CLI settings.
+// Headers given with -H apply to every target unless the target overrides them.
+func loadTargetsConfiguration(flags *CLIFlags) (*config.Config, error) {
+	targets, err := config.LoadTargets(flags.TargetsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := config.NewFromCLI(
+		"", flags.HTTPMethod, flags.Headers, "", flags.ContentType,
+		flags.ConcurrentUsers, flags.RequestsPerUser, flagDuration(flags.Duration), flags.Insecure,
+		flags.OutputFormat, flags.OutputFile, flags.RateLimit, flagDuration(flags.RampUp),
+		flags.DisableKeepAlive, flags.Percentiles, flags.ShowHistogram, flags.NoHdr,
+		flags.HTTP2, flags.ShowLiveStats,
+	)
+	cfg.DefaultHeaders = cfg.Requests[0].Headers
+	cfg.Requests = targets
+	cfg.SetDefaults()
+
 	return cfg, nil
 }
 
@@ -173,8 +510,8 @@ func applyConfigOverrides(cfg *config.Config, flags *CLIFlags) {
 	if flags.RequestsPerUser != 100 {
 		cfg.Settings.RequestsPerUser = flags.RequestsPerUser
 	}
-	if flags.DurationSeconds > 0 {
-		cfg.Settings.Duration = fmt.Sprintf("%ds", flags.DurationSeconds)
+	if flags.Duration != "" {
+		cfg.Settings.Duration = flagDuration(flags.Duration)
 	}
 	if flags.Insecure {
 		cfg.Settings.Insecure = true
@@ -188,8 +525,8 @@ func applyConfigOverrides(cfg *config.Config, flags *CLIFlags) {
 	if flags.RateLimit > 0 {
 		cfg.Settings.RateLimit = flags.RateLimit
 	}
-	if flags.RampUpSeconds > 0 {
-		cfg.Settings.RampUp = fmt.Sprintf("%ds", flags.RampUpSeconds)
+	if flags.RampUp != "" {
+		cfg.Settings.RampUp = flagDuration(flags.RampUp)
 	}
 	if flags.DisableKeepAlive {
 		cfg.Settings.DisableKeepAlive = true
@@ -211,8 +548,185 @@ func applyConfigOverrides(cfg *config.Config, flags *CLIFlags) {
 	}
 }
 
+// applyCommonOverrides applies CLI flags that override settings for every configuration source
+func applyCommonOverrides(cfg *config.Config, flags *CLIFlags) {
+	if flags.CaptureFailures > 0 {
+		cfg.Settings.CaptureFailures = flags.CaptureFailures
+	}
+	if flags.CaptureDir != "" {
+		cfg.Settings.CaptureDir = flags.CaptureDir
+	}
+	if flags.CaptureResponses > 0 || flags.CaptureResponsesDir != "" {
+		if cfg.Settings.CaptureResponses == nil {
+			cfg.Settings.CaptureResponses = &config.ResponseCaptureConfig{}
+		}
+		if flags.CaptureResponses > 0 {
+			cfg.Settings.CaptureResponses.Rate = flags.CaptureResponses
+		}
+		if flags.CaptureResponsesDir != "" {
+			cfg.Settings.CaptureResponses.Dir = flags.CaptureResponsesDir
+		}
+	}
+	if flags.ErrorSamples > 0 {
+		cfg.Settings.ErrorSamples = flags.ErrorSamples
+	}
+	if flags.CacheStats {
+		cfg.Settings.CacheStats = true
+	}
+	if flags.HistogramBuckets != "" {
+		cfg.Settings.HistogramBuckets = flags.HistogramBuckets
+	}
+	if flags.RequestID != "" {
+		cfg.Settings.RequestIDHeader = flags.RequestID
+	}
+	if flags.VerifyRequestID {
+		cfg.Settings.VerifyRequestID = true
+	}
+	if flags.Dashboard != "" {
+		cfg.Settings.Dashboard = flags.Dashboard
+	}
+	if flags.Engine != "" {
+		cfg.Settings.Engine = flags.Engine
+	}
+	if flags.Goroutines != 0 {
+		cfg.Settings.Workers = flags.Goroutines
+	}
+	if flags.MaxInFlight != 0 {
+		cfg.Settings.MaxInFlight = flags.MaxInFlight
+	}
+	if flags.DNSCache != "" {
+		cfg.Settings.DNSCache = flags.DNSCache
+	}
+	if flags.RoundRobinIPs {
+		cfg.Settings.RoundRobinIPs = true
+	}
+	if flags.Mergeable {
+		cfg.Output.Mergeable = true
+	}
+	if flags.HistoryDir != "" {
+		cfg.Output.HistoryDir = flags.HistoryDir
+	}
+	if len(flags.Metadata) > 0 && cfg.Metadata == nil {
+		cfg.Metadata = make(map[string]string, len(flags.Metadata))
+	}
+	for _, meta := range flags.Metadata {
+		cfg.Metadata[meta.Key] = meta.Value
+	}
+	cfg.Tags = append(cfg.Tags, flags.Tags...)
+	if flags.IdleTimeout != "" {
+		cfg.Settings.IdleConnTimeout = flags.IdleTimeout
+	}
+	if flags.MaxIdle != 0 {
+		cfg.Settings.MaxIdleConns = flags.MaxIdle
+	}
+	if flags.MaxIdlePerHost != 0 {
+		cfg.Settings.MaxIdlePerHost = flags.MaxIdlePerHost
+	}
+	if flags.TCPKeepAlive != "" {
+		cfg.Settings.TCPKeepAlive = flags.TCPKeepAlive
+	}
+	if flags.ConnPerUser {
+		cfg.Settings.ConnectionPerUser = true
+	}
+	if flags.UploadBW != "" {
+		cfg.Settings.UploadBandwidth, _ = config.ParseByteSize(flags.UploadBW) // Validated in validateFlags
+	}
+	if flags.DownloadBW != "" {
+		cfg.Settings.DownloadBandwidth, _ = config.ParseByteSize(flags.DownloadBW) // Validated in validateFlags
+	}
+	if flags.IPv4 {
+		cfg.Settings.IPVersion = 4
+	}
+	if flags.IPv6 {
+		cfg.Settings.IPVersion = 6
+	}
+	if flags.Interface != "" {
+		cfg.Settings.Interface = flags.Interface
+	}
+	if flags.LocalAddrs != "" {
+		cfg.Settings.LocalAddresses = nil
+		for _, addr := range strings.Split(flags.LocalAddrs, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				cfg.Settings.LocalAddresses = append(cfg.Settings.LocalAddresses, addr)
+			}
+		}
+	}
+	if flags.H2Connections != 0 {
+		cfg.Settings.HTTP2Connections = flags.H2Connections
+	}
+	if flags.Prewarm {
+		cfg.Settings.Prewarm = true
+	}
+	if flags.GracePeriod != "" {
+		cfg.Settings.GracePeriod = flags.GracePeriod
+	}
+	if flags.TUI {
+		cfg.Settings.TUI = true
+	}
+	if flags.Interval != "" {
+		cfg.Settings.ReportInterval = flags.Interval
+	}
+	if flags.Checkpoint != "" {
+		cfg.Settings.Checkpoint = flags.Checkpoint
+	}
+	if flags.CheckpointInterval != "" {
+		cfg.Settings.CheckpointInterval = flags.CheckpointInterval
+	}
+	if flags.StopAfterBytes != "" {
+		cfg.Settings.StopAfterBytes, _ = config.ParseByteSize(flags.StopAfterBytes) // Validated in validateFlags
+	}
+	if flags.MaxMemory != "" {
+		cfg.Settings.MaxMemory, _ = config.ParseByteSize(flags.MaxMemory) // Validated in validateFlags
+	}
+	if flags.ExpectStatus != "" {
+		expected, _ := config.ParseStatusList(flags.ExpectStatus) // Validated in validateFlags
+		for i := range cfg.Requests {
+			if len(cfg.Requests[i].ExpectedStatus) == 0 {
+				cfg.Requests[i].ExpectedStatus = expected
+			}
+		}
+	}
+	if flags.Until != "" {
+		cfg.Settings.Until = flags.Until
+	}
+	if flags.PluginsDir != "" {
+		cfg.Settings.PluginsDir = flags.PluginsDir
+	}
+	if flags.AuthPlugin != "" {
+		cfg.Settings.AuthPlugin = flags.AuthPlugin
+	}
+	if flags.OutputPlugins != "" {
+		cfg.Output.Plugins = nil
+		for _, name := range strings.Split(flags.OutputPlugins, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.Output.Plugins = append(cfg.Output.Plugins, name)
+			}
+		}
+	}
+	if flags.Sinks != "" {
+		cfg.Output.Sinks = nil
+		for _, spec := range strings.Split(flags.Sinks, ",") {
+			if spec = strings.TrimSpace(spec); spec != "" {
+				cfg.Output.Sinks = append(cfg.Output.Sinks, spec)
+			}
+		}
+	}
+	if flags.Script != "" {
+		cfg.Settings.Script = flags.Script
+	}
+	if cfg.Settings.Script != "" && len(flags.ScriptArgs) > 0 {
+		cfg.Settings.ScriptArgs = flags.ScriptArgs
+	}
+	if flags.JSScript != "" {
+		cfg.Settings.JSScript = flags.JSScript
+	}
+	if flags.Resume != "" && cfg.Settings.Checkpoint == "" {
+		cfg.Settings.Checkpoint = flags.Resume // Keep checkpointing to the file being resumed
+	}
+}
+
 // isDefaultPercentiles checks if the percentiles are the default values
-func isDefaultPercentiles(percentiles []int) bool {
+func isDefaultPercentiles(percentiles []float64) bool {
 	return len(percentiles) == 4 &&
 		percentiles[0] == 50 &&
 		percentiles[1] == 75 &&
@@ -220,8 +734,17 @@ func isDefaultPercentiles(percentiles []int) bool {
 		percentiles[3] == 99
 }
 
+// flagDuration turns a duration flag into a Go duration string. Plain numbers
+// are seconds, as the flags always took; "90s", "1h30m" or "500ms" are kept.
+func flagDuration(value string) string {
+	if _, err := strconv.Atoi(value); err == nil {
+		return value + "s"
+	}
+	return value
+}
+
 // printConfiguration prints the benchmark configuration to console
-func printConfiguration(cfg *config.Config, durationSec, timeoutSec, rampUpSec int, verboseMode bool) {
+func printConfiguration(cfg *config.Config, durationSec int, timeout time.Duration, rampUpSec int, verboseMode bool) {
 	if cfg.Name != "" {
 		fmt.Printf("Benchmark: %s\n", cfg.Name)
 	}
@@ -234,7 +757,10 @@ func printConfiguration(cfg *config.Config, durationSec, timeoutSec, rampUpSec i
 		}
 	}
 	fmt.Printf("Concurrent users: %d\n", cfg.Settings.ConcurrentUsers)
-	fmt.Printf("Request timeout: %d seconds\n", timeoutSec)
+	if cfg.WorkerCount() != cfg.Settings.ConcurrentUsers || cfg.InFlightLimit() != cfg.WorkerCount() {
+		fmt.Printf("Workers: %d (at most %d requests in flight)\n", cfg.WorkerCount(), cfg.InFlightLimit())
+	}
+	fmt.Printf("Request timeout: %s\n", timeout)
 
 	if cfg.Settings.Insecure {
 		fmt.Println("TLS verification: disabled")
@@ -254,6 +780,9 @@ func printConfiguration(cfg *config.Config, durationSec, timeoutSec, rampUpSec i
 	} else {
 		fmt.Printf("Requests per user: %d\n", cfg.Settings.RequestsPerUser)
 	}
+	if deadline, err := cfg.GetUntil(); err == nil && !deadline.IsZero() {
+		fmt.Printf("Stop by: %s (in %s)\n", deadline.Local().Format("2006-01-02 15:04:05 MST"), time.Until(deadline).Round(time.Second))
+	}
 
 	if verboseMode {
 		fmt.Printf("Percentiles: %v\n", cfg.Settings.Percentiles)
@@ -282,4 +811,3 @@ func exitWithError(format string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, "Error: "+format+"\n", args...)
 	os.Exit(1)
 }
-
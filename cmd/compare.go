@@ -0,0 +1,150 @@
+// Package main is the entry point for the benchmarking tool
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+	"github.com/benchmarking_go/pkg/output"
+)
+
+// compareProgressInterval is how often the per-target request counts are printed
+const compareProgressInterval = time.Second
+
+// runTargets benchmarks every target of the config at the same time with identical
+// load, so environmental noise affects them equally, and prints them side by side
+func runTargets(cfg *config.Config, flags *CLIFlags, durationSec int) {
+	if flags.Controller != "" || flags.Resume != "" || flags.CheckBaseline != "" {
+		exitWithError("targets cannot be combined with --controller, --resume or --check-baseline")
+	}
+	if cfg.Output.Format != "" && cfg.Output.Format != "console" && cfg.Output.Format != "json" {
+		exitWithError("targets support console and json output only")
+	}
+	timeout, rampUpSec := effectiveTimeouts(cfg, flags)
+	quietMode := flags.QuietMode || cfg.Output.Format == "json"
+
+	runners := make([]*benchmark.Runner, len(cfg.Targets))
+	for i, target := range cfg.Targets {
+		targetCfg, err := cfg.ForTarget(target)
+		if err != nil {
+			exitWithError("%v", err)
+		}
+		targetCfg.ResolveRequestVariables()
+		// Several progress bars can't share the terminal; runTargets reports progress itself
+		runners[i] = benchmark.NewRunner(targetCfg, durationSec, timeout, rampUpSec, true, flags.VerboseMode)
+	}
+
+	if !quietMode {
+		printConfiguration(runners[0].Config, durationSec, timeout, rampUpSec, flags.VerboseMode)
+		fmt.Println("Targets:")
+		for _, target := range cfg.Targets {
+			fmt.Printf("  - %s: %s\n", target.Name, target.BaseURL)
+		}
+		fmt.Println()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupted := setupSignalHandler(cancel, quietMode)
+
+	stopProgress := func() {}
+	if !quietMode {
+		stopProgress = printTargetProgress(ctx, cfg.Targets, runners)
+	}
+	results := make([]output.TargetStats, len(runners))
+	var wg sync.WaitGroup
+	for i, runner := range runners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = output.TargetStats{Target: cfg.Targets[i], Stats: runner.Run(ctx)}
+		}()
+	}
+	wg.Wait()
+	stopProgress()
+
+	// Thresholds apply to every target on its own; any failing target fails the run
+	thresholdsFailed := false
+	for i, runner := range runners {
+		var thresholds *benchmark.ThresholdResults
+		if runner.Config.HasThresholds() {
+			var err error
+			thresholds, err = benchmark.EvaluateConfigThresholds(results[i].Stats, runner.Config)
+			if err != nil {
+				exitWithError("threshold evaluation failed for %s: %v", cfg.Targets[i].Name, err)
+			}
+		}
+		if reason := runner.AbortReason(); reason != "" {
+			if thresholds == nil {
+				thresholds = &benchmark.ThresholdResults{Passed: true}
+			}
+			thresholds.RecordAbort(reason)
+		}
+		if thresholds != nil && !thresholds.Passed {
+			thresholdsFailed = true
+		}
+		results[i].Thresholds = thresholds
+	}
+
+	if err := output.WriteComparison(results, cfg); err != nil {
+		exitWithError("%v", err)
+	}
+	if !quietMode {
+		for _, result := range results {
+			if result.Thresholds != nil && len(result.Thresholds.Results) > 0 {
+				fmt.Printf("\n%s:%s", result.Target.Name, result.Thresholds.FormatResults())
+			}
+		}
+	}
+
+	// The exit code policy sees the worst outcome over all targets
+	code := 0
+	for _, result := range results {
+		if c := exitCode(cfg.ExitCodes, result.Stats, interrupted.Load(), thresholdsFailed); c != 0 {
+			code = c
+			break
+		}
+	}
+	if code != 0 {
+		os.Exit(code)
+	}
+}
+
+// printTargetProgress prints the completed requests of every target once per
+// interval on a single updating line. The returned function stops it.
+func printTargetProgress(ctx context.Context, targets []config.TargetConfig, runners []*benchmark.Runner) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(compareProgressInterval)
+		defer ticker.Stop()
+
+		start := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				fmt.Println()
+				return
+			case <-ticker.C:
+			}
+			parts := make([]string, len(runners))
+			for i, runner := range runners {
+				completed := atomic.LoadInt64(&runner.Stats.SuccessCount) + atomic.LoadInt64(&runner.Stats.FailureCount)
+				parts[i] = fmt.Sprintf("%s: %d", targets[i].Name, completed)
+			}
+			fmt.Printf("\r[%3.0fs] %s", time.Since(start).Seconds(), strings.Join(parts, "  "))
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,83 @@
+// Package main is the entry point for the benchmarking tool
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/benchmarking_go/pkg/output"
+)
+
+// convertExtensions maps output file extensions to the format they imply
+var convertExtensions = map[string]string{
+	".html": "html",
+	".htm":  "html",
+	".md":   "markdown",
+	".csv":  "csv",
+	".xml":  "junit",
+}
+
+// runConvert runs the `convert` subcommand: render a saved JSON result in
+// another format without rerunning the benchmark
+func runConvert(args []string) {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	outputFile := fs.String("o", "", "Output file (default: stdout)")
+	format := fs.String("format", "", "Target format: "+strings.Join(output.ConvertFormats, ", ")+" (default: from the output file extension)")
+	fs.Usage = displayConvertHelp
+	files := parseInterspersed(fs, args)
+	if len(files) != 1 {
+		displayConvertHelp()
+		exitWithError("expected one result file")
+	}
+
+	if *format == "" {
+		*format = convertExtensions[strings.ToLower(filepath.Ext(*outputFile))]
+		if *format == "" {
+			exitWithError("--format is required unless the output file ends in .html, .md, .csv or .xml")
+		}
+	}
+
+	result, err := output.LoadResult(files[0])
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *outputFile != "" {
+		file, err := os.Create(*outputFile)
+		if err != nil {
+			exitWithError("error creating output file: %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := output.ConvertResult(w, result, *format); err != nil {
+		exitWithError("%v", err)
+	}
+	if *outputFile != "" {
+		fmt.Fprintf(os.Stderr, "%s report saved to: %s\n", *format, *outputFile)
+	}
+}
+
+// displayConvertHelp shows the help message for the convert subcommand
+func displayConvertHelp() {
+	fmt.Println("Usage: benchmarking_go convert [options] <result.json>")
+	fmt.Println()
+	fmt.Println("Renders a JSON result saved with --output json in another format, so a")
+	fmt.Println("different report doesn't require rerunning the benchmark. Reports contain")
+	fmt.Println("what the JSON result holds; the HTML report has no latency histogram.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -o <file>                        Output file (default: stdout)")
+	fmt.Println("  --format <format>                html, markdown, csv or junit (default: from the extension")
+	fmt.Println("                                   of the output file: .html, .md, .csv or .xml)")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  benchmarking_go convert release.json -o release.html")
+	fmt.Println("  benchmarking_go convert release.json --format markdown >> $GITHUB_STEP_SUMMARY")
+	fmt.Println("  benchmarking_go convert release.json -o junit-benchmark.xml")
+}
@@ -0,0 +1,30 @@
+// Package main is the entry point for the benchmarking tool
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+	"github.com/benchmarking_go/pkg/dashboard"
+)
+
+// startDashboard serves the live web dashboard when one is configured and
+// returns a function that stops it
+func startDashboard(ctx context.Context, cfg *config.Config, stats *benchmark.Stats, quietMode bool) func() {
+	if cfg.Settings.Dashboard == "" {
+		return func() {}
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+	server, err := dashboard.Start(ctx, cfg.Settings.Dashboard, stats, cfg)
+	if err != nil {
+		stop()
+		exitWithError("%v", err)
+	}
+	if !quietMode {
+		fmt.Printf("Live dashboard: %s\n", server.URL())
+	}
+	return stop
+}
@@ -7,39 +7,124 @@ import "fmt"
 func displayHelp() {
 	fmt.Printf("Benchmarking Go HTTP Client v%s\n", version)
 	fmt.Println("Usage: benchmarking_go [options]")
+	fmt.Println("       benchmarking_go record [options]   Record traffic through a proxy into a scenario config")
+	fmt.Println("       benchmarking_go schedule <file>    Run benchmarks on cron schedules as a daemon")
+	fmt.Println("       benchmarking_go k8s <config>       Run distributed on Kubernetes worker pods")
+	fmt.Println("       benchmarking_go serve [options]    Start a local test server with configurable latency and statuses")
+	fmt.Println("       benchmarking_go merge <results...> Combine JSON results of several hosts into one report")
+	fmt.Println("       benchmarking_go convert <result>   Render a saved JSON result as HTML, Markdown, CSV or JUnit")
+	fmt.Println("       benchmarking_go trend <history>    Report requests/sec and p99 across the runs of a history directory")
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  -u, --url <url>                  The URL to benchmark")
 	fmt.Println("  -c, --concurrent-users <number>  Number of concurrent users (default: 10)")
+	fmt.Println("  --goroutines <number>            Workers sending requests (default: one per user)")
+	fmt.Println("  --max-in-flight <number>         Most requests in flight at once across all workers (default: one per worker)")
 	fmt.Println("  -r, --requests-per-user <number> Number of requests per user (default: 100)")
-	fmt.Println("  -d, --duration <seconds>         Duration in seconds for the benchmark")
+	fmt.Println("  -d, --duration <duration>        Duration of the benchmark (seconds, or e.g. '90s', '1h30m')")
+	fmt.Println("  --preset <name>                  Apply a preset: smoke, load, stress or soak (explicit flags override it)")
 	fmt.Println("  -m, --method <GET|POST|PUT|...>  HTTP method to use (default: GET)")
 	fmt.Println("  -H, --header <header:value>      Custom header to include in the request")
-	fmt.Println("  -b, --body <text>                Request body for POST/PUT")
+	fmt.Println("  --header-file <header:path>      Rotate header values read from a file (one per line)")
+	fmt.Println("  -b, --body <text>                Request body for POST/PUT ('-' reads from stdin)")
 	fmt.Println("  -t, --content-type <type>        Content-Type of the request body")
-	fmt.Println("  --timeout <seconds>              Timeout in seconds for each request (default: 30)")
+	fmt.Println("  --expect-status <statuses>       Statuses counted as success instead of 2xx (e.g. '404' or '401,403,5xx')")
+	fmt.Println("  --timeout <duration>             Timeout for each request (seconds, or e.g. '500ms'; default: 30s)")
 	fmt.Println("  --config <file>                  Path to JSON configuration file")
+	fmt.Println("  --targets <file>                 Path to vegeta-style plain-text targets file")
 	fmt.Println("  -o, --output <format>            Output format: json, csv, html, or empty for console")
 	fmt.Println("  --output-file <file>             Output file path (default: stdout)")
+	fmt.Println("  --meta <key=value>               Run metadata for results and metric labels (e.g. 'gitSha=abc123'), repeatable")
+	fmt.Println("  --tag <tag>                      Run tag for results and metric labels, repeatable or comma-separated")
+	fmt.Println("  --mergeable                      Embed the raw stats in JSON results so `merge` can combine them")
+	fmt.Println("  --history-dir <dir>              Append the run's summary to <dir>/history.jsonl (see `trend`)")
 	fmt.Println("  -k, --insecure                   Skip TLS certificate verification")
 	fmt.Println()
 	fmt.Println("Rate & Connection Options:")
 	fmt.Println("  -R, --rate <number>              Rate limit in requests per second (0 = unlimited)")
-	fmt.Println("  --ramp-up <seconds>              Gradually start workers over this duration")
+	fmt.Println("  --ramp-up <duration>             Gradually start workers over this duration (seconds, or e.g. '2m')")
+	fmt.Println("  --grace-period <duration>        Time in-flight requests get to finish when stopping (default: timeout)")
 	fmt.Println("  --disable-keepalive              Disable HTTP keep-alive connections")
+	fmt.Println("  --idle-timeout <duration>        Close pooled connections idle for this long (default: keep them)")
+	fmt.Println("  --max-idle <n>                   Idle connections kept across all hosts (default: one per in-flight request)")
+	fmt.Println("  --max-idle-per-host <n>          Idle connections kept per host (default: one per in-flight request)")
+	fmt.Println("  --tcp-keepalive <interval|off>   Interval of TCP keep-alive probes (default: 30s)")
+	fmt.Println("  --connection-per-user            Give every user a dedicated connection instead of sharing a pool")
+	fmt.Println("  --upload-bandwidth <size>        Cap the bytes sent per second on each connection (e.g. '256KB')")
+	fmt.Println("  --download-bandwidth <size>      Cap the bytes received per second on each connection (e.g. '1MB')")
+	fmt.Println("  --prewarm                        Open the connections before measuring (no handshakes in the results)")
+	fmt.Println()
+	fmt.Println("Long Run Options:")
+	fmt.Println("  --checkpoint <file>              Periodically save progress so an interrupted run can be resumed")
+	fmt.Println("  --checkpoint-interval <duration> Time between checkpoints (default: 30s)")
+	fmt.Println("  --resume <file>                  Resume an interrupted run from a checkpoint")
+	fmt.Println("  --stop-after-bytes <size>        Stop once this much response data is received (e.g. '50GB')")
+	fmt.Println("  --max-memory <size>              Memory budget; fewer latency samples are kept near it (e.g. '512MB')")
+	fmt.Println("  --until <time>                   Stop by this wall-clock time (e.g. '2024-07-01T06:00:00Z')")
+	fmt.Println("  --watch                          Rerun whenever the config file changes, comparing with the previous run")
 	fmt.Println()
 	fmt.Println("Output Options:")
 	fmt.Println("  -q, --quiet                      Quiet mode - only show final summary line")
 	fmt.Println("  -V, --verbose                    Verbose mode - show detailed request info")
 	fmt.Println("  -p, --percentiles <list>         Custom percentiles (e.g., '50,90,95,99,99.9')")
 	fmt.Println("  --histogram                      Show ASCII latency histogram in output")
+	fmt.Println("  --histogram-buckets <list|log>   Histogram bucket bounds (e.g. '100us,1ms,30s') or 'log' (default: 1ms to 10s)")
 	fmt.Println("  --live                           Show real-time stats during benchmark")
+	fmt.Println("  --dashboard <addr>               Serve a live web dashboard during the run (e.g. ':9090')")
+	fmt.Println("  --tui                            Full-screen terminal dashboard instead of the progress bar (p pauses)")
+	fmt.Println("  --interval <duration>            Print requests/sec, percentiles and errors every interval (e.g. '30s')")
 	fmt.Println()
 	fmt.Println("Protocol Options:")
 	fmt.Println("  --http2                          Enable HTTP/2 protocol")
+	fmt.Println("  --h2-connections <n>             Spread HTTP/2 workers over n connections per host (default: one)")
+	fmt.Println("  --dns-cache <once|ttl>           Resolve hosts before measuring and dial by IP, re-resolving after ttl (e.g. '30s')")
+	fmt.Println("  --round-robin-ips                Spread connections over all resolved IPs of a host and report per-IP stats")
+	fmt.Println("  --local-addresses <ips>          Spread connections over these local IPs, round-robin (comma-separated)")
+	fmt.Println("  -4                               Connect over IPv4 only")
+	fmt.Println("  -6                               Connect over IPv6 only")
+	fmt.Println("  --interface <name>               Send traffic out through this network interface (Linux only, e.g. 'eth1')")
+	fmt.Println("  --engine <nethttp|fasthttp>      HTTP client engine (default: nethttp; fasthttp is HTTP/1.1 only)")
+	fmt.Println()
+	fmt.Println("CPU Options:")
+	fmt.Println("  --gomaxprocs <number>            Number of OS threads running Go code at once (default: one per usable CPU)")
+	fmt.Println("  --cpus <list>                    Pin the process to these CPUs (Linux only, e.g. '0-3,6')")
+	fmt.Println("  --reserve-core                   Keep the last of the --cpus for the stats and progress goroutines")
 	fmt.Println()
 	fmt.Println("Statistics Options:")
-	fmt.Println("  --no-hdr                         Disable HdrHistogram (use legacy in-memory stats)")
+	fmt.Println("  --no-hdr                         Disable HdrHistogram (use a bounded sample of raw latencies)")
+	fmt.Println("  --cache-stats                    Report cache hit ratio and hit vs miss latencies from CDN cache headers")
+	fmt.Println("  --sink <specs>                   Feed metrics sinks during the run: console, json:<file>,")
+	fmt.Println("                                   requests:<file>, statsd:<host:port>, prometheus:<addr> (comma-separated)")
+	fmt.Println()
+	fmt.Println("Debugging Options:")
+	fmt.Println("  --capture-failures <number>      Save the first N failing requests/responses per error category")
+	fmt.Println("  --capture-dir <dir>              Directory for captured failures (default: failures)")
+	fmt.Println("  --capture-responses <rate>       Save this share of request/response pairs to disk (e.g. 0.01 for 1%)")
+	fmt.Println("  --capture-responses-dir <dir>    Directory for sampled responses (default: captures)")
+	fmt.Println("  --error-samples <number>         Keep N example responses (status, URL, start of body) per error in JSON/HTML reports")
+	fmt.Println("  --request-id <header>            Set this header to a unique ID on every request (e.g. X-Request-ID)")
+	fmt.Println("  --verify-request-id              Fail responses that don't echo the request ID in the same header")
+	fmt.Println()
+	fmt.Println("Plugin Options:")
+	fmt.Println("  --plugins-dir <dir>              Directory of plugin manifests (protocols, auth, sinks)")
+	fmt.Println("  --auth-plugin <name>             Plugin that supplies headers for every request")
+	fmt.Println("  --output-plugin <names>          Sink plugins that also receive the results")
+	fmt.Println()
+	fmt.Println("Scripting Options:")
+	fmt.Println("  -s, --script <file>              wrk-style Lua script; arguments after -- are passed to init")
+	fmt.Println("  --js <file>                      JavaScript file with jsRequest, jsCheck and iteration functions")
+	fmt.Println()
+	fmt.Println("Distributed Options:")
+	fmt.Println("  --controller <addr>              Coordinate workers, listening on this address (e.g. ':7000')")
+	fmt.Println("  --workers <number>               Number of workers the controller waits for")
+	fmt.Println("  --worker                         Run as a worker (the controller sends the config)")
+	fmt.Println("  --join <host:port>               Controller address to join as a worker")
+	fmt.Println()
+	fmt.Println("Control API:")
+	fmt.Println("  --listen <addr>                  Serve a REST API to start, watch and stop runs (e.g. ':8080')")
+	fmt.Println()
+	fmt.Println("CI Options:")
+	fmt.Println("  --check-baseline <file>          Fail if results regress against a previous JSON result")
 	fmt.Println()
 	fmt.Println("Other:")
 	fmt.Println("  -v, --version                    Display version")
@@ -63,9 +148,18 @@ func displayExamples() {
 	fmt.Println("  # Custom percentiles")
 	fmt.Println("  benchmarking_go -u https://example.com -c 10 -d 30 -p 50,90,95,99")
 	fmt.Println()
+	fmt.Println("  # POST a body piped from stdin")
+	fmt.Println("  generate-payload | benchmarking_go -u https://example.com -m POST -b -")
+	fmt.Println()
+	fmt.Println("  # Rotate User-Agent values from a file")
+	fmt.Println("  benchmarking_go -u https://example.com -c 10 -d 30 --header-file User-Agent:agents.txt")
+	fmt.Println()
 	fmt.Println("  # Using JSON configuration file")
 	fmt.Println("  benchmarking_go --config benchmark.json")
 	fmt.Println()
+	fmt.Println("  # Using a vegeta-style targets file")
+	fmt.Println("  benchmarking_go --targets targets.txt -c 20 -d 30")
+	fmt.Println()
 	fmt.Println("  # JSON output for CI/CD")
 	fmt.Println("  benchmarking_go --config benchmark.json -o json > results.json")
 	fmt.Println()
@@ -81,9 +175,25 @@ func displayExamples() {
 	fmt.Println("  # Show live stats during benchmark")
 	fmt.Println("  benchmarking_go -u https://example.com -c 10 -d 30 --live")
 	fmt.Println()
+	fmt.Println("  # Watch the run in a browser at http://localhost:9090/")
+	fmt.Println("  benchmarking_go -u https://example.com -c 10 -d 60 --dashboard :9090")
+	fmt.Println()
 	fmt.Println("  # Use HTTP/2 protocol")
 	fmt.Println("  benchmarking_go -u https://example.com -c 10 -d 30 --http2")
 	fmt.Println()
+	fmt.Println("  # Save the first 5 failing exchanges per error (e.g. HTTP 422) for debugging")
+	fmt.Println("  benchmarking_go -u https://example.com -c 10 -d 30 --capture-failures 5")
+	fmt.Println()
+	fmt.Println("  # Fail the build if p99 latency or throughput regressed against the last release")
+	fmt.Println("  benchmarking_go --config bench.json --check-baseline release.json")
+	fmt.Println()
+	fmt.Println("  # Spread the load over 3 machines: start the controller, then a worker on each")
+	fmt.Println("  benchmarking_go --config bench.json --controller :7000 --workers 3")
+	fmt.Println("  benchmarking_go --worker --join controller-host:7000")
+	fmt.Println()
+	fmt.Println("  # Let other services start benchmarks over HTTP")
+	fmt.Println("  benchmarking_go --listen :8080")
+	fmt.Println()
 	fmt.Println("  # Generate HTML report")
 	fmt.Println("  benchmarking_go -u https://example.com -c 10 -d 30 -o html")
 }
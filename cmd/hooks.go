@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+	"github.com/benchmarking_go/pkg/output"
+)
+
+// hookVariable matches the name=value lines a pre-run hook prints to set variables
+var hookVariable = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+
+// runPreRunHook runs hooks.preRun before the benchmark. Lines it prints as
+// name=value set variables (e.g. a fetched token); its other output goes to
+// stderr. A failure aborts the benchmark.
+func runPreRunHook(cfg *config.Config, durationSec int) error {
+	if cfg.Hooks == nil || len(cfg.Hooks.PreRun) == 0 {
+		return nil
+	}
+	var stdout bytes.Buffer
+	if err := runHook(cfg, cfg.Hooks.PreRun, hookEnv(cfg, "preRun", durationSec), &stdout); err != nil {
+		return fmt.Errorf("preRun hook failed: %w", err)
+	}
+
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := hookVariable.FindStringSubmatch(line); m != nil {
+			if cfg.Variables == nil {
+				cfg.Variables = make(map[string]string)
+			}
+			cfg.Variables[m[1]] = m[2]
+			continue
+		}
+		fmt.Fprintln(os.Stderr, line)
+	}
+	return nil
+}
+
+// runPostRunHook runs hooks.postRun once the outcome is known and before the
+// results are written, with the results in a JSON file. Unless
+// ignorePostRunFailure is set, a failure is recorded as a failed check, so it
+// shows in the reports and the exit code. Returns the updated threshold results.
+func runPostRunHook(cfg *config.Config, durationSec int, stats *benchmark.Stats, thresholds *benchmark.ThresholdResults, interrupted bool, abortReason string, quietMode bool) *benchmark.ThresholdResults {
+	if cfg.Hooks == nil || len(cfg.Hooks.PostRun) == 0 {
+		return thresholds
+	}
+
+	env := hookEnv(cfg, "postRun", durationSec)
+	passed := thresholds == nil || thresholds.Passed
+	env = append(env,
+		"BENCH_TOTAL_REQUESTS="+strconv.FormatInt(stats.TotalRequests, 10),
+		"BENCH_FAILURES="+strconv.FormatInt(stats.FailureCount, 10),
+		"BENCH_RPS="+strconv.FormatFloat(stats.RequestsPerSecond, 'f', 2, 64),
+		"BENCH_AVG_LATENCY_MS="+strconv.FormatFloat(stats.AverageResponseTime()/1000, 'f', 3, 64),
+		"BENCH_PASSED="+strconv.FormatBool(passed),
+		"BENCH_INTERRUPTED="+strconv.FormatBool(interrupted),
+		"BENCH_ABORT_REASON="+abortReason,
+	)
+
+	err := func() error {
+		file, err := writeHookResults(stats, cfg, thresholds)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(file)
+		return runHook(cfg, cfg.Hooks.PostRun, append(env, "BENCH_RESULTS_FILE="+file), os.Stderr)
+	}()
+	if err == nil {
+		return thresholds
+	}
+
+	if cfg.Hooks.IgnorePostRunFailure {
+		if !quietMode {
+			fmt.Printf("Warning: postRun hook failed: %v\n", err)
+		}
+		return thresholds
+	}
+	if thresholds == nil {
+		thresholds = &benchmark.ThresholdResults{Passed: true}
+	}
+	thresholds.RecordFailure("Post-run Hook", err.Error(), "success")
+	return thresholds
+}
+
+// writeHookResults writes the results, as in the JSON report, to a temporary file
+func writeHookResults(stats *benchmark.Stats, cfg *config.Config, thresholds *benchmark.ThresholdResults) (string, error) {
+	result := output.ToJSONResult(stats, cfg)
+	result.Thresholds = output.ToThresholdSummary(thresholds)
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("error encoding JSON: %w", err)
+	}
+	file, err := os.CreateTemp("", "benchmark-results-*.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to write results for the postRun hook: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write results for the postRun hook: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// runHook runs a hook command with the run's environment, its stderr going to
+// ours. The error includes the exit status.
+func runHook(cfg *config.Config, args, env []string, stdout io.Writer) error {
+	timeout, _ := cfg.Hooks.GetTimeout() // Validated with the config
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = env
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s timed out after %s", args[0], timeout)
+	}
+	return err
+}
+
+// hookEnv returns our environment plus the BENCH_* variables describing the run
+func hookEnv(cfg *config.Config, phase string, durationSec int) []string {
+	target := cfg.BaseURL
+	if target == "" && len(cfg.Requests) > 0 {
+		target = cfg.Requests[0].URL
+	}
+	mode := "requests"
+	if cfg.IsScenarioMode() {
+		mode = "scenario"
+	}
+	return append(os.Environ(),
+		"BENCH_PHASE="+phase,
+		"BENCH_NAME="+cfg.Name,
+		"BENCH_MODE="+mode,
+		"BENCH_TARGET="+target,
+		"BENCH_USERS="+strconv.Itoa(cfg.Settings.ConcurrentUsers),
+		"BENCH_REQUESTS_PER_USER="+strconv.Itoa(cfg.Settings.RequestsPerUser),
+		"BENCH_DURATION="+strconv.Itoa(durationSec),
+	)
+}
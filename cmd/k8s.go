@@ -0,0 +1,202 @@
+// Package main is the entry point for the benchmarking tool
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+	"github.com/benchmarking_go/pkg/distributed"
+	"github.com/benchmarking_go/pkg/k8s"
+)
+
+// k8sTeardownTimeout bounds how long removing the worker pods may take
+const k8sTeardownTimeout = 30 * time.Second
+
+// runK8s runs the `k8s` subcommand: a distributed run whose workers are started
+// as pods on a Kubernetes cluster and removed again afterwards
+func runK8s(args []string) {
+	fs := flag.NewFlagSet("k8s", flag.ExitOnError)
+	workers := fs.Int("workers", 3, "Number of worker pods")
+	image := fs.String("image", k8s.DefaultImage, "Worker container image")
+	namespace := fs.String("namespace", "default", "Namespace for the worker pods")
+	kubeContext := fs.String("context", "", "kubeconfig context (default: current context)")
+	manifestFile := fs.String("manifest", "", "Worker manifest template (default: built-in Job)")
+	listen := fs.String("listen", ":7000", "Address the controller listens on")
+	advertise := fs.String("advertise", "", "Address the pods use to reach the controller (default: this host's name and the listen port)")
+	kubectl := fs.String("kubectl", "kubectl", "kubectl binary")
+	quiet := fs.Bool("quiet", false, "Only print the results")
+	fs.Usage = displayK8sHelp
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		displayK8sHelp()
+		exitWithError("expected one config file")
+	}
+	if *workers < 1 {
+		exitWithError("--workers must be at least 1")
+	}
+
+	cfg, err := config.Load(fs.Arg(0))
+	if err != nil {
+		exitWithError("%v", err)
+	}
+	durationSec, err := validateConfig(cfg)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+	if len(cfg.Targets) > 0 {
+		exitWithError("targets are not supported in k8s runs")
+	}
+	if err := distributed.CheckWorkers(cfg, *workers); err != nil {
+		exitWithError("%v", err)
+	}
+	cfg.ResolveRequestVariables()
+
+	controllerAddr, err := advertiseAddress(*advertise, *listen)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+	manifestTemplate := ""
+	if *manifestFile != "" {
+		if manifestTemplate, err = k8s.LoadManifest(*manifestFile); err != nil {
+			exitWithError("%v", err)
+		}
+	}
+	manifest, err := k8s.RenderManifest(manifestTemplate, k8s.ManifestData{
+		Name:       fmt.Sprintf("benchmarking-go-%d", time.Now().Unix()),
+		Namespace:  *namespace,
+		Image:      *image,
+		Workers:    *workers,
+		Controller: controllerAddr,
+	})
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	quietMode := *quiet || cfg.Output.Format == "json" || cfg.Output.Format == "csv"
+	if !quietMode {
+		printConfiguration(cfg, durationSec, cfg.GetTimeout(), cfg.GetRampUpSeconds(), false)
+		fmt.Printf("Workers: %d pod(s) in namespace %s, joining %s\n\n", *workers, *namespace, controllerAddr)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupted := setupSignalHandler(cancel, quietMode)
+
+	cluster := &k8s.Cluster{Kubectl: *kubectl, Namespace: *namespace, Context: *kubeContext}
+	controller := distributed.NewController(*listen, *workers, cfg, durationSec, cfg.GetTimeout(), cfg.GetRampUpSeconds(), quietMode)
+	stats, err := runK8sWorkers(ctx, cluster, manifest, controller, quietMode)
+	if stats == nil {
+		exitWithError("%v", err)
+	}
+	if err != nil && !quietMode {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	var thresholds *benchmark.ThresholdResults
+	if cfg.HasThresholds() {
+		if thresholds, err = benchmark.EvaluateConfigThresholds(stats, cfg); err != nil {
+			exitWithError("threshold evaluation failed: %v", err)
+		}
+	}
+	if reason := controller.AbortReason(); reason != "" {
+		if thresholds == nil {
+			thresholds = &benchmark.ThresholdResults{Passed: true}
+		}
+		thresholds.RecordAbort(reason)
+	}
+
+	writeResults(stats, cfg, *quiet, thresholds)
+	thresholdsFailed := false
+	if thresholds != nil {
+		if !quietMode {
+			fmt.Print(thresholds.FormatResults())
+		}
+		thresholdsFailed = !thresholds.Passed
+	}
+	if code := exitCode(cfg.ExitCodes, stats, interrupted.Load(), thresholdsFailed); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// runK8sWorkers starts the worker pods, runs the controller until they reported
+// and removes the pods again, also when the run fails or is interrupted
+func runK8sWorkers(ctx context.Context, cluster *k8s.Cluster, manifest []byte, controller *distributed.Controller, quietMode bool) (*benchmark.Stats, error) {
+	type outcome struct {
+		stats *benchmark.Stats
+		err   error
+	}
+	// The controller listens before the pods exist, so early pods can join right away
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan outcome, 1)
+	go func() {
+		stats, err := controller.Run(runCtx)
+		done <- outcome{stats, err}
+	}()
+
+	if err := cluster.Apply(ctx, manifest); err != nil {
+		cancel()
+		<-done
+		return nil, err
+	}
+	defer func() {
+		if !quietMode {
+			fmt.Println("Removing worker pods...")
+		}
+		teardownCtx, cancel := context.WithTimeout(context.Background(), k8sTeardownTimeout)
+		defer cancel()
+		if err := cluster.Delete(teardownCtx, manifest); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}()
+	result := <-done
+	return result.stats, result.err
+}
+
+// advertiseAddress returns the host:port the worker pods join. Without an explicit
+// address it combines this host's name with the listen port.
+func advertiseAddress(advertise, listen string) (string, error) {
+	if advertise != "" {
+		return advertise, nil
+	}
+	_, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return "", fmt.Errorf("invalid --listen address %q: %w", listen, err)
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine the controller address, set --advertise: %w", err)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// displayK8sHelp shows the help message for the k8s subcommand
+func displayK8sHelp() {
+	fmt.Println("Usage: benchmarking_go k8s [options] <config.json>")
+	fmt.Println()
+	fmt.Println("Runs a distributed benchmark on a Kubernetes cluster: starts the worker pods")
+	fmt.Println("with kubectl, hands each one its share of the config, merges their results")
+	fmt.Println("and deletes the pods again. The pods must be able to reach this controller.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --workers <n>                    Number of worker pods (default: 3)")
+	fmt.Printf("  --image <image>                  Worker container image (default: %s)\n", k8s.DefaultImage)
+	fmt.Println("  --namespace <ns>                 Namespace for the worker pods (default: default)")
+	fmt.Println("  --context <name>                 kubeconfig context (default: current context)")
+	fmt.Println("  --manifest <file>                Worker manifest template (default: built-in Job)")
+	fmt.Println("  --listen <addr>                  Address the controller listens on (default: :7000)")
+	fmt.Println("  --advertise <host:port>          Address the pods use to reach the controller")
+	fmt.Println("                                   (default: this host's name and the listen port)")
+	fmt.Println("  --kubectl <path>                 kubectl binary (default: kubectl)")
+	fmt.Println("  --quiet                          Only print the results")
+	fmt.Println()
+	fmt.Println("Manifest templates use Go template syntax with the fields .Name, .Namespace,")
+	fmt.Println(".Image, .Workers and .Controller. Worker containers must run:")
+	fmt.Println("  benchmarking_go --worker --join {{.Controller}}")
+}
@@ -6,15 +6,51 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"runtime"
+	"sync/atomic"
+	"time"
 
 	"github.com/benchmarking_go/pkg/benchmark"
 	"github.com/benchmarking_go/pkg/config"
+	"github.com/benchmarking_go/pkg/cpuset"
+	"github.com/benchmarking_go/pkg/distributed"
+	"github.com/benchmarking_go/pkg/metrics"
 	"github.com/benchmarking_go/pkg/output"
 )
 
 const version = "2.2.0"
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "record" {
+		runRecord(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "schedule" {
+		runSchedule(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "k8s" {
+		runK8s(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		runServe(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "merge" {
+		runMerge(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "convert" {
+		runConvert(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "trend" {
+		runTrend(os.Args[2:])
+		return
+	}
+
 	// Parse command line flags
 	flags := parseFlags()
 
@@ -27,10 +63,31 @@ func main() {
 	if err := validateFlags(flags); err != nil {
 		exitWithError("%v", err)
 	}
+	if err := applyCPUFlags(flags); err != nil {
+		exitWithError("%v", err)
+	}
+
+	// Workers get their configuration from the controller
+	if flags.Worker {
+		runWorker(flags)
+		return
+	}
+
+	// In API mode benchmarks are started through HTTP requests
+	if flags.Listen != "" {
+		runAPIServer(flags)
+		return
+	}
 
 	// Set default values
 	setDefaults(flags)
 
+	// In watch mode the benchmark reruns whenever the config file changes
+	if flags.Watch {
+		runWatch(flags)
+		return
+	}
+
 	// Load or create configuration
 	cfg, err := loadConfiguration(flags)
 	if err != nil {
@@ -42,20 +99,47 @@ func main() {
 		return
 	}
 
-	// Parse duration and timeout
-	durationSec, err := cfg.GetDurationSeconds()
+	// Parse duration and validate the settings
+	durationSec, err := validateConfig(cfg)
 	if err != nil {
 		exitWithError("%v", err)
 	}
 
-	timeoutSec := cfg.GetTimeoutSeconds()
-	if flags.Timeout != 30 { // CLI override
-		timeoutSec = flags.Timeout
+	// Comparison runs benchmark every target side by side
+	if len(cfg.Targets) > 0 {
+		runTargets(cfg, flags, durationSec)
+		return
+	}
+
+	// Fail before any worker joins when the users cannot be spread over the workers
+	if flags.Controller != "" {
+		if err := distributed.CheckWorkers(cfg, flags.Workers); err != nil {
+			exitWithError("%v", err)
+		}
 	}
 
-	rampUpSec := cfg.GetRampUpSeconds()
-	if flags.RampUpSeconds > 0 { // CLI override
-		rampUpSec = flags.RampUpSeconds
+	var checkpoint *benchmark.Checkpoint
+	if flags.Resume != "" {
+		checkpoint, err = benchmark.LoadCheckpoint(flags.Resume)
+		if err != nil {
+			exitWithError("%v", err)
+		}
+	}
+
+	// Load the baseline up front so a bad path fails before the benchmark runs
+	var baseline *benchmark.Baseline
+	if flags.CheckBaseline != "" {
+		baseline, err = output.LoadBaseline(flags.CheckBaseline)
+		if err != nil {
+			exitWithError("%v", err)
+		}
+	}
+
+	timeout, rampUpSec := effectiveTimeouts(cfg, flags)
+
+	// Seed data, fetch tokens and the like before anything is measured
+	if err := runPreRunHook(cfg, durationSec); err != nil {
+		exitWithError("%v", err)
 	}
 
 	// Resolve variables
@@ -67,7 +151,7 @@ func main() {
 
 	// Print configuration
 	if !effectiveQuietMode {
-		printConfiguration(cfg, durationSec, timeoutSec, rampUpSec, flags.VerboseMode)
+		printConfiguration(cfg, durationSec, timeout, rampUpSec, flags.VerboseMode)
 	}
 
 	// Set up context with cancellation
@@ -75,60 +159,204 @@ func main() {
 	defer cancel()
 
 	// Handle Ctrl+C
-	setupSignalHandler(cancel, effectiveQuietMode)
-
-	// Create and run benchmark
-	runner := benchmark.NewRunner(cfg, durationSec, timeoutSec, rampUpSec, effectiveQuietMode, flags.VerboseMode)
-	stats := runner.Run(ctx)
+	interrupted := setupSignalHandler(cancel, effectiveQuietMode)
 
-	// Output results
-	writeResults(stats, cfg, flags.QuietMode)
+	// Create and run benchmark, locally or spread over workers
+	var stats *benchmark.Stats
+	var runner *benchmark.Runner
+	var abortReason string
+	if flags.Controller != "" {
+		controller := distributed.NewController(flags.Controller, flags.Workers, cfg, durationSec, timeout, rampUpSec, effectiveQuietMode)
+		stats, err = controller.Run(ctx)
+		if stats == nil {
+			exitWithError("%v", err)
+		}
+		if err != nil && !effectiveQuietMode {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		abortReason = controller.AbortReason()
+	} else {
+		runner = benchmark.NewRunner(cfg, durationSec, timeout, rampUpSec, effectiveQuietMode, flags.VerboseMode)
+		sinks, err := metrics.OpenAll(cfg.Output.Sinks, cfg.RunLabels(), os.Stderr)
+		if err != nil {
+			exitWithError("%v", err)
+		}
+		runner.Sinks = sinks
+		if checkpoint != nil {
+			if err := runner.RestoreCheckpoint(checkpoint); err != nil {
+				exitWithError("cannot resume: %v", err)
+			}
+			if !effectiveQuietMode {
+				fmt.Printf("Resuming from %s: %d requests over %.0fs already completed\n\n",
+					flags.Resume, checkpoint.Stats.TotalRequests, checkpoint.Elapsed)
+			}
+		}
+		stats = runLocal(ctx, runner, effectiveQuietMode)
+		abortReason = runner.AbortReason()
+		if err := metrics.CloseAll(sinks); err != nil && !effectiveQuietMode {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
 
-	// Evaluate thresholds if defined
-	if cfg.Thresholds.HasThresholds() {
-		thresholdResults, err := benchmark.EvaluateThresholds(stats, &cfg.Thresholds)
+	// Evaluate thresholds if defined, plus the baseline comparison when requested;
+	// an early abort by rolling thresholds counts as a failure
+	var thresholdResults *benchmark.ThresholdResults
+	if cfg.HasThresholds() {
+		thresholdResults, err = benchmark.EvaluateConfigThresholds(stats, cfg)
 		if err != nil {
 			exitWithError("threshold evaluation failed: %v", err)
 		}
+	}
+	if baseline != nil {
+		if thresholdResults == nil {
+			thresholdResults = &benchmark.ThresholdResults{Passed: true}
+		}
+		if err := thresholdResults.EvaluateBaseline(stats, baseline, cfg.BaselineThresholds); err != nil {
+			exitWithError("baseline comparison failed: %v", err)
+		}
+	}
+	if abortReason != "" {
+		if thresholdResults == nil {
+			thresholdResults = &benchmark.ThresholdResults{Passed: true}
+		}
+		thresholdResults.RecordAbort(abortReason)
+	}
+	thresholdResults = runPostRunHook(cfg, durationSec, stats, thresholdResults, interrupted.Load(), abortReason, effectiveQuietMode)
+
+	// Output results
+	writeResults(stats, cfg, flags.QuietMode, thresholdResults)
 
-		// Print threshold results unless in quiet mode with non-console output
+	if runner != nil {
+		if saved, dir := runner.CapturedFailures(); saved > 0 && !effectiveQuietMode {
+			fmt.Printf("\n  Captured %d failing request(s) in %s\n", saved, dir)
+		}
+		if saved, dir := runner.SampledResponses(); saved > 0 && !effectiveQuietMode {
+			fmt.Printf("\n  Sampled %d response(s) in %s\n", saved, dir)
+		}
+	}
+
+	// Print threshold results unless in quiet mode with non-console output
+	thresholdsFailed := false
+	if thresholdResults != nil {
 		if !effectiveQuietMode {
 			fmt.Print(thresholdResults.FormatResults())
 		}
+		thresholdsFailed = !thresholdResults.Passed
+	}
+
+	// Exit with the code configured for the outcome (for CI/CD integration)
+	if code := exitCode(cfg.ExitCodes, stats, interrupted.Load(), thresholdsFailed); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// validateConfig checks the configured settings up front so mistakes fail before
+// the benchmark runs, and returns the duration in seconds (0 in request count mode)
+func validateConfig(cfg *config.Config) (int, error) {
+	if err := cfg.Validate(); err != nil {
+		return 0, err
+	}
+	return cfg.GetDurationSeconds()
+}
 
-		// Exit with code 1 if thresholds failed (for CI/CD integration)
-		if !thresholdResults.Passed {
-			os.Exit(1)
+// applyCPUFlags pins the process to the --cpus and applies --gomaxprocs
+func applyCPUFlags(flags *CLIFlags) error {
+	if flags.CPUs != "" {
+		cpus, _ := cpuset.Parse(flags.CPUs) // Validated in validateFlags
+		if err := cpuset.Pin(cpus, flags.ReserveCore); err != nil {
+			return err
 		}
 	}
+	if flags.GoMaxProcs > 0 {
+		runtime.GOMAXPROCS(flags.GoMaxProcs)
+	}
+	return nil
+}
+
+// effectiveTimeouts returns the request timeout and the ramp-up in seconds, with CLI flags overriding the config
+func effectiveTimeouts(cfg *config.Config, flags *CLIFlags) (time.Duration, int) {
+	timeout := cfg.GetTimeout()
+	if flags.Timeout != "" { // CLI override
+		timeout, _ = time.ParseDuration(flagDuration(flags.Timeout)) // Validated in validateFlags
+	}
+	return timeout, cfg.GetRampUpSeconds() // --ramp-up is applied to the config
+}
+
+// runLocal runs the benchmark in this process, with the live dashboard and the
+// pause and statistics signals active while it runs
+func runLocal(ctx context.Context, runner *benchmark.Runner, quietMode bool) *benchmark.Stats {
+	stopDashboard := startDashboard(ctx, runner.Config, runner.Stats, quietMode)
+	stopPauseSignals := handlePauseSignals(runner, quietMode)
+	stopDumpSignals := handleDumpSignals(runner)
+	stats := runner.Run(ctx)
+	stopDumpSignals()
+	stopPauseSignals()
+	stopDashboard()
+	return stats
 }
 
-// setupSignalHandler sets up handling for Ctrl+C
-func setupSignalHandler(cancel context.CancelFunc, quietMode bool) {
+// exitCode maps the benchmark outcome to a process exit code using the configured policy.
+// Without a policy only failed thresholds produce a non-zero code (1).
+func exitCode(policy *config.ExitCodeConfig, stats *benchmark.Stats, interrupted, thresholdsFailed bool) int {
+	codes := config.ExitCodeConfig{}
+	if policy != nil {
+		codes = *policy
+	}
+	if codes.ThresholdFailure == 0 {
+		codes.ThresholdFailure = 1
+	}
+
+	switch {
+	case interrupted && codes.Interrupted != 0:
+		return codes.Interrupted
+	case stats.Http5xxCount > 0 && codes.ServerErrors != 0:
+		return codes.ServerErrors
+	case stats.Http4xxCount > 0 && codes.ClientErrors != 0:
+		return codes.ClientErrors
+	case stats.FailureCount > 0 && codes.AnyFailure != 0:
+		return codes.AnyFailure
+	case thresholdsFailed:
+		return codes.ThresholdFailure
+	}
+	return 0
+}
+
+// setupSignalHandler sets up handling for Ctrl+C and reports whether it was pressed.
+// The first Ctrl+C cancels ctx; a second one exits right away.
+func setupSignalHandler(cancel context.CancelFunc, quietMode bool) *atomic.Bool {
+	interrupted := &atomic.Bool{}
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
 	go func() {
 		<-c
+		interrupted.Store(true)
 		if !quietMode {
-			fmt.Println("\nBenchmark interrupted, shutting down...")
+			fmt.Println("\nBenchmark interrupted, shutting down (press Ctrl+C again to exit immediately)...")
 		}
 		cancel()
+
+		// A second Ctrl+C skips the grace period for in-flight requests
+		<-c
+		fmt.Fprintln(os.Stderr, "\nExiting without waiting for in-flight requests")
+		os.Exit(130)
 	}()
+	return interrupted
 }
 
-// writeResults writes the benchmark results in the appropriate format
-func writeResults(stats *benchmark.Stats, cfg *config.Config, quietMode bool) {
+// writeResults writes the benchmark results in the appropriate format.
+// thresholds may be nil when none are configured.
+func writeResults(stats *benchmark.Stats, cfg *config.Config, quietMode bool, thresholds *benchmark.ThresholdResults) {
 	switch cfg.Output.Format {
 	case "json":
-		if err := output.WriteJSON(stats, cfg); err != nil {
+		if err := output.WriteJSON(stats, cfg, thresholds); err != nil {
 			exitWithError("%v", err)
 		}
 	case "csv":
-		if err := output.WriteCSV(stats, cfg); err != nil {
+		if err := output.WriteCSV(stats, cfg, thresholds); err != nil {
 			exitWithError("%v", err)
 		}
 	case "html":
-		if err := output.WriteHTML(stats, cfg); err != nil {
+		if err := output.WriteHTML(stats, cfg, thresholds); err != nil {
 			exitWithError("%v", err)
 		}
 	default:
@@ -138,4 +366,13 @@ func writeResults(stats *benchmark.Stats, cfg *config.Config, quietMode bool) {
 			output.WriteConsole(stats, cfg)
 		}
 	}
+	if err := output.WritePlugins(stats, cfg, thresholds); err != nil {
+		exitWithError("%v", err)
+	}
+	if cfg.Output.HistoryDir != "" {
+		// The results are out already, so a history failure doesn't fail the run
+		if err := output.AppendHistory(cfg.Output.HistoryDir, output.NewHistoryEntry(stats, cfg, thresholds)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
 }
@@ -0,0 +1,72 @@
+// Package main is the entry point for the benchmarking tool
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/benchmarking_go/pkg/output"
+)
+
+// runMerge runs the `merge` subcommand: combine the JSON results of several
+// load generators into one report
+func runMerge(args []string) {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	outputFile := fs.String("o", "", "Output file (default: stdout)")
+	format := fs.String("format", "json", "Report format: json, csv, html or console")
+	fs.Usage = displayMergeHelp
+	files := parseInterspersed(fs, args)
+	if len(files) < 2 {
+		displayMergeHelp()
+		exitWithError("expected at least two result files")
+	}
+	switch *format {
+	case "json", "csv", "html", "console":
+	default:
+		exitWithError("invalid --format %q: expected json, csv, html or console", *format)
+	}
+
+	stats, cfg, err := output.MergeResults(files)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+	cfg.Output.Format = *format
+	cfg.Output.File = *outputFile
+	// Keep the raw stats so merged results can be merged again
+	cfg.Output.Mergeable = true
+	writeResults(stats, cfg, false, nil)
+}
+
+// parseInterspersed parses args with fs, allowing flags after the positional
+// arguments (e.g. `merge a.json b.json -o combined.json`), and returns the
+// positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// displayMergeHelp shows the help message for the merge subcommand
+func displayMergeHelp() {
+	fmt.Println("Usage: benchmarking_go merge [options] <result.json> <result.json>...")
+	fmt.Println()
+	fmt.Println("Combines JSON results from several load generator hosts into one report.")
+	fmt.Println("Counters, latency histograms and per-endpoint stats are summed, so the")
+	fmt.Println("percentiles are those of all requests together; the duration is the longest")
+	fmt.Println("of the runs, as they ran side by side. Results must be written with")
+	fmt.Println("--mergeable, which embeds the raw stats.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -o <file>                        Output file (default: stdout)")
+	fmt.Println("  --format <format>                Report format: json, csv, html or console (default: json)")
+	fmt.Println()
+	fmt.Println("Example:")
+	fmt.Println("  benchmarking_go -u https://api.example.com -c 100 -d 60 -o json --mergeable --output-file host1.json")
+	fmt.Println("  benchmarking_go merge host1.json host2.json host3.json -o combined.json")
+}
@@ -0,0 +1,97 @@
+// Package main is the entry point for the benchmarking tool
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+
+	"github.com/benchmarking_go/pkg/record"
+)
+
+// runRecord runs the `record` subcommand: a proxy that writes captured traffic as a scenario config
+func runRecord(args []string) {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	listen := fs.String("listen", "127.0.0.1:8888", "Address for the recording proxy")
+	outputFile := fs.String("output-file", "recorded.json", "Scenario config file to write")
+	name := fs.String("name", "Recorded scenario", "Scenario name")
+	filter := fs.String("filter", "", "Only record URLs matching this regular expression")
+	caCert := fs.String("ca-cert", "recorder-ca.pem", "CA certificate for HTTPS interception (created if missing)")
+	caKey := fs.String("ca-key", "recorder-ca-key.pem", "CA private key for HTTPS interception")
+	noHTTPS := fs.Bool("no-https", false, "Tunnel HTTPS without recording it (no CA needed)")
+	insecure := fs.Bool("insecure", false, "Skip TLS certificate verification of upstream servers")
+	verbose := fs.Bool("verbose", false, "Print each recorded request")
+	fs.Usage = displayRecordHelp
+	fs.Parse(args)
+
+	var filterRegex *regexp.Regexp
+	if *filter != "" {
+		var err error
+		if filterRegex, err = regexp.Compile(*filter); err != nil {
+			exitWithError("invalid --filter: %v", err)
+		}
+	}
+
+	var ca *record.CA
+	if !*noHTTPS {
+		var created bool
+		var err error
+		ca, created, err = record.LoadOrCreateCA(*caCert, *caKey)
+		if err != nil {
+			exitWithError("%v", err)
+		}
+		if created {
+			fmt.Printf("Created CA certificate %s - trust it in your browser/app to record HTTPS\n", *caCert)
+		}
+	}
+
+	recorder := record.NewRecorder(ca, filterRegex, *insecure, *verbose)
+	recorder.Log = os.Stdout
+	server := &http.Server{Addr: *listen, Handler: recorder}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	fmt.Printf("Recording proxy listening on %s (set it as your HTTP/HTTPS proxy)\n", *listen)
+	fmt.Println("Press Ctrl+C to stop and write the scenario config")
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		exitWithError("recording proxy failed: %v", err)
+	}
+
+	if err := recorder.WriteConfig(*outputFile, *name); err != nil {
+		exitWithError("%v", err)
+	}
+	fmt.Printf("\nRecorded %d request(s) to %s\n", recorder.Count(), *outputFile)
+	fmt.Printf("Replay with: benchmarking_go --config %s\n", *outputFile)
+}
+
+// displayRecordHelp shows the help message for the record subcommand
+func displayRecordHelp() {
+	fmt.Println("Usage: benchmarking_go record [options]")
+	fmt.Println()
+	fmt.Println("Starts a local proxy that records the traffic sent through it and writes")
+	fmt.Println("a scenario config (steps, headers, bodies, think times) on Ctrl+C.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --listen <addr>                  Proxy address (default: 127.0.0.1:8888)")
+	fmt.Println("  --output-file <file>             Scenario config to write (default: recorded.json)")
+	fmt.Println("  --name <name>                    Scenario name")
+	fmt.Println("  --filter <regex>                 Only record URLs matching this regular expression")
+	fmt.Println("  --ca-cert <file>                 CA certificate for HTTPS interception (created if missing)")
+	fmt.Println("  --ca-key <file>                  CA private key (default: recorder-ca-key.pem)")
+	fmt.Println("  --no-https                       Tunnel HTTPS without recording it")
+	fmt.Println("  --insecure                       Skip TLS verification of upstream servers")
+	fmt.Println("  --verbose                        Print each recorded request")
+	fmt.Println()
+	fmt.Println("Example:")
+	fmt.Println("  benchmarking_go record --filter 'api\\.example\\.com' --output-file checkout.json")
+	fmt.Println("  curl -x http://127.0.0.1:8888 --cacert recorder-ca.pem https://api.example.com/items")
+}
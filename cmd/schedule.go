@@ -0,0 +1,62 @@
+// Package main is the entry point for the benchmarking tool
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/benchmarking_go/pkg/schedule"
+)
+
+// runSchedule runs the `schedule` subcommand: a daemon that runs benchmarks on cron schedules
+func runSchedule(args []string) {
+	fs := flag.NewFlagSet("schedule", flag.ExitOnError)
+	quiet := fs.Bool("quiet", false, "Don't log job starts and results")
+	fs.Usage = displayScheduleHelp
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		displayScheduleHelp()
+		exitWithError("expected one schedule file")
+	}
+
+	file, err := schedule.Load(fs.Arg(0))
+	if err != nil {
+		exitWithError("%v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupSignalHandler(cancel, *quiet)
+
+	if !*quiet {
+		fmt.Printf("Scheduler running %d job(s), writing history to %s\n", len(file.Jobs), file.HistoryDir)
+	}
+	daemon := schedule.NewDaemon(file, *quiet)
+	daemon.Log = os.Stdout
+	if err := daemon.Run(ctx); err != nil {
+		exitWithError("%v", err)
+	}
+}
+
+// displayScheduleHelp shows the help message for the schedule subcommand
+func displayScheduleHelp() {
+	fmt.Println("Usage: benchmarking_go schedule [options] <schedule.json>")
+	fmt.Println()
+	fmt.Println("Stays resident and runs the benchmarks listed in the schedule file on their")
+	fmt.Println("cron schedules, one at a time. Each run's summary is appended to the history")
+	fmt.Println("directory and, when configured, POSTed to a webhook.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --quiet                          Don't log job starts and results")
+	fmt.Println()
+	fmt.Println("Example schedule file:")
+	fmt.Println(`  {`)
+	fmt.Println(`    "historyDir": "bench-history",`)
+	fmt.Println(`    "notify": {"webhook": "https://hooks.example.com/bench", "onFailure": true},`)
+	fmt.Println(`    "jobs": [`)
+	fmt.Println(`      {"name": "nightly-api", "schedule": "0 2 * * *", "config": "api.json"}`)
+	fmt.Println(`    ]`)
+	fmt.Println(`  }`)
+}
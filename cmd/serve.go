@@ -0,0 +1,80 @@
+// Package main is the entry point for the benchmarking tool
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/benchmarking_go/pkg/config"
+	"github.com/benchmarking_go/pkg/testserver"
+)
+
+// runServe runs the `serve` subcommand: a local test target with configurable
+// latency, statuses and payload sizes
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := fs.String("listen", "127.0.0.1:8080", "Address to listen on")
+	latency := fs.String("latency", "0s", "Response delay: '20ms', 'uniform(10ms,50ms)', 'normal(20ms,5ms)' or 'exponential(20ms)'")
+	statuses := fs.String("status", "200", "Weighted response statuses (e.g. '200:95,500:4,503:1')")
+	size := fs.String("size", "0", "Response payload size or range (e.g. '1KB' or '1KB-64KB'; 0 = small JSON body)")
+	quiet := fs.Bool("quiet", false, "Don't print the listening address and request count")
+	fs.Usage = displayServeHelp
+	fs.Parse(args)
+
+	server := &testserver.Server{}
+	var err error
+	if server.Latency, err = config.ParseDelay(*latency); err != nil {
+		exitWithError("invalid --latency: %v", err)
+	}
+	if server.Statuses, err = testserver.ParseStatusMix(*statuses); err != nil {
+		exitWithError("invalid --status: %v", err)
+	}
+	if server.Size, err = testserver.ParseSizeRange(*size); err != nil {
+		exitWithError("invalid --size: %v", err)
+	}
+
+	httpServer := &http.Server{Addr: *listen, Handler: server}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		httpServer.Close()
+	}()
+
+	if !*quiet {
+		fmt.Printf("Test server listening on http://%s (latency %s, status %s, size %s)\n", *listen, *latency, *statuses, *size)
+		fmt.Println("Press Ctrl+C to stop")
+	}
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		exitWithError("test server failed: %v", err)
+	}
+	if !*quiet {
+		fmt.Printf("\nServed %d request(s)\n", server.Requests())
+	}
+}
+
+// displayServeHelp shows the help message for the serve subcommand
+func displayServeHelp() {
+	fmt.Println("Usage: benchmarking_go serve [options]")
+	fmt.Println()
+	fmt.Println("Starts a local HTTP server answering every request with a delay, status and")
+	fmt.Println("payload drawn from the options, to try configurations and reports without an")
+	fmt.Println("external endpoint. The query parameters latency, status and size override the")
+	fmt.Println("options per request (e.g. /slow?latency=2s or /missing?status=404).")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --listen <addr>                  Address to listen on (default: 127.0.0.1:8080)")
+	fmt.Println("  --latency <dist>                 Response delay: '20ms', 'uniform(10ms,50ms)', 'normal(20ms,5ms)'")
+	fmt.Println("                                   (mean, standard deviation) or 'exponential(20ms)' (mean)")
+	fmt.Println("  --status <mix>                   Weighted statuses (default: 200; e.g. '200:95,500:4,503:1')")
+	fmt.Println("  --size <size>                    Payload size or range (e.g. '1KB' or '1KB-64KB'; default: small JSON)")
+	fmt.Println("  --quiet                          Don't print the listening address and request count")
+	fmt.Println()
+	fmt.Println("Example:")
+	fmt.Println("  benchmarking_go serve --latency 'normal(20ms,5ms)' --status 200:98,503:2 --size 2KB-16KB")
+	fmt.Println("  benchmarking_go -u http://127.0.0.1:8080/ -c 50 -d 30")
+}
@@ -0,0 +1,79 @@
+//go:build !windows
+
+// Package main is the entry point for the benchmarking tool
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/output"
+)
+
+// handlePauseSignals pauses the benchmark on SIGTSTP (Ctrl+Z) and resumes it on
+// the next SIGTSTP or on SIGCONT. The returned function stops the handling.
+func handlePauseSignals(runner *benchmark.Runner, quietMode bool) func() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGTSTP, syscall.SIGCONT)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-c:
+				var paused bool
+				if sig == syscall.SIGCONT {
+					runner.Resume()
+				} else {
+					paused = runner.TogglePause()
+				}
+				if !quietMode && !runner.Config.Settings.TUI {
+					if paused {
+						fmt.Println("\n[info] Benchmark paused, press Ctrl+Z again to resume")
+					} else {
+						fmt.Println("\n[info] Benchmark resumed")
+					}
+				}
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
+
+// handleDumpSignals prints the statistics so far to stderr on SIGUSR1 or SIGQUIT,
+// without stopping the benchmark. The returned function stops the handling.
+func handleDumpSignals(runner *benchmark.Runner) func() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGUSR1, syscall.SIGQUIT)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-c:
+				writeInterimStats(runner)
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
+
+// writeInterimStats prints a snapshot of a running benchmark's statistics to stderr
+func writeInterimStats(runner *benchmark.Runner) {
+	stats := runner.InterimStats()
+	fmt.Fprintf(os.Stderr, "\n=== Interim statistics after %.0fs: %d requests, %.2f req/s ===\n",
+		stats.TotalDuration, stats.TotalRequests, stats.RequestsPerSecond)
+	output.WriteConsoleTo(os.Stderr, stats, runner.Config)
+	fmt.Fprintln(os.Stderr, "=== End of interim statistics ===")
+}
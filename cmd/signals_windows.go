@@ -0,0 +1,17 @@
+//go:build windows
+
+// Package main is the entry point for the benchmarking tool
+package main
+
+import "github.com/benchmarking_go/pkg/benchmark"
+
+// handlePauseSignals does nothing on Windows, which has no SIGTSTP; use the
+// TUI's p key to pause instead
+func handlePauseSignals(runner *benchmark.Runner, quietMode bool) func() {
+	return func() {}
+}
+
+// handleDumpSignals does nothing on Windows, which has no SIGUSR1 or SIGQUIT
+func handleDumpSignals(runner *benchmark.Runner) func() {
+	return func() {}
+}
@@ -0,0 +1,82 @@
+// Package main is the entry point for the benchmarking tool
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/benchmarking_go/pkg/output"
+)
+
+// runTrend runs the `trend` subcommand: report how requests/sec and p99
+// latency developed over the runs appended to a history directory
+func runTrend(args []string) {
+	fs := flag.NewFlagSet("trend", flag.ExitOnError)
+	outputFile := fs.String("o", "", "Output file (default: stdout)")
+	format := fs.String("format", "", "Report format: "+strings.Join(output.TrendFormats, ", ")+" (default: from the output file extension, else markdown)")
+	last := fs.Int("last", 20, "Number of most recent runs to report (0 = all)")
+	name := fs.String("name", "", "Only report runs of the benchmark with this name")
+	fs.Usage = displayTrendHelp
+	paths := parseInterspersed(fs, args)
+	if len(paths) != 1 {
+		displayTrendHelp()
+		exitWithError("expected one history directory or file")
+	}
+
+	if *format == "" {
+		*format = convertExtensions[strings.ToLower(filepath.Ext(*outputFile))]
+		if *format != "html" {
+			*format = "markdown"
+		}
+	}
+
+	entries, err := output.LoadHistory(paths[0])
+	if err != nil {
+		exitWithError("%v", err)
+	}
+	entries = output.SelectTrend(entries, *name, *last)
+	if len(entries) == 0 && *name != "" {
+		exitWithError("no runs named %q in %s", *name, paths[0])
+	}
+
+	var w io.Writer = os.Stdout
+	if *outputFile != "" {
+		file, err := os.Create(*outputFile)
+		if err != nil {
+			exitWithError("error creating output file: %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := output.WriteTrend(w, entries, *format); err != nil {
+		exitWithError("%v", err)
+	}
+	if *outputFile != "" {
+		fmt.Fprintf(os.Stderr, "Trend report saved to: %s\n", *outputFile)
+	}
+}
+
+// displayTrendHelp shows the help message for the trend subcommand
+func displayTrendHelp() {
+	fmt.Println("Usage: benchmarking_go trend [options] <history-dir>")
+	fmt.Println()
+	fmt.Println("Reports requests/sec and p99 latency across the runs appended to a history")
+	fmt.Println("directory with --history-dir (or by the schedule subcommand), with the change")
+	fmt.Println("from run to run and each run's metadata and tags.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -o <file>                        Output file (default: stdout)")
+	fmt.Println("  --format <format>                html or markdown (default: html for a .html output file,")
+	fmt.Println("                                   else markdown)")
+	fmt.Println("  --last <n>                       Number of most recent runs to report (default: 20, 0 = all)")
+	fmt.Println("  --name <name>                    Only report runs of the benchmark with this name")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  benchmarking_go --config api.json --history-dir ./bench-history --meta gitSha=$GIT_SHA")
+	fmt.Println("  benchmarking_go trend ./bench-history -o trend.html")
+	fmt.Println("  benchmarking_go trend ./bench-history --last 10 >> $GITHUB_STEP_SUMMARY")
+}
@@ -0,0 +1,135 @@
+// Package main is the entry point for the benchmarking tool
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/output"
+)
+
+// Watch mode timing
+const (
+	watchPollInterval = 250 * time.Millisecond // How often the config file is checked
+	watchDebounce     = 500 * time.Millisecond // How long the file must be unchanged before rerunning
+)
+
+// fileState identifies a version of a file
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// runWatch runs the benchmark and reruns it every time the config file changes,
+// printing how each run compares to the previous one, until Ctrl+C
+func runWatch(flags *CLIFlags) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupSignalHandler(cancel, flags.QuietMode)
+
+	var previous *output.HistoryEntry
+	for {
+		state := statFile(flags.ConfigFile)
+		if current := runWatchIteration(ctx, flags); current != nil {
+			if previous != nil {
+				fmt.Print("\n" + output.FormatComparison(previous, current))
+			}
+			previous = current
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		fmt.Printf("\nWatching %s for changes (Ctrl+C to exit)...\n", flags.ConfigFile)
+		if !waitForChange(ctx, flags.ConfigFile, state) {
+			return
+		}
+		fmt.Printf("\n%s changed, rerunning\n\n", flags.ConfigFile)
+	}
+}
+
+// runWatchIteration loads the config and runs the benchmark once. It returns the
+// run's summary, or nil when the config is invalid, so watching continues until it is fixed.
+func runWatchIteration(ctx context.Context, flags *CLIFlags) *output.HistoryEntry {
+	cfg, err := loadConfiguration(flags)
+	var durationSec int
+	if err == nil {
+		durationSec, err = validateConfig(cfg)
+	}
+	if err == nil && len(cfg.Targets) > 0 {
+		err = fmt.Errorf("targets are not supported in watch mode")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil
+	}
+	timeout, rampUpSec := effectiveTimeouts(cfg, flags)
+	cfg.ResolveRequestVariables()
+
+	quietMode := flags.QuietMode || cfg.Output.Format == "json" || cfg.Output.Format == "csv"
+	if !quietMode {
+		printConfiguration(cfg, durationSec, timeout, rampUpSec, flags.VerboseMode)
+	}
+
+	runner := benchmark.NewRunner(cfg, durationSec, timeout, rampUpSec, quietMode, flags.VerboseMode)
+	stats := runLocal(ctx, runner, quietMode)
+
+	var thresholds *benchmark.ThresholdResults
+	if cfg.HasThresholds() {
+		if thresholds, err = benchmark.EvaluateConfigThresholds(stats, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: threshold evaluation failed: %v\n", err)
+		}
+	}
+	if reason := runner.AbortReason(); reason != "" {
+		if thresholds == nil {
+			thresholds = &benchmark.ThresholdResults{Passed: true}
+		}
+		thresholds.RecordAbort(reason)
+	}
+
+	writeResults(stats, cfg, flags.QuietMode, thresholds)
+	if thresholds != nil && !quietMode {
+		fmt.Print(thresholds.FormatResults())
+	}
+
+	entry := output.NewHistoryEntry(stats, cfg, thresholds)
+	return &entry
+}
+
+// waitForChange waits until the file differs from state and then stays unchanged
+// for the debounce period, so an editor's several writes trigger one run. It
+// returns false if ctx is done first.
+func waitForChange(ctx context.Context, filename string, state fileState) bool {
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	var changedAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+
+		current := statFile(filename)
+		if current != state {
+			state, changedAt = current, time.Now()
+			continue
+		}
+		if !changedAt.IsZero() && time.Since(changedAt) >= watchDebounce {
+			return true
+		}
+	}
+}
+
+// statFile returns the file's current state; a missing file has the zero state
+func statFile(filename string) fileState {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}
+}
@@ -0,0 +1,21 @@
+// Package main is the entry point for the benchmarking tool
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/benchmarking_go/pkg/distributed"
+)
+
+// runWorker runs this process as a worker: it joins the controller, runs the
+// share of the benchmark it is assigned and reports the results back
+func runWorker(flags *CLIFlags) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setupSignalHandler(cancel, flags.QuietMode)
+
+	if err := distributed.RunWorker(ctx, flags.Join, os.Stdout, flags.QuietMode); err != nil {
+		exitWithError("%v", err)
+	}
+}
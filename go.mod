@@ -6,12 +6,25 @@ go 1.24.2
 
 require (
 	github.com/HdrHistogram/hdrhistogram-go v1.2.0
+	github.com/dop251/goja v0.0.0-20260106131823-651366fbe6e3
+	github.com/tidwall/gjson v1.18.0
+	github.com/valyala/fasthttp v1.65.0
+	github.com/yuin/gopher-lua v1.1.2
 	golang.org/x/net v0.47.0
+	google.golang.org/grpc v1.77.0
 )
 
 require (
-	github.com/tidwall/gjson v1.18.0 // indirect
+	github.com/andybalholm/brotli v1.2.0 // indirect
+	github.com/dlclark/regexp2 v1.11.4 // indirect
+	github.com/go-sourcemap/sourcemap v2.1.3+incompatible // indirect
+	github.com/google/pprof v0.0.0-20230207041349-798e818bf904 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20251022142026-3a174f9686a8 // indirect
+	google.golang.org/protobuf v1.36.10 // indirect
 )
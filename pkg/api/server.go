@@ -0,0 +1,260 @@
+// Package api exposes a REST API to start, watch and stop benchmarks, so runs
+// can be orchestrated by other services
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+	"github.com/benchmarking_go/pkg/output"
+)
+
+// Run states
+const (
+	StatusRunning  = "running"
+	StatusFinished = "finished"
+)
+
+// Server runs one benchmark at a time on behalf of API clients
+type Server struct {
+	mu     sync.Mutex
+	nextID int
+	run    *run // Current or most recent run
+}
+
+// run is a benchmark started through the API
+type run struct {
+	id       int
+	cfg      *config.Config
+	runner   *benchmark.Runner
+	started  time.Time
+	finished time.Time
+	cancel   context.CancelFunc
+	result   *output.Result // Set once the run has finished
+}
+
+// LiveStats is the response of GET /stats
+type LiveStats struct {
+	ID                int               `json:"id"`
+	Status            string            `json:"status"`
+	ElapsedSeconds    float64           `json:"elapsed_seconds"`
+	Requests          int64             `json:"requests"`
+	SuccessCount      int64             `json:"success_count"`
+	FailureCount      int64             `json:"failure_count"`
+	RequestsPerSecond float64           `json:"requests_per_second"`
+	AvgLatency        string            `json:"avg_latency"`
+	Percentiles       map[string]string `json:"percentiles"`
+}
+
+// NewServer creates an API server with no benchmark running
+func NewServer() *Server {
+	return &Server{}
+}
+
+// Handler returns the HTTP handler serving the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /run", s.handleRun)
+	mux.HandleFunc("GET /stats", s.handleStats)
+	mux.HandleFunc("POST /stop", s.handleStop)
+	mux.HandleFunc("GET /results", s.handleResults)
+	return mux
+}
+
+// ListenAndServe serves the API on addr until ctx is cancelled, stopping any running benchmark
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	server := &http.Server{Addr: addr, Handler: s.Handler()}
+	go func() {
+		<-ctx.Done()
+		s.stop()
+		server.Close()
+	}()
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("api server failed: %w", err)
+	}
+	return nil
+}
+
+// handleRun starts a benchmark from the posted JSON configuration
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read config: %v", err)
+		return
+	}
+	cfg, err := config.Parse(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid config: %v", err)
+		return
+	}
+	if len(cfg.Requests) == 0 && !cfg.IsScenarioMode() {
+		writeError(w, http.StatusBadRequest, "config has no requests or steps")
+		return
+	}
+	durationSec, err := cfg.GetDurationSeconds()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if err := cfg.ValidateSLO(); err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if _, err := cfg.GetGracePeriod(0); err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	cfg.ResolveRequestVariables()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.run != nil && s.run.result == nil {
+		writeError(w, http.StatusConflict, "benchmark %d is still running", s.run.id)
+		return
+	}
+
+	s.nextID++
+	ctx, cancel := context.WithCancel(context.Background())
+	current := &run{
+		id:      s.nextID,
+		cfg:     cfg,
+		runner:  benchmark.NewRunner(cfg, durationSec, cfg.GetTimeout(), cfg.GetRampUpSeconds(), true, false),
+		started: time.Now(),
+		cancel:  cancel,
+	}
+	s.run = current
+	go s.execute(ctx, current)
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{"id": current.id, "status": StatusRunning})
+}
+
+// execute runs the benchmark and stores its final results
+func (s *Server) execute(ctx context.Context, current *run) {
+	defer current.cancel()
+	stats := current.runner.Run(ctx)
+
+	result := output.ToJSONResult(stats, current.cfg)
+	var thresholds *benchmark.ThresholdResults
+	if current.cfg.HasThresholds() {
+		var err error
+		if thresholds, err = benchmark.EvaluateConfigThresholds(stats, current.cfg); err != nil {
+			if result.Errors == nil {
+				result.Errors = make(map[string]int)
+			}
+			result.Errors[fmt.Sprintf("threshold evaluation failed: %v", err)]++
+		}
+	}
+	if reason := current.runner.AbortReason(); reason != "" {
+		if thresholds == nil {
+			thresholds = &benchmark.ThresholdResults{Passed: true}
+		}
+		thresholds.RecordAbort(reason)
+	}
+	result.Thresholds = output.ToThresholdSummary(thresholds)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current.finished = time.Now()
+	current.result = result
+}
+
+// handleStats reports live statistics of the current (or last) run
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	current := s.run
+	var status string
+	var elapsed time.Duration
+	if current != nil {
+		status, elapsed = StatusRunning, time.Since(current.started)
+		if current.result != nil {
+			status, elapsed = StatusFinished, current.finished.Sub(current.started)
+		}
+	}
+	s.mu.Unlock()
+
+	if current == nil {
+		writeError(w, http.StatusNotFound, "no benchmark has been started")
+		return
+	}
+
+	stats := current.runner.Stats
+	live := &LiveStats{
+		ID:             current.id,
+		Status:         status,
+		ElapsedSeconds: elapsed.Seconds(),
+		SuccessCount:   atomic.LoadInt64(&stats.SuccessCount),
+		FailureCount:   atomic.LoadInt64(&stats.FailureCount),
+		AvgLatency:     output.FormatLatency(stats.AverageResponseTime()),
+		Percentiles:    make(map[string]string),
+	}
+	live.Requests = live.SuccessCount + live.FailureCount
+	if elapsed > 0 {
+		live.RequestsPerSecond = float64(live.Requests) / elapsed.Seconds()
+	}
+	for _, p := range current.cfg.Settings.Percentiles {
+		live.Percentiles["p"+output.FormatPercentile(p)] = output.FormatLatency(float64(stats.GetLatencyPercentile(p)))
+	}
+	writeJSON(w, http.StatusOK, live)
+}
+
+// handleStop stops the running benchmark; its results become available shortly after
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	if !s.stop() {
+		writeError(w, http.StatusConflict, "no benchmark is running")
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "stopping"})
+}
+
+// stop cancels the running benchmark and reports whether one was running
+func (s *Server) stop() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.run == nil || s.run.result != nil {
+		return false
+	}
+	s.run.cancel()
+	return true
+}
+
+// handleResults returns the final results of the last run in the JSON output format
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	current := s.run
+	var result *output.Result
+	if current != nil {
+		result = current.result
+	}
+	s.mu.Unlock()
+
+	switch {
+	case current == nil:
+		writeError(w, http.StatusNotFound, "no benchmark has been started")
+	case result == nil:
+		writeError(w, http.StatusConflict, "benchmark %d is still running", current.id)
+	default:
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeJSON(w, status, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,362 @@
+// Package bench is the library interface to the load generator. It runs a
+// benchmark in-process and returns typed results without writing to stdout,
+// so other Go programs and tests can embed it:
+//
+//	results, err := bench.New(
+//		bench.WithURL("http://localhost:8080/health"),
+//		bench.WithConcurrency(50),
+//		bench.WithDuration(30*time.Second),
+//	).Run(ctx)
+//
+// Everything a JSON config file can express is available through WithConfig.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+	"github.com/benchmarking_go/pkg/metrics"
+)
+
+// Benchmark is a configured benchmark, ready to run
+type Benchmark struct {
+	cfg      *config.Config
+	log      io.Writer
+	verbose  bool
+	hooks    benchmark.Hooks
+	funcs    map[string]benchmark.TemplateFunction
+	sinks    []benchmark.MetricsSink
+	progress []func(Progress)
+	closers  []func() // Run when Run returns (e.g. closing progress channels)
+	err      error    // First invalid option, reported by Run
+}
+
+// Response is what an OnResponse hook sees of a request
+type Response = benchmark.Response
+
+// Option configures a Benchmark
+type Option func(*Benchmark)
+
+// New creates a benchmark from options. Without WithConfig it starts from a
+// single GET request with the usual defaults (10 users, 100 requests each).
+// Invalid options are reported when the benchmark is run.
+func New(opts ...Option) *Benchmark {
+	b := &Benchmark{
+		cfg: &config.Config{Requests: []config.RequestConfig{{Name: "Request"}}},
+		log: io.Discard,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Config returns the configuration the benchmark runs with. Changes made to it
+// before Run take effect.
+func (b *Benchmark) Config() *config.Config {
+	return b.cfg
+}
+
+// Run runs the benchmark until it completes or ctx is cancelled, and returns
+// its results. A cancelled run still returns the results collected so far,
+// with Interrupted set. Invalid options or settings are returned as an error
+// before any request is sent.
+func (b *Benchmark) Run(ctx context.Context) (*Results, error) {
+	defer b.close()
+	if b.err != nil {
+		return nil, b.err
+	}
+	cfg := b.cfg
+	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid benchmark: %w", err)
+	}
+	durationSec, err := cfg.GetDurationSeconds()
+	if err != nil {
+		return nil, fmt.Errorf("invalid benchmark: %w", err)
+	}
+
+	runner := benchmark.NewRunner(cfg, durationSec, cfg.GetTimeout(), cfg.GetRampUpSeconds(), true, b.verbose)
+	runner.Log = b.log
+	if len(b.hooks.OnRequest) > 0 || len(b.hooks.OnResponse) > 0 {
+		runner.Hooks = &b.hooks
+	}
+	runner.Functions = b.funcs
+	sinks, err := metrics.OpenAll(cfg.Output.Sinks, cfg.RunLabels(), b.log)
+	if err != nil {
+		return nil, fmt.Errorf("invalid benchmark: %w", err)
+	}
+	defer metrics.CloseAll(sinks)
+	runner.Sinks = append(sinks, b.sinks...)
+	if len(b.progress) > 0 {
+		progress := &progressSink{callbacks: b.progress}
+		if durationSec == 0 && !cfg.IsScenarioMode() {
+			progress.total = int64(cfg.Settings.ConcurrentUsers) * int64(cfg.Settings.RequestsPerUser)
+		}
+		runner.Sinks = append(runner.Sinks, progress)
+	}
+	stats := runner.Run(ctx)
+
+	var thresholds *benchmark.ThresholdResults
+	if cfg.HasThresholds() {
+		if thresholds, err = benchmark.EvaluateConfigThresholds(stats, cfg); err != nil {
+			return nil, fmt.Errorf("error evaluating thresholds: %w", err)
+		}
+	}
+	results := newResults(stats, cfg, thresholds)
+	results.Interrupted = ctx.Err() != nil
+	results.AbortReason = runner.AbortReason()
+	return results, nil
+}
+
+// close runs the closers once
+func (b *Benchmark) close() {
+	for _, fn := range b.closers {
+		fn()
+	}
+	b.closers = nil
+}
+
+// WithConfig runs a full configuration, e.g. one loaded with config.Load.
+// Options after it adjust that configuration.
+func WithConfig(cfg *config.Config) Option {
+	return func(b *Benchmark) {
+		if cfg == nil {
+			b.fail(fmt.Errorf("config must not be nil"))
+			return
+		}
+		b.cfg = cfg
+	}
+}
+
+// WithURL sets the URL of the benchmarked request
+func WithURL(url string) Option {
+	return func(b *Benchmark) {
+		if req := b.request("WithURL"); req != nil {
+			req.URL = url
+		}
+	}
+}
+
+// WithMethod sets the HTTP method of the benchmarked request (GET by default)
+func WithMethod(method string) Option {
+	return func(b *Benchmark) {
+		if req := b.request("WithMethod"); req != nil {
+			req.Method = method
+		}
+	}
+}
+
+// WithHeader sets a header on the benchmarked request
+func WithHeader(key, value string) Option {
+	return func(b *Benchmark) {
+		if req := b.request("WithHeader"); req != nil {
+			if req.Headers == nil {
+				req.Headers = make(map[string]string)
+			}
+			req.Headers[key] = value
+		}
+	}
+}
+
+// WithBody sets the body of the benchmarked request
+func WithBody(body string) Option {
+	return func(b *Benchmark) {
+		if req := b.request("WithBody"); req != nil {
+			req.Body = body
+		}
+	}
+}
+
+// WithConcurrency sets the number of concurrent users
+func WithConcurrency(users int) Option {
+	return func(b *Benchmark) {
+		if users <= 0 {
+			b.fail(fmt.Errorf("concurrency must be positive, got %d", users))
+			return
+		}
+		b.cfg.Settings.ConcurrentUsers = users
+	}
+}
+
+// WithRequestsPerUser sets how many requests each user sends in request count mode
+func WithRequestsPerUser(requests int) Option {
+	return func(b *Benchmark) {
+		if requests <= 0 {
+			b.fail(fmt.Errorf("requests per user must be positive, got %d", requests))
+			return
+		}
+		b.cfg.Settings.RequestsPerUser = requests
+	}
+}
+
+// WithDuration runs the benchmark for a duration instead of a request count.
+// It is counted in whole seconds.
+func WithDuration(d time.Duration) Option {
+	return func(b *Benchmark) {
+		if d < time.Second {
+			b.fail(fmt.Errorf("duration must be at least 1s, got %s", d))
+			return
+		}
+		b.cfg.Settings.Duration = d.String()
+	}
+}
+
+// WithTimeout sets the per-request timeout (30s by default)
+func WithTimeout(d time.Duration) Option {
+	return func(b *Benchmark) {
+		if d <= 0 {
+			b.fail(fmt.Errorf("timeout must be positive, got %s", d))
+			return
+		}
+		b.cfg.Settings.Timeout = d.String()
+	}
+}
+
+// WithRampUp starts the users gradually over a duration
+func WithRampUp(d time.Duration) Option {
+	return func(b *Benchmark) {
+		if d < 0 {
+			b.fail(fmt.Errorf("ramp-up must not be negative, got %s", d))
+			return
+		}
+		b.cfg.Settings.RampUp = d.String()
+	}
+}
+
+// WithRateLimit limits the total request rate in requests per second
+func WithRateLimit(requestsPerSecond int) Option {
+	return func(b *Benchmark) {
+		if requestsPerSecond < 0 {
+			b.fail(fmt.Errorf("rate limit must not be negative, got %d", requestsPerSecond))
+			return
+		}
+		b.cfg.Settings.RateLimit = requestsPerSecond
+	}
+}
+
+// WithPercentiles sets the latency percentiles reported in the results
+func WithPercentiles(percentiles ...float64) Option {
+	return func(b *Benchmark) {
+		for _, p := range percentiles {
+			if p <= 0 || p > 100 {
+				b.fail(fmt.Errorf("percentile %g is out of range (0, 100]", p))
+				return
+			}
+		}
+		b.cfg.Settings.Percentiles = percentiles
+	}
+}
+
+// WithMetadata adds a run metadata entry (e.g. "gitSha"), reported with the
+// results and as a label of the metrics sinks
+func WithMetadata(key, value string) Option {
+	return func(b *Benchmark) {
+		if b.cfg.Metadata == nil {
+			b.cfg.Metadata = make(map[string]string)
+		}
+		b.cfg.Metadata[key] = value
+	}
+}
+
+// WithTags adds run tags, reported with the results and as a label of the
+// metrics sinks
+func WithTags(tags ...string) Option {
+	return func(b *Benchmark) {
+		b.cfg.Tags = append(b.cfg.Tags, tags...)
+	}
+}
+
+// WithLog sends warnings and progress messages to w instead of discarding them.
+// With verbose set, every request and scenario step is logged as well.
+func WithLog(w io.Writer, verbose bool) Option {
+	return func(b *Benchmark) {
+		if w == nil {
+			w = io.Discard
+		}
+		b.log = w
+		b.verbose = verbose
+	}
+}
+
+// WithOnRequest adds a hook that runs before each request is sent, after the
+// configured headers and body are set. It may change the URL, headers or body
+// (see SetRequestBody); an error fails the request without sending it. Hooks
+// run concurrently from all users.
+func WithOnRequest(hook func(req *http.Request) error) Option {
+	return func(b *Benchmark) {
+		b.hooks.OnRequest = append(b.hooks.OnRequest, hook)
+	}
+}
+
+// WithOnResponse adds a hook that runs after each request, e.g. to record
+// custom metrics. For completed requests its result decides whether the
+// request counts as a success; resp.Success holds the verdict so far, and
+// resp.Error sets the failure message when rejecting. Registering one keeps
+// the start of every response body (up to 1 MiB) for the hooks. Hooks run
+// concurrently from all users.
+func WithOnResponse(hook func(resp *Response) bool) Option {
+	return func(b *Benchmark) {
+		b.hooks.OnResponse = append(b.hooks.OnResponse, hook)
+	}
+}
+
+// WithFunction makes {{$name}} available in the scenario steps of this
+// benchmark, taking precedence over a built-in function of the same name. fn is
+// called for every placeholder, concurrently from all users.
+func WithFunction(name string, fn func() string) Option {
+	return func(b *Benchmark) {
+		if !config.ValidFunctionName(name) {
+			b.fail(fmt.Errorf("WithFunction: invalid function name %q", name))
+			return
+		}
+		if fn == nil {
+			b.fail(fmt.Errorf("WithFunction: function %q is nil", name))
+			return
+		}
+		if b.funcs == nil {
+			b.funcs = make(map[string]benchmark.TemplateFunction)
+		}
+		b.funcs[name] = fn
+	}
+}
+
+// WithSink feeds a metrics sink during the run: every request, every error,
+// and a snapshot of the totals each second and at the end. Built-in sinks
+// are in pkg/metrics; the caller closes sinks that hold resources.
+func WithSink(sink benchmark.MetricsSink) Option {
+	return func(b *Benchmark) {
+		if sink == nil {
+			b.fail(fmt.Errorf("WithSink: sink must not be nil"))
+			return
+		}
+		b.sinks = append(b.sinks, sink)
+	}
+}
+
+// SetRequestBody replaces the body of a request, e.g. from an OnRequest hook
+func SetRequestBody(req *http.Request, body []byte) {
+	benchmark.SetRequestBody(req, body)
+}
+
+// request returns the single request that request options apply to, or nil
+// (recording an error) when the configuration has several or uses scenarios
+func (b *Benchmark) request(option string) *config.RequestConfig {
+	if len(b.cfg.Requests) != 1 || b.cfg.IsScenarioMode() {
+		b.fail(fmt.Errorf("%s needs a config with exactly one request", option))
+		return nil
+	}
+	return &b.cfg.Requests[0]
+}
+
+// fail records the first invalid option
+func (b *Benchmark) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
@@ -0,0 +1,90 @@
+package bench
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+)
+
+// Progress is a periodic progress event of a running benchmark, for host
+// applications rendering their own progress display
+type Progress struct {
+	Elapsed           time.Duration // Active time so far (pauses excluded)
+	Completed         int64         // Requests (or scenario steps) completed so far
+	Total             int64         // Requests planned in request count mode (0 in duration and scenario mode)
+	Errors            int64         // Failed requests so far
+	RequestsPerSecond float64       // Average rate so far
+	P99               time.Duration // 99th percentile latency so far
+	Final             bool          // The run has ended; this is the last event
+}
+
+// WithProgress calls fn with a progress event every second and once more when
+// the run ends. fn runs on the benchmark's reporting goroutine and should
+// return quickly.
+func WithProgress(fn func(Progress)) Option {
+	return func(b *Benchmark) {
+		if fn == nil {
+			b.fail(fmt.Errorf("WithProgress: callback must not be nil"))
+			return
+		}
+		b.progress = append(b.progress, fn)
+	}
+}
+
+// WithProgressChannel sends progress events to ch, like WithProgress. Events
+// that don't fit in the channel's buffer are dropped, so a slow reader never
+// stalls the benchmark. ch is closed when Run first returns.
+func WithProgressChannel(ch chan<- Progress) Option {
+	return func(b *Benchmark) {
+		if ch == nil {
+			b.fail(fmt.Errorf("WithProgressChannel: channel must not be nil"))
+			return
+		}
+		closed := false // Later runs of the benchmark send nothing
+		b.progress = append(b.progress, func(p Progress) {
+			if closed {
+				return
+			}
+			select {
+			case ch <- p:
+			default:
+			}
+		})
+		b.closers = append(b.closers, func() {
+			closed = true
+			close(ch)
+		})
+	}
+}
+
+// progressSink turns the runner's metrics snapshots into progress events
+type progressSink struct {
+	total     int64
+	callbacks []func(Progress)
+}
+
+// RecordRequest is a no-op; progress is reported from snapshots
+func (p *progressSink) RecordRequest(benchmark.RequestMetric) {}
+
+// RecordError is a no-op; progress is reported from snapshots
+func (p *progressSink) RecordError(string, string) {}
+
+// Snapshot sends a progress event to every callback
+func (p *progressSink) Snapshot(snap *benchmark.StatsSnapshot, final bool) error {
+	stats := benchmark.NewStatsWithOptions(snap.Histogram != nil, false)
+	stats.Merge(snap)
+	event := Progress{
+		Elapsed:           time.Duration(snap.TotalDuration * float64(time.Second)),
+		Completed:         snap.TotalRequests,
+		Total:             p.total,
+		Errors:            snap.FailureCount,
+		RequestsPerSecond: stats.RequestsPerSecond,
+		P99:               time.Duration(stats.GetLatencyPercentile(99)) * time.Microsecond,
+		Final:             final,
+	}
+	for _, fn := range p.callbacks {
+		fn(event)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package bench
+
+import (
+	"sort"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// Results are the outcome of a benchmark run
+type Results struct {
+	Duration          time.Duration
+	Requests          int64 // Completed requests (successes and failures)
+	Successes         int64
+	Failures          int64
+	Cancelled         int64 // Requests in flight when the run stopped
+	Skipped           int64 // Scenario steps skipped by their `when` condition
+	RequestsPerSecond float64
+	Bytes             int64 // Response bytes received
+
+	Latency        Latency // All completed requests
+	SuccessLatency Latency // Successful requests only
+	FailureLatency Latency // Failed requests only
+	TTFB           Latency // Time to first byte (until the response headers arrived)
+
+	StatusCodes StatusCodes
+	Errors      map[string]int  // Failure counts by error message
+	Endpoints   []EndpointStats // Per request (or scenario step), ordered by name
+
+	Thresholds  *benchmark.ThresholdResults // Threshold verdicts (nil when none are configured)
+	Interrupted bool                        // The context was cancelled before the run completed
+	AbortReason string                      // Why rolling thresholds stopped the run ("" if they did not)
+
+	// Stats are the full statistics, e.g. for the report writers of the output package
+	Stats *benchmark.Stats
+}
+
+// Latency summarizes a latency distribution
+type Latency struct {
+	Count       int64
+	Mean        time.Duration
+	Max         time.Duration
+	Percentiles map[float64]time.Duration // By percentile, e.g. 99
+}
+
+// StatusCodes counts responses by status class. Other counts requests without
+// an HTTP status, such as connection errors and timeouts.
+type StatusCodes struct {
+	Informational int64 // 1xx
+	Success       int64 // 2xx
+	Redirect      int64 // 3xx
+	ClientError   int64 // 4xx
+	ServerError   int64 // 5xx
+	Other         int64
+}
+
+// EndpointStats are the results of one request (or scenario step)
+type EndpointStats struct {
+	Name      string
+	URL       string
+	Method    string
+	Requests  int64
+	Successes int64
+	Failures  int64
+	Mean      time.Duration
+	Errors    map[string]int
+}
+
+// Passed reports whether the run completed without failures and met its thresholds
+func (r *Results) Passed() bool {
+	return r.Failures == 0 && !r.Interrupted && r.AbortReason == "" &&
+		(r.Thresholds == nil || r.Thresholds.Passed)
+}
+
+// newResults collects the results of a finished run
+func newResults(stats *benchmark.Stats, cfg *config.Config, thresholds *benchmark.ThresholdResults) *Results {
+	percentiles := cfg.Settings.Percentiles
+	success, failure := stats.LatencyByOutcome(percentiles)
+	ttfb := stats.TTFB(percentiles)
+
+	results := &Results{
+		Duration:          time.Duration(stats.TotalDuration * float64(time.Second)),
+		Requests:          stats.TotalRequests,
+		Successes:         stats.SuccessCount,
+		Failures:          stats.FailureCount,
+		Cancelled:         stats.CancelledCount,
+		Skipped:           stats.SkippedCount,
+		RequestsPerSecond: stats.RequestsPerSecond,
+		Bytes:             stats.TotalBytes,
+		Latency: Latency{
+			Count:       success.Count + failure.Count,
+			Mean:        micros(stats.AverageResponseTime()),
+			Max:         micros(float64(stats.MaxResponseTime())),
+			Percentiles: make(map[float64]time.Duration, len(percentiles)),
+		},
+		SuccessLatency: outcomeLatency(success, percentiles),
+		FailureLatency: outcomeLatency(failure, percentiles),
+		TTFB:           outcomeLatency(ttfb, percentiles),
+		StatusCodes: StatusCodes{
+			Informational: stats.Http1xxCount,
+			Success:       stats.Http2xxCount,
+			Redirect:      stats.Http3xxCount,
+			ClientError:   stats.Http4xxCount,
+			ServerError:   stats.Http5xxCount,
+			Other:         stats.OtherCount,
+		},
+		Errors:     stats.GetErrors(),
+		Thresholds: thresholds,
+		Stats:      stats,
+	}
+	for _, p := range percentiles {
+		results.Latency.Percentiles[p] = micros(float64(stats.GetLatencyPercentile(p)))
+	}
+
+	stats.Lock()
+	for _, rs := range stats.RequestStats {
+		endpoint := EndpointStats{
+			Name:      rs.Name,
+			URL:       rs.URL,
+			Method:    rs.Method,
+			Requests:  rs.RequestCount,
+			Successes: rs.SuccessCount,
+			Failures:  rs.FailureCount,
+			Errors:    make(map[string]int, len(rs.Errors)),
+		}
+		if rs.RequestCount > 0 {
+			endpoint.Mean = micros(float64(rs.TotalLatency) / float64(rs.RequestCount))
+		}
+		for msg, count := range rs.Errors {
+			endpoint.Errors[msg] = count
+		}
+		results.Endpoints = append(results.Endpoints, endpoint)
+	}
+	stats.Unlock()
+	sort.Slice(results.Endpoints, func(i, j int) bool { return results.Endpoints[i].Name < results.Endpoints[j].Name })
+
+	return results
+}
+
+// outcomeLatency converts the latencies of one request outcome
+func outcomeLatency(summary benchmark.LatencySummary, percentiles []float64) Latency {
+	latency := Latency{
+		Count:       summary.Count,
+		Mean:        micros(summary.AvgUs),
+		Max:         micros(float64(summary.MaxUs)),
+		Percentiles: make(map[float64]time.Duration, len(percentiles)),
+	}
+	for i, p := range percentiles {
+		latency.Percentiles[p] = micros(float64(summary.Percentiles[i]))
+	}
+	return latency
+}
+
+// micros converts microseconds to a duration
+func micros(us float64) time.Duration {
+	return time.Duration(us * float64(time.Microsecond))
+}
@@ -0,0 +1,75 @@
+package benchmark
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+	"time"
+)
+
+// addressTransport records every request in the stats of the server IP its
+// connection goes to, so uneven balancing across a host's addresses shows.
+// A request counts as failed when it gets no response or a 5xx status.
+type addressTransport struct {
+	base  http.RoundTripper
+	stats *Stats
+}
+
+// RoundTrip sends the request, noting the address of the connection it gets.
+// The request is recorded once its response body is closed.
+func (t *addressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	var ip string
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if addr, ok := info.Conn.RemoteAddr().(*net.TCPAddr); ok {
+				ip = addr.IP.String()
+			}
+		},
+	}
+	resp, err := t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	if ip == "" {
+		return resp, err // No connection (dial failed, or an engine without traces)
+	}
+	if err != nil {
+		t.stats.RecordAddress(ip, time.Since(start).Microseconds(), false)
+		return nil, err
+	}
+	resp.Body = &addressBody{ReadCloser: resp.Body, transport: t, ip: ip, start: start, success: resp.StatusCode < 500}
+	return resp, nil
+}
+
+// addressBody records its request when the response body is closed
+type addressBody struct {
+	io.ReadCloser
+	transport *addressTransport
+	ip        string
+	start     time.Time
+	success   bool
+	closed    atomic.Bool
+}
+
+// Close closes the body and records the request
+func (b *addressBody) Close() error {
+	if b.closed.CompareAndSwap(false, true) {
+		b.transport.stats.RecordAddress(b.ip, time.Since(b.start).Microseconds(), b.success)
+	}
+	return b.ReadCloser.Close()
+}
+
+// trackAddresses wraps the HTTP clients' transports to record per-IP stats
+// when connections are spread over the resolved addresses
+func (r *Runner) trackAddresses() {
+	if !r.Config.Settings.RoundRobinIPs {
+		return
+	}
+	clients := r.clients
+	if len(clients) == 0 {
+		clients = []*http.Client{r.client}
+	}
+	for _, client := range clients {
+		client.Transport = &addressTransport{base: client.Transport, stats: r.Stats}
+	}
+}
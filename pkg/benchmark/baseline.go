@@ -0,0 +1,132 @@
+package benchmark
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// Baseline holds the headline metrics of a previous run that results are compared against
+type Baseline struct {
+	Source            string           // File the baseline was loaded from
+	RequestsPerSecond float64          // Average requests per second
+	ErrorRate         float64          // Failed share of requests
+	Latency           map[string]int64 // Microseconds by metric name ("avg", "p95", "p99.9")
+}
+
+// EvaluateBaseline compares the results with a baseline run and appends a check per
+// configured regression limit. A nil thresholds uses DefaultBaselineThresholds.
+func (r *ThresholdResults) EvaluateBaseline(stats *Stats, baseline *Baseline, thresholds *config.BaselineThresholdConfig) error {
+	if thresholds == nil {
+		thresholds = config.DefaultBaselineThresholds()
+	}
+
+	// Check latency metrics in a stable order
+	metrics := make([]string, 0, len(thresholds.MaxLatencyRegression))
+	for metric := range thresholds.MaxLatencyRegression {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	for _, metric := range metrics {
+		result, err := checkLatencyRegression(stats, baseline, metric, thresholds.MaxLatencyRegression[metric])
+		if err != nil {
+			return err
+		}
+		r.add(result)
+	}
+
+	if thresholds.MaxRPSDrop > 0 {
+		r.add(checkRPSDrop(stats, baseline, thresholds.MaxRPSDrop))
+	}
+	if thresholds.MaxErrorRateIncrease > 0 {
+		r.add(checkErrorRateIncrease(stats, baseline, thresholds.MaxErrorRateIncrease))
+	}
+	return nil
+}
+
+// add appends a threshold result and updates the overall verdict
+func (r *ThresholdResults) add(result ThresholdResult) {
+	r.Results = append(r.Results, result)
+	if !result.Passed {
+		r.Passed = false
+	}
+}
+
+// currentLatency returns the current run's value for a latency metric name
+func currentLatency(stats *Stats, metric string) (int64, error) {
+	if metric == "avg" {
+		return int64(stats.AverageResponseTime()), nil
+	}
+	percentile, err := strconv.ParseFloat(strings.TrimPrefix(metric, "p"), 64)
+	if err != nil || !strings.HasPrefix(metric, "p") || percentile <= 0 || percentile > 100 {
+		return 0, fmt.Errorf("invalid baseline latency metric %q (use \"avg\" or a percentile like \"p95\")", metric)
+	}
+	return stats.GetLatencyPercentile(percentile), nil
+}
+
+// checkLatencyRegression checks that a latency metric grew by at most maxRegression
+func checkLatencyRegression(stats *Stats, baseline *Baseline, metric string, maxRegression float64) (ThresholdResult, error) {
+	actual, err := currentLatency(stats, metric)
+	if err != nil {
+		return ThresholdResult{}, err
+	}
+	base, ok := baseline.Latency[metric]
+	if !ok || base <= 0 {
+		return ThresholdResult{}, fmt.Errorf("baseline %s has no %s latency (report it with --percentiles)", baseline.Source, metric)
+	}
+
+	change := float64(actual-base) / float64(base)
+	passed := change <= maxRegression
+	label := strings.ToUpper(metric[:1]) + metric[1:]
+	expected := fmt.Sprintf("≤ %+.1f%% (baseline %s)", maxRegression*100, formatMicroseconds(base))
+	actualStr := fmt.Sprintf("%+.1f%% (%s)", change*100, formatMicroseconds(actual))
+
+	return ThresholdResult{
+		Name:     fmt.Sprintf("Baseline %s Latency", label),
+		Passed:   passed,
+		Expected: expected,
+		Actual:   actualStr,
+		Message:  formatResultMessage(fmt.Sprintf("%s Latency vs baseline", label), passed, actualStr, expected),
+	}, nil
+}
+
+// checkRPSDrop checks that requests per second fell by at most maxDrop
+func checkRPSDrop(stats *Stats, baseline *Baseline, maxDrop float64) ThresholdResult {
+	change := 0.0
+	if baseline.RequestsPerSecond > 0 {
+		change = (stats.RequestsPerSecond - baseline.RequestsPerSecond) / baseline.RequestsPerSecond
+	}
+	passed := change >= -maxDrop
+	expected := fmt.Sprintf("≥ %+.1f%% (baseline %.2f)", -maxDrop*100, baseline.RequestsPerSecond)
+	actual := fmt.Sprintf("%+.1f%% (%.2f)", change*100, stats.RequestsPerSecond)
+
+	return ThresholdResult{
+		Name:     "Baseline Requests/sec",
+		Passed:   passed,
+		Expected: expected,
+		Actual:   actual,
+		Message:  formatResultMessage("Requests/sec vs baseline", passed, actual, expected),
+	}
+}
+
+// checkErrorRateIncrease checks that the error rate rose by at most maxIncrease (absolute)
+func checkErrorRateIncrease(stats *Stats, baseline *Baseline, maxIncrease float64) ThresholdResult {
+	errorRate := 0.0
+	if total := stats.SuccessCount + stats.FailureCount; total > 0 {
+		errorRate = float64(stats.FailureCount) / float64(total)
+	}
+	passed := errorRate-baseline.ErrorRate <= maxIncrease
+	expected := fmt.Sprintf("≤ %.2f%% (baseline %.2f%%)", (baseline.ErrorRate+maxIncrease)*100, baseline.ErrorRate*100)
+	actual := fmt.Sprintf("%.2f%%", errorRate*100)
+
+	return ThresholdResult{
+		Name:     "Baseline Error Rate",
+		Passed:   passed,
+		Expected: expected,
+		Actual:   actual,
+		Message:  formatResultMessage("Error Rate vs baseline", passed, actual, expected),
+	}
+}
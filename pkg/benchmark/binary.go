@@ -0,0 +1,122 @@
+// Package benchmark provides benchmarking functionality
+package benchmark
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// bodyDigest holds the size and requested checksums of a response body
+type bodyDigest struct {
+	size   int64
+	sha256 string
+	md5    string
+}
+
+// bodyHasher computes the checksums a validation asks for as a body is
+// written to it, so a body can be checked while it is being read elsewhere
+type bodyHasher struct {
+	sha, md hash.Hash
+	writer  io.Writer
+}
+
+// newBodyHasher creates a hasher for the checksums of validate. It returns nil
+// when no checksum is asked for.
+func newBodyHasher(validate *config.ValidateConfig) *bodyHasher {
+	if validate == nil || (validate.SHA256 == "" && validate.MD5 == "") {
+		return nil
+	}
+	h := &bodyHasher{}
+	writers := []io.Writer{}
+	if validate.SHA256 != "" {
+		h.sha = sha256.New()
+		writers = append(writers, h.sha)
+	}
+	if validate.MD5 != "" {
+		h.md = md5.New()
+		writers = append(writers, h.md)
+	}
+	h.writer = io.MultiWriter(writers...)
+	return h
+}
+
+// Write hashes p
+func (h *bodyHasher) Write(p []byte) (int, error) {
+	return h.writer.Write(p)
+}
+
+// digest returns the checksums of everything written, for a body of size bytes
+func (h *bodyHasher) digest(size int64) *bodyDigest {
+	digest := &bodyDigest{size: size}
+	if h == nil {
+		return digest
+	}
+	if h.sha != nil {
+		digest.sha256 = hex.EncodeToString(h.sha.Sum(nil))
+	}
+	if h.md != nil {
+		digest.md5 = hex.EncodeToString(h.md.Sum(nil))
+	}
+	return digest
+}
+
+// readBinaryBody streams a response body through size and checksum counters
+// without keeping it in memory. Only the checksums the validation asks for are computed.
+func readBinaryBody(body io.Reader, validate *config.ValidateConfig) (*bodyDigest, error) {
+	hasher := newBodyHasher(validate)
+
+	var size int64
+	var err error
+	if hasher != nil {
+		size, err = copyBody(hasher, body)
+	} else {
+		size, err = discardBody(body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return hasher.digest(size), nil
+}
+
+// digestBytes computes the size and requested checksums of an in-memory body
+func digestBytes(body []byte, validate *config.ValidateConfig) *bodyDigest {
+	digest := &bodyDigest{size: int64(len(body))}
+	if validate != nil && validate.SHA256 != "" {
+		sum := sha256.Sum256(body)
+		digest.sha256 = hex.EncodeToString(sum[:])
+	}
+	if validate != nil && validate.MD5 != "" {
+		sum := md5.Sum(body)
+		digest.md5 = hex.EncodeToString(sum[:])
+	}
+	return digest
+}
+
+// validateDigest checks body size and checksum assertions
+func validateDigest(digest *bodyDigest, validate *config.ValidateConfig) []string {
+	var errors []string
+
+	if validate.Size > 0 && digest.size != validate.Size {
+		errors = append(errors, fmt.Sprintf("body size: expected %d bytes, got %d", validate.Size, digest.size))
+	}
+	if validate.MinSize > 0 && digest.size < validate.MinSize {
+		errors = append(errors, fmt.Sprintf("body size %d bytes below minimum %d", digest.size, validate.MinSize))
+	}
+	if validate.MaxSize > 0 && digest.size > validate.MaxSize {
+		errors = append(errors, fmt.Sprintf("body size %d bytes above maximum %d", digest.size, validate.MaxSize))
+	}
+	if validate.SHA256 != "" && !strings.EqualFold(digest.sha256, validate.SHA256) {
+		errors = append(errors, fmt.Sprintf("sha256 mismatch: expected %s, got %s", validate.SHA256, digest.sha256))
+	}
+	if validate.MD5 != "" && !strings.EqualFold(digest.md5, validate.MD5) {
+		errors = append(errors, fmt.Sprintf("md5 mismatch: expected %s, got %s", validate.MD5, digest.md5))
+	}
+	return errors
+}
@@ -0,0 +1,26 @@
+//go:build linux
+
+package benchmark
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// bindToInterface returns a net.Dialer Control function sending the
+// connection's traffic out through the named interface (SO_BINDTODEVICE)
+func bindToInterface(name string) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		var bindErr error
+		err := c.Control(func(fd uintptr) {
+			bindErr = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, name)
+		})
+		if err != nil {
+			return err
+		}
+		if bindErr != nil {
+			return fmt.Errorf("bind to interface %s: %w", name, bindErr)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package benchmark
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// bindToInterface is only implemented on Linux; connections fail elsewhere
+func bindToInterface(name string) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		return fmt.Errorf("binding to interface %s is only supported on Linux", name)
+	}
+}
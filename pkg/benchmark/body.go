@@ -0,0 +1,86 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// preparedBody is a request body serialized once and reused for every request
+type preparedBody struct {
+	text      string
+	templated bool               // Contains placeholders that must be resolved per request
+	tmpl      *template.Template // bodyTemplate executed per request (nil for other bodies)
+	err       error
+}
+
+// bodyTemplateData is what a bodyTemplate is executed with
+type bodyTemplateData struct {
+	Vars      map[string]string // Config variables, or the scenario's current variables
+	Worker    int               // Index of the virtual user sending the request
+	functions templateFunctions
+}
+
+// Func returns the value of a {{$name}} template function, e.g. {{.Func "uuid"}}
+func (d *bodyTemplateData) Func(name string) (string, error) {
+	fn, ok := d.functions[name]
+	if !ok {
+		return "", fmt.Errorf("unknown function %q", name)
+	}
+	return fn(), nil
+}
+
+// newPreparedBody reads or marshals a body once
+func newPreparedBody(text string, err error) *preparedBody {
+	return &preparedBody{text: text, templated: strings.Contains(text, "{{"), err: err}
+}
+
+// newTemplateBody parses a bodyTemplate once
+func newTemplateBody(name, source string) *preparedBody {
+	tmpl, err := config.ParseBodyTemplate(name, source)
+	return &preparedBody{tmpl: tmpl, err: err}
+}
+
+// render executes a bodyTemplate for one request
+func (p *preparedBody) render(data *bodyTemplateData) (string, error) {
+	var b strings.Builder
+	if err := p.tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("body template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// requestBody returns the serialized body of a request, preparing it on first
+// use. A bodyTemplate is executed for worker.
+func (r *Runner) requestBody(reqConfig *config.RequestConfig, worker int) (string, error) {
+	body, ok := r.bodies.Load(reqConfig)
+	if !ok {
+		var prepared *preparedBody
+		if reqConfig.BodyTemplate != "" {
+			prepared = newTemplateBody(reqConfig.Name, reqConfig.BodyTemplate)
+		} else {
+			prepared = newPreparedBody(config.PrepareRequestBody(reqConfig))
+		}
+		body, _ = r.bodies.LoadOrStore(reqConfig, prepared)
+	}
+	prepared := body.(*preparedBody)
+	if prepared.err != nil || prepared.tmpl == nil {
+		return prepared.text, prepared.err
+	}
+	return prepared.render(&bodyTemplateData{Vars: r.Config.Variables, Worker: worker, functions: r.functions})
+}
+
+// newBodyRequest creates a request sending body. The body is read through a
+// strings.Reader, which shares the string's memory instead of copying it, and
+// lets the request set ContentLength and GetBody so redirects and retries can
+// replay it. An empty body sends none.
+func newBodyRequest(ctx context.Context, method, url, body string) (*http.Request, error) {
+	if body == "" {
+		return http.NewRequestWithContext(ctx, method, url, nil)
+	}
+	return http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+}
@@ -0,0 +1,77 @@
+package benchmark
+
+import (
+	"io"
+	"sync"
+)
+
+const (
+	copyBufferSize      = 32 * 1024 // Size of the pooled buffers response bodies are drained through
+	maxKeptBody         = 1 << 20   // Bytes of a body kept when only error messages and failure captures need it
+	maxPreallocatedBody = 64 << 20  // Largest Content-Length a body buffer is allocated for up front
+)
+
+// copyBuffers pools the buffers used to drain response bodies, so reading a body
+// that isn't needed allocates nothing
+var copyBuffers = sync.Pool{
+	New: func() any {
+		buf := make([]byte, copyBufferSize)
+		return &buf
+	},
+}
+
+// discardBody reads a response body to the end without keeping it and returns its size
+func discardBody(body io.Reader) (int64, error) {
+	buf := copyBuffers.Get().(*[]byte)
+	defer copyBuffers.Put(buf)
+
+	var size int64
+	for {
+		n, err := body.Read(*buf)
+		size += int64(n)
+		if err == io.EOF {
+			return size, nil
+		}
+		if err != nil {
+			return size, err
+		}
+	}
+}
+
+// copyBody copies a response body to dst through a pooled buffer
+func copyBody(dst io.Writer, body io.Reader) (int64, error) {
+	buf := copyBuffers.Get().(*[]byte)
+	defer copyBuffers.Put(buf)
+	return io.CopyBuffer(dst, body, *buf)
+}
+
+// readBody reads a whole response body into memory. A known Content-Length
+// (-1 when unknown) sizes the buffer once, instead of growing and copying it
+// while a large body arrives.
+func readBody(body io.Reader, contentLength int64) ([]byte, error) {
+	if contentLength <= 0 || contentLength > maxPreallocatedBody {
+		return io.ReadAll(body)
+	}
+	data := make([]byte, contentLength)
+	n, err := io.ReadFull(body, data)
+	if err != nil {
+		return data[:n], err
+	}
+	// Anything beyond the announced length is still read
+	rest, err := io.ReadAll(body)
+	if len(rest) > 0 {
+		data = append(data, rest...)
+	}
+	return data, err
+}
+
+// readBodyPrefix keeps the first limit bytes of a response body and drains the
+// rest without keeping it, returning the kept bytes and the full size
+func readBodyPrefix(body io.Reader, contentLength, limit int64) ([]byte, int64, error) {
+	kept, err := readBody(io.LimitReader(body, limit), min(contentLength, limit))
+	if err != nil {
+		return kept, int64(len(kept)), err
+	}
+	rest, err := discardBody(body)
+	return kept, int64(len(kept)) + rest, err
+}
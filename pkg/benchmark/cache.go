@@ -0,0 +1,230 @@
+package benchmark
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Cache outcomes of a response, as told by its cache headers
+const (
+	cacheUnknown = iota
+	cacheHit
+	cacheMiss
+)
+
+// cacheOutcome classifies a response by the headers CDNs and caching proxies
+// set: CF-Cache-Status, X-Cache (CloudFront, Fastly, Varnish), a non-zero Age,
+// and Via entries that name a hit or miss
+func cacheOutcome(header http.Header) int {
+	switch strings.ToUpper(strings.TrimSpace(header.Get("CF-Cache-Status"))) {
+	case "HIT", "STALE", "UPDATING", "REVALIDATED":
+		return cacheHit
+	case "MISS", "EXPIRED", "BYPASS", "DYNAMIC":
+		return cacheMiss
+	}
+	if outcome := cacheKeyword(header.Values("X-Cache")); outcome != cacheUnknown {
+		return outcome
+	}
+	if age, err := strconv.Atoi(strings.TrimSpace(header.Get("Age"))); err == nil && age > 0 {
+		return cacheHit
+	}
+	return cacheKeyword(header.Values("Via"))
+}
+
+// cacheKeyword looks for HIT or MISS in the last entry of a header that lists
+// one entry per cache layer (e.g. Fastly's "MISS, HIT"), the one nearest the client
+func cacheKeyword(values []string) int {
+	if len(values) == 0 {
+		return cacheUnknown
+	}
+	entries := strings.Split(values[len(values)-1], ",")
+	last := strings.ToUpper(entries[len(entries)-1])
+	switch {
+	case strings.Contains(last, "HIT"):
+		return cacheHit
+	case strings.Contains(last, "MISS"):
+		return cacheMiss
+	}
+	return cacheUnknown
+}
+
+// cacheTracker splits responses by cache outcome, overall and per endpoint
+type cacheTracker struct {
+	mu        sync.Mutex
+	hit       latencySeries
+	miss      latencySeries
+	unknown   int64
+	endpoints map[string]*CacheEndpoint
+}
+
+// CacheEndpoint counts the cache outcomes of one request or scenario step
+type CacheEndpoint struct {
+	Name          string `json:"name"`
+	Hits          int64  `json:"hits"`
+	Misses        int64  `json:"misses"`
+	Unknown       int64  `json:"unknown,omitempty"`
+	HitLatencyUs  int64  `json:"hitLatencyUs"` // Total, for the average
+	MissLatencyUs int64  `json:"missLatencyUs"`
+}
+
+// HitRatio returns the share of hits among the responses with a known outcome
+func (e CacheEndpoint) HitRatio() float64 {
+	return hitRatio(e.Hits, e.Misses)
+}
+
+// AvgHitUs returns the average latency of hits in microseconds
+func (e CacheEndpoint) AvgHitUs() float64 {
+	if e.Hits == 0 {
+		return 0
+	}
+	return float64(e.HitLatencyUs) / float64(e.Hits)
+}
+
+// AvgMissUs returns the average latency of misses in microseconds
+func (e CacheEndpoint) AvgMissUs() float64 {
+	if e.Misses == 0 {
+		return 0
+	}
+	return float64(e.MissLatencyUs) / float64(e.Misses)
+}
+
+// hitRatio returns hits over hits and misses (0 without either)
+func hitRatio(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// newCacheTracker creates an empty tracker, with histograms when useHdr is set
+func newCacheTracker(useHdr bool) *cacheTracker {
+	return &cacheTracker{
+		hit:       newLatencySeries(useHdr),
+		miss:      newLatencySeries(useHdr),
+		endpoints: make(map[string]*CacheEndpoint),
+	}
+}
+
+// SetCacheTracking makes the stats classify every response as a cache hit or
+// miss by its headers (see CacheReport)
+func (s *Stats) SetCacheTracking(enabled bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !enabled {
+		s.cache = nil
+	} else if s.cache == nil {
+		s.cache = newCacheTracker(s.useHdr)
+	}
+}
+
+// RecordCache records the cache outcome of a response to the named request.
+// It does nothing unless cache tracking is enabled.
+func (s *Stats) RecordCache(name string, header http.Header, responseTimeMicros int64) {
+	ct := s.cache
+	if ct == nil {
+		return
+	}
+	outcome := cacheOutcome(header)
+
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	endpoint := ct.endpoints[name]
+	if endpoint == nil {
+		endpoint = &CacheEndpoint{Name: name}
+		ct.endpoints[name] = endpoint
+	}
+	switch outcome {
+	case cacheHit:
+		ct.hit.record(responseTimeMicros)
+		endpoint.Hits++
+		endpoint.HitLatencyUs += responseTimeMicros
+	case cacheMiss:
+		ct.miss.record(responseTimeMicros)
+		endpoint.Misses++
+		endpoint.MissLatencyUs += responseTimeMicros
+	default:
+		ct.unknown++
+		endpoint.Unknown++
+	}
+}
+
+// CacheReport is the cache behaviour seen during a run
+type CacheReport struct {
+	Hits      int64
+	Misses    int64
+	Unknown   int64 // Responses without a recognizable cache header
+	HitRatio  float64
+	Hit       LatencySummary
+	Miss      LatencySummary
+	Endpoints []CacheEndpoint // By name
+}
+
+// CacheReport returns the cache hit ratio and the latencies of hits and misses
+// with the given percentiles, or nil when cache tracking is off
+func (s *Stats) CacheReport(percentiles []float64) *CacheReport {
+	ct := s.cache
+	if ct == nil {
+		return nil
+	}
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	report := &CacheReport{
+		Hits:     ct.hit.count,
+		Misses:   ct.miss.count,
+		Unknown:  ct.unknown,
+		HitRatio: hitRatio(ct.hit.count, ct.miss.count),
+		Hit:      ct.hit.summary(percentiles),
+		Miss:     ct.miss.summary(percentiles),
+	}
+	for _, endpoint := range ct.endpoints {
+		report.Endpoints = append(report.Endpoints, *endpoint)
+	}
+	sort.Slice(report.Endpoints, func(i, j int) bool { return report.Endpoints[i].Name < report.Endpoints[j].Name })
+	return report
+}
+
+// CacheSnapshot is a serializable copy of the cache outcomes
+type CacheSnapshot struct {
+	Hit       *LatencySeriesSnapshot `json:"hit"`
+	Miss      *LatencySeriesSnapshot `json:"miss"`
+	Unknown   int64                  `json:"unknown,omitempty"`
+	Endpoints []CacheEndpoint        `json:"endpoints,omitempty"`
+}
+
+// snapshot copies the tracker
+func (ct *cacheTracker) snapshot() *CacheSnapshot {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	snap := &CacheSnapshot{Hit: ct.hit.snapshot(), Miss: ct.miss.snapshot(), Unknown: ct.unknown}
+	for _, endpoint := range ct.endpoints {
+		snap.Endpoints = append(snap.Endpoints, *endpoint)
+	}
+	return snap
+}
+
+// merge adds a snapshot taken in another process
+func (ct *cacheTracker) merge(snap *CacheSnapshot) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	ct.hit.merge(snap.Hit)
+	ct.miss.merge(snap.Miss)
+	ct.unknown += snap.Unknown
+	for _, other := range snap.Endpoints {
+		endpoint := ct.endpoints[other.Name]
+		if endpoint == nil {
+			endpoint = &CacheEndpoint{Name: other.Name}
+			ct.endpoints[other.Name] = endpoint
+		}
+		endpoint.Hits += other.Hits
+		endpoint.Misses += other.Misses
+		endpoint.Unknown += other.Unknown
+		endpoint.HitLatencyUs += other.HitLatencyUs
+		endpoint.MissLatencyUs += other.MissLatencyUs
+	}
+}
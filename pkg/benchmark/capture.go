@@ -0,0 +1,212 @@
+// Package benchmark provides benchmarking functionality
+package benchmark
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// DefaultCaptureDir is the directory failing exchanges are written to when none is configured
+const DefaultCaptureDir = "failures"
+
+// FailureCapture writes the full request and response of the first N failures
+// in each error category to a debug directory
+type FailureCapture struct {
+	dir    string
+	limit  int
+	mu     sync.Mutex
+	counts map[string]int
+	saved  int
+}
+
+// NewFailureCapture creates a failure capture that keeps up to limit exchanges
+// per error category. It returns nil (capture disabled) when limit <= 0.
+func NewFailureCapture(dir string, limit int) *FailureCapture {
+	if limit <= 0 {
+		return nil
+	}
+	if dir == "" {
+		dir = DefaultCaptureDir
+	}
+	return &FailureCapture{
+		dir:    dir,
+		limit:  limit,
+		counts: make(map[string]int),
+	}
+}
+
+// Capture records one failed exchange if its category has not reached the limit.
+// resp may be nil for transport errors; respBody is the already-read response body.
+func (fc *FailureCapture) Capture(category, errMsg string, req *http.Request, reqBody string, resp *http.Response, respBody []byte) {
+	if fc == nil {
+		return
+	}
+
+	fc.mu.Lock()
+	if fc.counts[category] >= fc.limit {
+		fc.mu.Unlock()
+		return
+	}
+	fc.counts[category]++
+	n := fc.counts[category]
+	fc.saved++
+	fc.mu.Unlock()
+
+	name := fmt.Sprintf("%s-%03d.txt", captureFileName(category), n)
+	writeExchange(fc.dir, name, "Error: "+errMsg, req, reqBody, resp, respBody)
+}
+
+// writeExchange writes a request and its response (if any) to a text file in
+// dir, under a "# <title>" line
+func writeExchange(dir, name, title string, req *http.Request, reqBody string, resp *http.Response, respBody []byte) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", title)
+	fmt.Fprintf(&sb, "# Time: %s\n\n", time.Now().Format(time.RFC3339Nano))
+
+	if req != nil {
+		fmt.Fprintf(&sb, "> %s %s %s\n", req.Method, req.URL.String(), req.Proto)
+		writeCapturedHeaders(&sb, "> ", req.Header)
+		sb.WriteString("\n")
+		if reqBody != "" {
+			sb.WriteString(reqBody)
+			sb.WriteString("\n\n")
+		}
+	}
+
+	if resp != nil {
+		fmt.Fprintf(&sb, "< %s %s\n", resp.Proto, resp.Status)
+		writeCapturedHeaders(&sb, "< ", resp.Header)
+		sb.WriteString("\n")
+		sb.Write(respBody)
+		sb.WriteString("\n")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	// Best effort: a failed debug write must not affect the benchmark
+	_ = os.WriteFile(filepath.Join(dir, name), []byte(sb.String()), 0644)
+}
+
+// Saved returns the number of exchanges written so far
+func (fc *FailureCapture) Saved() int {
+	if fc == nil {
+		return 0
+	}
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.saved
+}
+
+// Dir returns the directory captures are written to
+func (fc *FailureCapture) Dir() string {
+	if fc == nil {
+		return ""
+	}
+	return fc.dir
+}
+
+// DefaultSampleDir is the directory sampled exchanges are written to when none is configured
+const DefaultSampleDir = "captures"
+
+// ResponseSampler writes a random sample of request/response pairs to disk, so
+// responses can be spot-checked after the run without verbose logging
+type ResponseSampler struct {
+	dir   string
+	rate  float64
+	saved atomic.Int64
+}
+
+// NewResponseSampler creates a sampler for cfg. It returns nil (sampling
+// disabled) when cfg is nil.
+func NewResponseSampler(cfg *config.ResponseCaptureConfig) *ResponseSampler {
+	if cfg == nil || cfg.Rate <= 0 {
+		return nil
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dir = DefaultSampleDir
+	}
+	return &ResponseSampler{dir: dir, rate: cfg.Rate}
+}
+
+// Sample decides whether the next response is saved, before its body is read
+func (rs *ResponseSampler) Sample() bool {
+	return rs != nil && rand.Float64() < rs.rate
+}
+
+// Save writes a sampled exchange; errMsg is empty for successful responses.
+// respBody is the already-read response body.
+func (rs *ResponseSampler) Save(errMsg string, req *http.Request, reqBody string, resp *http.Response, respBody []byte) {
+	title := "Result: success"
+	if errMsg != "" {
+		title = "Result: failure: " + errMsg
+	}
+	n := rs.saved.Add(1)
+	writeExchange(rs.dir, fmt.Sprintf("sample-%06d.txt", n), title, req, reqBody, resp, respBody)
+}
+
+// Saved returns the number of exchanges written so far
+func (rs *ResponseSampler) Saved() int64 {
+	if rs == nil {
+		return 0
+	}
+	return rs.saved.Load()
+}
+
+// Dir returns the directory samples are written to
+func (rs *ResponseSampler) Dir() string {
+	if rs == nil {
+		return ""
+	}
+	return rs.dir
+}
+
+// writeCapturedHeaders writes headers in sorted order with a direction prefix
+func writeCapturedHeaders(sb *strings.Builder, prefix string, headers http.Header) {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range headers[key] {
+			fmt.Fprintf(sb, "%s%s: %s\n", prefix, key, value)
+		}
+	}
+}
+
+// unsafeFileChars matches characters not allowed in capture file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// captureFileName turns an error category into a safe file name prefix
+func captureFileName(category string) string {
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(category, "_"), "_")
+	if len(name) > 60 {
+		name = name[:60]
+	}
+	if name == "" {
+		name = "error"
+	}
+	return name
+}
+
+// failureCategory returns the capture category for a response status or error:
+// "HTTP_422" for HTTP failures, otherwise the categorized error message
+func failureCategory(statusCode int, errMsg string) string {
+	if statusCode > 0 {
+		return fmt.Sprintf("HTTP_%d", statusCode)
+	}
+	return errMsg
+}
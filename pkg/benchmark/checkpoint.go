@@ -0,0 +1,216 @@
+package benchmark
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Checkpoint is the saved state of a benchmark, from which an interrupted run
+// can resume and end with continuous statistics
+type Checkpoint struct {
+	Name      string         `json:"name"`
+	SavedAt   time.Time      `json:"savedAt"`
+	Elapsed   float64        `json:"elapsed"`   // Seconds run so far, excluding pauses
+	Completed int64          `json:"completed"` // Requests (scenario iterations in scenario mode) completed
+	Stats     *StatsSnapshot `json:"stats"`
+
+	RequestRates []float64          `json:"requestRates,omitempty"` // Request rate samples so far, for Req/Sec Stdev
+	Workers      []CheckpointWorker `json:"workers,omitempty"`      // Per-worker counters so far
+}
+
+// CheckpointWorker is the state of one worker's per-worker breakdown in a checkpoint
+type CheckpointWorker struct {
+	ID           int   `json:"id"`
+	Requests     int64 `json:"requests"`
+	Failures     int64 `json:"failures"`
+	TotalLatency int64 `json:"totalLatency"` // Microseconds
+	MaxLatency   int64 `json:"maxLatency"`   // Microseconds
+}
+
+// LoadCheckpoint reads a checkpoint file written by Save
+func LoadCheckpoint(filename string) (*Checkpoint, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %s: %w", filename, err)
+	}
+	var cp Checkpoint
+	if err := json.NewDecoder(reader).Decode(&cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", filename, err)
+	}
+	if cp.Stats == nil {
+		return nil, fmt.Errorf("checkpoint %s has no stats", filename)
+	}
+	return &cp, nil
+}
+
+// Save writes the checkpoint as gzip-compressed JSON. It writes a temporary
+// file first, so a crash mid-write leaves the previous checkpoint intact.
+func (c *Checkpoint) Save(filename string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(writer).Encode(c); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// RestoreCheckpoint continues from a checkpoint: its stats become the starting
+// point, and its elapsed time and completed requests count toward the duration
+// and request totals. Call it before Run.
+func (r *Runner) RestoreCheckpoint(cp *Checkpoint) error {
+	if cp.Name != r.Config.Name {
+		return fmt.Errorf("checkpoint is for benchmark %q, not %q", cp.Name, r.Config.Name)
+	}
+	if r.DurationSec > 0 && cp.Elapsed >= float64(r.DurationSec) {
+		return fmt.Errorf("checkpoint already covers the full %ds duration", r.DurationSec)
+	}
+	if r.DurationSec <= 0 && cp.Completed >= int64(r.Config.Settings.ConcurrentUsers*r.Config.Settings.RequestsPerUser) {
+		return fmt.Errorf("checkpoint already completed all %d requests", cp.Completed)
+	}
+
+	r.Stats.resume(cp)
+	r.resumeOffset = time.Duration(cp.Elapsed * float64(time.Second))
+	r.resumeCompleted = cp.Completed
+	if r.Config.IsScenarioMode() {
+		r.executedSteps = cp.Stats.TotalRequests
+	}
+	return nil
+}
+
+// resume continues these stats from a checkpoint of an earlier leg of the same
+// run. Unlike Merge, which combines processes that ran side by side, the legs
+// ran one after the other: the peak request rate is the higher of the two, and
+// the rate samples and per-worker counters carry on from the checkpoint.
+func (s *Stats) resume(cp *Checkpoint) {
+	s.mutex.Lock()
+	peak := s.maxRequestRate
+	s.mutex.Unlock()
+
+	s.Merge(cp.Stats)
+	for _, saved := range cp.Workers {
+		w := s.NewWorker(saved.ID)
+		atomic.AddInt64(&w.requests, saved.Requests)
+		atomic.AddInt64(&w.failures, saved.Failures)
+		atomic.AddInt64(&w.totalLatency, saved.TotalLatency)
+		if saved.MaxLatency > atomic.LoadInt64(&w.maxLatency) {
+			atomic.StoreInt64(&w.maxLatency, saved.MaxLatency)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.maxRequestRate = max(peak, cp.Stats.MaxRequestRate)
+	s.requestRates = append(append([]float64(nil), cp.RequestRates...), s.requestRates...)
+}
+
+// checkpointState returns what a checkpoint keeps beyond the stats snapshot:
+// the request rate samples and the per-worker counters
+func (s *Stats) checkpointState() ([]float64, []CheckpointWorker) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rates := append([]float64(nil), s.requestRates...)
+	workers := make([]CheckpointWorker, 0, len(s.workers))
+	for _, w := range s.workers {
+		workers = append(workers, CheckpointWorker{
+			ID:           w.id,
+			Requests:     atomic.LoadInt64(&w.requests),
+			Failures:     atomic.LoadInt64(&w.failures),
+			TotalLatency: atomic.LoadInt64(&w.totalLatency),
+			MaxLatency:   atomic.LoadInt64(&w.maxLatency),
+		})
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].ID < workers[j].ID })
+	return rates, workers
+}
+
+// remainingWork returns how many requests (or scenario iterations) fixed count
+// mode sends: users × requestsPerUser, less what a restored checkpoint completed
+func (r *Runner) remainingWork() int {
+	return max(0, r.Config.Settings.ConcurrentUsers*r.Config.Settings.RequestsPerUser-int(r.resumeCompleted))
+}
+
+// takeWork claims the next request (or scenario iteration) in fixed count mode.
+// Workers claim work one at a time from a shared counter, so exactly the
+// configured total is sent, and faster workers take on more of it instead of
+// idling while slow ones finish a fixed share.
+func (r *Runner) takeWork() bool {
+	return r.pending.Add(-1) >= 0
+}
+
+// startCheckpoints saves a checkpoint every interval while the benchmark runs.
+// completed counts the requests (or scenario iterations) done. The returned
+// function stops the periodic saves and writes a final checkpoint.
+func (r *Runner) startCheckpoints(completed *int64) func() {
+	filename := r.Config.Settings.Checkpoint
+	if filename == "" {
+		return func() {}
+	}
+	interval, err := r.Config.GetCheckpointInterval()
+	if err != nil {
+		interval = 30 * time.Second
+	}
+
+	save := func() {
+		cp := &Checkpoint{
+			Name:      r.Config.Name,
+			SavedAt:   time.Now(),
+			Completed: atomic.LoadInt64(completed),
+			Stats:     r.interimSnapshot(),
+		}
+		cp.Elapsed = cp.Stats.TotalDuration
+		cp.RequestRates, cp.Workers = r.Stats.checkpointState()
+		if err := cp.Save(filename); err != nil && !r.QuietMode {
+			fmt.Fprintf(r.Log, "\n[warn] %v\n", err)
+		}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				save()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		save()
+	}
+}
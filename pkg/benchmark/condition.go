@@ -0,0 +1,91 @@
+// Package benchmark provides benchmarking functionality
+package benchmark
+
+import (
+	"strconv"
+	"strings"
+)
+
+// conditionOperators lists supported comparison operators, longest first so
+// that ">=" is matched before ">"
+var conditionOperators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+// evaluateCondition evaluates a step `when` condition after variables have
+// been resolved. Supported forms:
+//   - "a == b", "a != b" - string comparison (quotes around operands are optional)
+//   - "a > b", "a >= b", "a < b", "a <= b" - numeric comparison
+//   - "a" - true unless empty, "false" or "0"
+//   - "!a" - negation of the above
+func evaluateCondition(condition string) bool {
+	condition = strings.TrimSpace(condition)
+
+	for _, op := range conditionOperators {
+		idx := strings.Index(condition, op)
+		if idx == -1 {
+			continue
+		}
+		left := unquote(strings.TrimSpace(condition[:idx]))
+		right := unquote(strings.TrimSpace(condition[idx+len(op):]))
+		return compareValues(left, op, right)
+	}
+
+	if strings.HasPrefix(condition, "!") {
+		return !isTruthy(unquote(strings.TrimSpace(condition[1:])))
+	}
+	return isTruthy(unquote(condition))
+}
+
+// compareValues compares two operands with the given operator
+func compareValues(left, op, right string) bool {
+	switch op {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	}
+
+	l, errL := strconv.ParseFloat(left, 64)
+	r, errR := strconv.ParseFloat(right, 64)
+	if errL != nil || errR != nil {
+		// Fall back to lexical comparison for non-numeric operands
+		switch op {
+		case ">":
+			return left > right
+		case ">=":
+			return left >= right
+		case "<":
+			return left < right
+		default:
+			return left <= right
+		}
+	}
+
+	switch op {
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	case "<":
+		return l < r
+	default:
+		return l <= r
+	}
+}
+
+// isTruthy reports whether a resolved value should be treated as true
+func isTruthy(value string) bool {
+	switch strings.ToLower(value) {
+	case "", "false", "0":
+		return false
+	}
+	// Unresolved placeholders mean the variable was never extracted
+	return !strings.Contains(value, "{{")
+}
+
+// unquote strips one pair of matching single or double quotes
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,238 @@
+// Package benchmark provides benchmarking functionality
+package benchmark
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cssCompound is a compound selector such as `input.token[name="csrf"]`
+type cssCompound struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []cssAttr
+	child   bool // Combined with the previous compound by ">" rather than whitespace
+}
+
+// cssAttr is an attribute condition such as [name^="csrf"]
+type cssAttr struct {
+	name  string
+	op    string // "", "=", "^=", "$=", "*=", "~="
+	value string
+}
+
+// selectCSS returns the elements matching a CSS selector in document order.
+// Supported: type, #id, .class, [attr], [attr=v], [attr^=v], [attr$=v],
+// [attr*=v], [attr~=v], descendant (space) and child (>) combinators, and
+// selector groups separated by commas.
+func selectCSS(root *docNode, selector string) ([]*docNode, error) {
+	var groups [][]cssCompound
+	for _, group := range splitOutsideQuotes(selector, ",") {
+		compounds, err := parseCSSSelector(strings.TrimSpace(group))
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, compounds)
+	}
+
+	var matches []*docNode
+	for _, node := range root.descendants() {
+		if !node.isElement() {
+			continue
+		}
+		for _, compounds := range groups {
+			if matchCSSChain(node, compounds) {
+				matches = append(matches, node)
+				break
+			}
+		}
+	}
+	return matches, nil
+}
+
+// cssValue returns the value of the first element matching a CSS extraction
+// expression. A trailing "@attr" returns that attribute; otherwise the text content.
+func cssValue(root *docNode, expr string) (string, bool, error) {
+	selector, attr := splitCSSAttribute(expr)
+	nodes, err := selectCSS(root, selector)
+	if err != nil {
+		return "", false, err
+	}
+	if len(nodes) == 0 {
+		return "", false, nil
+	}
+	if attr == "" {
+		return nodes[0].value(), true, nil
+	}
+	value, ok := nodes[0].attrs[strings.ToLower(attr)]
+	return value, ok, nil
+}
+
+// splitCSSAttribute splits `form#login@action` into the selector and attribute name
+func splitCSSAttribute(expr string) (string, string) {
+	parts := splitOutsideQuotes(expr, "@")
+	if len(parts) < 2 {
+		return strings.TrimSpace(expr), ""
+	}
+	attr := strings.TrimSpace(parts[len(parts)-1])
+	selector := strings.TrimSpace(expr[:len(expr)-len(parts[len(parts)-1])-1])
+	return selector, attr
+}
+
+// parseCSSSelector parses a selector (without commas) into compounds
+func parseCSSSelector(selector string) ([]cssCompound, error) {
+	if selector == "" {
+		return nil, fmt.Errorf("empty css selector")
+	}
+
+	// Normalize the child combinator so it splits as its own token
+	tokens := strings.Fields(strings.ReplaceAll(selector, ">", " > "))
+	var compounds []cssCompound
+	child := false
+	for _, tok := range tokens {
+		if tok == ">" {
+			child = true
+			continue
+		}
+		compound, err := parseCSSCompound(tok)
+		if err != nil {
+			return nil, fmt.Errorf("invalid css selector %s: %w", selector, err)
+		}
+		compound.child = child
+		child = false
+		compounds = append(compounds, compound)
+	}
+	if len(compounds) == 0 || child {
+		return nil, fmt.Errorf("invalid css selector: %s", selector)
+	}
+	return compounds, nil
+}
+
+// parseCSSCompound parses a single compound selector token
+func parseCSSCompound(tok string) (cssCompound, error) {
+	var c cssCompound
+	i := 0
+	readName := func() string {
+		start := i
+		for i < len(tok) && !strings.ContainsRune("#.[", rune(tok[i])) {
+			i++
+		}
+		return tok[start:i]
+	}
+
+	c.tag = strings.ToLower(readName())
+	if c.tag == "*" {
+		c.tag = ""
+	}
+	for i < len(tok) {
+		switch tok[i] {
+		case '#':
+			i++
+			c.id = readName()
+		case '.':
+			i++
+			c.classes = append(c.classes, readName())
+		case '[':
+			end := strings.IndexByte(tok[i:], ']')
+			if end == -1 {
+				return c, fmt.Errorf("unterminated attribute selector")
+			}
+			c.attrs = append(c.attrs, parseCSSAttr(tok[i+1:i+end]))
+			i += end + 1
+		default:
+			return c, fmt.Errorf("unexpected character %q", tok[i])
+		}
+	}
+	return c, nil
+}
+
+// parseCSSAttr parses the inside of an attribute selector
+func parseCSSAttr(s string) cssAttr {
+	for _, op := range []string{"^=", "$=", "*=", "~=", "="} {
+		if idx := strings.Index(s, op); idx != -1 {
+			return cssAttr{
+				name:  strings.ToLower(strings.TrimSpace(s[:idx])),
+				op:    op,
+				value: unquote(strings.TrimSpace(s[idx+len(op):])),
+			}
+		}
+	}
+	return cssAttr{name: strings.ToLower(strings.TrimSpace(s))}
+}
+
+// matchCSSChain reports whether a node matches the full compound chain,
+// checking the last compound against the node and the rest against its ancestors
+func matchCSSChain(node *docNode, compounds []cssCompound) bool {
+	last := compounds[len(compounds)-1]
+	if !last.matches(node) {
+		return false
+	}
+	if len(compounds) == 1 {
+		return true
+	}
+
+	rest := compounds[:len(compounds)-1]
+	if last.child {
+		return node.parent != nil && matchCSSChain(node.parent, rest)
+	}
+	for ancestor := node.parent; ancestor != nil; ancestor = ancestor.parent {
+		if matchCSSChain(ancestor, rest) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether a single element matches this compound selector
+func (c cssCompound) matches(node *docNode) bool {
+	if !node.isElement() {
+		return false
+	}
+	if c.tag != "" && node.name != c.tag {
+		return false
+	}
+	if c.id != "" && node.attrs["id"] != c.id {
+		return false
+	}
+	if len(c.classes) > 0 {
+		classes := strings.Fields(node.attrs["class"])
+		for _, want := range c.classes {
+			if !containsString(classes, want) {
+				return false
+			}
+		}
+	}
+	for _, a := range c.attrs {
+		value, ok := node.attrs[a.name]
+		if !ok {
+			return false
+		}
+		switch a.op {
+		case "=":
+			ok = value == a.value
+		case "^=":
+			ok = strings.HasPrefix(value, a.value)
+		case "$=":
+			ok = strings.HasSuffix(value, a.value)
+		case "*=":
+			ok = strings.Contains(value, a.value)
+		case "~=":
+			ok = containsString(strings.Fields(value), a.value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
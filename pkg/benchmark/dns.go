@@ -0,0 +1,132 @@
+package benchmark
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dnsCache resolves hostnames once and keeps the addresses for ttl, so new
+// connections dial by IP instead of querying the resolver each time
+type dnsCache struct {
+	ttl        time.Duration // 0 = never re-resolve
+	dialer     *sourceDialer
+	resolver   *net.Resolver
+	mutex      sync.Mutex
+	entries    map[string]*dnsEntry
+	roundRobin bool          // Start each dial at the next address instead of the first
+	next       atomic.Uint64 // Dials so far, for roundRobin
+}
+
+// dnsEntry holds the addresses of one host
+type dnsEntry struct {
+	mutex   sync.Mutex // Held while resolving, so concurrent dials share one lookup
+	addrs   []string
+	expires time.Time
+}
+
+// newDNSCache creates a DNS cache dialing with dialer
+func newDNSCache(ttl time.Duration, dialer *sourceDialer) *dnsCache {
+	return &dnsCache{ttl: ttl, dialer: dialer, resolver: net.DefaultResolver, entries: make(map[string]*dnsEntry)}
+}
+
+// lookup returns the addresses of host, resolving it when it is not cached or
+// its TTL expired. If re-resolving fails, the previous addresses are kept.
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	c.mutex.Lock()
+	entry := c.entries[host]
+	if entry == nil {
+		entry = &dnsEntry{}
+		c.entries[host] = entry
+	}
+	c.mutex.Unlock()
+
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+	if entry.addrs != nil && (c.ttl == 0 || time.Now().Before(entry.expires)) {
+		return entry.addrs, nil
+	}
+	addrs, err := c.resolver.LookupHost(ctx, host)
+	if err != nil {
+		if entry.addrs != nil {
+			return entry.addrs, nil
+		}
+		return nil, err
+	}
+	entry.addrs = addrs
+	entry.expires = time.Now().Add(c.ttl)
+	return addrs, nil
+}
+
+// DialContext connects to addr through the cached addresses of its host,
+// trying them in order. With roundRobin each dial starts at the next address,
+// so connections are spread over all of them.
+func (c *dnsCache) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return c.dialer.DialContext(ctx, network, addr)
+	}
+	addrs, err := c.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	start := 0
+	if c.roundRobin {
+		start = int((c.next.Add(1) - 1) % uint64(len(addrs)))
+	}
+	var lastErr error
+	for i := range addrs {
+		ip := addrs[(start+i)%len(addrs)]
+		conn, err := c.dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// DialTLSContext connects like DialContext and performs the TLS handshake.
+// cfg carries the server name, so certificates are still checked against the
+// hostname rather than the IP.
+func (c *dnsCache) DialTLSContext(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+	conn, err := c.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return tlsHandshake(ctx, conn, cfg)
+}
+
+// Dial connects without a context, for the fasthttp engine
+func (c *dnsCache) Dial(addr string) (net.Conn, error) {
+	return c.DialContext(context.Background(), "tcp", addr)
+}
+
+// resolveHosts resolves every target host before measuring starts, so the
+// first requests don't include DNS lookups. Failures only warn; those hosts
+// are resolved again when connecting.
+func (r *Runner) resolveHosts(ctx context.Context) {
+	if r.dns == nil {
+		return
+	}
+	for _, origin := range r.targetOrigins() {
+		u, err := url.Parse(origin)
+		if err != nil || net.ParseIP(u.Hostname()) != nil {
+			continue
+		}
+		addrs, err := r.dns.lookup(ctx, u.Hostname())
+		if r.QuietMode {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(r.Log, "[warn] Resolving %s failed: %v\n", u.Hostname(), err)
+		} else if r.VerboseMode {
+			fmt.Fprintf(r.Log, "Resolved %s to %v\n", u.Hostname(), addrs)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package benchmark provides benchmarking functionality
+package benchmark
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// docNode is a minimal document tree used for XPath and CSS extraction.
+// Element nodes have a name; text nodes have an empty name and carry text.
+type docNode struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	isAttr   bool // Attribute pseudo-node produced by an XPath @attr step
+	parent   *docNode
+	children []*docNode
+}
+
+// isElement reports whether the node is an element
+func (n *docNode) isElement() bool {
+	return n.name != "" && !n.isAttr
+}
+
+// value returns the string value of a node: attribute value, text, or the
+// concatenated text content of an element
+func (n *docNode) value() string {
+	if n.isAttr || n.name == "" {
+		return n.text
+	}
+	var sb strings.Builder
+	n.collectText(&sb)
+	return strings.TrimSpace(sb.String())
+}
+
+func (n *docNode) collectText(sb *strings.Builder) {
+	if n.name == "" {
+		sb.WriteString(n.text)
+		return
+	}
+	for _, c := range n.children {
+		c.collectText(sb)
+	}
+}
+
+// elements returns the element children of a node
+func (n *docNode) elements() []*docNode {
+	var elems []*docNode
+	for _, c := range n.children {
+		if c.isElement() {
+			elems = append(elems, c)
+		}
+	}
+	return elems
+}
+
+// descendants returns all descendant nodes in document order
+func (n *docNode) descendants() []*docNode {
+	var nodes []*docNode
+	var walk func(*docNode)
+	walk = func(node *docNode) {
+		for _, c := range node.children {
+			nodes = append(nodes, c)
+			walk(c)
+		}
+	}
+	walk(n)
+	return nodes
+}
+
+// parseHTMLDocument parses an HTML body into a document tree
+func parseHTMLDocument(body string) (*docNode, error) {
+	root, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	return convertHTMLNode(root, nil), nil
+}
+
+// convertHTMLNode converts an html.Node tree into a docNode tree.
+// Comments and doctypes are dropped; element names are lowercase.
+func convertHTMLNode(n *html.Node, parent *docNode) *docNode {
+	node := &docNode{parent: parent}
+	switch n.Type {
+	case html.ElementNode:
+		node.name = strings.ToLower(n.Data)
+		node.attrs = make(map[string]string, len(n.Attr))
+		for _, a := range n.Attr {
+			node.attrs[strings.ToLower(a.Key)] = a.Val
+		}
+	case html.TextNode:
+		node.text = n.Data
+	case html.DocumentNode:
+		node.name = "#document"
+	default:
+		return nil
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if child := convertHTMLNode(c, node); child != nil {
+			node.children = append(node.children, child)
+		}
+	}
+	return node
+}
+
+// parseXMLDocument parses an XML (e.g. SOAP) body into a document tree.
+// Namespace prefixes are dropped: elements and attributes use their local names.
+func parseXMLDocument(body string) (*docNode, error) {
+	root := &docNode{name: "#document"}
+	current := root
+
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	decoder.Strict = false
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &docNode{
+				name:   t.Name.Local,
+				attrs:  make(map[string]string, len(t.Attr)),
+				parent: current,
+			}
+			for _, a := range t.Attr {
+				node.attrs[a.Name.Local] = a.Value
+			}
+			current.children = append(current.children, node)
+			current = node
+		case xml.EndElement:
+			if current.parent != nil {
+				current = current.parent
+			}
+		case xml.CharData:
+			current.children = append(current.children, &docNode{text: string(t), parent: current})
+		}
+	}
+	return root, nil
+}
+
+// isXMLContent reports whether a response should be parsed as XML rather than HTML
+func isXMLContent(contentType, body string) bool {
+	if strings.Contains(contentType, "html") {
+		return false
+	}
+	if strings.Contains(contentType, "xml") {
+		return true
+	}
+	return strings.HasPrefix(strings.TrimSpace(body), "<?xml")
+}
@@ -0,0 +1,119 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/benchmarking_go/pkg/config"
+	"github.com/valyala/fasthttp"
+)
+
+// fastHTTPTransport sends requests with fasthttp. It is an http.RoundTripper, so
+// the runner, scenarios, stats and reports work the same with either engine;
+// redirects and cookies are still handled by the http.Client around it.
+type fastHTTPTransport struct {
+	client           *fasthttp.Client
+	timeout          time.Duration
+	disableKeepAlive bool
+}
+
+// newFastHTTPTransport creates a fasthttp transport with up to conns connections per host,
+// dialing through dns unless it is nil, and otherwise through dialer
+func newFastHTTPTransport(cfg *config.Config, tlsConfig *tls.Config, timeout time.Duration, dns *dnsCache, dialer *sourceDialer, conns int) *fastHTTPTransport {
+	transport := &fastHTTPTransport{
+		client: &fasthttp.Client{
+			MaxConnsPerHost:               conns,
+			MaxConnWaitTimeout:            timeout,
+			TLSConfig:                     tlsConfig,
+			NoDefaultUserAgentHeader:      true,
+			DisableHeaderNamesNormalizing: true,
+			DisablePathNormalizing:        true,
+		},
+		timeout:          timeout,
+		disableKeepAlive: cfg.IsKeepAliveDisabled(),
+	}
+	if idleTimeout, _ := cfg.GetIdleConnTimeout(); idleTimeout > 0 {
+		transport.client.MaxIdleConnDuration = idleTimeout
+	}
+	if dns != nil {
+		transport.client.Dial = dns.Dial
+	} else {
+		transport.client.Dial = dialer.Dial
+	}
+	return transport
+}
+
+// RoundTrip sends a request and converts the fasthttp response
+func (t *fastHTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	freq := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(freq)
+
+	freq.Header.SetMethod(req.Method)
+	freq.SetRequestURI(req.URL.String())
+	if req.Host != "" && req.Host != req.URL.Host {
+		freq.Header.SetHost(req.Host)
+	}
+	for key, values := range req.Header {
+		for _, value := range values {
+			freq.Header.Add(key, value)
+		}
+	}
+	if t.disableKeepAlive {
+		freq.SetConnectionClose()
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		defer req.Body.Close()
+		freq.SetBodyStream(req.Body, int(req.ContentLength))
+	}
+
+	deadline, ok := req.Context().Deadline()
+	if !ok {
+		deadline = time.Now().Add(t.timeout)
+	}
+	fresp := fasthttp.AcquireResponse()
+	if err := t.client.DoDeadline(freq, fresp, deadline); err != nil {
+		fasthttp.ReleaseResponse(fresp)
+		if errors.Is(err, fasthttp.ErrTimeout) {
+			err = context.DeadlineExceeded // Reported like a net/http timeout
+		}
+		return nil, err
+	}
+
+	status := fresp.StatusCode()
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		ContentLength: int64(len(fresp.Body())),
+		Request:       req,
+	}
+	for key, value := range fresp.Header.All() {
+		resp.Header.Add(string(key), string(value))
+	}
+	resp.Body = &fastHTTPBody{Reader: bytes.NewReader(fresp.Body()), resp: fresp}
+	return resp, nil
+}
+
+// fastHTTPBody reads a fasthttp response body and releases the response on Close
+type fastHTTPBody struct {
+	*bytes.Reader
+	resp *fasthttp.Response
+}
+
+// Close releases the response, whose buffer the reader uses
+func (b *fastHTTPBody) Close() error {
+	if b.resp != nil {
+		b.Reader.Reset(nil)
+		fasthttp.ReleaseResponse(b.resp)
+		b.resp = nil
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package benchmark
+
+import (
+	"net/http"
+	"strings"
+)
+
+// maxSampleBody is how much of a response body an error sample keeps
+const maxSampleBody = 512
+
+// ErrorSample is an example of a failed request, reported with its error
+// message so the error can be debugged without rerunning the benchmark
+type ErrorSample struct {
+	Status int    `json:"status,omitempty"` // 0 when no response was received
+	URL    string `json:"url,omitempty"`
+	Body   string `json:"body,omitempty"` // Start of the response body
+}
+
+// SetErrorSamples makes the stats keep up to n examples of each error
+// message (0, the default, keeps none)
+func (s *Stats) SetErrorSamples(n int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.errorSampleLimit = n
+}
+
+// AddErrorSample keeps an example of a failed request under its error
+// message, unless enough examples of it are kept already
+func (s *Stats) AddErrorSample(message string, req *http.Request, status int, body []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.errorSamples[message]) >= s.errorSampleLimit {
+		return
+	}
+	sample := ErrorSample{Status: status, Body: sampleBody(body)}
+	if req != nil {
+		sample.URL = req.URL.String()
+	}
+	if s.errorSamples == nil {
+		s.errorSamples = make(map[string][]ErrorSample)
+	}
+	s.errorSamples[message] = append(s.errorSamples[message], sample)
+}
+
+// GetErrorSamples returns a copy of the kept examples by error message
+func (s *Stats) GetErrorSamples() map[string][]ErrorSample {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.copyErrorSamples()
+}
+
+// copyErrorSamples copies the kept examples. The caller must hold s.mutex.
+func (s *Stats) copyErrorSamples() map[string][]ErrorSample {
+	if len(s.errorSamples) == 0 {
+		return nil
+	}
+	samples := make(map[string][]ErrorSample, len(s.errorSamples))
+	for msg, list := range s.errorSamples {
+		samples[msg] = append([]ErrorSample(nil), list...)
+	}
+	return samples
+}
+
+// mergeErrorSamples adds the examples of another process, keeping at most
+// the limit (or as many as one process kept, when merging without a limit).
+// The caller must hold s.mutex.
+func (s *Stats) mergeErrorSamples(samples map[string][]ErrorSample) {
+	for msg, list := range samples {
+		limit := max(s.errorSampleLimit, len(list))
+		if s.errorSamples == nil {
+			s.errorSamples = make(map[string][]ErrorSample)
+		}
+		kept := s.errorSamples[msg]
+		for _, sample := range list {
+			if len(kept) >= limit {
+				break
+			}
+			kept = append(kept, sample)
+		}
+		s.errorSamples[msg] = kept
+	}
+}
+
+// sampleBody returns the start of a body as valid UTF-8 text
+func sampleBody(body []byte) string {
+	truncated := len(body) > maxSampleBody
+	if truncated {
+		body = body[:maxSampleBody]
+	}
+	text := strings.ToValidUTF8(string(body), "")
+	if truncated {
+		text += "…"
+	}
+	return text
+}
@@ -0,0 +1,476 @@
+// Package benchmark provides benchmarking functionality
+package benchmark
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tidwall/gjson"
+)
+
+// exprToken is a lexical token of a validation expression
+type exprToken struct {
+	kind string // "num", "str", "path", "ident", "op"
+	text string
+}
+
+// exprParser evaluates a validation expression while parsing it.
+//
+// Grammar (lowest to highest precedence):
+//
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | comparison
+//	comparison = [ operand ] [ ("==" | "!=" | ">" | ">=" | "<" | "<=") operand ]
+//	operand    = number | 'string' | true | false | null | value
+//	           | $.json.path | func(args...) | "(" or ")"
+//
+// Values are nil, bool, float64, string, or a gjson.Result for arrays and objects.
+type exprParser struct {
+	tokens     []exprToken
+	pos        int
+	body       string      // JSON document that $ paths resolve against
+	subject    interface{} // Implicit left operand for comparisons like "> 5"
+	hasSubject bool
+}
+
+// evaluateExpression evaluates a boolean expression against a JSON body,
+// e.g. `$.count > 0 && $.items[0].price <= 100`
+func evaluateExpression(expr, body string) (bool, error) {
+	p, err := newExprParser(expr)
+	if err != nil {
+		return false, err
+	}
+	p.body = body
+	return p.run()
+}
+
+// matchExpression evaluates an expression about a single value. Comparisons
+// may omit their left operand, which then defaults to the value, so that
+// "> 0 && <= 100" and "value > 0 && value <= 100" are equivalent.
+func matchExpression(expr string, actual gjson.Result) (bool, error) {
+	p, err := newExprParser(expr)
+	if err != nil {
+		return false, err
+	}
+	p.subject = fromGJSON(actual)
+	p.hasSubject = true
+	return p.run()
+}
+
+// isValueExpression reports whether an expected validation value is an
+// expression (it starts with a comparison operator) rather than a literal
+func isValueExpression(s string) bool {
+	s = strings.TrimSpace(s)
+	for _, op := range conditionOperators {
+		if strings.HasPrefix(s, op) {
+			return true
+		}
+	}
+	return false
+}
+
+func newExprParser(expr string) (*exprParser, error) {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	return &exprParser{tokens: tokens}, nil
+}
+
+func (p *exprParser) run() (bool, error) {
+	value, err := p.parseOr()
+	if err != nil {
+		return false, err
+	}
+	if p.pos < len(p.tokens) {
+		return false, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return truthy(value), nil
+}
+
+// peek returns the next token, or an empty token at the end of input
+func (p *exprParser) peek() exprToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return exprToken{}
+}
+
+// accept consumes the next token if it is the given operator
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == "op" && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (interface{}, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = truthy(left) || truthy(right)
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (interface{}, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = truthy(left) && truthy(right)
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (interface{}, error) {
+	if p.accept("!") {
+		value, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return !truthy(value), nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (interface{}, error) {
+	var left interface{}
+	if isComparisonToken(p.peek()) && p.hasSubject {
+		left = p.subject
+	} else {
+		var err error
+		if left, err = p.parseOperand(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !isComparisonToken(p.peek()) {
+		return left, nil
+	}
+	op := p.peek().text
+	p.pos++
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return compareExprValues(left, op, right), nil
+}
+
+func (p *exprParser) parseOperand() (interface{}, error) {
+	t := p.peek()
+	if t.kind == "" {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+
+	switch t.kind {
+	case "num":
+		return strconv.ParseFloat(t.text, 64)
+	case "str":
+		return t.text, nil
+	case "path":
+		if t.text == "$" {
+			return fromGJSON(gjson.Parse(p.body)), nil
+		}
+		return fromGJSON(gjson.Get(p.body, toGJSONPath(t.text))), nil
+	case "ident":
+		if p.accept("(") {
+			return p.parseCall(t.text)
+		}
+		switch t.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		case "value":
+			if p.hasSubject {
+				return p.subject, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown identifier %q", t.text)
+	}
+
+	if t.text == "(" {
+		value, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+// parseCall parses the arguments of a function call and applies the function
+func (p *exprParser) parseCall(name string) (interface{}, error) {
+	var args []interface{}
+	if !p.accept(")") {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.accept(")") {
+				break
+			}
+			if !p.accept(",") {
+				return nil, fmt.Errorf("expected , or ) in call to %s()", name)
+			}
+		}
+	}
+	return callExprFunction(name, args)
+}
+
+// callExprFunction applies a built-in function:
+//   - len(x) - length of a string, array or object
+//   - exists(x) - whether a path resolved to a non-null value
+//   - contains(a, b) - substring test, or array membership when a is an array
+//   - startsWith(a, b), endsWith(a, b) - prefix and suffix tests
+//   - matches(a, pattern) - regular expression match
+func callExprFunction(name string, args []interface{}) (interface{}, error) {
+	want := map[string]int{"len": 1, "exists": 1, "contains": 2, "startsWith": 2, "endsWith": 2, "matches": 2}
+	n, ok := want[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s()", name)
+	}
+	if len(args) != n {
+		return nil, fmt.Errorf("%s() takes %d argument(s), got %d", name, n, len(args))
+	}
+
+	switch name {
+	case "len":
+		switch v := args[0].(type) {
+		case nil:
+			return float64(0), nil
+		case gjson.Result:
+			if v.IsArray() {
+				return float64(len(v.Array())), nil
+			}
+			return float64(len(v.Map())), nil
+		default:
+			return float64(len([]rune(exprString(v)))), nil
+		}
+	case "exists":
+		return args[0] != nil, nil
+	case "contains":
+		if arr, ok := args[0].(gjson.Result); ok && arr.IsArray() {
+			for _, item := range arr.Array() {
+				if equalExprValues(fromGJSON(item), args[1]) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		return strings.Contains(exprString(args[0]), exprString(args[1])), nil
+	case "startsWith":
+		return strings.HasPrefix(exprString(args[0]), exprString(args[1])), nil
+	case "endsWith":
+		return strings.HasSuffix(exprString(args[0]), exprString(args[1])), nil
+	default:
+		re, err := compilePattern(exprString(args[1]))
+		if err != nil {
+			return nil, fmt.Errorf("matches(): %w", err)
+		}
+		return re.MatchString(exprString(args[0])), nil
+	}
+}
+
+// compareExprValues applies a comparison operator to two values
+func compareExprValues(left interface{}, op string, right interface{}) bool {
+	switch op {
+	case "==":
+		return equalExprValues(left, right)
+	case "!=":
+		return !equalExprValues(left, right)
+	}
+	// Ordering against a missing value is always false
+	if left == nil || right == nil {
+		return false
+	}
+	return compareValues(exprString(left), op, exprString(right))
+}
+
+// equalExprValues compares two values, numerically when either side is a number
+func equalExprValues(left, right interface{}) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	_, leftNum := left.(float64)
+	_, rightNum := right.(float64)
+	if leftNum || rightNum {
+		l, errL := strconv.ParseFloat(exprString(left), 64)
+		r, errR := strconv.ParseFloat(exprString(right), 64)
+		if errL == nil && errR == nil {
+			return l == r
+		}
+	}
+	return exprString(left) == exprString(right)
+}
+
+// fromGJSON converts a gjson result to an expression value
+func fromGJSON(r gjson.Result) interface{} {
+	if !r.Exists() {
+		return nil
+	}
+	switch r.Type {
+	case gjson.Number:
+		return r.Num
+	case gjson.String:
+		return r.Str
+	case gjson.True:
+		return true
+	case gjson.False:
+		return false
+	case gjson.Null:
+		return nil
+	default:
+		return r
+	}
+}
+
+// exprString returns the string form of an expression value
+func exprString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	case string:
+		return val
+	case gjson.Result:
+		return val.Raw
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// truthy reports whether an expression value counts as true
+func truthy(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case float64:
+		return val != 0
+	case string:
+		return val != ""
+	case gjson.Result:
+		if val.IsArray() {
+			return len(val.Array()) > 0
+		}
+		return val.Exists()
+	default:
+		return true
+	}
+}
+
+// isComparisonToken reports whether a token is a comparison operator
+func isComparisonToken(t exprToken) bool {
+	return t.kind == "op" && containsString(conditionOperators, t.text)
+}
+
+// exprOperators lists operator tokens, two-character operators first
+var exprOperators = []string{"&&", "||", "==", "!=", ">=", "<=", ">", "<", "!", "(", ")", ","}
+
+// tokenizeExpression splits an expression into tokens
+func tokenizeExpression(expr string) ([]exprToken, error) {
+	var tokens []exprToken
+	i := 0
+	for i < len(expr) {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated string in expression: %s", expr)
+			}
+			tokens = append(tokens, exprToken{kind: "str", text: expr[i+1 : i+1+end]})
+			i += end + 2
+		case isDigit(c) || (c == '-' && i+1 < len(expr) && isDigit(expr[i+1]) && !followsValue(tokens)):
+			start := i
+			i++
+			for i < len(expr) && (isDigit(expr[i]) || expr[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: "num", text: expr[start:i]})
+		case c == '$':
+			start := i
+			depth := 0
+			for i < len(expr) {
+				ch := expr[i]
+				if ch == '[' {
+					depth++
+				} else if ch == ']' {
+					depth--
+				} else if depth == 0 && (ch == ' ' || strings.IndexByte("=!<>&|(),", ch) != -1) {
+					break
+				}
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: "path", text: expr[start:i]})
+		case c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(expr) && (expr[i] == '_' || isDigit(expr[i]) || unicode.IsLetter(rune(expr[i]))) {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: "ident", text: expr[start:i]})
+		default:
+			matched := false
+			for _, op := range exprOperators {
+				if strings.HasPrefix(expr[i:], op) {
+					tokens = append(tokens, exprToken{kind: "op", text: op})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q in expression: %s", c, expr)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// followsValue reports whether the previous token ends an operand, in which
+// case a following "-" cannot start a negative number
+func followsValue(tokens []exprToken) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	return last.kind != "op" || last.text == ")"
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
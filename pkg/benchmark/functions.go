@@ -0,0 +1,168 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	mrand "math/rand"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// TemplateFunction generates the value of a {{$name}} placeholder in scenario
+// steps, or of {{.Func "name"}} in a bodyTemplate. It is called for every use,
+// concurrently from all users.
+type TemplateFunction func() string
+
+// templateFunctions are the {{$name}} functions of a run, by name
+type templateFunctions map[string]TemplateFunction
+
+// functionTimeout is how long an exec function's command may run
+const functionTimeout = 30 * time.Second
+
+var (
+	functionsMu         sync.RWMutex
+	registeredFunctions = templateFunctions{
+		"uuid":      generateUUID,
+		"randomInt": generateRandomInt,
+		"timestamp": func() string {
+			return strconv.FormatInt(time.Now().UnixMilli(), 10)
+		},
+		"iteration": func() string {
+			return strconv.FormatInt(atomic.AddInt64(&iterationCounter, 1), 10)
+		},
+		"randomUser": generateRandomUser,
+	}
+)
+
+// RegisterFunction makes {{$name}} available to the scenario steps of later
+// runs, replacing a function of the same name (built-in ones included).
+// Functions declared in a config's "functions" take precedence.
+func RegisterFunction(name string, fn TemplateFunction) error {
+	if !config.ValidFunctionName(name) {
+		return fmt.Errorf("invalid function name %q (use letters, digits and underscores)", name)
+	}
+	if fn == nil {
+		return fmt.Errorf("function %q is nil", name)
+	}
+	functionsMu.Lock()
+	defer functionsMu.Unlock()
+	registeredFunctions[name] = fn
+	return nil
+}
+
+// defaultFunctions returns the built-in and registered functions
+func defaultFunctions() templateFunctions {
+	functionsMu.RLock()
+	defer functionsMu.RUnlock()
+	functions := make(templateFunctions, len(registeredFunctions))
+	for name, fn := range registeredFunctions {
+		functions[name] = fn
+	}
+	return functions
+}
+
+// newTemplateFunctions returns the functions of a run: the registered ones,
+// the runner's own and those declared in the config, in increasing precedence.
+// Exec functions run their command now, so its failures are reported before
+// the run and the first requests don't wait for it.
+func (r *Runner) newTemplateFunctions(ctx context.Context) templateFunctions {
+	functions := defaultFunctions()
+	for name, fn := range r.Functions {
+		functions[name] = fn
+	}
+	for name, decl := range r.Config.Functions {
+		if len(decl.Values) > 0 {
+			values := decl.Values
+			functions[name] = func() string {
+				return values[mrand.Intn(len(values))]
+			}
+			continue
+		}
+		every, _ := decl.GetEvery() // Checked by config validation
+		fn := &execFunction{name: name, args: decl.Exec, every: every, runner: r}
+		fn.refresh(ctx)
+		functions[name] = fn.value
+	}
+	return functions
+}
+
+// execFunction is a declared function printing the output of a command,
+// which is reused until it is older than every
+type execFunction struct {
+	name    string
+	args    []string
+	every   time.Duration // 0 = run the command once
+	runner  *Runner
+	mu      sync.Mutex
+	output  string
+	fetched time.Time
+}
+
+// value returns the command's output, running it again once it is stale
+func (f *execFunction) value() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.every > 0 && time.Since(f.fetched) >= f.every {
+		f.refresh(context.Background())
+	}
+	return f.output
+}
+
+// refresh runs the command. On failure it warns and keeps the previous output
+// until the next refresh.
+func (f *execFunction) refresh(ctx context.Context) {
+	f.fetched = time.Now()
+	ctx, cancel := context.WithTimeout(ctx, functionTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, f.args[0], f.args[1:]...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		if !f.runner.QuietMode {
+			fmt.Fprintf(f.runner.Log, "[warn] function %s: %v\n", f.name, err)
+		}
+		return
+	}
+	f.output = strings.TrimSpace(string(out))
+}
+
+// resolveDynamicFunctions replaces {{$name}} placeholders with the values of
+// their functions, calling the function for every placeholder. Unknown
+// functions are left as they are.
+func resolveDynamicFunctions(input string, functions templateFunctions) string {
+	if !strings.Contains(input, "{{$") {
+		return input
+	}
+	var b strings.Builder
+	rest := input
+	for {
+		start := strings.Index(rest, "{{$")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(rest[:start])
+		if fn, ok := functions[rest[start+3:end]]; ok {
+			b.WriteString(fn())
+		} else {
+			b.WriteString(rest[start : end+2])
+		}
+		rest = rest[end+2:]
+	}
+	b.WriteString(rest)
+	return b.String()
+}
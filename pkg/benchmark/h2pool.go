@@ -0,0 +1,102 @@
+package benchmark
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"golang.org/x/net/http2"
+)
+
+// ConnectionSummary is the traffic of one connection of the HTTP/2 pool
+type ConnectionSummary struct {
+	ID         int
+	Requests   int64 // Streams opened
+	MaxStreams int64 // Most streams open at the same time
+}
+
+// poolTransport is one HTTP/2 transport of the pool, which keeps its own
+// connection to each host, and counts the streams sent over it
+type poolTransport struct {
+	transport  *http2.Transport
+	requests   int64
+	active     int64
+	maxStreams int64
+}
+
+// RoundTrip sends a request as a stream of this transport's connection. The
+// stream counts as open until its response body is closed.
+func (t *poolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt64(&t.requests, 1)
+	active := atomic.AddInt64(&t.active, 1)
+	for {
+		current := atomic.LoadInt64(&t.maxStreams)
+		if active <= current || atomic.CompareAndSwapInt64(&t.maxStreams, current, active) {
+			break
+		}
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		atomic.AddInt64(&t.active, -1)
+		return nil, err
+	}
+	resp.Body = &streamBody{ReadCloser: resp.Body, transport: t}
+	return resp, nil
+}
+
+// streamBody marks its stream closed when the response body is closed
+type streamBody struct {
+	io.ReadCloser
+	transport *poolTransport
+	closed    atomic.Bool
+}
+
+// Close closes the body and the stream
+func (b *streamBody) Close() error {
+	if b.closed.CompareAndSwap(false, true) {
+		atomic.AddInt64(&b.transport.active, -1)
+	}
+	return b.ReadCloser.Close()
+}
+
+// createHTTP2Pool creates one HTTP/2 client per pooled connection. Each has its
+// own transport, so the load is spread over that many connections to each host
+// instead of being multiplexed over one; workers are assigned round-robin.
+func (r *Runner) createHTTP2Pool(tlsConfig *tls.Config, size int) {
+	r.pool = make([]*poolTransport, size)
+	r.clients = make([]*http.Client, size)
+	for i := range r.pool {
+		r.pool[i] = &poolTransport{transport: r.newHTTP2Transport(tlsConfig)}
+		r.clients[i] = &http.Client{
+			Timeout:   r.Timeout,
+			Transport: r.pool[i],
+		}
+	}
+	r.client = r.clients[0]
+}
+
+// clientFor returns the HTTP client of a worker
+func (r *Runner) clientFor(workerIndex int) *http.Client {
+	if len(r.clients) == 0 {
+		return r.client
+	}
+	return r.clients[workerIndex%len(r.clients)]
+}
+
+// recordConnections stores the traffic of each pooled connection in the stats
+func (r *Runner) recordConnections() {
+	if len(r.pool) == 0 {
+		return
+	}
+	summaries := make([]ConnectionSummary, len(r.pool))
+	for i, t := range r.pool {
+		summaries[i] = ConnectionSummary{
+			ID:         i,
+			Requests:   atomic.LoadInt64(&t.requests),
+			MaxStreams: atomic.LoadInt64(&t.maxStreams),
+		}
+	}
+	r.Stats.SetConnections(summaries)
+}
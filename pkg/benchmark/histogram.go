@@ -4,6 +4,7 @@ package benchmark
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 
 	"github.com/HdrHistogram/hdrhistogram-go"
@@ -93,25 +94,80 @@ func (h *HdrStats) GetHistogramBuckets() []HistogramBucket {
 	return h.GetCustomBuckets(nil)
 }
 
+// defaultHistogramBounds are the upper bounds of the histogram buckets in
+// microseconds unless others are configured: 1ms to 10s
+var defaultHistogramBounds = []int64{
+	1000,     // 1ms
+	5000,     // 5ms
+	10000,    // 10ms
+	25000,    // 25ms
+	50000,    // 50ms
+	100000,   // 100ms
+	250000,   // 250ms
+	500000,   // 500ms
+	1000000,  // 1s
+	2500000,  // 2.5s
+	5000000,  // 5s
+	10000000, // 10s
+}
+
+// logHistogramBuckets is the number of log-scale buckets
+const logHistogramBuckets = 12
+
+// logHistogramBounds returns bucket bounds on a log scale from minValue to
+// just above maxValue: each bound is the previous one times the same factor, rounded up
+// to two significant digits. The buckets span the recorded latencies whether
+// they are microseconds or minutes.
+func logHistogramBounds(minValue, maxValue int64) []int64 {
+	low, high := float64(max(minValue, 1)), float64(maxValue+1)
+	factor := math.Pow(high/low, 1/float64(logHistogramBuckets))
+	var bounds []int64
+	for i := 1; i <= logHistogramBuckets; i++ {
+		bound := ceilSignificant(low*math.Pow(factor, float64(i)), 2)
+		if len(bounds) > 0 && bound <= bounds[len(bounds)-1] {
+			continue
+		}
+		bounds = append(bounds, bound)
+	}
+	return bounds
+}
+
+// ceilSignificant rounds v up to the given number of significant digits
+func ceilSignificant(v float64, digits int) int64 {
+	scale := math.Pow(10, math.Floor(math.Log10(v))-float64(digits-1))
+	if scale < 1 {
+		return int64(math.Ceil(v))
+	}
+	return int64(math.Ceil(v/scale) * scale)
+}
+
+// SetHistogramBuckets sets the upper bounds of the histogram buckets in
+// microseconds, or log-scale buckets fitted to the recorded latencies when
+// logScale is set. Without either, the buckets span 1ms to 10s.
+func (s *Stats) SetHistogramBuckets(bounds []int64, logScale bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.histogramBounds = bounds
+	s.logHistogram = logScale
+}
+
+// histogramBoundsFor returns the bucket bounds for latencies from min to max.
+// The caller must hold s.mutex.
+func (s *Stats) histogramBoundsFor(min, max int64) []int64 {
+	switch {
+	case s.logHistogram:
+		return logHistogramBounds(min, max)
+	case len(s.histogramBounds) > 0:
+		return s.histogramBounds
+	}
+	return defaultHistogramBounds
+}
+
 // GetCustomBuckets returns histogram buckets with custom boundaries
 // If boundaries is nil, uses default boundaries
 func (h *HdrStats) GetCustomBuckets(boundaries []int64) []HistogramBucket {
-	// Default boundaries in microseconds
 	if boundaries == nil {
-		boundaries = []int64{
-			1000,      // 1ms
-			5000,      // 5ms
-			10000,     // 10ms
-			25000,     // 25ms
-			50000,     // 50ms
-			100000,    // 100ms
-			250000,    // 250ms
-			500000,    // 500ms
-			1000000,   // 1s
-			2500000,   // 2.5s
-			5000000,   // 5s
-			10000000,  // 10s
-		}
+		boundaries = defaultHistogramBounds
 	}
 
 	totalCount := h.histogram.TotalCount()
@@ -197,14 +253,15 @@ func FormatDuration(us int64) string {
 	}
 }
 
-// FormatDurationShort formats microseconds to shorter human-readable string
+// FormatDurationShort formats microseconds to shorter human-readable string,
+// with decimals only where needed (e.g. "2.5ms", "10s")
 func FormatDurationShort(us int64) string {
 	if us < 1000 {
 		return fmt.Sprintf("%dus", us)
 	} else if us < 1000000 {
-		return fmt.Sprintf("%.0fms", float64(us)/1000)
+		return strconv.FormatFloat(math.Round(float64(us)/10)/100, 'f', -1, 64) + "ms"
 	} else {
-		return fmt.Sprintf("%.1fs", float64(us)/1000000)
+		return strconv.FormatFloat(math.Round(float64(us)/10000)/100, 'f', -1, 64) + "s"
 	}
 }
 
@@ -281,6 +338,15 @@ func (h *HdrStats) Merge(other *HdrStats) {
 	h.count += other.count
 }
 
+// MergeSnapshot merges an exported histogram (e.g. from another process) into this one
+func (h *HdrStats) MergeSnapshot(snapshot *hdrhistogram.Snapshot) {
+	other := hdrhistogram.Import(snapshot)
+	if other.TotalCount() == 0 {
+		return
+	}
+	h.Merge(&HdrStats{histogram: other, minValue: other.Min(), maxValue: other.Max(), count: other.TotalCount()})
+}
+
 // Reset resets the histogram
 func (h *HdrStats) Reset() {
 	h.histogram.Reset()
@@ -0,0 +1,91 @@
+package benchmark
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Hooks are callbacks run around every HTTP request of a benchmark (simple
+// requests and scenario steps; WebSocket steps are not included). They let
+// library users change requests and judge responses without forking. Hooks
+// run concurrently from all workers and must be safe for that.
+type Hooks struct {
+	// OnRequest runs before each request is sent, after the configured headers
+	// and body are set. It may change the URL, headers or body (see
+	// SetRequestBody). An error fails the request without sending it.
+	OnRequest []func(req *http.Request) error
+
+	// OnResponse runs after each request completes or fails to, e.g. to record
+	// custom metrics. For completed requests it returns whether the request
+	// counts as a success; resp.Success holds the verdict so far (status and
+	// validation), which later hooks see updated. The return value is ignored
+	// for requests that got no response.
+	OnResponse []func(resp *Response) bool
+}
+
+// Response is what an OnResponse hook sees of a request
+type Response struct {
+	Request    *http.Request
+	StatusCode int         // 0 when no response was received
+	Header     http.Header // nil when no response was received
+	Body       []byte      // The start of the body (up to 1 MiB; nil for binary scenario steps)
+	Latency    time.Duration
+	Err        error // Why no response was received, or why reading the body failed
+	Success    bool  // Verdict of the benchmark and the hooks before this one
+
+	// Error is the failure message recorded when a hook rejects the response
+	// ("rejected by response hook" if left empty)
+	Error string
+}
+
+// SetRequestBody replaces the body of a request, e.g. from an OnRequest hook
+func SetRequestBody(req *http.Request, body []byte) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+}
+
+// hasResponseHooks reports whether response bodies must be kept for the hooks
+func (h *Hooks) hasResponseHooks() bool {
+	return h != nil && len(h.OnResponse) > 0
+}
+
+// request runs the OnRequest hooks, stopping at the first error
+func (h *Hooks) request(req *http.Request) error {
+	if h == nil {
+		return nil
+	}
+	for _, hook := range h.OnRequest {
+		if err := hook(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// response runs the OnResponse hooks and returns the final verdict, with the
+// failure message to record when the hooks rejected an otherwise successful response
+func (h *Hooks) response(resp *Response) (bool, string) {
+	if !h.hasResponseHooks() {
+		return resp.Success, ""
+	}
+	passed := resp.Success
+	verdict := passed
+	for _, hook := range h.OnResponse {
+		resp.Success = verdict
+		if accepted := hook(resp); resp.Err == nil {
+			verdict = accepted
+		}
+	}
+	if verdict || !passed {
+		return verdict, ""
+	}
+	if resp.Error == "" {
+		return false, "rejected by response hook"
+	}
+	return false, resp.Error
+}
@@ -0,0 +1,62 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/benchmarking_go/pkg/cpuset"
+	"github.com/benchmarking_go/pkg/progress"
+)
+
+// startIntervalReports prints the stats of the last report interval (requests/sec,
+// percentiles, errors) through display every interval. Paused time is skipped.
+// The returned function stops the reports.
+func (r *Runner) startIntervalReports(ctx context.Context, display progress.Display, stopwatch time.Time) func() {
+	if r.QuietMode {
+		return func() {}
+	}
+	interval, err := r.Config.GetReportInterval()
+	if err != nil {
+		fmt.Fprintf(r.Log, "[warn] Interval reports disabled: %v\n", err)
+		return func() {}
+	}
+	if interval == 0 {
+		return func() {}
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+	go func() {
+		cpuset.PinToReserved()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := r.Stats.snapshotWindow()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			now := r.Stats.snapshotWindow()
+			if r.pause.paused() {
+				// Start a fresh interval on resume
+				last = now
+				continue
+			}
+			metrics := r.Stats.windowMetrics(last, now)
+			display.Message(progress.FormatIntervalReport(&progress.IntervalReport{
+				Elapsed:        r.activeElapsed(stopwatch),
+				Requests:       metrics.successCount + metrics.failureCount,
+				Errors:         metrics.failureCount,
+				RequestsPerSec: metrics.requestsPerSecond,
+				P50Us:          metrics.percentile(50),
+				P95Us:          metrics.percentile(95),
+				P99Us:          metrics.percentile(99),
+			}))
+			last = now
+		}
+	}()
+	return stop
+}
@@ -0,0 +1,309 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benchmarking_go/pkg/config"
+	"github.com/dop251/goja"
+)
+
+// jsIterationFunction is the script function run at the start of every scenario iteration
+const jsIterationFunction = "iteration"
+
+// javaScript runs the functions of the configured JavaScript file: jsRequest
+// functions change a request before it is sent, jsCheck functions judge its
+// response, and iteration(vars) runs before every scenario iteration. As in
+// k6, every virtual user has its own runtime, in which the file's top-level
+// code runs once; runtimes are created on a user's first call.
+type javaScript struct {
+	program *goja.Program
+	log     io.Writer
+	err     error // Why the script could not be loaded; fails every request
+
+	hasIteration bool
+
+	mu       sync.Mutex
+	runtimes map[int]*jsRuntime
+}
+
+// jsRuntime is the JavaScript runtime of one virtual user
+type jsRuntime struct {
+	vm        *goja.Runtime
+	jsonParse goja.Callable
+}
+
+// loadJavaScript compiles the configured JavaScript file and checks that the
+// functions requests and steps refer to exist. If that fails, every request
+// fails with the error.
+func (r *Runner) loadJavaScript() {
+	if r.Config.Settings.JSScript == "" {
+		return
+	}
+	js, err := newJavaScript(r.Config, r.Log)
+	if err != nil {
+		js = &javaScript{err: fmt.Errorf("jsScript: %w", err)}
+		if !r.QuietMode {
+			fmt.Fprintf(r.Log, "[warn] %v\n", js.err)
+		}
+	}
+	r.js = js
+}
+
+// newJavaScript compiles the script and runs it for the first user
+func newJavaScript(cfg *config.Config, log io.Writer) (*javaScript, error) {
+	path := cfg.Settings.JSScript
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	program, err := goja.Compile(path, string(source), false)
+	if err != nil {
+		return nil, err
+	}
+	js := &javaScript{program: program, log: log, runtimes: make(map[int]*jsRuntime)}
+
+	rt, err := js.runtime(0)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range cfg.JavaScriptFunctions() {
+		if _, ok := goja.AssertFunction(rt.vm.Get(name)); !ok {
+			return nil, fmt.Errorf("%s is not a function", name)
+		}
+	}
+	_, js.hasIteration = goja.AssertFunction(rt.vm.Get(jsIterationFunction))
+	return js, nil
+}
+
+// runtime returns the runtime of a virtual user, creating it on first use
+func (js *javaScript) runtime(vu int) (*jsRuntime, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if rt, ok := js.runtimes[vu]; ok {
+		return rt, nil
+	}
+
+	vm := goja.New()
+	console := vm.NewObject()
+	console.Set("log", js.print)
+	vm.Set("console", console)
+	if _, err := vm.RunProgram(js.program); err != nil {
+		return nil, jsError(err)
+	}
+	parse, _ := goja.AssertFunction(vm.Get("JSON").ToObject(vm).Get("parse"))
+	rt := &jsRuntime{vm: vm, jsonParse: parse}
+	js.runtimes[vu] = rt
+	return rt, nil
+}
+
+// function returns a user's runtime and one of its functions
+func (js *javaScript) function(vu int, name string) (*jsRuntime, goja.Callable, error) {
+	if js.err != nil {
+		return nil, nil, js.err
+	}
+	rt, err := js.runtime(vu)
+	if err != nil {
+		return nil, nil, err
+	}
+	fn, ok := goja.AssertFunction(rt.vm.Get(name))
+	if !ok {
+		return nil, nil, fmt.Errorf("%s is not a function", name)
+	}
+	return rt, fn, nil
+}
+
+// request calls a jsRequest function with the request as {method, url,
+// headers, body} and the variables. The function changes that object or
+// returns a new one, which becomes the request. request returns the new body
+// and the variables the function set.
+func (js *javaScript) request(vu int, name string, req *http.Request, body string, vars map[string]string) (string, map[string]string, error) {
+	if js == nil || name == "" {
+		return body, nil, nil
+	}
+	rt, fn, err := js.function(vu, name)
+	if err != nil {
+		return "", nil, err
+	}
+	vm := rt.vm
+
+	object := vm.NewObject()
+	object.Set("method", req.Method)
+	object.Set("url", req.URL.String())
+	object.Set("headers", jsHeaders(vm, req.Header))
+	object.Set("body", body)
+	varsObject := jsVariables(vm, vars)
+	result, err := fn(goja.Undefined(), object, varsObject)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", name, jsError(err))
+	}
+	if !isNullish(result) {
+		object = result.ToObject(vm)
+	}
+
+	target, err := url.Parse(jsText(object.Get("url")))
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: invalid url: %w", name, err)
+	}
+	req.URL = target
+	req.Host = ""
+	if method := jsText(object.Get("method")); method != "" {
+		req.Method = strings.ToUpper(method)
+	}
+	req.Header = make(http.Header)
+	if headers := object.Get("headers"); !isNullish(headers) {
+		headerObject := headers.ToObject(vm)
+		for _, key := range headerObject.Keys() {
+			req.Header.Set(key, jsText(headerObject.Get(key)))
+		}
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+		req.Header.Del("Host")
+	}
+	if newBody := jsText(object.Get("body")); newBody != body {
+		body = newBody
+		SetRequestBody(req, []byte(body))
+	}
+	return body, changedVariables(varsObject, vars), nil
+}
+
+// check calls a jsCheck function with the response as {status, headers, body,
+// latency (ms), json()} and the variables. The response fails the check when
+// the function returns false or a message, or throws. check returns the
+// failure message ("" if the check passed) and the variables the function set.
+func (js *javaScript) check(vu int, name string, resp *http.Response, body []byte, latency time.Duration, vars map[string]string) (string, map[string]string) {
+	if js == nil || name == "" {
+		return "", nil
+	}
+	rt, fn, err := js.function(vu, name)
+	if err != nil {
+		return err.Error(), nil
+	}
+	vm := rt.vm
+
+	text := string(body)
+	object := vm.NewObject()
+	object.Set("status", resp.StatusCode)
+	object.Set("headers", jsHeaders(vm, resp.Header))
+	object.Set("body", text)
+	object.Set("latency", float64(latency.Microseconds())/1000)
+	object.Set("json", func() (goja.Value, error) {
+		return rt.jsonParse(goja.Undefined(), vm.ToValue(text))
+	})
+	varsObject := jsVariables(vm, vars)
+	result, err := fn(goja.Undefined(), object, varsObject)
+	changed := changedVariables(varsObject, vars)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("%s: %v", name, jsError(err)), changed
+	case isNullish(result):
+		return "", changed
+	}
+	if message, ok := result.Export().(string); ok {
+		return message, changed
+	}
+	if !result.ToBoolean() {
+		return name + " failed", changed
+	}
+	return "", changed
+}
+
+// iteration calls the script's iteration(vars) function, if it has one, and
+// returns the variables it set
+func (js *javaScript) iteration(vu int, vars map[string]string) (map[string]string, error) {
+	if js == nil || !js.hasIteration {
+		return nil, nil
+	}
+	rt, fn, err := js.function(vu, jsIterationFunction)
+	if err != nil {
+		return nil, err
+	}
+	varsObject := jsVariables(rt.vm, vars)
+	if _, err := fn(goja.Undefined(), varsObject); err != nil {
+		return nil, fmt.Errorf("%s: %w", jsIterationFunction, jsError(err))
+	}
+	return changedVariables(varsObject, vars), nil
+}
+
+// print writes the values passed to console.log to the runner's log
+func (js *javaScript) print(call goja.FunctionCall) goja.Value {
+	values := make([]string, len(call.Arguments))
+	for i, arg := range call.Arguments {
+		values[i] = jsText(arg)
+	}
+	fmt.Fprintln(js.log, strings.Join(values, " "))
+	return goja.Undefined()
+}
+
+// jsHeaders returns headers as an object of their first values
+func jsHeaders(vm *goja.Runtime, header http.Header) *goja.Object {
+	object := vm.NewObject()
+	for key := range header {
+		object.Set(key, header.Get(key))
+	}
+	return object
+}
+
+// jsVariables returns the variables as an object the script may change
+func jsVariables(vm *goja.Runtime, vars map[string]string) *goja.Object {
+	object := vm.NewObject()
+	for key, value := range vars {
+		object.Set(key, value)
+	}
+	return object
+}
+
+// changedVariables returns the variables the script added or changed
+func changedVariables(object *goja.Object, before map[string]string) map[string]string {
+	var changed map[string]string
+	for _, key := range object.Keys() {
+		value := jsText(object.Get(key))
+		if old, ok := before[key]; ok && old == value {
+			continue
+		}
+		if changed == nil {
+			changed = make(map[string]string)
+		}
+		changed[key] = value
+	}
+	return changed
+}
+
+// jsText converts a script value to text: strings as they are, objects and
+// arrays as JSON, and undefined or null as ""
+func jsText(value goja.Value) string {
+	if isNullish(value) {
+		return ""
+	}
+	switch exported := value.Export().(type) {
+	case string:
+		return exported
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(exported)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return value.String()
+}
+
+// isNullish reports whether a script value is missing, undefined or null
+func isNullish(value goja.Value) bool {
+	return value == nil || goja.IsUndefined(value) || goja.IsNull(value)
+}
+
+// jsError drops the stack trace from a script exception, keeping its message
+func jsError(err error) error {
+	if exception, ok := err.(*goja.Exception); ok {
+		return fmt.Errorf("%s", exception.Value().String())
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+)
+
+// byteLimitCheckInterval is how often the received data volume is compared to stopAfterBytes
+const byteLimitCheckInterval = 50 * time.Millisecond
+
+// monitorByteLimit calls stop once the response data received reaches the
+// stopAfterBytes setting, ending the benchmark like Ctrl+C: in-flight requests
+// get the grace period. The returned function stops the monitor.
+func (r *Runner) monitorByteLimit(ctx context.Context, stop context.CancelFunc) func() {
+	limit := int64(r.Config.Settings.StopAfterBytes)
+	if limit <= 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(byteLimitCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if received := atomic.LoadInt64(&r.Stats.TotalBytes); received >= limit {
+				if !r.QuietMode {
+					fmt.Fprintf(r.Log, "\n[info] Received %s, reached stopAfterBytes limit of %s\n", formatBytes(received), formatBytes(limit))
+				}
+				stop()
+				return
+			}
+		}
+	}()
+	return cancel
+}
+
+// Memory budget (maxMemory)
+const (
+	memoryCheckInterval  = time.Second
+	memoryHighWater      = 0.8  // Fraction of maxMemory at which latency samples are downsampled
+	minReservoirSamples  = 1000 // Downsampling stops at this many samples per series
+	firstDownsampleRatio = 10   // The first downsampling keeps a tenth of the samples, later ones half
+)
+
+// monitorMemory keeps the process within the maxMemory setting. It sets it as
+// the Go runtime's soft memory limit and, whenever the heap approaches it,
+// keeps fewer raw latency samples per series. The returned function stops the
+// monitor.
+func (r *Runner) monitorMemory(ctx context.Context) func() {
+	limit := int64(r.Config.Settings.MaxMemory)
+	if limit <= 0 {
+		return func() {}
+	}
+	previousLimit := debug.SetMemoryLimit(limit)
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(memoryCheckInterval)
+		defer ticker.Stop()
+
+		sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+		capacity := maxReservoirSamples
+		warned := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			metrics.Read(sample)
+			heap := int64(sample[0].Value.Uint64())
+			if float64(heap) < memoryHighWater*float64(limit) {
+				continue
+			}
+
+			if capacity > minReservoirSamples {
+				if capacity == maxReservoirSamples {
+					capacity /= firstDownsampleRatio
+				} else {
+					capacity /= 2
+				}
+				capacity = max(capacity, minReservoirSamples)
+				if r.Stats.downsample(capacity) && !r.QuietMode {
+					fmt.Fprintf(r.Log, "\n[info] Heap at %s of the %s memory budget, keeping %d latency samples per series\n",
+						formatBytes(heap), formatBytes(limit), capacity)
+				}
+			} else if !warned && !r.QuietMode {
+				fmt.Fprintf(r.Log, "\n[warn] Heap at %s of the %s memory budget with latency samples already at the minimum\n",
+					formatBytes(heap), formatBytes(limit))
+				warned = true
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+		debug.SetMemoryLimit(previousLimit)
+	}
+}
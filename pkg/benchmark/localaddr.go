@@ -0,0 +1,148 @@
+package benchmark
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync/atomic"
+)
+
+// AddressFamilies counts the connections opened over each IP version
+type AddressFamilies struct {
+	IPv4 int64
+	IPv6 int64
+}
+
+// ConnectionChurn counts the connections opened and closed during a run. Few
+// closes and many requests per connection mean the idle pool kept connections
+// alive; closes close to the opens mean they were churned (idle timeouts, a
+// small idle pool, keep-alive disabled or the server closing them).
+type ConnectionChurn struct {
+	Opened int64
+	Closed int64 // By either side before the run ended
+}
+
+// sourceDialer opens connections, binding each one to the next of the
+// configured local addresses so connections are spread over all of them. That
+// multiplies the ephemeral ports available and avoids per-client-IP limits.
+// Without local addresses it dials like its net.Dialer. It also forces the IP
+// version when one is configured and counts the connections of each version,
+// and how many of them were closed. Connections are throttled to the upload
+// and download bandwidth if set.
+type sourceDialer struct {
+	dialer   *net.Dialer
+	network  string // "tcp4" or "tcp6" to force an IP version, "tcp" for either
+	addrs    []*net.TCPAddr
+	upload   int64 // Bytes per second per connection (0 = unlimited)
+	download int64 // Bytes per second per connection (0 = unlimited)
+	next     atomic.Uint64
+	ipv4     atomic.Int64
+	ipv6     atomic.Int64
+	closed   atomic.Int64
+}
+
+// newSourceDialer creates a dialer connecting over network and binding
+// connections to addrs in turn
+func newSourceDialer(dialer *net.Dialer, network string, addrs []net.IP) *sourceDialer {
+	d := &sourceDialer{dialer: dialer, network: network}
+	for _, ip := range addrs {
+		d.addrs = append(d.addrs, &net.TCPAddr{IP: ip})
+	}
+	return d
+}
+
+// DialContext connects to addr from the next local address. When addr is a
+// hostname, net.Dialer only tries its addresses of the forced IP version and
+// of that local address's family.
+func (d *sourceDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if network == "tcp" {
+		network = d.network
+	}
+	dialer := d.dialer
+	if len(d.addrs) > 0 {
+		bound := *d.dialer
+		bound.LocalAddr = d.addrs[(d.next.Add(1)-1)%uint64(len(d.addrs))]
+		dialer = &bound
+	}
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	if remote, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		if remote.IP.To4() != nil {
+			d.ipv4.Add(1)
+		} else {
+			d.ipv6.Add(1)
+		}
+	}
+	return d.throttle(&trackedConn{Conn: conn, dialer: d}), nil
+}
+
+// trackedConn counts its close in the dialer that opened it
+type trackedConn struct {
+	net.Conn
+	dialer *sourceDialer
+	closed atomic.Bool
+}
+
+// Close closes the connection, counting only the first call
+func (c *trackedConn) Close() error {
+	if c.closed.CompareAndSwap(false, true) {
+		c.dialer.closed.Add(1)
+	}
+	return c.Conn.Close()
+}
+
+// DialTLSContext connects like DialContext and performs the TLS handshake
+func (d *sourceDialer) DialTLSContext(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return tlsHandshake(ctx, conn, cfg)
+}
+
+// Dial connects without a context, for the fasthttp engine
+func (d *sourceDialer) Dial(addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), "tcp", addr)
+}
+
+// tlsHandshake performs the client TLS handshake over conn, closing it on failure
+func tlsHandshake(ctx context.Context, conn net.Conn, cfg *tls.Config) (net.Conn, error) {
+	tlsConn := tls.Client(conn, cfg)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// recordDialer stores how many connections were opened over IPv4 and over
+// IPv6, and how many were closed, in the stats
+func (r *Runner) recordDialer() {
+	if r.dialer == nil {
+		return
+	}
+	families := AddressFamilies{
+		IPv4: r.dialer.ipv4.Load(),
+		IPv6: r.dialer.ipv6.Load(),
+	}
+	r.Stats.SetAddressFamilies(families)
+	r.Stats.SetConnectionChurn(ConnectionChurn{
+		Opened: families.IPv4 + families.IPv6,
+		Closed: r.dialer.closed.Load(),
+	})
+}
+
+// checkInterface warns when the interface connections are bound to doesn't
+// exist on this host, as every connection would fail
+func (r *Runner) checkInterface() {
+	iface := r.Config.Settings.Interface
+	if iface == "" || r.QuietMode {
+		return
+	}
+	if _, err := net.InterfaceByName(iface); err != nil {
+		fmt.Fprintf(r.Log, "[warn] Interface %s: %v\n", iface, err)
+	}
+}
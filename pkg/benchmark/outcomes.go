@@ -0,0 +1,118 @@
+package benchmark
+
+import "github.com/HdrHistogram/hdrhistogram-go"
+
+// Request outcomes that latencies are split by
+const (
+	outcomeSuccess = iota
+	outcomeFailure
+)
+
+// outcomeOf returns the outcome index of a request
+func outcomeOf(success bool) int {
+	if success {
+		return outcomeSuccess
+	}
+	return outcomeFailure
+}
+
+// latencySeries is the latency distribution of one request outcome, kept
+// apart so that fast failures (e.g. immediate 503s) don't flatter the success
+// latencies, and slow timeouts don't hide in the combined numbers
+type latencySeries struct {
+	total   int64
+	count   int64
+	max     int64
+	hdr     *HdrStats // nil in legacy mode
+	samples sampleReservoir
+}
+
+// LatencySummary summarizes the latencies of one request outcome
+type LatencySummary struct {
+	Count       int64
+	AvgUs       float64
+	MaxUs       int64
+	Percentiles []int64 // In the order of the requested percentiles
+}
+
+// newLatencySeries creates an empty series, with a histogram when useHdr is set
+func newLatencySeries(useHdr bool) latencySeries {
+	var series latencySeries
+	if useHdr {
+		if hdr, err := NewHdrStats(1, 60000000, 3); err == nil {
+			series.hdr = hdr
+		}
+	}
+	return series
+}
+
+// record adds one latency to the series
+func (ls *latencySeries) record(responseTimeMicros int64) {
+	ls.total += responseTimeMicros
+	ls.count++
+	ls.max = max(ls.max, responseTimeMicros)
+	if ls.hdr != nil {
+		ls.hdr.RecordValue(responseTimeMicros)
+	} else {
+		ls.samples.add(float64(responseTimeMicros))
+	}
+}
+
+// summary returns the series' count, average, maximum and the given percentiles
+func (ls *latencySeries) summary(percentiles []float64) LatencySummary {
+	summary := LatencySummary{Count: ls.count, MaxUs: ls.max, Percentiles: make([]int64, len(percentiles))}
+	if ls.count == 0 {
+		return summary
+	}
+	summary.AvgUs = float64(ls.total) / float64(ls.count)
+	for i, p := range percentiles {
+		if ls.hdr != nil {
+			summary.Percentiles[i] = ls.hdr.Percentile(p)
+		} else {
+			summary.Percentiles[i] = ls.samples.percentile(p)
+		}
+	}
+	return summary
+}
+
+// LatencyByOutcome returns the latencies of successful and of failed requests
+// separately, with the given percentiles. The combined figures are the usual
+// latency statistics.
+func (s *Stats) LatencyByOutcome(percentiles []float64) (success, failure LatencySummary) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.flushLatencies()
+
+	return s.outcomes[outcomeSuccess].summary(percentiles), s.outcomes[outcomeFailure].summary(percentiles)
+}
+
+// LatencySeriesSnapshot is a serializable copy of one outcome's latencies
+type LatencySeriesSnapshot struct {
+	Total     int64                  `json:"total"`
+	Count     int64                  `json:"count"`
+	Max       int64                  `json:"max"`
+	Histogram *hdrhistogram.Snapshot `json:"histogram,omitempty"`
+	Samples   []float64              `json:"samples,omitempty"`
+}
+
+// snapshot copies the series. The caller must hold s.mutex.
+func (ls *latencySeries) snapshot() *LatencySeriesSnapshot {
+	snap := &LatencySeriesSnapshot{Total: ls.total, Count: ls.count, Max: ls.max}
+	if ls.hdr != nil {
+		snap.Histogram = ls.hdr.Export()
+	} else {
+		snap.Samples = append([]float64(nil), ls.samples.values()...)
+	}
+	return snap
+}
+
+// merge adds a snapshot taken in another process. The caller must hold s.mutex.
+func (ls *latencySeries) merge(snap *LatencySeriesSnapshot) {
+	if snap == nil {
+		return
+	}
+	ls.total += snap.Total
+	ls.count += snap.Count
+	ls.max = max(ls.max, snap.Max)
+	mergeLatencies(ls.hdr, &ls.samples, snap.Histogram, snap.Samples)
+}
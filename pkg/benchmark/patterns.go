@@ -0,0 +1,53 @@
+package benchmark
+
+import (
+	"regexp"
+	"sync"
+	"sync/atomic"
+)
+
+// maxCachedEntries bounds each memo cache. Error strings can embed varying
+// details (e.g. local ports), so only the first distinct ones are kept.
+const maxCachedEntries = 1024
+
+// memoCache remembers computed values for up to maxCachedEntries distinct keys,
+// so the work is done once per distinct input rather than once per request
+type memoCache[V any] struct {
+	entries sync.Map
+	size    atomic.Int64
+}
+
+// get returns the cached value for key, computing and caching it on a miss
+func (c *memoCache[V]) get(key string, compute func(string) V) V {
+	if value, ok := c.entries.Load(key); ok {
+		return value.(V)
+	}
+	value := compute(key)
+	if c.size.Load() < maxCachedEntries {
+		if _, loaded := c.entries.LoadOrStore(key, value); !loaded {
+			c.size.Add(1)
+		}
+	}
+	return value
+}
+
+// compiledPattern is the result of compiling a regular expression
+type compiledPattern struct {
+	re  *regexp.Regexp
+	err error
+}
+
+var (
+	errorCategories  memoCache[string]          // categorizeError results by error text
+	errorMessages    memoCache[string]          // extractErrorMessage results by content type and body
+	compiledPatterns memoCache[compiledPattern] // User supplied regular expressions
+)
+
+// compilePattern compiles a regular expression once and reuses it afterwards
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	compiled := compiledPatterns.get(pattern, func(pattern string) compiledPattern {
+		re, err := regexp.Compile(pattern)
+		return compiledPattern{re: re, err: err}
+	})
+	return compiled.re, compiled.err
+}
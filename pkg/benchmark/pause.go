@@ -0,0 +1,123 @@
+package benchmark
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pauseGate holds workers back while the benchmark is paused and tracks the
+// paused time, which is left out of the duration and throughput math
+type pauseGate struct {
+	mutex    sync.Mutex
+	resumed  chan struct{} // Closed on resume; nil while running
+	pausedAt time.Time
+	total    time.Duration // Completed pauses
+}
+
+// pause stops new requests; it reports false if already paused
+func (g *pauseGate) pause() bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.resumed != nil {
+		return false
+	}
+	g.resumed = make(chan struct{})
+	g.pausedAt = time.Now()
+	return true
+}
+
+// resume lets workers continue; it reports false if not paused
+func (g *pauseGate) resume() bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.resumed == nil {
+		return false
+	}
+	close(g.resumed)
+	g.resumed = nil
+	g.total += time.Since(g.pausedAt)
+	return true
+}
+
+// paused reports whether the benchmark is paused
+func (g *pauseGate) paused() bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.resumed != nil
+}
+
+// pausedFor returns the total paused time, including a pause in progress
+func (g *pauseGate) pausedFor() time.Duration {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.resumed != nil {
+		return g.total + time.Since(g.pausedAt)
+	}
+	return g.total
+}
+
+// wait blocks while paused. It returns false if ctx is done or stop is closed first.
+func (g *pauseGate) wait(ctx context.Context, stop <-chan struct{}) bool {
+	g.mutex.Lock()
+	resumed := g.resumed
+	g.mutex.Unlock()
+	if resumed == nil {
+		return true
+	}
+	select {
+	case <-resumed:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-stop:
+		return false
+	}
+}
+
+// Pause stops sending new requests until Resume. In-flight requests (and the
+// current scenario iteration) complete. It reports false if already paused.
+func (r *Runner) Pause() bool {
+	return r.pause.pause()
+}
+
+// Resume continues a paused benchmark; it reports false if it was not paused
+func (r *Runner) Resume() bool {
+	return r.pause.resume()
+}
+
+// TogglePause pauses a running benchmark or resumes a paused one and reports whether it is now paused
+func (r *Runner) TogglePause() bool {
+	if r.pause.resume() {
+		return false
+	}
+	return r.pause.pause()
+}
+
+// Paused reports whether the benchmark is paused
+func (r *Runner) Paused() bool {
+	return r.pause.paused()
+}
+
+// activeElapsed returns the time since start, excluding paused time and
+// including the time already run before a resumed checkpoint
+func (r *Runner) activeElapsed(start time.Time) time.Duration {
+	return time.Since(start) - r.pause.pausedFor() + r.resumeOffset
+}
+
+// waitActive waits until d of unpaused time has passed since start. It returns false if ctx is done first.
+func (r *Runner) waitActive(ctx context.Context, start time.Time, d time.Duration) bool {
+	for {
+		remaining := d - r.activeElapsed(start)
+		if remaining <= 0 {
+			return true
+		}
+		timer := time.NewTimer(remaining)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		}
+	}
+}
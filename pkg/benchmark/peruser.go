@@ -0,0 +1,35 @@
+package benchmark
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// createClientPerUser gives every user its own client, with a transport that
+// holds a single connection per host. Connections are never shared, so each
+// user looks like a distinct client to the server: per-connection state and
+// per-connection rate limits apply to each user instead of to a shared pool.
+func (r *Runner) createClientPerUser(tlsConfig *tls.Config) {
+	r.clients = make([]*http.Client, r.Config.WorkerCount())
+	for i := range r.clients {
+		r.clients[i] = &http.Client{
+			Timeout:   r.Timeout,
+			Transport: r.newUserTransport(tlsConfig),
+		}
+	}
+	r.client = r.clients[0]
+}
+
+// newUserTransport creates the single-connection transport of one user
+func (r *Runner) newUserTransport(tlsConfig *tls.Config) http.RoundTripper {
+	switch {
+	case r.Config.Settings.HTTP2:
+		return r.newHTTP2Transport(tlsConfig)
+	case r.Config.Settings.Engine == config.EngineFastHTTP:
+		return newFastHTTPTransport(r.Config, tlsConfig, r.Timeout, r.dns, r.dialer, 1)
+	default:
+		return r.newHTTP1Transport(tlsConfig, 1)
+	}
+}
@@ -0,0 +1,232 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/benchmarking_go/pkg/plugin"
+)
+
+// pluginSet holds the plugin processes of a run: the auth plugin and every
+// protocol plugin found in the plugins directory
+type pluginSet struct {
+	auth      *authHeaders              // nil without an auth plugin
+	protocols map[string]*plugin.Client // By URL scheme
+	clients   []*plugin.Client          // All started plugins, to stop after the run
+	err       error                     // Why the plugins could not be started; fails every request
+}
+
+// authHeaders caches the headers of an auth plugin until they expire
+type authHeaders struct {
+	client  *plugin.Client
+	mu      sync.Mutex
+	headers map[string]string
+	expires time.Time // Zero when the headers never expire
+}
+
+// pluginTransport adds the auth plugin's headers to every request and sends
+// requests for plugin URL schemes through their protocol plugins. Like the
+// fasthttp engine it is an http.RoundTripper, so stats, scenarios and reports
+// work unchanged.
+type pluginTransport struct {
+	base    http.RoundTripper
+	plugins *pluginSet
+}
+
+// startPlugins starts the configured plugins and routes the HTTP clients
+// through them. If a plugin cannot be started, every request fails with the
+// error rather than silently going without it.
+func (r *Runner) startPlugins(ctx context.Context) {
+	registry, err := r.Config.Plugins()
+	if registry == nil && err == nil {
+		return
+	}
+
+	plugins := &pluginSet{protocols: make(map[string]*plugin.Client)}
+	if err == nil {
+		err = plugins.start(ctx, registry, r.Config.Settings.AuthPlugin, r.Timeout)
+	}
+	if err != nil {
+		plugins.err = err
+		if !r.QuietMode {
+			fmt.Fprintf(r.Log, "[warn] %v\n", err)
+		}
+	} else if r.VerboseMode {
+		for _, client := range plugins.clients {
+			fmt.Fprintf(r.Log, "Started plugin %s\n", client.Name())
+		}
+	}
+	r.plugins = plugins
+
+	clients := r.clients
+	if len(clients) == 0 {
+		clients = []*http.Client{r.client}
+	}
+	for _, client := range clients {
+		client.Transport = &pluginTransport{base: client.Transport, plugins: plugins}
+	}
+}
+
+// start starts the auth plugin and the protocol plugins and fetches the first auth headers
+func (p *pluginSet) start(ctx context.Context, registry plugin.Registry, authPlugin string, timeout time.Duration) error {
+	started := make(map[string]*plugin.Client)
+	run := func(manifest *plugin.Manifest) (*plugin.Client, error) {
+		if client, ok := started[manifest.Name]; ok {
+			return client, nil
+		}
+		client, err := plugin.Start(manifest, nil)
+		if err != nil {
+			return nil, err
+		}
+		started[manifest.Name] = client
+		p.clients = append(p.clients, client)
+		return client, nil
+	}
+
+	for scheme, manifest := range registry.Protocols() {
+		client, err := run(manifest)
+		if err != nil {
+			return err
+		}
+		p.protocols[scheme] = client
+	}
+
+	if authPlugin == "" {
+		return nil
+	}
+	manifest, err := registry.Get(authPlugin, plugin.KindAuth)
+	if err != nil {
+		return err
+	}
+	client, err := run(manifest)
+	if err != nil {
+		return err
+	}
+	p.auth = &authHeaders{client: client}
+
+	// Fetch the first headers now, so the first requests aren't slowed down by it
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	_, err = p.auth.get(ctx)
+	return err
+}
+
+// closePlugins stops the plugin processes after the run
+func (r *Runner) closePlugins() {
+	if r.plugins == nil {
+		return
+	}
+	for _, client := range r.plugins.clients {
+		if err := client.Close(); err != nil && !r.QuietMode {
+			fmt.Fprintf(r.Log, "[warn] %v\n", err)
+		}
+	}
+}
+
+// get returns the auth headers, asking the plugin for new ones once they expire
+func (a *authHeaders) get(ctx context.Context) (map[string]string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.headers != nil && (a.expires.IsZero() || time.Now().Before(a.expires)) {
+		return a.headers, nil
+	}
+
+	reply, err := a.client.Call(ctx, &plugin.Message{Type: plugin.TypeAuth})
+	if err != nil {
+		return nil, err
+	}
+	a.expires = time.Time{}
+	if reply.TTL != "" {
+		ttl, err := time.ParseDuration(reply.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: invalid ttl: %w", a.client.Name(), err)
+		}
+		a.expires = time.Now().Add(ttl)
+	}
+	a.headers = reply.Headers
+	if a.headers == nil {
+		a.headers = map[string]string{}
+	}
+	return a.headers, nil
+}
+
+// RoundTrip adds the auth headers and sends the request through its protocol plugin, if any
+func (t *pluginTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.plugins.err != nil {
+		closeBody(req)
+		return nil, t.plugins.err
+	}
+	if t.plugins.auth != nil {
+		headers, err := t.plugins.auth.get(req.Context())
+		if err != nil {
+			closeBody(req)
+			return nil, err
+		}
+		req = req.Clone(req.Context()) // A RoundTripper must not change the caller's request
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+	}
+	if client, ok := t.plugins.protocols[req.URL.Scheme]; ok {
+		return sendThroughPlugin(client, req)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// sendThroughPlugin has a protocol plugin send a request and converts its reply
+func sendThroughPlugin(client *plugin.Client, req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := client.Call(req.Context(), &plugin.Message{
+		Type: plugin.TypeRequest,
+		Request: &plugin.Request{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: req.Header,
+			Body:    body,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reply.Response == nil {
+		return nil, fmt.Errorf("plugin %s: reply has no response", client.Name())
+	}
+
+	resp := reply.Response
+	header := http.Header(resp.Headers)
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.Status, http.StatusText(resp.Status)),
+		StatusCode:    resp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}, nil
+}
+
+// closeBody closes the body of a request that won't be sent
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
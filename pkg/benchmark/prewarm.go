@@ -0,0 +1,127 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// prepareConnections creates the HTTP client before measuring starts, checks
+// the interface to send from, starts the plugins, resolves the target hosts when DNS caching is on and optionally
+// prewarms connections
+func (r *Runner) prepareConnections(ctx context.Context) {
+	r.createHTTPClient()
+	r.trackAddresses()
+	r.trackRedirects()
+	r.checkInterface()
+	r.startPlugins(ctx)
+	r.resolveHosts(ctx)
+	if r.Config.Settings.Prewarm {
+		r.prewarm(ctx)
+	}
+}
+
+// prewarm opens the connection pool before measuring: every user sends a HEAD
+// request to each target host at the same time, so all connections (TCP and
+// TLS) are established and kept idle for the workers. Failures only warn.
+func (r *Runner) prewarm(ctx context.Context) {
+	origins := r.targetOrigins()
+	if len(origins) == 0 {
+		return
+	}
+	users := r.Config.InFlightLimit()
+	if r.Config.Settings.ConnectionPerUser {
+		users = len(r.clients) // Every user has its own connection to open
+	}
+	if !r.QuietMode {
+		fmt.Fprintf(r.Log, "Prewarming %d connection(s) to %d host(s)...", users, len(origins))
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	var failed int64
+	for _, origin := range origins {
+		for i := 0; i < users; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := r.warmConnection(ctx, r.clientFor(i), origin); err != nil {
+					atomic.AddInt64(&failed, 1)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	if !r.QuietMode {
+		fmt.Fprintf(r.Log, " done in %s\n", time.Since(start).Round(time.Millisecond))
+		if failed > 0 {
+			fmt.Fprintf(r.Log, "[warn] %d prewarm request(s) failed\n", failed)
+		}
+		fmt.Fprintln(r.Log)
+	}
+}
+
+// warmConnection sends one HEAD request to origin and reads the response, which
+// returns its connection to the idle pool
+func (r *Runner) warmConnection(ctx context.Context, client *http.Client, origin string) error {
+	reqCtx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodHead, origin, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "benchmarking_go/2.1")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// targetOrigins returns the distinct scheme://host origins the benchmark sends
+// to. URLs that still contain placeholders after resolving the config
+// variables are skipped, since their host is only known at run time.
+func (r *Runner) targetOrigins() []string {
+	var urls []string
+	if r.Config.IsScenarioMode() {
+		for _, step := range r.Config.AllSteps() {
+			if step.WebSocket == nil {
+				urls = append(urls, step.URL)
+			}
+		}
+	} else {
+		for _, req := range r.Config.Requests {
+			urls = append(urls, req.URL)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var origins []string
+	for _, raw := range urls {
+		resolved := config.ResolveVariables(raw, r.Config.Variables)
+		if strings.Contains(resolved, "{{") {
+			continue
+		}
+		u, err := url.Parse(resolved)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			continue
+		}
+		origin := u.Scheme + "://" + u.Host + "/"
+		if !seen[origin] {
+			seen[origin] = true
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
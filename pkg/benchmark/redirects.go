@@ -0,0 +1,162 @@
+package benchmark
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// redirectTransport records the latency of every redirect hop: the round trip
+// of a request that was answered with a redirect for the client to follow
+type redirectTransport struct {
+	base  http.RoundTripper
+	stats *Stats
+}
+
+// RoundTrip sends the request, recording its latency when it gets a redirect
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && isRedirect(resp) {
+		t.stats.recordRedirectHop(time.Since(start).Microseconds())
+	}
+	return resp, err
+}
+
+// isRedirect reports whether the client follows a response to another location
+func isRedirect(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return resp.Header.Get("Location") != ""
+	}
+	return false
+}
+
+// redirectHops counts the redirects the client followed to get a response
+func redirectHops(resp *http.Response) int64 {
+	var hops int64
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		hops++
+	}
+	return hops
+}
+
+// trackRedirects wraps the HTTP clients' transports to time redirect hops
+func (r *Runner) trackRedirects() {
+	clients := r.clients
+	if len(clients) == 0 {
+		clients = []*http.Client{r.client}
+	}
+	for _, client := range clients {
+		client.Transport = &redirectTransport{base: client.Transport, stats: r.Stats}
+	}
+}
+
+// redirectTracker counts redirect chains. The counters are updated atomically
+// for every response; only the hop latencies take the lock.
+type redirectTracker struct {
+	responses  int64 // Responses received, redirected or not
+	redirected int64 // Responses reached through at least one redirect
+	hops       int64
+	maxHops    int64
+
+	mu         sync.Mutex
+	hopLatency latencySeries
+}
+
+// RecordRedirects records the length of the redirect chain that led to one response
+func (s *Stats) RecordRedirects(hops int64) {
+	rt := &s.redirects
+	atomic.AddInt64(&rt.responses, 1)
+	if hops == 0 {
+		return
+	}
+	atomic.AddInt64(&rt.redirected, 1)
+	atomic.AddInt64(&rt.hops, hops)
+	for {
+		current := atomic.LoadInt64(&rt.maxHops)
+		if hops <= current || atomic.CompareAndSwapInt64(&rt.maxHops, current, hops) {
+			return
+		}
+	}
+}
+
+// recordRedirectHop records the latency of one redirect hop
+func (s *Stats) recordRedirectHop(latencyMicros int64) {
+	s.redirects.mu.Lock()
+	s.redirects.hopLatency.record(latencyMicros)
+	s.redirects.mu.Unlock()
+}
+
+// RedirectReport is the redirect behaviour seen during a run
+type RedirectReport struct {
+	Responses      int64
+	Redirected     int64   // Responses reached through at least one redirect
+	RedirectedRate float64 // Share of the responses that were redirected
+	Hops           int64
+	AvgHops        float64 // Chain length of the redirected responses
+	MaxHops        int64
+	HopLatency     LatencySummary
+}
+
+// RedirectReport returns how many requests were redirected, their average
+// chain length and the latency of single hops with the given percentiles, or
+// nil when no request was redirected
+func (s *Stats) RedirectReport(percentiles []float64) *RedirectReport {
+	rt := &s.redirects
+	redirected := atomic.LoadInt64(&rt.redirected)
+	if redirected == 0 {
+		return nil
+	}
+	report := &RedirectReport{
+		Responses:  atomic.LoadInt64(&rt.responses),
+		Redirected: redirected,
+		Hops:       atomic.LoadInt64(&rt.hops),
+		MaxHops:    atomic.LoadInt64(&rt.maxHops),
+	}
+	report.RedirectedRate = float64(report.Redirected) / float64(report.Responses)
+	report.AvgHops = float64(report.Hops) / float64(report.Redirected)
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	report.HopLatency = rt.hopLatency.summary(percentiles)
+	return report
+}
+
+// RedirectSnapshot is a serializable copy of the redirect counts
+type RedirectSnapshot struct {
+	Responses  int64                  `json:"responses"`
+	Redirected int64                  `json:"redirected"`
+	Hops       int64                  `json:"hops"`
+	MaxHops    int64                  `json:"maxHops"`
+	HopLatency *LatencySeriesSnapshot `json:"hopLatency"`
+}
+
+// snapshot copies the tracker
+func (rt *redirectTracker) snapshot() *RedirectSnapshot {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return &RedirectSnapshot{
+		Responses:  atomic.LoadInt64(&rt.responses),
+		Redirected: atomic.LoadInt64(&rt.redirected),
+		Hops:       atomic.LoadInt64(&rt.hops),
+		MaxHops:    atomic.LoadInt64(&rt.maxHops),
+		HopLatency: rt.hopLatency.snapshot(),
+	}
+}
+
+// merge adds a snapshot taken in another process
+func (rt *redirectTracker) merge(snap *RedirectSnapshot) {
+	atomic.AddInt64(&rt.responses, snap.Responses)
+	atomic.AddInt64(&rt.redirected, snap.Redirected)
+	atomic.AddInt64(&rt.hops, snap.Hops)
+	if snap.MaxHops > atomic.LoadInt64(&rt.maxHops) {
+		atomic.StoreInt64(&rt.maxHops, snap.MaxHops)
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.hopLatency.merge(snap.HopLatency)
+}
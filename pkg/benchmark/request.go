@@ -2,7 +2,6 @@
 package benchmark
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -18,19 +17,43 @@ import (
 	"golang.org/x/net/http2"
 )
 
-// extractErrorMessage extracts error messages from response body
+// Patterns for cleaning up plain text error bodies
+var (
+	htmlTagRegex    = regexp.MustCompile(`<[^>]*>`)
+	whitespaceRegex = regexp.MustCompile(`\s+`)
+)
+
+// maxCachedErrorBody is the largest error body whose message is cached
+const maxCachedErrorBody = 1024
+
+// extractErrorMessage extracts error messages from response body. Servers tend to
+// repeat the same error bodies, so messages of small bodies are cached.
 func extractErrorMessage(body []byte, contentType string) string {
 	if len(body) == 0 {
 		return ""
 	}
+	isJSON := strings.Contains(contentType, "json")
+	if len(body) > maxCachedErrorBody {
+		return parseErrorMessage(string(body), isJSON)
+	}
+	key := string(body)
+	if isJSON {
+		key = "json:" + key
+	}
+	return errorMessages.get(key, func(string) string {
+		return parseErrorMessage(string(body), isJSON)
+	})
+}
 
+// parseErrorMessage extracts the error message of a response body
+func parseErrorMessage(bodyStr string, isJSON bool) string {
 	// Limit message length
 	const maxMessageLength = 100
 
 	// Try JSON parsing first if content type suggests JSON
-	if strings.Contains(contentType, "json") || (len(body) > 0 && body[0] == '{') {
+	if isJSON || bodyStr[0] == '{' {
 		var result map[string]interface{}
-		if err := json.Unmarshal(body, &result); err == nil {
+		if err := json.Unmarshal([]byte(bodyStr), &result); err == nil {
 			// Try common error message fields
 			for _, key := range []string{"error", "message", "msg", "detail", "error_description", "errorMessage"} {
 				if msg, ok := result[key].(string); ok && msg != "" {
@@ -53,18 +76,15 @@ func extractErrorMessage(body []byte, contentType string) string {
 	}
 
 	// Try plain text parsing
-	bodyStr := string(body)
 	bodyStr = strings.TrimSpace(bodyStr)
 
 	// Remove HTML tags if present
 	if strings.Contains(bodyStr, "<") {
-		htmlTagRegex := regexp.MustCompile(`<[^>]*>`)
 		bodyStr = htmlTagRegex.ReplaceAllString(bodyStr, " ")
 		bodyStr = strings.TrimSpace(bodyStr)
 	}
 
 	// Normalize whitespace (replace multiple spaces/newlines with single space)
-	whitespaceRegex := regexp.MustCompile(`\s+`)
 	bodyStr = whitespaceRegex.ReplaceAllString(bodyStr, " ")
 	bodyStr = strings.TrimSpace(bodyStr)
 
@@ -82,9 +102,14 @@ func extractErrorMessage(body []byte, contentType string) string {
 	return ""
 }
 
-// categorizeError normalizes error messages for proper grouping
+// categorizeError normalizes error messages for proper grouping. Categories are
+// cached by error text, since failing runs tend to repeat the same few errors.
 func categorizeError(err error) string {
-	errStr := err.Error()
+	return errorCategories.get(err.Error(), classifyError)
+}
+
+// classifyError maps an error text to its category
+func classifyError(errStr string) string {
 
 	// Connection/network errors
 	if strings.Contains(errStr, "connection refused") {
@@ -132,110 +157,205 @@ func (r *Runner) createHTTPClient() {
 		InsecureSkipVerify: r.Config.Settings.Insecure,
 	}
 
+	keepAlive, _ := r.Config.GetTCPKeepAlive() // Validated with the config
+	dialer := newSourceDialer(&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: keepAlive,
+	}, r.Config.GetNetwork(), r.Config.GetLocalAddresses())
+	if iface := r.Config.Settings.Interface; iface != "" {
+		dialer.dialer.Control = bindToInterface(iface)
+	}
+	dialer.upload = int64(r.Config.Settings.UploadBandwidth)
+	dialer.download = int64(r.Config.Settings.DownloadBandwidth)
+	r.dialer = dialer
+	if enabled, ttl, _ := r.Config.GetDNSCache(); enabled || r.Config.Settings.RoundRobinIPs {
+		r.dns = newDNSCache(ttl, dialer)
+		r.dns.roundRobin = r.Config.Settings.RoundRobinIPs
+	}
+
+	// A dedicated client and connection for every user
+	if r.Config.Settings.ConnectionPerUser {
+		r.createClientPerUser(tlsConfig)
+		return
+	}
+
 	// Check if HTTP/2 is enabled
 	if r.Config.Settings.HTTP2 {
 		r.createHTTP2Client(tlsConfig)
 		return
 	}
 
+	// fasthttp engine (HTTP/1.1 only)
+	if r.Config.Settings.Engine == config.EngineFastHTTP {
+		timeout := r.Timeout
+		r.client = &http.Client{
+			Timeout:   timeout,
+			Transport: newFastHTTPTransport(r.Config, tlsConfig, timeout, r.dns, dialer, r.Config.InFlightLimit()),
+		}
+		return
+	}
+
 	// Standard HTTP/1.1 transport
+	r.client = &http.Client{
+		Timeout:   r.Timeout,
+		Transport: r.newHTTP1Transport(tlsConfig, r.Config.InFlightLimit()),
+	}
+}
+
+// newHTTP1Transport creates an HTTP/1.1 transport with up to conns connections per host
+func (r *Runner) newHTTP1Transport(tlsConfig *tls.Config, conns int) *http.Transport {
+	maxIdle, maxIdlePerHost := r.Config.GetMaxIdleConns()
+	idleTimeout, _ := r.Config.GetIdleConnTimeout()
 	transport := &http.Transport{
-		MaxIdleConns:        r.Config.Settings.ConcurrentUsers,
-		MaxIdleConnsPerHost: r.Config.Settings.ConcurrentUsers,
-		MaxConnsPerHost:     r.Config.Settings.ConcurrentUsers,
+		MaxIdleConns:        maxIdle,
+		MaxIdleConnsPerHost: min(maxIdlePerHost, conns),
+		IdleConnTimeout:     idleTimeout,
+		MaxConnsPerHost:     conns,
 		DisableCompression:  false,
 		DisableKeepAlives:   r.Config.IsKeepAliveDisabled(),
 		TLSClientConfig:     tlsConfig,
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
+		DialContext:         r.dialer.DialContext,
 	}
-
-	r.client = &http.Client{
-		Timeout:   time.Duration(r.TimeoutSec) * time.Second,
-		Transport: transport,
+	if r.dns != nil {
+		transport.DialContext = r.dns.DialContext
 	}
+	return transport
 }
 
-// createHTTP2Client creates an HTTP/2 enabled client
-func (r *Runner) createHTTP2Client(tlsConfig *tls.Config) {
-	// HTTP/2 transport
+// newHTTP2Transport creates an HTTP/2 transport, dialing through the DNS cache if there is one
+func (r *Runner) newHTTP2Transport(tlsConfig *tls.Config) *http2.Transport {
 	transport := &http2.Transport{
 		TLSClientConfig: tlsConfig,
 		AllowHTTP:       false, // Only allow HTTPS for HTTP/2
 		ReadIdleTimeout: 30 * time.Second,
 		PingTimeout:     15 * time.Second,
 	}
+	transport.IdleConnTimeout, _ = r.Config.GetIdleConnTimeout()
+	if r.dns != nil {
+		transport.DialTLSContext = r.dns.DialTLSContext
+	} else {
+		transport.DialTLSContext = r.dialer.DialTLSContext
+	}
+	return transport
+}
+
+// createHTTP2Client creates an HTTP/2 enabled client
+func (r *Runner) createHTTP2Client(tlsConfig *tls.Config) {
+	if size := r.Config.Settings.HTTP2Connections; size > 0 {
+		r.createHTTP2Pool(tlsConfig, size)
+		return
+	}
 
 	r.client = &http.Client{
-		Timeout:   time.Duration(r.TimeoutSec) * time.Second,
-		Transport: transport,
+		Timeout:   r.Timeout,
+		Transport: r.newHTTP2Transport(tlsConfig),
 	}
 }
 
-// processRequest processes a single HTTP request and records statistics
-// Note: This function will complete the full request cycle regardless of stopSending signal
-// to ensure all started requests are properly recorded in statistics
-func (r *Runner) processRequest(ctx context.Context, reqConfig *config.RequestConfig) {
+// processRequest processes a single HTTP request and records statistics.
+// Requests started before stopSending still complete; the request context is
+// derived from ctx, so once the grace period ends (or the run is interrupted)
+// they are cancelled instead of running into the timeout. Cancelled requests
+// are counted separately and processRequest returns false for them.
+func (r *Runner) processRequest(ctx context.Context, worker *WorkerStats, reqConfig *config.RequestConfig) bool {
+	// A script's delay() comes before the request, outside its latency
+	if !r.script.wait(ctx, worker.id) {
+		return false
+	}
 	requestStart := time.Now()
 
-	reqCtx, cancel := context.WithTimeout(context.Background(), time.Duration(r.TimeoutSec)*time.Second)
+	reqCtx, cancel := context.WithTimeout(ctx, r.Timeout)
 	defer cancel()
 
-	// Prepare body
-	body, err := config.PrepareRequestBody(reqConfig)
+	// Create the request, or have the script create it
+	var req *http.Request
+	var body string
+	var err error
+	if r.script != nil {
+		req, body, err = r.script.request(reqCtx, worker.id)
+	} else {
+		req, body, err = r.newRequest(reqCtx, worker.id, reqConfig)
+	}
 	if err != nil {
 		errMsg := categorizeError(err)
+		responseTime := time.Since(requestStart).Microseconds()
 		r.Stats.IncrementFailure()
 		r.Stats.AddError(errMsg)
 		r.Stats.AddStatusCode(0) // Track as 'other' for non-HTTP failure
-		r.updateRequestStats(reqConfig, 0, time.Since(requestStart).Microseconds(), errMsg)
-		return
+		worker.AddResponseTime(responseTime, false)
+		r.updateRequestStats(worker, reqConfig, false, 0, responseTime, errMsg, "")
+		return true
 	}
+	requestID := setRequestID(req, &r.Config.Settings)
 
-	// Resolve URL variables
-	url := config.ResolveVariables(reqConfig.URL, r.Config.Variables)
-
-	// Create request
-	var req *http.Request
-	if body != "" {
-		req, err = http.NewRequestWithContext(reqCtx, reqConfig.Method, url, bytes.NewBufferString(body))
-	} else {
-		req, err = http.NewRequestWithContext(reqCtx, reqConfig.Method, url, nil)
+	// Let the jsRequest function, then library hooks change the request
+	if body, _, err = r.js.request(worker.id, reqConfig.JSRequest, req, body, nil); err != nil {
+		errMsg := "jsRequest: " + err.Error()
+		responseTime := time.Since(requestStart).Microseconds()
+		r.Stats.IncrementFailure()
+		r.Stats.AddError(errMsg)
+		r.Stats.AddStatusCode(0) // Track as 'other' for non-HTTP failure
+		worker.AddResponseTime(responseTime, false)
+		r.updateRequestStats(worker, reqConfig, false, 0, responseTime, errMsg, requestID)
+		return true
 	}
-
-	if err != nil {
-		errMsg := categorizeError(err)
+	if err := r.Hooks.request(req); err != nil {
+		errMsg := "request hook: " + err.Error()
+		responseTime := time.Since(requestStart).Microseconds()
 		r.Stats.IncrementFailure()
 		r.Stats.AddError(errMsg)
 		r.Stats.AddStatusCode(0) // Track as 'other' for non-HTTP failure
-		r.updateRequestStats(reqConfig, 0, time.Since(requestStart).Microseconds(), errMsg)
-		return
+		worker.AddResponseTime(responseTime, false)
+		r.updateRequestStats(worker, reqConfig, false, 0, responseTime, errMsg, requestID)
+		return true
 	}
 
-	// Add headers
-	r.addHeaders(req, reqConfig, body)
-
 	// Verbose logging
 	if r.VerboseMode {
-		fmt.Printf("[verbose] %s %s\n", reqConfig.Method, url)
+		fmt.Fprintf(r.Log, "[verbose] %s %s\n", req.Method, req.URL)
 	}
 
 	// Send request
-	resp, err := r.client.Do(req)
+	resp, err := r.clientFor(worker.id).Do(req)
 	if err != nil {
+		if ctx.Err() != nil {
+			r.Stats.IncrementCancelled()
+			return false
+		}
 		errMsg := categorizeError(err)
+		responseTime := time.Since(requestStart).Microseconds()
 		r.Stats.IncrementFailure()
 		r.Stats.AddStatusCode(0) // Track as 'other' for connection/timeout errors
 		r.Stats.AddError(errMsg)
-		r.updateRequestStats(reqConfig, 0, time.Since(requestStart).Microseconds(), errMsg)
-		return
+		worker.AddResponseTime(responseTime, false)
+		r.updateRequestStats(worker, reqConfig, false, 0, responseTime, errMsg, requestID)
+		r.capture.Capture(errMsg, err.Error(), req, body, nil, nil)
+		r.Stats.AddErrorSample(errMsg, req, 0, nil)
+		r.Hooks.response(&Response{Request: req, Latency: time.Duration(responseTime) * time.Microsecond, Err: err})
+		return true
 	}
 	defer resp.Body.Close()
+	worker.AddTTFB(time.Since(requestStart).Microseconds())
+	r.Stats.RecordRedirects(redirectHops(resp))
+	r.Stats.RecordServerTiming(resp.Header)
 
 	// Record response
-	r.recordResponse(ctx, resp, reqConfig, requestStart)
+	return r.recordResponse(ctx, worker, resp, reqConfig, body, requestStart, requestID)
+}
+
+// newRequest creates a request from its config, returning it with its body
+func (r *Runner) newRequest(ctx context.Context, worker int, reqConfig *config.RequestConfig) (*http.Request, string, error) {
+	body, err := r.requestBody(reqConfig, worker)
+	if err != nil {
+		return nil, "", err
+	}
+	url := config.ResolveVariables(reqConfig.URL, r.Config.Variables)
+	req, err := newBodyRequest(ctx, reqConfig.Method, url, body)
+	if err != nil {
+		return nil, "", err
+	}
+	r.addHeaders(req, reqConfig, body)
+	return req, body, nil
 }
 
 // addHeaders adds all required headers to the request
@@ -245,10 +365,16 @@ func (r *Runner) addHeaders(req *http.Request, reqConfig *config.RequestConfig,
 		req.Header.Set(key, config.ResolveVariables(value, r.Config.Variables))
 	}
 
+	resolve := func(value string) string {
+		return config.ResolveVariables(value, r.Config.Variables)
+	}
+	setRotatingHeaders(req, r.Config.RotateHeaders, resolve)
+
 	// Add request-specific headers
 	for key, value := range reqConfig.Headers {
 		req.Header.Set(key, config.ResolveVariables(value, r.Config.Variables))
 	}
+	setRotatingHeaders(req, reqConfig.RotateHeaders, resolve)
 
 	// Set default content type for body
 	if body != "" && req.Header.Get("Content-Type") == "" {
@@ -261,26 +387,103 @@ func (r *Runner) addHeaders(req *http.Request, reqConfig *config.RequestConfig,
 	}
 }
 
-// recordResponse records the response statistics
-func (r *Runner) recordResponse(ctx context.Context, resp *http.Response, reqConfig *config.RequestConfig, requestStart time.Time) {
-	r.Stats.AddStatusCode(resp.StatusCode)
+// setRotatingHeaders sets one randomly picked value for each rotating header
+func setRotatingHeaders(req *http.Request, pools config.HeaderPools, resolve func(string) string) {
+	for key, pool := range pools {
+		req.Header.Set(key, resolve(pool.Pick()))
+	}
+}
 
-	respBody, err := io.ReadAll(resp.Body)
+// recordResponse records the response statistics. It returns false when the
+// benchmark was cancelled while the body was being read.
+func (r *Runner) recordResponse(ctx context.Context, worker *WorkerStats, resp *http.Response, reqConfig *config.RequestConfig, reqBody string, requestStart time.Time, requestID string) bool {
+
+	// Only failed responses are kept, up to maxKeptBody, for their error message
+	// and the failure capture; successful bodies are drained and counted unless
+	// response hooks or a jsCheck function need them or the response is sampled.
+	// Size and checksum checks hash the whole body as it is read.
+	var respBody []byte
+	var size int64
+	var err error
+	expected := reqConfig.IsExpectedStatus(resp.StatusCode)
+	sampled := r.sampler.Sample()
+	checks := reqConfig.BodyChecks()
+	hasher := newBodyHasher(checks)
+	var body io.Reader = resp.Body
+	if hasher != nil {
+		body = io.TeeReader(resp.Body, hasher)
+	}
+	if expected && !sampled && !r.Hooks.hasResponseHooks() && !r.script.needsBody() && reqConfig.JSCheck == "" {
+		size, err = discardBody(body)
+	} else {
+		respBody, size, err = readBodyPrefix(body, resp.ContentLength, maxKeptBody)
+	}
 	if err != nil {
+		if ctx.Err() != nil {
+			r.Stats.IncrementCancelled()
+			return false
+		}
+		r.Stats.AddStatusCode(resp.StatusCode)
 		errMsg := categorizeError(err)
+		responseTime := time.Since(requestStart).Microseconds()
 		r.Stats.IncrementFailure()
 		r.Stats.AddError(errMsg)
-		r.updateRequestStats(reqConfig, 0, time.Since(requestStart).Microseconds(), errMsg)
-		return
+		r.Stats.AddErrorSample(errMsg, resp.Request, resp.StatusCode, respBody)
+		worker.AddResponseTime(responseTime, false)
+		r.updateRequestStats(worker, reqConfig, false, resp.StatusCode, responseTime, errMsg, requestID)
+		r.Hooks.response(&Response{Request: resp.Request, StatusCode: resp.StatusCode, Header: resp.Header,
+			Latency: time.Duration(responseTime) * time.Microsecond, Err: err})
+		return true
 	}
 
-	r.Stats.AddBytes(int64(len(respBody)))
+	r.Stats.AddStatusCode(resp.StatusCode)
+	r.Stats.AddBytes(size)
 
 	responseTime := time.Since(requestStart).Microseconds()
 
+	// Library hooks may overrule the status check
+	success, hookErr := r.Hooks.response(&Response{Request: resp.Request, StatusCode: resp.StatusCode, Header: resp.Header,
+		Body: respBody, Latency: time.Duration(responseTime) * time.Microsecond, Success: expected})
+	if err := r.script.response(worker.id, resp, respBody); err != nil {
+		success, hookErr = false, err.Error()
+	}
+	// The jsCheck function judges a response the status check passed
+	if success {
+		if msg, _ := r.js.check(worker.id, reqConfig.JSCheck, resp, respBody, time.Duration(responseTime)*time.Microsecond, nil); msg != "" {
+			success, hookErr = false, msg
+		}
+	}
+	// A response that doesn't echo the request ID fails, whatever its status
+	idErr := requestIDMismatch(resp, &r.Config.Settings, requestID)
+	// So does a successful response whose body fails its size or checksum check
+	var bodyErr string
+	if checks != nil && success {
+		bodyErr = strings.Join(validateDigest(hasher.digest(size), checks), "; ")
+	}
+
 	var errMsg string
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+	if success && idErr == "" && bodyErr == "" {
 		r.Stats.IncrementSuccess()
+		r.Stats.recordGood(responseTime)
+	} else if success && bodyErr != "" {
+		success, errMsg = false, bodyErr
+		r.Stats.IncrementFailure()
+		r.Stats.IncrementBodyMismatch()
+		r.Stats.AddError(errMsg)
+		r.capture.Capture("body_mismatch", errMsg, resp.Request, reqBody, resp, respBody)
+		r.Stats.AddErrorSample(errMsg, resp.Request, resp.StatusCode, respBody)
+	} else if success {
+		success, errMsg = false, idErr
+		r.Stats.IncrementFailure()
+		r.Stats.AddError(errMsg)
+		r.capture.Capture("request_id", errMsg, resp.Request, reqBody, resp, respBody)
+		r.Stats.AddErrorSample(errMsg, resp.Request, resp.StatusCode, respBody)
+	} else if hookErr != "" {
+		errMsg = hookErr
+		r.Stats.IncrementFailure()
+		r.Stats.AddError(errMsg)
+		r.capture.Capture("hook_rejected", errMsg, resp.Request, reqBody, resp, respBody)
+		r.Stats.AddErrorSample(errMsg, resp.Request, resp.StatusCode, respBody)
 	} else {
 		// Include HTTP status text for better error reporting
 		statusText := http.StatusText(resp.StatusCode)
@@ -301,27 +504,42 @@ func (r *Runner) recordResponse(ctx context.Context, resp *http.Response, reqCon
 
 		r.Stats.IncrementFailure()
 		r.Stats.AddError(errMsg)
+		r.capture.Capture(failureCategory(resp.StatusCode, errMsg), errMsg, resp.Request, reqBody, resp, respBody)
+		r.Stats.AddErrorSample(errMsg, resp.Request, resp.StatusCode, respBody)
 	}
 
-	r.Stats.AddResponseTime(responseTime)
+	worker.AddResponseTime(responseTime, success)
+	r.Stats.RecordCache(reqConfig.Name, resp.Header, responseTime)
+	if sampled {
+		r.sampler.Save(errMsg, resp.Request, reqBody, resp, respBody)
+	}
 
 	// Verbose response logging
 	if r.VerboseMode {
 		url := config.ResolveVariables(reqConfig.URL, r.Config.Variables)
-		fmt.Printf("[verbose] %s %s -> %d (%s)\n", reqConfig.Method, url, resp.StatusCode, time.Duration(responseTime)*time.Microsecond)
+		fmt.Fprintf(r.Log, "[verbose] %s %s -> %d (%s)\n", reqConfig.Method, url, resp.StatusCode, time.Duration(responseTime)*time.Microsecond)
 	}
 
 	// Update per-request stats
-	r.updateRequestStats(reqConfig, resp.StatusCode, responseTime, errMsg)
+	r.updateRequestStats(worker, reqConfig, success, resp.StatusCode, responseTime, errMsg, requestID)
+	return true
 }
 
-// updateRequestStats updates the per-request statistics
-func (r *Runner) updateRequestStats(reqConfig *config.RequestConfig, statusCode int, responseTime int64, errMsg string) {
+// updateRequestStats updates the per-request and per-worker statistics and
+// reports the request to the metrics sinks
+func (r *Runner) updateRequestStats(worker *WorkerStats, reqConfig *config.RequestConfig, success bool, status int, responseTime int64, errMsg, requestID string) {
+	worker.RecordRequest(responseTime, success)
+	if len(r.Sinks) > 0 {
+		metricsSinks(r.Sinks).request(RequestMetric{Name: reqConfig.Name, Method: reqConfig.Method, Status: status,
+			Latency: time.Duration(responseTime) * time.Microsecond, Success: success, RequestID: requestID}, errMsg)
+	}
+
 	reqStats := r.Stats.GetOrCreateRequestStats(reqConfig.Name, reqConfig.URL, reqConfig.Method)
 	reqStats.Mutex.Lock()
 	reqStats.RequestCount++
 	reqStats.TotalLatency += responseTime
-	if statusCode >= 200 && statusCode < 300 {
+	reqStats.RecordLatency(responseTime)
+	if success {
 		reqStats.SuccessCount++
 	} else {
 		reqStats.FailureCount++
@@ -0,0 +1,37 @@
+package benchmark
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// setRequestID sets a fresh UUID in the configured request ID header, so each
+// request can be found in the server's logs, and returns it ("" when no header
+// is configured)
+func setRequestID(req *http.Request, settings *config.Settings) string {
+	if settings.RequestIDHeader == "" {
+		return ""
+	}
+	id := generateUUID()
+	req.Header.Set(settings.RequestIDHeader, id)
+	return id
+}
+
+// requestIDMismatch returns why a response fails the request ID check, which
+// requires the server to echo the ID in the same header, or "" when it passes
+// or verifyRequestId is off
+func requestIDMismatch(resp *http.Response, settings *config.Settings, id string) string {
+	if !settings.VerifyRequestID || id == "" {
+		return ""
+	}
+	switch echoed := resp.Header.Get(settings.RequestIDHeader); echoed {
+	case id:
+		return ""
+	case "":
+		return fmt.Sprintf("request ID not echoed in %s", settings.RequestIDHeader)
+	default:
+		return fmt.Sprintf("request ID mismatch in %s", settings.RequestIDHeader)
+	}
+}
@@ -0,0 +1,121 @@
+package benchmark
+
+import (
+	"math/rand/v2"
+	"sort"
+)
+
+// maxReservoirSamples bounds the raw latency samples kept without HdrHistogram
+const maxReservoirSamples = 100000
+
+// sampleReservoir keeps a bounded, uniformly chosen subset of all latency
+// samples for percentile queries (reservoir sampling), so memory stays constant
+// however long a run lasts. With windowed set it also keeps the newest samples
+// for rolling windows.
+type sampleReservoir struct {
+	samples  []float64 // Uniform sample of everything added
+	seen     int64     // Number of samples added
+	sorted   bool      // Whether samples is currently sorted
+	capacity int       // Samples kept (0 = maxReservoirSamples); lowered by shrink
+
+	windowed bool
+	recent   []float64 // Ring buffer of the newest samples; sample n is at n % len
+}
+
+// limit returns the number of samples kept
+func (r *sampleReservoir) limit() int {
+	if r.capacity > 0 {
+		return r.capacity
+	}
+	return maxReservoirSamples
+}
+
+// add adds a latency sample
+func (r *sampleReservoir) add(value float64) {
+	limit := r.limit()
+	if r.windowed {
+		if r.recent == nil {
+			r.recent = make([]float64, limit)
+		}
+		r.recent[r.seen%int64(len(r.recent))] = value
+	}
+	r.seen++
+
+	if len(r.samples) < limit {
+		r.samples = append(r.samples, value)
+		r.sorted = false
+		return
+	}
+	// Keep the new sample with probability capacity/seen, replacing a random one
+	if i := rand.Int64N(r.seen); i < int64(limit) {
+		r.samples[i] = value
+		r.sorted = false
+	}
+}
+
+// count returns the number of samples added
+func (r *sampleReservoir) count() int64 {
+	return r.seen
+}
+
+// values returns the retained samples. The slice must not be modified.
+func (r *sampleReservoir) values() []float64 {
+	return r.samples
+}
+
+// percentile returns the latency at the given percentile (0-100) of the retained samples
+func (r *sampleReservoir) percentile(percentile float64) int64 {
+	if !r.sorted {
+		sort.Float64s(r.samples)
+		r.sorted = true
+	}
+	return sortedPercentile(r.samples, percentile)
+}
+
+// between returns a copy of the samples added from the from-th up to the to-th,
+// as far as they are still retained among the newest ones. Only windowed
+// reservoirs keep them.
+func (r *sampleReservoir) between(from, to int64) []float64 {
+	if !r.windowed || r.recent == nil {
+		return nil
+	}
+	size := int64(len(r.recent))
+	from = max(from, r.seen-size)
+	values := make([]float64, 0, max(0, to-from))
+	for i := from; i < to; i++ {
+		values = append(values, r.recent[i%size])
+	}
+	return values
+}
+
+// shrink lowers the number of samples kept to capacity, keeping a uniform
+// subset of the retained samples and the newest ones. It reports whether
+// samples were dropped.
+func (r *sampleReservoir) shrink(capacity int) bool {
+	if capacity >= r.limit() {
+		return false
+	}
+	r.capacity = capacity
+
+	dropped := false
+	if len(r.samples) > capacity {
+		// Partial Fisher-Yates shuffle picks capacity samples at random
+		for i := 0; i < capacity; i++ {
+			j := i + rand.IntN(len(r.samples)-i)
+			r.samples[i], r.samples[j] = r.samples[j], r.samples[i]
+		}
+		r.samples = append([]float64(nil), r.samples[:capacity]...)
+		r.sorted = false
+		dropped = true
+	}
+	if r.recent != nil {
+		recent := make([]float64, capacity)
+		size := int64(len(r.recent))
+		for n := max(0, r.seen-int64(capacity)); n < r.seen; n++ {
+			recent[n%int64(capacity)] = r.recent[n%size]
+		}
+		r.recent = recent
+		dropped = true
+	}
+	return dropped
+}
@@ -0,0 +1,186 @@
+// Package benchmark provides benchmarking functionality
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// windowTolerance absorbs ticker jitter when deciding whether a full window has elapsed
+const windowTolerance = 100 * time.Millisecond
+
+// windowSnapshot holds the cumulative stats at one moment. The difference
+// between two snapshots describes the traffic in between.
+type windowSnapshot struct {
+	at        time.Time
+	success   int64
+	failure   int64
+	bytes     int64
+	good      int64                  // Requests meeting the SLO (when one is configured)
+	latency   int64                  // Sum of response times in microseconds
+	responses int64                  // Number of recorded response times
+	hdr       *hdrhistogram.Snapshot // Latency bucket counts (HdrHistogram mode)
+	samples   int64                  // Number of raw latency samples (legacy mode)
+}
+
+// snapshotWindow captures the current cumulative stats
+func (s *Stats) snapshotWindow() *windowSnapshot {
+	snap := &windowSnapshot{
+		at:      time.Now(),
+		success: atomic.LoadInt64(&s.SuccessCount),
+		failure: atomic.LoadInt64(&s.FailureCount),
+		bytes:   atomic.LoadInt64(&s.TotalBytes),
+	}
+	if s.slo != nil {
+		snap.good = atomic.LoadInt64(&s.slo.good)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.flushLatencies()
+	snap.latency = s.totalResponseTime
+	snap.responses = s.responseCount
+	if s.useHdr && s.hdrStats != nil {
+		snap.hdr = s.hdrStats.Export()
+	} else {
+		snap.samples = s.responseTimes.count()
+	}
+	return snap
+}
+
+// windowMetrics builds threshold metrics for the traffic between two snapshots
+func (s *Stats) windowMetrics(from, to *windowSnapshot) *thresholdMetrics {
+	metrics := &thresholdMetrics{
+		successCount: to.success - from.success,
+		failureCount: to.failure - from.failure,
+		bytesTracked: true,
+		totalBytes:   to.bytes - from.bytes,
+	}
+	if responses := to.responses - from.responses; responses > 0 {
+		metrics.avgLatency = float64(to.latency-from.latency) / float64(responses)
+	}
+	if seconds := to.at.Sub(from.at).Seconds(); seconds > 0 {
+		metrics.requestsPerSecond = float64(metrics.successCount+metrics.failureCount) / seconds
+		metrics.throughputMBps = float64(metrics.totalBytes) / 1024.0 / 1024.0 / seconds
+	}
+	if s.slo != nil {
+		metrics.slo = s.slo.report(metrics.successCount+metrics.failureCount, to.good-from.good, to.at.Sub(from.at).Seconds())
+	}
+
+	if from.hdr != nil && to.hdr != nil {
+		counts := make([]int64, len(to.hdr.Counts))
+		for i := range counts {
+			counts[i] = to.hdr.Counts[i] - from.hdr.Counts[i]
+		}
+		window := hdrhistogram.Import(&hdrhistogram.Snapshot{
+			LowestTrackableValue:  to.hdr.LowestTrackableValue,
+			HighestTrackableValue: to.hdr.HighestTrackableValue,
+			SignificantFigures:    to.hdr.SignificantFigures,
+			Counts:                counts,
+		})
+		metrics.percentile = func(percentile float64) int64 {
+			return window.ValueAtQuantile(percentile)
+		}
+	} else {
+		s.mutex.Lock()
+		samples := s.responseTimes.between(from.samples, to.samples)
+		s.mutex.Unlock()
+		metrics.percentile = func(percentile float64) int64 {
+			return percentileOf(samples, percentile)
+		}
+	}
+	return metrics
+}
+
+// monitorRollingThresholds evaluates the rolling thresholds every second over the
+// trailing window. When abort is enabled and a violation outlasts the grace windows,
+// it records the reason and calls abort. The returned function stops the monitor.
+func (r *Runner) monitorRollingThresholds(ctx context.Context, abort context.CancelFunc) func() {
+	rolling := r.Config.RollingThresholds
+	if rolling == nil || !rolling.HasThresholds() {
+		return func() {}
+	}
+	window, err := rolling.WindowDuration()
+	if err != nil {
+		fmt.Fprintf(r.Log, "[warn] Rolling thresholds disabled: %v\n", err)
+		return func() {}
+	}
+	graceChecks := rolling.GraceWindows * int(window/time.Second)
+
+	ctx, stop := context.WithCancel(ctx)
+	start := time.Now()
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		history := []*windowSnapshot{r.Stats.snapshotWindow()}
+		violations := 0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			now := r.Stats.snapshotWindow()
+			if r.pause.paused() {
+				// No traffic is expected while paused; start a fresh window on resume
+				history = []*windowSnapshot{now}
+				violations = 0
+				continue
+			}
+			history = append(history, now)
+			// Keep the newest snapshot that is at least one window old as the window start
+			for len(history) > 2 && now.at.Sub(history[1].at) >= window-windowTolerance {
+				history = history[1:]
+			}
+			if now.at.Sub(history[0].at) < window-windowTolerance {
+				continue // First full window not reached yet
+			}
+
+			results := &ThresholdResults{Passed: true}
+			if err := results.evaluate(r.Stats.windowMetrics(history[0], now), &rolling.ThresholdConfig, ""); err != nil {
+				fmt.Fprintf(r.Log, "[warn] Rolling thresholds disabled: %v\n", err)
+				return
+			}
+			if results.Passed {
+				violations = 0
+				continue
+			}
+
+			violations++
+			elapsed := now.at.Sub(start).Round(time.Second)
+			if violations == 1 && !r.QuietMode {
+				for _, result := range results.Results {
+					if !result.Passed {
+						fmt.Fprintf(r.Log, "\n[threshold] %s window ending at %s: %s\n", window, elapsed, result.Message)
+					}
+				}
+			}
+			if rolling.Abort && violations > graceChecks {
+				for _, result := range results.Results {
+					if !result.Passed {
+						reason := fmt.Sprintf("%s %s in the %s window ending at %s", result.Name, result.Actual, window, elapsed)
+						r.abortReason.Store(&reason)
+						break
+					}
+				}
+				abort()
+				return
+			}
+		}
+	}()
+	return stop
+}
+
+// AbortReason returns why rolling thresholds aborted the benchmark, or "" if they did not
+func (r *Runner) AbortReason() string {
+	if reason := r.abortReason.Load(); reason != nil {
+		return *reason
+	}
+	return ""
+}
@@ -3,14 +3,19 @@ package benchmark
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
+	"os"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/benchmarking_go/pkg/config"
+	"github.com/benchmarking_go/pkg/cpuset"
 	"github.com/benchmarking_go/pkg/progress"
 )
 
@@ -18,38 +23,91 @@ import (
 type Runner struct {
 	Config        *config.Config
 	DurationSec   int
-	TimeoutSec    int
+	Timeout       time.Duration // Per-request timeout
 	RampUpSec     int
 	QuietMode     bool
 	VerboseMode   bool
+	Log           io.Writer // Receives progress, warnings and verbose output (os.Stdout by default)
 	Stats         *Stats
+	Hooks         *Hooks                      // Callbacks around every request (nil = none)
+	Functions     map[string]TemplateFunction // Extra {{$name}} functions for this run's scenario steps
+	Sinks         []MetricsSink               // Export targets receiving every measurement
 	client        *http.Client
+	clients       []*http.Client   // One per pooled HTTP/2 connection (nil without a pool)
+	pool          []*poolTransport // Pooled HTTP/2 connections, counting their streams
+	dns           *dnsCache        // Resolved target hosts (nil without DNS caching)
+	dialer        *sourceDialer    // Opens connections, counting them per IP version
+	plugins       *pluginSet       // Running plugin processes (nil without a plugins directory)
+	script        *script          // wrk-style Lua script (nil without one)
+	js            *javaScript      // jsScript functions (nil without one)
 	selector      *WeightedRequestSelector
 	rateLimiter   *RateLimiter
+	limiters      NamedRateLimiters // Per-request (or per-step) rate limits
+	capture       *FailureCapture   // Writes the first failing exchanges per category to disk
+	sampler       *ResponseSampler  // Writes a random sample of exchanges to disk
+	bodies        sync.Map          // Serialized request bodies by *config.RequestConfig
+	globals       *GlobalVariables  // Scenario variables shared by all virtual users
+	functions     templateFunctions // {{$name}} functions, registered and declared
+	sinkFailed    []bool            // Sinks that failed a snapshot, warned about once
 	activeWorkers int32
+	executedSteps int64         // Scenario steps that actually sent a request
+	pending       atomic.Int64  // Requests (or scenario iterations) not yet taken by a worker in fixed count mode
 	stopSending   chan struct{} // Signal to stop sending new requests (graceful shutdown)
+
+	abortReason atomic.Pointer[string]    // Set when rolling thresholds abort the run
+	series      *TimeSeries               // Per-second samples feeding the TUI (nil without --tui)
+	pause       pauseGate                 // Holds workers back while paused
+	startedAt   atomic.Pointer[time.Time] // When Run started, for interim stats
+
+	resumeOffset    time.Duration // Active time run before the restored checkpoint
+	resumeCompleted int64         // Requests (or scenario iterations) completed before the restored checkpoint
 }
 
+// TUI history sizes
+const (
+	tuiHistory    = 120 // Seconds of sparkline history
+	tuiErrorLines = 8   // Lines of the error log tail
+)
+
 // NewRunner creates a new benchmark runner
-func NewRunner(cfg *config.Config, durationSec, timeoutSec, rampUpSec int, quietMode, verboseMode bool) *Runner {
+func NewRunner(cfg *config.Config, durationSec int, timeout time.Duration, rampUpSec int, quietMode, verboseMode bool) *Runner {
 	// Create stats with histogram settings from config
 	useHdr := !cfg.Settings.DisableHdr
 	showHistogram := cfg.Settings.ShowHistogram
 	stats := NewStatsWithOptions(useHdr, showHistogram)
+	stats.SetSLO(cfg.SLO)
+	stats.SetErrorSamples(cfg.Settings.ErrorSamples)
+	stats.SetCacheTracking(cfg.Settings.CacheStats)
+	bounds, logScale, _ := cfg.GetHistogramBuckets() // Validated with the config
+	stats.SetHistogramBuckets(bounds, logScale)
 
 	return &Runner{
 		Config:      cfg,
 		DurationSec: durationSec,
-		TimeoutSec:  timeoutSec,
+		Timeout:     timeout,
 		RampUpSec:   rampUpSec,
 		QuietMode:   quietMode,
 		VerboseMode: verboseMode,
+		Log:         os.Stdout,
 		Stats:       stats,
 		selector:    NewWeightedRequestSelector(cfg.Requests),
+		capture:     NewFailureCapture(cfg.Settings.CaptureDir, cfg.Settings.CaptureFailures),
+		sampler:     NewResponseSampler(cfg.Settings.CaptureResponses),
+		globals:     NewGlobalVariables(),
 		stopSending: make(chan struct{}),
 	}
 }
 
+// CapturedFailures returns the number of failing exchanges written to disk and the directory
+func (r *Runner) CapturedFailures() (int, string) {
+	return r.capture.Saved(), r.capture.Dir()
+}
+
+// SampledResponses returns the number of sampled exchanges written to disk and the directory
+func (r *Runner) SampledResponses() (int64, string) {
+	return r.sampler.Saved(), r.sampler.Dir()
+}
+
 // Run executes the benchmark
 func (r *Runner) Run(ctx context.Context) *Stats {
 	// Check if scenario mode
@@ -57,104 +115,165 @@ func (r *Runner) Run(ctx context.Context) *Stats {
 		return r.RunScenario(ctx)
 	}
 
+	// Create HTTP client, resolve hosts and optionally open connections before measuring
+	r.prepareConnections(ctx)
+	defer r.closePlugins()
+	r.loadScript()
+	r.loadJavaScript()
+	r.functions = r.newTemplateFunctions(ctx)
+
 	var wg sync.WaitGroup
 	stopwatch := time.Now()
+	r.startedAt.Store(&stopwatch)
 
 	// Initialize rate limiter if configured
 	if r.Config.Settings.RateLimit > 0 {
 		r.rateLimiter = NewRateLimiter(r.Config.Settings.RateLimit)
-		defer r.rateLimiter.Stop()
 	}
 
+	// Per-request rate limits
+	r.limiters = make(NamedRateLimiters)
+	for _, req := range r.Config.Requests {
+		if req.RateLimit > 0 {
+			r.limiters[req.Name] = NewRateLimiter(req.RateLimit)
+		}
+	}
+
+	// Rolling thresholds and the data volume limit stop the run through the same path as Ctrl+C
+	ctx, abort := context.WithCancel(ctx)
+	defer abort()
+
 	// Create cancellation context
 	benchCtx, benchCancel := r.createBenchmarkContext(ctx)
-	if r.DurationSec <= 0 {
-		defer benchCancel()
-	}
+	defer benchCancel()
 
 	totalRequests := r.calculateTotalRequests()
-	var completedRequests int64 = 0
+	completedRequests := r.resumeCompleted
+	r.pending.Store(int64(r.remainingWork()))
 
 	// Console output
 	if !r.QuietMode {
 		r.printBenchmarkStart(totalRequests)
 	}
 
-	progressBar := progress.NewBarWithOptions(r.DurationSec > 0, r.QuietMode, r.Config.Settings.ShowLiveStats)
+	progressBar := r.newProgressDisplay(benchCtx)
 	defer progressBar.Close()
 
 	// Start progress tracking
 	r.startProgressTracking(benchCtx, stopwatch, &completedRequests, totalRequests, progressBar)
 
-	// Create HTTP client
-	r.createHTTPClient()
-
 	// Start workers
+	stopMonitor := r.monitorRollingThresholds(benchCtx, abort)
+	stopByteLimit := r.monitorByteLimit(benchCtx, abort)
+	stopMemory := r.monitorMemory(benchCtx)
+	stopCheckpoints := r.startCheckpoints(&completedRequests)
+	stopReports := r.startIntervalReports(benchCtx, progressBar, stopwatch)
+	stopSinks := r.startSinks(benchCtx)
 	r.startWorkers(benchCtx, benchCancel, &wg, &completedRequests, totalRequests)
 
 	wg.Wait()
+	stopMonitor()
+	stopByteLimit()
+	stopMemory()
+	r.recordConnections()
+	r.recordDialer()
+	stopCheckpoints()
+	stopReports()
+	stopSinks()
+
+	// Calculate final statistics, leaving out paused time
+	elapsed := r.activeElapsed(stopwatch)
+	progressBar.ForceComplete(elapsed, int(completedRequests))
 
-	progressBar.ForceComplete(time.Since(stopwatch), int(completedRequests))
-
-	// Calculate final statistics
-	elapsed := time.Since(stopwatch)
 	r.Stats.TotalRequests = completedRequests
 	r.Stats.TotalDuration = elapsed.Seconds()
 	r.Stats.RequestsPerSecond = float64(completedRequests) / r.Stats.TotalDuration
+	r.finishSinks()
 
 	if !r.QuietMode {
-		fmt.Println(" Done!")
+		fmt.Fprintln(r.Log, " Done!")
 	}
+	r.script.done(r.Stats)
 
 	return r.Stats
 }
 
 // RunScenario executes the benchmark in scenario mode
 func (r *Runner) RunScenario(ctx context.Context) *Stats {
+	// Create HTTP client, resolve hosts and optionally open connections before measuring
+	r.prepareConnections(ctx)
+	defer r.closePlugins()
+	r.loadJavaScript()
+	r.functions = r.newTemplateFunctions(ctx)
+
 	var wg sync.WaitGroup
 	stopwatch := time.Now()
+	r.startedAt.Store(&stopwatch)
+
+	// Per-step rate limits, vuInit steps included
+	r.limiters = make(NamedRateLimiters)
+	for _, step := range r.Config.AllStepsWithInit() {
+		if step.RateLimit > 0 {
+			r.limiters[step.Name] = NewRateLimiter(step.RateLimit)
+		}
+	}
+
+	// Rolling thresholds and the data volume limit stop the run through the same path as Ctrl+C
+	ctx, abort := context.WithCancel(ctx)
+	defer abort()
 
 	// Create cancellation context
 	benchCtx, benchCancel := r.createBenchmarkContext(ctx)
-	if r.DurationSec <= 0 {
-		defer benchCancel()
-	}
+	defer benchCancel()
 
 	// In scenario mode, each "iteration" is one complete scenario run
 	// Total requests = scenarios * steps per scenario
 	totalScenarios := r.Config.Settings.ConcurrentUsers * r.Config.Settings.RequestsPerUser
 	stepsPerScenario := len(r.Config.Steps)
-	var completedScenarios int64 = 0
+	completedScenarios := r.resumeCompleted
+	r.pending.Store(int64(r.remainingWork()))
 
 	// Console output
 	if !r.QuietMode {
 		r.printScenarioStart(totalScenarios, stepsPerScenario)
 	}
 
-	progressBar := progress.NewBarWithOptions(r.DurationSec > 0, r.QuietMode, r.Config.Settings.ShowLiveStats)
+	progressBar := r.newProgressDisplay(benchCtx)
 	defer progressBar.Close()
 
-	// Create HTTP client
-	r.createHTTPClient()
-
 	// Start progress tracking for scenarios
 	r.startScenarioProgressTracking(benchCtx, stopwatch, &completedScenarios, totalScenarios, progressBar)
 
 	// Start scenario workers
+	stopMonitor := r.monitorRollingThresholds(benchCtx, abort)
+	stopByteLimit := r.monitorByteLimit(benchCtx, abort)
+	stopMemory := r.monitorMemory(benchCtx)
+	stopCheckpoints := r.startCheckpoints(&completedScenarios)
+	stopReports := r.startIntervalReports(benchCtx, progressBar, stopwatch)
+	stopSinks := r.startSinks(benchCtx)
 	r.startScenarioWorkers(benchCtx, benchCancel, &wg, &completedScenarios, totalScenarios)
 
 	wg.Wait()
-
-	progressBar.ForceComplete(time.Since(stopwatch), int(completedScenarios))
-
-	// Calculate final statistics
-	elapsed := time.Since(stopwatch)
-	r.Stats.TotalRequests = completedScenarios * int64(stepsPerScenario)
+	stopMonitor()
+	stopByteLimit()
+	stopMemory()
+	r.recordConnections()
+	r.recordDialer()
+	stopCheckpoints()
+	stopReports()
+	stopSinks()
+
+	// Calculate final statistics, leaving out paused time
+	elapsed := r.activeElapsed(stopwatch)
+	progressBar.ForceComplete(elapsed, int(completedScenarios))
+
+	r.Stats.TotalRequests = atomic.LoadInt64(&r.executedSteps)
 	r.Stats.TotalDuration = elapsed.Seconds()
 	r.Stats.RequestsPerSecond = float64(r.Stats.TotalRequests) / r.Stats.TotalDuration
+	r.finishSinks()
 
 	if !r.QuietMode {
-		fmt.Println(" Done!")
+		fmt.Fprintln(r.Log, " Done!")
 	}
 
 	return r.Stats
@@ -162,38 +281,62 @@ func (r *Runner) RunScenario(ctx context.Context) *Stats {
 
 // printScenarioStart prints the scenario benchmark configuration at start
 func (r *Runner) printScenarioStart(totalScenarios, stepsPerScenario int) {
-	fmt.Printf("Scenario: %s\n", r.Config.Name)
+	fmt.Fprintf(r.Log, "Scenario: %s\n", r.Config.Name)
 	if r.Config.Description != "" {
-		fmt.Printf("Description: %s\n", r.Config.Description)
+		fmt.Fprintf(r.Log, "Description: %s\n", r.Config.Description)
 	}
-	fmt.Printf("Steps: %d\n", stepsPerScenario)
-	for i, step := range r.Config.Steps {
-		fmt.Printf("  %d. %s: %s %s\n", i+1, step.Name, step.Method, step.URL)
+	if len(r.Config.VUInit) > 0 {
+		fmt.Fprintf(r.Log, "VU init steps: %d (once per user)\n", len(r.Config.VUInit))
+		for i, step := range r.Config.VUInit {
+			fmt.Fprintf(r.Log, "  %d. %s: %s %s\n", i+1, step.Name, step.Method, step.URL)
+		}
+	}
+	if len(r.Config.Scenarios) > 0 {
+		totalWeight := 0
+		for _, sc := range r.Config.Scenarios {
+			totalWeight += sc.GetWeight()
+		}
+		fmt.Fprintf(r.Log, "Scenarios: %d\n", len(r.Config.Scenarios))
+		for _, sc := range r.Config.Scenarios {
+			fmt.Fprintf(r.Log, "  %s (%.0f%%, %d steps)\n", sc.Name, float64(sc.GetWeight())*100/float64(totalWeight), len(sc.Steps))
+			for i, step := range sc.Steps {
+				fmt.Fprintf(r.Log, "    %d. %s: %s %s\n", i+1, step.Name, step.Method, step.URL)
+			}
+		}
+	} else {
+		fmt.Fprintf(r.Log, "Steps: %d\n", stepsPerScenario)
+		for i, step := range r.Config.Steps {
+			fmt.Fprintf(r.Log, "  %d. %s: %s %s\n", i+1, step.Name, step.Method, step.URL)
+		}
 	}
-	fmt.Printf("Concurrent users: %d\n", r.Config.Settings.ConcurrentUsers)
+	fmt.Fprintf(r.Log, "Concurrent users: %d\n", r.Config.WorkerCount())
 	if r.DurationSec > 0 {
-		fmt.Printf("Duration: %d seconds\n", r.DurationSec)
+		fmt.Fprintf(r.Log, "Duration: %d seconds\n", r.DurationSec)
+	} else if len(r.Config.Scenarios) > 0 {
+		fmt.Fprintf(r.Log, "Scenarios per user: %d (total: %d scenarios)\n",
+			r.Config.Settings.RequestsPerUser, totalScenarios)
 	} else {
-		fmt.Printf("Scenarios per user: %d (total: %d scenarios, %d requests)\n",
+		fmt.Fprintf(r.Log, "Scenarios per user: %d (total: %d scenarios, %d requests)\n",
 			r.Config.Settings.RequestsPerUser, totalScenarios, totalScenarios*stepsPerScenario)
 	}
-	fmt.Println()
+	fmt.Fprintln(r.Log)
 }
 
 // startScenarioProgressTracking starts progress tracking for scenario mode
-func (r *Runner) startScenarioProgressTracking(ctx context.Context, stopwatch time.Time, completedScenarios *int64, totalScenarios int, progressBar *progress.Bar) {
+func (r *Runner) startScenarioProgressTracking(ctx context.Context, stopwatch time.Time, completedScenarios *int64, totalScenarios int, progressBar progress.Display) {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	go func() {
+		cpuset.PinToReserved()
 		defer ticker.Stop()
-		for {
+		var p99Us float64
+		for tick := 0; ; tick++ {
 			select {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				elapsedSeconds := time.Since(stopwatch).Seconds()
+				elapsedSeconds := r.activeElapsed(stopwatch).Seconds()
 				completed := atomic.LoadInt64(completedScenarios)
-				stepsPerScenario := len(r.Config.Steps)
-				totalRequests := completed * int64(stepsPerScenario)
+				totalRequests := atomic.LoadInt64(&r.executedSteps)
 
 				currentRate := float64(0)
 				if elapsedSeconds > 0 {
@@ -201,16 +344,8 @@ func (r *Runner) startScenarioProgressTracking(ctx context.Context, stopwatch ti
 					r.Stats.AddRequestRate(currentRate)
 				}
 
-				// Build live stats if enabled
-				var liveStats *progress.LiveStats
-				if r.Config.Settings.ShowLiveStats {
-					liveStats = &progress.LiveStats{
-						RequestsPerSec: currentRate,
-						AvgLatencyUs:   r.Stats.AverageResponseTime(),
-						ErrorCount:     atomic.LoadInt64(&r.Stats.FailureCount),
-						SuccessCount:   atomic.LoadInt64(&r.Stats.SuccessCount),
-					}
-				}
+				p99Us = r.progressP99(tick, p99Us)
+				liveStats := r.liveStats(currentRate, stopwatch, p99Us)
 
 				if r.DurationSec > 0 {
 					progressPercent := math.Min(1.0, elapsedSeconds/float64(r.DurationSec))
@@ -223,17 +358,54 @@ func (r *Runner) startScenarioProgressTracking(ctx context.Context, stopwatch ti
 	}()
 }
 
+// inFlightSlots bounds how many requests (or scenario iterations) all workers
+// have in flight at once. It is nil when every worker may have one, so workers
+// don't contend on a channel that can never be full.
+type inFlightSlots chan struct{}
+
+// newInFlightSlots creates the in-flight bound for the configured workers
+func (r *Runner) newInFlightSlots() inFlightSlots {
+	limit := r.Config.InFlightLimit()
+	if limit >= r.Config.WorkerCount() {
+		return nil
+	}
+	return make(inFlightSlots, limit)
+}
+
+// acquire waits for a free slot, returning false if the benchmark stops first
+func (s inFlightSlots) acquire(ctx context.Context, stop <-chan struct{}) bool {
+	if s == nil {
+		return true
+	}
+	select {
+	case s <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-stop:
+		return false
+	}
+}
+
+// release frees the slot taken by acquire
+func (s inFlightSlots) release() {
+	if s != nil {
+		<-s
+	}
+}
+
 // startScenarioWorkers starts scenario worker goroutines
 func (r *Runner) startScenarioWorkers(ctx context.Context, cancel context.CancelFunc, wg *sync.WaitGroup, completedScenarios *int64, totalScenarios int) {
-	semaphore := make(chan struct{}, r.Config.Settings.ConcurrentUsers)
+	semaphore := r.newInFlightSlots()
+	workers := r.Config.WorkerCount()
 
 	// Calculate ramp-up delay per worker
 	rampUpDelay := time.Duration(0)
-	if r.RampUpSec > 0 && r.Config.Settings.ConcurrentUsers > 1 {
-		rampUpDelay = time.Duration(r.RampUpSec) * time.Second / time.Duration(r.Config.Settings.ConcurrentUsers-1)
+	if r.RampUpSec > 0 && workers > 1 {
+		rampUpDelay = time.Duration(r.RampUpSec) * time.Second / time.Duration(workers-1)
 	}
 
-	for i := 0; i < r.Config.Settings.ConcurrentUsers; i++ {
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		workerIndex := i
 
@@ -245,7 +417,7 @@ func (r *Runner) startScenarioWorkers(ctx context.Context, cancel context.Cancel
 }
 
 // runScenarioWorker runs a single scenario worker
-func (r *Runner) runScenarioWorker(ctx context.Context, cancel context.CancelFunc, workerIndex int, rampUpDelay time.Duration, semaphore chan struct{}, completedScenarios *int64, totalScenarios int) {
+func (r *Runner) runScenarioWorker(ctx context.Context, cancel context.CancelFunc, workerIndex int, rampUpDelay time.Duration, semaphore inFlightSlots, completedScenarios *int64, totalScenarios int) {
 	// Apply ramp-up delay
 	if rampUpDelay > 0 && workerIndex > 0 {
 		select {
@@ -259,10 +431,34 @@ func (r *Runner) runScenarioWorker(ctx context.Context, cancel context.CancelFun
 	defer atomic.AddInt32(&r.activeWorkers, -1)
 
 	if r.VerboseMode && !r.QuietMode {
-		fmt.Printf("[verbose] Scenario worker %d started\n", workerIndex)
+		fmt.Fprintf(r.Log, "[verbose] Scenario worker %d started\n", workerIndex)
 	}
 
-	executor := NewScenarioExecutor(r.Config, r.client, r.TimeoutSec, r.VerboseMode, r.Stats)
+	executor := NewScenarioExecutor(r.Config, r.clientFor(workerIndex), r.Timeout, r.VerboseMode, r.Stats)
+	executor.worker = r.Stats.NewWorker(workerIndex)
+	executor.capture = r.capture
+	executor.sampler = r.sampler
+	executor.limiters = r.limiters
+	executor.globals = r.globals
+	executor.log = r.Log
+	executor.hooks = r.Hooks
+	executor.functions = r.functions
+	executor.js = r.js
+	executor.vu = workerIndex
+	executor.sinks = r.Sinks
+
+	// Run per-VU initialization (e.g. login) once before the iterations
+	if len(r.Config.VUInit) > 0 {
+		initResult := executor.InitVU(ctx)
+		if !initResult.Success && !r.QuietMode {
+			for _, sr := range initResult.StepResults {
+				if !sr.Success {
+					fmt.Fprintf(r.Log, "\n[warn] Worker %d: vuInit step %s failed (status %d) %s%s\n",
+						workerIndex, sr.StepName, sr.StatusCode, sr.Error, strings.Join(sr.ValidationErrs, "; "))
+				}
+			}
+		}
+	}
 
 	if r.DurationSec > 0 {
 		// Duration mode
@@ -270,80 +466,122 @@ func (r *Runner) runScenarioWorker(ctx context.Context, cancel context.CancelFun
 			select {
 			case <-ctx.Done():
 				return
+			case <-r.stopSending:
+				return
 			default:
 			}
+			if !r.pause.wait(ctx, r.stopSending) {
+				return
+			}
 
-			select {
-			case <-ctx.Done():
+			if !semaphore.acquire(ctx, r.stopSending) {
 				return
-			case semaphore <- struct{}{}:
-				executor.ExecuteScenario(ctx)
-				atomic.AddInt64(completedScenarios, 1)
-				<-semaphore
 			}
+			result := executor.ExecuteScenario(ctx)
+			atomic.AddInt64(&r.executedSteps, int64(result.ExecutedSteps()))
+			atomic.AddInt64(completedScenarios, 1)
+			semaphore.release()
 		}
 	} else {
 		// Fixed count mode
-		for j := 0; j < r.Config.Settings.RequestsPerUser; j++ {
+		for r.takeWork() {
 			select {
 			case <-ctx.Done():
 				return
+			case <-r.stopSending:
+				return
 			default:
 			}
+			if !r.pause.wait(ctx, r.stopSending) {
+				return
+			}
 
-			select {
-			case <-ctx.Done():
+			if !semaphore.acquire(ctx, r.stopSending) {
 				return
-			case semaphore <- struct{}{}:
-				executor.ExecuteScenario(ctx)
-				atomic.AddInt64(completedScenarios, 1)
-				<-semaphore
+			}
+			result := executor.ExecuteScenario(ctx)
+			atomic.AddInt64(&r.executedSteps, int64(result.ExecutedSteps()))
+			atomic.AddInt64(completedScenarios, 1)
+			semaphore.release()
 
-				completed := atomic.LoadInt64(completedScenarios)
-				if completed >= int64(totalScenarios) {
-					cancel()
-					return
-				}
+			completed := atomic.LoadInt64(completedScenarios)
+			if completed >= int64(totalScenarios) {
+				cancel()
+				return
 			}
 		}
 	}
 }
 
-// createBenchmarkContext creates the benchmark context with optional duration timer
-// Uses graceful shutdown: stops sending new requests when duration ends,
-// then waits for grace period (timeout) to allow in-flight requests to complete
+// createBenchmarkContext creates the context requests run under. The benchmark
+// stops when the duration is reached (not counting paused time), at the
+// wall-clock deadline, or when ctx is cancelled by Ctrl+C or a rolling threshold
+// abort: workers stop sending new requests and in-flight requests get the grace
+// period to complete before the returned context is cancelled.
 func (r *Runner) createBenchmarkContext(ctx context.Context) (context.Context, context.CancelFunc) {
-	if r.DurationSec > 0 {
-		benchCtx, benchCancel := context.WithCancel(ctx)
-		go func() {
-			// Wait for benchmark duration
-			timer := time.NewTimer(time.Duration(r.DurationSec) * time.Second)
-			defer timer.Stop()
-			select {
-			case <-timer.C:
-				// Signal workers to stop sending new requests
-				close(r.stopSending)
-				if !r.QuietMode {
-					fmt.Printf("\n[info] Duration reached, waiting up to %ds for in-flight requests to complete...\n", r.TimeoutSec)
-				}
-				// Grace period: wait for timeout duration to allow in-flight requests to complete
-				graceTimer := time.NewTimer(time.Duration(r.TimeoutSec) * time.Second)
-				defer graceTimer.Stop()
-				select {
-				case <-graceTimer.C:
-					// Grace period expired, force cancel
-					benchCancel()
-				case <-ctx.Done():
-					benchCancel()
-				}
-			case <-ctx.Done():
-				close(r.stopSending)
-				benchCancel()
+	benchCtx, benchCancel := context.WithCancel(context.WithoutCancel(ctx))
+	start := time.Now()
+	go func() {
+		waitCtx, stopWaiting := context.WithCancel(ctx)
+		if deadline, err := r.Config.GetUntil(); err == nil && !deadline.IsZero() {
+			waitCtx, stopWaiting = context.WithDeadline(ctx, deadline)
+		}
+		defer stopWaiting()
+		stopOnFinish := context.AfterFunc(benchCtx, stopWaiting)
+		defer stopOnFinish()
+
+		durationReached := false
+		if r.DurationSec > 0 {
+			durationReached = r.waitActive(waitCtx, start, time.Duration(r.DurationSec)*time.Second)
+		} else {
+			<-waitCtx.Done()
+		}
+		if benchCtx.Err() != nil {
+			return // Finished on its own
+		}
+		deadlineReached := errors.Is(waitCtx.Err(), context.DeadlineExceeded)
+
+		// Signal workers to stop sending new requests
+		close(r.stopSending)
+		grace := r.gracePeriod()
+		if grace <= 0 {
+			benchCancel()
+			return
+		}
+		if !r.QuietMode {
+			reason := "Stopping"
+			if durationReached {
+				reason = "Duration reached"
+			} else if deadlineReached {
+				reason = "Deadline reached"
 			}
-		}()
-		return benchCtx, benchCancel
+			fmt.Fprintf(r.Log, "\n[info] %s, waiting up to %s for in-flight requests to complete...\n", reason, grace)
+		}
+
+		// Once the duration or deadline is reached, Ctrl+C still cancels in-flight requests at once
+		var interrupted <-chan struct{}
+		if durationReached || deadlineReached {
+			interrupted = ctx.Done()
+		}
+		graceTimer := time.NewTimer(grace)
+		defer graceTimer.Stop()
+		select {
+		case <-graceTimer.C:
+		case <-interrupted:
+		case <-benchCtx.Done():
+		}
+		benchCancel()
+	}()
+	return benchCtx, benchCancel
+}
+
+// gracePeriod returns how long in-flight requests may take to finish once the benchmark stops
+func (r *Runner) gracePeriod() time.Duration {
+	grace, err := r.Config.GetGracePeriod(r.Timeout)
+	if err != nil {
+		return r.Timeout
 	}
-	return context.WithCancel(ctx)
+	return grace
 }
 
 // calculateTotalRequests calculates the total number of requests for fixed-request mode
@@ -354,33 +592,85 @@ func (r *Runner) calculateTotalRequests() int {
 	return -1
 }
 
+// newProgressDisplay creates the full-screen terminal UI when enabled, otherwise the progress bar
+func (r *Runner) newProgressDisplay(ctx context.Context) progress.Display {
+	if r.Config.Settings.TUI && !r.QuietMode {
+		r.series = r.Stats.CollectTimeSeries(ctx, time.Second, []float64{99})
+		return progress.NewTUI(r.Config.Name, r.DurationSec > 0, func() { r.TogglePause() })
+	}
+	return progress.NewBarWithOptions(r.DurationSec > 0, r.QuietMode, r.Config.Settings.ShowLiveStats)
+}
+
+// liveStats builds the statistics shown while running, or nil in quiet mode.
+// p99Us is the current p99 latency, which the caller refreshes.
+func (r *Runner) liveStats(currentRate float64, stopwatch time.Time, p99Us float64) *progress.LiveStats {
+	if r.QuietMode && r.series == nil {
+		return nil
+	}
+	stats := &progress.LiveStats{
+		RequestsPerSec: currentRate,
+		AvgLatencyUs:   r.Stats.AverageResponseTime(),
+		ErrorCount:     atomic.LoadInt64(&r.Stats.FailureCount),
+		SuccessCount:   atomic.LoadInt64(&r.Stats.SuccessCount),
+		Elapsed:        r.activeElapsed(stopwatch),
+		P99Us:          p99Us,
+	}
+	if r.series == nil {
+		return stats
+	}
+
+	stats.Paused = r.pause.paused()
+	stats.ActiveWorkers = int(atomic.LoadInt32(&r.activeWorkers))
+	stats.TotalWorkers = r.Config.WorkerCount()
+	stats.StatusCodes = [6]int64{
+		atomic.LoadInt64(&r.Stats.Http1xxCount),
+		atomic.LoadInt64(&r.Stats.Http2xxCount),
+		atomic.LoadInt64(&r.Stats.Http3xxCount),
+		atomic.LoadInt64(&r.Stats.Http4xxCount),
+		atomic.LoadInt64(&r.Stats.Http5xxCount),
+		atomic.LoadInt64(&r.Stats.OtherCount),
+	}
+	for _, point := range r.series.Last(tuiHistory) {
+		stats.RPSHistory = append(stats.RPSHistory, point.RequestsPerSecond)
+		stats.P99History = append(stats.P99History, float64(point.Percentiles["p99"]))
+	}
+	for _, e := range r.Stats.RecentErrors(tuiErrorLines) {
+		stats.RecentErrors = append(stats.RecentErrors, e.Time.Format("15:04:05")+" "+e.Message)
+	}
+	return stats
+}
+
+// progressP99 returns the p99 latency to show at the given progress tick.
+// Percentiles are costly without HdrHistogram, so the progress bar's is
+// refreshed once a second (every 10th tick); the TUI's on every tick.
+func (r *Runner) progressP99(tick int, current float64) float64 {
+	if r.QuietMode || (r.series == nil && tick%10 != 0) {
+		return current
+	}
+	return float64(r.Stats.GetLatencyPercentile(99))
+}
+
 // startProgressTracking starts the goroutine that tracks progress and request rates
-func (r *Runner) startProgressTracking(ctx context.Context, stopwatch time.Time, completedRequests *int64, totalRequests int, progressBar *progress.Bar) {
+func (r *Runner) startProgressTracking(ctx context.Context, stopwatch time.Time, completedRequests *int64, totalRequests int, progressBar progress.Display) {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	go func() {
+		cpuset.PinToReserved()
 		defer ticker.Stop()
-		for {
+		var p99Us float64
+		for tick := 0; ; tick++ {
 			select {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				elapsedSeconds := time.Since(stopwatch).Seconds()
+				elapsedSeconds := r.activeElapsed(stopwatch).Seconds()
 				currentRate := float64(0)
 				if elapsedSeconds > 0 {
 					currentRate = float64(atomic.LoadInt64(completedRequests)) / elapsedSeconds
 					r.Stats.AddRequestRate(currentRate)
 				}
 
-				// Build live stats if enabled
-				var liveStats *progress.LiveStats
-				if r.Config.Settings.ShowLiveStats {
-					liveStats = &progress.LiveStats{
-						RequestsPerSec: currentRate,
-						AvgLatencyUs:   r.Stats.AverageResponseTime(),
-						ErrorCount:     atomic.LoadInt64(&r.Stats.FailureCount),
-						SuccessCount:   atomic.LoadInt64(&r.Stats.SuccessCount),
-					}
-				}
+				p99Us = r.progressP99(tick, p99Us)
+				liveStats := r.liveStats(currentRate, stopwatch, p99Us)
 
 				reqCount := int(atomic.LoadInt64(completedRequests))
 				if r.DurationSec > 0 {
@@ -396,15 +686,16 @@ func (r *Runner) startProgressTracking(ctx context.Context, stopwatch time.Time,
 
 // startWorkers starts all worker goroutines with optional ramp-up
 func (r *Runner) startWorkers(ctx context.Context, cancel context.CancelFunc, wg *sync.WaitGroup, completedRequests *int64, totalRequests int) {
-	semaphore := make(chan struct{}, r.Config.Settings.ConcurrentUsers)
+	semaphore := r.newInFlightSlots()
+	workers := r.Config.WorkerCount()
 
 	// Calculate ramp-up delay per worker
 	rampUpDelay := time.Duration(0)
-	if r.RampUpSec > 0 && r.Config.Settings.ConcurrentUsers > 1 {
-		rampUpDelay = time.Duration(r.RampUpSec) * time.Second / time.Duration(r.Config.Settings.ConcurrentUsers-1)
+	if r.RampUpSec > 0 && workers > 1 {
+		rampUpDelay = time.Duration(r.RampUpSec) * time.Second / time.Duration(workers-1)
 	}
 
-	for i := 0; i < r.Config.Settings.ConcurrentUsers; i++ {
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		workerIndex := i
 
@@ -416,7 +707,7 @@ func (r *Runner) startWorkers(ctx context.Context, cancel context.CancelFunc, wg
 }
 
 // runWorker runs a single worker goroutine
-func (r *Runner) runWorker(ctx context.Context, cancel context.CancelFunc, workerIndex int, rampUpDelay time.Duration, semaphore chan struct{}, completedRequests *int64, totalRequests int) {
+func (r *Runner) runWorker(ctx context.Context, cancel context.CancelFunc, workerIndex int, rampUpDelay time.Duration, semaphore inFlightSlots, completedRequests *int64, totalRequests int) {
 	// Apply ramp-up delay
 	if rampUpDelay > 0 && workerIndex > 0 {
 		select {
@@ -430,19 +721,20 @@ func (r *Runner) runWorker(ctx context.Context, cancel context.CancelFunc, worke
 	defer atomic.AddInt32(&r.activeWorkers, -1)
 
 	if r.VerboseMode && !r.QuietMode {
-		fmt.Printf("[verbose] Worker %d started\n", workerIndex)
+		fmt.Fprintf(r.Log, "[verbose] Worker %d started\n", workerIndex)
 	}
 
+	worker := r.Stats.NewWorker(workerIndex)
 	if r.DurationSec > 0 {
-		r.runDurationWorker(ctx, semaphore, completedRequests)
+		r.runDurationWorker(ctx, worker, semaphore, completedRequests)
 	} else {
-		r.runFixedWorker(ctx, cancel, semaphore, completedRequests, totalRequests)
+		r.runFixedWorker(ctx, cancel, worker, semaphore, completedRequests, totalRequests)
 	}
 }
 
 // runDurationWorker runs requests until stopSending is signaled (duration mode)
 // After stopSending, allows current in-flight request to complete before exiting
-func (r *Runner) runDurationWorker(ctx context.Context, semaphore chan struct{}, completedRequests *int64) {
+func (r *Runner) runDurationWorker(ctx context.Context, worker *WorkerStats, semaphore inFlightSlots, completedRequests *int64) {
 	for {
 		// Check if we should stop sending new requests
 		select {
@@ -450,6 +742,9 @@ func (r *Runner) runDurationWorker(ctx context.Context, semaphore chan struct{},
 			return
 		default:
 		}
+		if !r.pause.wait(ctx, r.stopSending) {
+			return
+		}
 
 		// Wait for rate limiter (still respect stopSending for quick exit)
 		if r.rateLimiter != nil {
@@ -463,47 +758,74 @@ func (r *Runner) runDurationWorker(ctx context.Context, semaphore chan struct{},
 			}
 		}
 
-		select {
-		case <-r.stopSending:
+		if !semaphore.acquire(ctx, r.stopSending) {
+			return
+		}
+		reqConfig, ok := r.selectRequest(ctx)
+		if !ok {
+			semaphore.release()
 			return
-		case semaphore <- struct{}{}:
-			reqConfig := r.selector.Select()
-			// Process request - will complete even if stopSending triggers during execution
-			r.processRequest(ctx, reqConfig)
+		}
+		// Process request - will complete even if stopSending triggers during execution
+		if r.processRequest(ctx, worker, reqConfig) {
 			atomic.AddInt64(completedRequests, 1)
-			<-semaphore
 		}
+		semaphore.release()
+	}
+}
+
+// selectRequest picks the next request by weight. A request whose own rate
+// limit is exhausted is re-picked so other requests keep running unconstrained;
+// if every pick is limited, it waits for the last one's limiter.
+func (r *Runner) selectRequest(ctx context.Context) (*config.RequestConfig, bool) {
+	reqConfig := r.selector.Select()
+	for attempt := 0; attempt < maxLimitedPicks; attempt++ {
+		if r.limiters.Get(reqConfig.Name).TryAcquire() {
+			return reqConfig, true
+		}
+		reqConfig = r.selector.Select()
 	}
+	return reqConfig, r.limiters.Get(reqConfig.Name).Wait(ctx)
 }
 
-// runFixedWorker runs a fixed number of requests per worker
-func (r *Runner) runFixedWorker(ctx context.Context, cancel context.CancelFunc, semaphore chan struct{}, completedRequests *int64, totalRequests int) {
-	for j := 0; j < r.Config.Settings.RequestsPerUser; j++ {
+// runFixedWorker sends requests until all of the fixed count are taken
+func (r *Runner) runFixedWorker(ctx context.Context, cancel context.CancelFunc, worker *WorkerStats, semaphore inFlightSlots, completedRequests *int64, totalRequests int) {
+	for r.takeWork() {
 		select {
 		case <-ctx.Done():
 			return
+		case <-r.stopSending:
+			return
 		default:
 		}
+		if !r.pause.wait(ctx, r.stopSending) {
+			return
+		}
 
 		// Wait for rate limiter
 		if r.rateLimiter != nil && !r.rateLimiter.Wait(ctx) {
 			return
 		}
 
-		select {
-		case <-ctx.Done():
+		if !semaphore.acquire(ctx, r.stopSending) {
 			return
-		case semaphore <- struct{}{}:
-			reqConfig := r.selector.Select()
-			r.processRequest(ctx, reqConfig)
-			atomic.AddInt64(completedRequests, 1)
-			<-semaphore
+		}
+		reqConfig, ok := r.selectRequest(ctx)
+		if !ok {
+			semaphore.release()
+			return
+		}
+		finished := r.processRequest(ctx, worker, reqConfig)
+		semaphore.release()
+		if !finished {
+			return
+		}
+		atomic.AddInt64(completedRequests, 1)
 
-			completed := atomic.LoadInt64(completedRequests)
-			if completed >= int64(totalRequests) {
-				cancel()
-				return
-			}
+		completed := atomic.LoadInt64(completedRequests)
+		if completed >= int64(totalRequests) {
+			cancel()
+			return
 		}
 	}
 }
@@ -512,32 +834,32 @@ func (r *Runner) runFixedWorker(ctx context.Context, cancel context.CancelFunc,
 func (r *Runner) printBenchmarkStart(totalRequests int) {
 	if r.DurationSec > 0 {
 		if len(r.Config.Requests) == 1 {
-			fmt.Printf("Benchmarking %s for %ds using %d connections\n",
-				r.Config.Requests[0].URL, r.DurationSec, r.Config.Settings.ConcurrentUsers)
+			fmt.Fprintf(r.Log, "Benchmarking %s for %ds using %d connections\n",
+				r.Config.Requests[0].URL, r.DurationSec, r.Config.InFlightLimit())
 		} else {
-			fmt.Printf("Benchmarking %d URLs for %ds using %d connections\n",
-				len(r.Config.Requests), r.DurationSec, r.Config.Settings.ConcurrentUsers)
+			fmt.Fprintf(r.Log, "Benchmarking %d URLs for %ds using %d connections\n",
+				len(r.Config.Requests), r.DurationSec, r.Config.InFlightLimit())
 		}
 	} else {
 		if len(r.Config.Requests) == 1 {
-			fmt.Printf("Benchmarking %s with %d requests using %d connections\n",
-				r.Config.Requests[0].URL, totalRequests, r.Config.Settings.ConcurrentUsers)
+			fmt.Fprintf(r.Log, "Benchmarking %s with %d requests using %d connections\n",
+				r.Config.Requests[0].URL, totalRequests, r.Config.InFlightLimit())
 		} else {
-			fmt.Printf("Benchmarking %d URLs with %d requests using %d connections\n",
-				len(r.Config.Requests), totalRequests, r.Config.Settings.ConcurrentUsers)
+			fmt.Fprintf(r.Log, "Benchmarking %d URLs with %d requests using %d connections\n",
+				len(r.Config.Requests), totalRequests, r.Config.InFlightLimit())
 		}
 	}
 
 	// Print additional info in verbose mode
 	if r.VerboseMode {
 		if r.Config.Settings.RateLimit > 0 {
-			fmt.Printf("  Rate limit: %d req/s\n", r.Config.Settings.RateLimit)
+			fmt.Fprintf(r.Log, "  Rate limit: %d req/s\n", r.Config.Settings.RateLimit)
 		}
 		if r.RampUpSec > 0 {
-			fmt.Printf("  Ramp-up: %ds\n", r.RampUpSec)
+			fmt.Fprintf(r.Log, "  Ramp-up: %ds\n", r.RampUpSec)
 		}
 		if r.Config.IsKeepAliveDisabled() {
-			fmt.Println("  Keep-alive: disabled")
+			fmt.Fprintln(r.Log, "  Keep-alive: disabled")
 		}
 	}
 }
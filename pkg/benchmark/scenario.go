@@ -2,20 +2,18 @@
 package benchmark
 
 import (
-	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"io"
 	"math/big"
 	mrand "math/rand"
 	"net/http"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
-	"sync/atomic"
 	"time"
 
 	"github.com/benchmarking_go/pkg/config"
@@ -33,83 +31,511 @@ type ScenarioResult struct {
 	Variables     map[string]string // Final state of variables after scenario
 }
 
+// ExecutedSteps returns the number of steps that completed a request (i.e. were
+// neither skipped nor cancelled)
+func (r *ScenarioResult) ExecutedSteps() int {
+	count := 0
+	for _, sr := range r.StepResults {
+		if !sr.Skipped && !sr.Cancelled {
+			count++
+		}
+	}
+	return count
+}
+
 // StepResult represents the result of a single step
 type StepResult struct {
 	StepName       string
 	Success        bool
+	Skipped        bool // Step was skipped because its `when` condition was false
+	Cancelled      bool // Step's request was cut off by the end of the run before completing
 	StatusCode     int
 	ResponseTime   time.Duration
+	RequestID      string // Injected request ID header value ("" without requestIdHeader)
 	Error          string
 	ExtractedVars  map[string]string
 	ValidationErrs []string
+	untilMet       bool // Poll steps: the response matched the poll condition
 }
 
 // ScenarioExecutor executes scenario sequences
 type ScenarioExecutor struct {
 	config      *config.Config
 	client      *http.Client
-	timeoutSec  int
+	timeout     time.Duration
 	verboseMode bool
+	log         io.Writer // Receives verbose output
+	hooks       *Hooks    // Library callbacks around every request (nil = none)
 	stats       *Stats
+	worker      *WorkerStats                         // This user's own stats (nil outside a benchmark run)
+	vuVariables map[string]string                    // Per-user variables (vuInit and vu-scoped extractions), kept across iterations
+	scenarios   *WeightedScenarioSelector            // Picks a named scenario per iteration (nil for a single step list)
+	capture     *FailureCapture                      // Writes the first failing exchanges to disk (nil = disabled)
+	sampler     *ResponseSampler                     // Writes a random sample of exchanges to disk (nil = disabled)
+	limiters    NamedRateLimiters                    // Per-step rate limits shared by all virtual users
+	persisted   map[string]string                    // Variables carried over between this user's iterations
+	iterations  int                                  // Completed iterations of this user
+	globals     *GlobalVariables                     // Variables shared by all virtual users
+	bodies      map[*config.StepConfig]*preparedBody // Serialized step bodies, prepared once per user
+	functions   templateFunctions                    // {{$name}} functions
+	vu          int                                  // Index of this virtual user
+	js          *javaScript                          // jsScript functions (nil without one)
+	sinks       metricsSinks                         // Export targets receiving every step
 }
 
 // NewScenarioExecutor creates a new scenario executor
-func NewScenarioExecutor(cfg *config.Config, client *http.Client, timeoutSec int, verboseMode bool, stats *Stats) *ScenarioExecutor {
-	return &ScenarioExecutor{
+func NewScenarioExecutor(cfg *config.Config, client *http.Client, timeout time.Duration, verboseMode bool, stats *Stats) *ScenarioExecutor {
+	executor := &ScenarioExecutor{
 		config:      cfg,
 		client:      client,
-		timeoutSec:  timeoutSec,
+		timeout:     timeout,
 		verboseMode: verboseMode,
+		log:         os.Stdout,
 		stats:       stats,
+		vuVariables: make(map[string]string),
+		bodies:      make(map[*config.StepConfig]*preparedBody),
+		functions:   defaultFunctions(),
+	}
+	if len(cfg.Scenarios) > 0 {
+		executor.scenarios = NewWeightedScenarioSelector(cfg.Scenarios)
 	}
+	return executor
 }
 
-// ExecuteScenario runs all steps in the scenario sequence
+// InitVU runs the vuInit steps once for this virtual user (e.g. login).
+// Variables they extract persist across all of this user's scenario iterations.
+// Init steps are not counted in the benchmark statistics.
+func (e *ScenarioExecutor) InitVU(ctx context.Context) *ScenarioResult {
+	initExecutor := *e
+	initExecutor.stats = NewStatsWithOptions(false, false)
+	initExecutor.worker = nil
+	initExecutor.sinks = nil
+
+	result := initExecutor.runSteps(ctx, e.config.VUInit, false)
+	globals := e.globals.Snapshot()
+	for k, v := range result.Variables {
+		if _, isGlobal := globals[k]; isGlobal {
+			continue
+		}
+		if e.config.Variables[k] != v {
+			e.vuVariables[k] = v
+		}
+	}
+	return result
+}
+
+// ExecuteScenario runs all steps in the scenario sequence. When several named
+// scenarios are configured, one is picked by weight for this iteration.
 func (e *ScenarioExecutor) ExecuteScenario(ctx context.Context) *ScenarioResult {
+	if e.scenarios == nil {
+		result := e.runSteps(ctx, e.config.Steps, true)
+		e.recordTransactions(result)
+		e.persistVariables(result)
+		return result
+	}
+
+	sc := e.scenarios.Select()
+	if e.verboseMode {
+		fmt.Fprintf(e.log, "[scenario] Running scenario: %s\n", sc.Name)
+	}
+	result := e.runSteps(ctx, sc.Steps, true)
+	e.recordTransactions(result)
+	e.persistVariables(result)
+	if ctx.Err() == nil {
+		// Iterations cut short by the end of the benchmark are not counted
+		e.stats.RecordScenario(sc.Name, result.TotalDuration.Microseconds(), result.Success)
+	}
+	return result
+}
+
+// persistVariables keeps the configured variables for this user's next iteration,
+// applying the resetEvery and resetOnFailure controls
+func (e *ScenarioExecutor) persistVariables(result *ScenarioResult) {
+	persist := e.config.Persist
+	if persist == nil {
+		return
+	}
+
+	e.iterations++
+	if (persist.ResetOnFailure && !result.Success) ||
+		(persist.ResetEvery > 0 && e.iterations%persist.ResetEvery == 0) {
+		e.persisted = nil
+		return
+	}
+
+	// Rebuild from the final variables so that step resets also clear persisted values
+	globals := e.globals.Snapshot()
+	e.persisted = make(map[string]string)
+	for k, v := range result.Variables {
+		if _, isConfig := e.config.Variables[k]; isConfig {
+			continue
+		}
+		if _, isVU := e.vuVariables[k]; isVU {
+			continue
+		}
+		if _, isGlobal := globals[k]; isGlobal {
+			continue
+		}
+		if persist.Keeps(k) {
+			e.persisted[k] = v
+		}
+	}
+}
+
+// recordTransactions records the combined duration of each transaction whose
+// steps ran in this iteration. A transaction fails if any of its steps failed.
+func (e *ScenarioExecutor) recordTransactions(result *ScenarioResult) {
+	for _, txn := range e.config.Transactions {
+		var duration time.Duration
+		executed, cancelled := false, false
+		success := true
+		for _, stepResult := range result.StepResults {
+			if stepResult.Skipped || !containsString(txn.Steps, stepResult.StepName) {
+				continue
+			}
+			executed = true
+			cancelled = cancelled || stepResult.Cancelled
+			duration += stepResult.ResponseTime
+			success = success && stepResult.Success
+		}
+		// Transactions cut short by the end of the run are not counted
+		if executed && !cancelled {
+			e.stats.RecordTransaction(txn.Name, duration.Microseconds(), success)
+		}
+	}
+}
+
+// runSteps runs a sequence of steps, starting from the config variables plus any per-VU variables.
+// For a scenario iteration, the script's iteration function runs first.
+func (e *ScenarioExecutor) runSteps(ctx context.Context, steps []config.StepConfig, iteration bool) *ScenarioResult {
 	result := &ScenarioResult{
 		Success:     true,
-		StepResults: make([]StepResult, 0, len(e.config.Steps)),
+		StepResults: make([]StepResult, 0, len(steps)),
 		Variables:   copyVariables(e.config.Variables),
 	}
+	// Narrower scopes win: config < global < per-user < persisted < this iteration
+	for k, v := range e.globals.Snapshot() {
+		result.Variables[k] = v
+	}
+	for k, v := range e.vuVariables {
+		result.Variables[k] = v
+	}
+	for k, v := range e.persisted {
+		result.Variables[k] = v
+	}
 
 	scenarioStart := time.Now()
+	if iteration {
+		vars, err := e.js.iteration(e.vu, result.Variables)
+		if err != nil {
+			result.Success = false
+			e.stats.AddError(err.Error())
+			result.TotalDuration = time.Since(scenarioStart)
+			return result
+		}
+		for k, v := range vars {
+			result.Variables[k] = v
+		}
+	}
+	e.runStepList(ctx, steps, result)
+	result.TotalDuration = time.Since(scenarioStart)
+	return result
+}
 
-	for i, step := range e.config.Steps {
+// runStepList runs steps in order, adding their results and variables to result.
+// It returns false when the iteration has to end early (cancellation or a failure action).
+func (e *ScenarioExecutor) runStepList(ctx context.Context, steps []config.StepConfig, result *ScenarioResult) bool {
+	for i := range steps {
+		step := &steps[i]
 		select {
 		case <-ctx.Done():
 			result.Success = false
-			return result
+			return false
 		default:
 		}
 
-		// Handle step delay
-		if step.Delay != "" {
-			if delay, err := time.ParseDuration(step.Delay); err == nil {
-				time.Sleep(delay)
+		// Skip steps whose condition is not met
+		if step.When != "" && !evaluateCondition(resolveVariables(step.When, result.Variables, e.functions)) {
+			result.StepResults = append(result.StepResults, StepResult{StepName: step.Name, Success: true, Skipped: true})
+			e.stats.IncrementSkipped()
+			if e.verboseMode {
+				fmt.Fprintf(e.log, "[scenario] Step %d: %s skipped (when: %s)\n", i+1, step.Name, step.When)
 			}
+			continue
 		}
 
-		stepResult := e.executeStep(ctx, &step, result.Variables, i)
-		result.StepResults = append(result.StepResults, stepResult)
+		// A foreach step runs once per element of a JSON array variable
+		iterations := []string{""}
+		if step.ForEach != "" {
+			iterations = jsonArrayElements(result.Variables[step.ForEach], step.ForEachLimit)
+			if len(iterations) == 0 {
+				result.StepResults = append(result.StepResults, StepResult{StepName: step.Name, Success: true, Skipped: true})
+				e.stats.IncrementSkipped()
+				if e.verboseMode {
+					fmt.Fprintf(e.log, "[scenario] Step %d: %s skipped (foreach: %s is empty)\n", i+1, step.Name, step.ForEach)
+				}
+				continue
+			}
+		}
 
-		// Merge extracted variables
-		for k, v := range stepResult.ExtractedVars {
-			result.Variables[k] = v
+		for n, element := range iterations {
+			if step.ForEach != "" {
+				itemVar := step.ForEachVariable()
+				result.Variables[itemVar] = element
+				result.Variables[itemVar+"Index"] = strconv.Itoa(n)
+			}
+
+			// Handle step delay (fixed or sampled from a distribution)
+			if step.Delay != "" {
+				spec, _ := config.ParseDelay(step.Delay) // Validated with the config
+				if delay := spec.Sample(); delay > 0 {
+					select {
+					case <-ctx.Done():
+						result.Success = false
+						return false
+					case <-time.After(delay):
+					}
+				}
+			}
+
+			stepResult, ok := e.runStep(ctx, step, result, i)
+			if !ok {
+				return false
+			}
+
+			// A status branch runs its steps, then optionally retries the step once;
+			// the step's (final) result still goes through the failure handling below
+			if branch, found := step.StatusBranch(stepResult.StatusCode); found {
+				if e.verboseMode {
+					fmt.Fprintf(e.log, "[scenario] Step %d: %s returned %d, running %d branch step(s)\n", i+1, step.Name, stepResult.StatusCode, len(branch.Steps))
+				}
+				if !e.runStepList(ctx, branch.Steps, result) {
+					return false
+				}
+				if branch.Retry {
+					if stepResult, ok = e.runStep(ctx, step, result, i); !ok {
+						return false
+					}
+				}
+			}
+
+			if !stepResult.Success {
+				result.Success = false
+
+				switch step.FailureAction() {
+				case config.OnFailureAbort:
+					// End the iteration; remaining steps are not run or counted
+					if e.verboseMode {
+						fmt.Fprintf(e.log, "[scenario] Step %d: %s failed, aborting iteration\n", i+1, step.Name)
+					}
+					return false
+				case config.OnFailureSkipRemaining:
+					// End the iteration; remaining steps are reported as skipped
+					if e.verboseMode {
+						fmt.Fprintf(e.log, "[scenario] Step %d: %s failed, skipping remaining steps\n", i+1, step.Name)
+					}
+					for _, remaining := range steps[i+1:] {
+						result.StepResults = append(result.StepResults, StepResult{StepName: remaining.Name, Success: true, Skipped: true})
+						e.stats.IncrementSkipped()
+					}
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// runStep sends one step after its rate limit and merges the result into the iteration.
+// It returns false if the benchmark ended while waiting for the rate limit or
+// while the step's request was in flight.
+func (e *ScenarioExecutor) runStep(ctx context.Context, step *config.StepConfig, result *ScenarioResult, stepIndex int) (StepResult, bool) {
+	// Per-step rate limit
+	if !e.limiters.Get(step.Name).Wait(ctx) {
+		result.Success = false
+		return StepResult{}, false
+	}
+
+	var stepResult StepResult
+	if step.Poll != nil {
+		stepResult = e.pollStep(ctx, step, result.Variables, stepIndex)
+	} else if step.WebSocket != nil {
+		stepResult = e.executeWebSocketStep(ctx, step, result.Variables, stepIndex)
+	} else {
+		stepResult = e.executeStep(ctx, step, result.Variables, stepIndex, nil)
+	}
+	result.StepResults = append(result.StepResults, stepResult)
+	if stepResult.Cancelled {
+		// The run ended mid-request; the iteration ends without failure handling
+		result.Success = false
+		return stepResult, false
+	}
+	if e.worker != nil {
+		e.worker.RecordRequest(stepResult.ResponseTime.Microseconds(), stepResult.Success)
+	}
+
+	// Merge extracted variables
+	for k, v := range stepResult.ExtractedVars {
+		result.Variables[k] = v
+		switch step.VariableScope(k) {
+		case config.ScopeGlobal:
+			e.globals.Set(k, v)
+		case config.ScopeVU:
+			e.vuVariables[k] = v
+		}
+	}
+	if stepResult.Success {
+		for _, name := range step.Reset {
+			delete(result.Variables, name)
+			delete(e.vuVariables, name)
+			e.globals.Delete(name)
+		}
+	}
+	return stepResult, true
+}
+
+// extractStepVariables extracts the step's variables from a response body or headers
+func (e *ScenarioExecutor) extractStepVariables(step *config.StepConfig, body string, headers http.Header, doc *lazyDocument, result *StepResult) {
+	for varName, jsonPath := range step.Extract {
+		value := extractValue(body, jsonPath, headers, doc)
+		if value != "" {
+			result.ExtractedVars[varName] = value
+			if e.verboseMode {
+				fmt.Fprintf(e.log, "[scenario] Extracted %s = %s\n", varName, truncateString(value, 50))
+			}
+		}
+	}
+}
+
+// addResponseTime records a response time through this user's worker stats when it has them
+func (e *ScenarioExecutor) addResponseTime(responseTimeMicros int64, success bool) {
+	if e.worker != nil {
+		e.worker.AddResponseTime(responseTimeMicros, success)
+		return
+	}
+	e.stats.AddResponseTime(responseTimeMicros, success)
+}
+
+// addTTFB records a time to first byte through this user's worker stats when it has them
+func (e *ScenarioExecutor) addTTFB(ttfbMicros int64) {
+	if e.worker != nil {
+		e.worker.AddTTFB(ttfbMicros)
+		return
+	}
+	e.stats.RecordTTFB(ttfbMicros)
+}
+
+// recordStepStats updates the per-step and overall success/failure counts and
+// records the response time under the step's final outcome. statusOK reports
+// whether the response status itself counts as a success.
+func (e *ScenarioExecutor) recordStepStats(step *config.StepConfig, result *StepResult, statusOK bool) {
+	e.addResponseTime(result.ResponseTime.Microseconds(), result.Success && statusOK)
+
+	reqStats := e.stats.GetOrCreateRequestStats(step.Name, step.URL, step.Method)
+	reqStats.Mutex.Lock()
+	defer reqStats.Mutex.Unlock()
+	reqStats.RequestCount++
+	reqStats.TotalLatency += result.ResponseTime.Microseconds()
+	reqStats.RecordLatency(result.ResponseTime.Microseconds())
+	if result.Success && statusOK {
+		reqStats.SuccessCount++
+		e.stats.IncrementSuccess()
+		e.stats.recordGood(result.ResponseTime.Microseconds())
+	} else {
+		reqStats.FailureCount++
+		if result.Success { // Only increment if not already failed
+			e.stats.IncrementFailure()
+			result.Success = false
+		}
+	}
+
+	if len(e.sinks) > 0 {
+		errMsg := result.Error
+		if errMsg == "" && len(result.ValidationErrs) > 0 {
+			errMsg = strings.Join(result.ValidationErrs, "; ")
+		} else if errMsg == "" && !result.Success {
+			errMsg = fmt.Sprintf("HTTP %d", result.StatusCode)
 		}
+		e.sinks.request(RequestMetric{Name: step.Name, Method: step.Method, Status: result.StatusCode,
+			Latency: result.ResponseTime, Success: result.Success, RequestID: result.RequestID}, errMsg)
+	}
+}
 
-		if !stepResult.Success {
+// recordStepFailure records a step that failed before a response was read in
+// full: its elapsed time (result.ResponseTime) as a failure latency, in the
+// step's own stats and in the metrics sinks
+func (e *ScenarioExecutor) recordStepFailure(step *config.StepConfig, result *StepResult, errMsg string) {
+	latency := result.ResponseTime.Microseconds()
+	e.addResponseTime(latency, false)
+
+	reqStats := e.stats.GetOrCreateRequestStats(step.Name, step.URL, step.Method)
+	reqStats.Mutex.Lock()
+	reqStats.RequestCount++
+	reqStats.TotalLatency += latency
+	reqStats.RecordLatency(latency)
+	reqStats.FailureCount++
+	if errMsg != "" {
+		reqStats.Errors[errMsg]++
+	}
+	reqStats.Mutex.Unlock()
+
+	e.sinks.request(RequestMetric{Name: step.Name, Method: step.Method, Status: result.StatusCode,
+		Latency: result.ResponseTime, RequestID: result.RequestID}, errMsg)
+}
+
+// pollStep repeats a step until its poll condition holds, it fails, or the attempt
+// limit or timeout is reached. Every attempt is counted as a request; the total
+// wait is recorded as a separate poll metric.
+func (e *ScenarioExecutor) pollStep(ctx context.Context, step *config.StepConfig, variables map[string]string, stepIndex int) StepResult {
+	interval, timeout, err := step.Poll.Durations()
+	if err != nil {
+		e.stats.IncrementFailure()
+		e.stats.AddError(err.Error())
+		return StepResult{StepName: step.Name, Error: err.Error()}
+	}
+
+	poll := &pollAttempt{start: time.Now(), interval: interval, timeout: timeout, maxAttempts: step.Poll.MaxAttempts}
+	for poll.number = 1; ; poll.number++ {
+		result := e.executeStep(ctx, step, variables, stepIndex, poll)
+		if ctx.Err() != nil {
+			result.Success = false
+			return result
+		}
+		if !result.Success || result.untilMet {
+			e.stats.RecordPoll(step.Name, time.Since(poll.start).Microseconds(), result.Success)
+			return result
+		}
+
+		if e.verboseMode {
+			fmt.Fprintf(e.log, "[scenario] Step %d: %s poll condition not met, retrying in %s\n", stepIndex+1, step.Name, interval)
+		}
+		select {
+		case <-ctx.Done():
 			result.Success = false
-			// Continue or break based on step criticality
-			// For now, we continue to get all stats
+			return result
+		case <-time.After(interval):
 		}
 	}
+}
 
-	result.TotalDuration = time.Since(scenarioStart)
-	return result
+// pollAttempt is one attempt of a poll step
+type pollAttempt struct {
+	number      int
+	start       time.Time
+	interval    time.Duration
+	timeout     time.Duration
+	maxAttempts int
+}
+
+// last reports whether the poll gives up if this attempt doesn't meet the
+// condition: the attempt limit is reached or another wait would pass the timeout
+func (p *pollAttempt) last() bool {
+	return (p.maxAttempts > 0 && p.number >= p.maxAttempts) || time.Since(p.start)+p.interval > p.timeout
 }
 
-// executeStep executes a single step and returns the result
-func (e *ScenarioExecutor) executeStep(ctx context.Context, step *config.StepConfig, variables map[string]string, stepIndex int) StepResult {
+// executeStep executes a single step and returns the result. poll is the
+// current attempt of a poll step (nil for other steps).
+func (e *ScenarioExecutor) executeStep(ctx context.Context, step *config.StepConfig, variables map[string]string, stepIndex int, poll *pollAttempt) StepResult {
 	result := StepResult{
 		StepName:      step.Name,
 		Success:       true,
@@ -119,43 +545,69 @@ func (e *ScenarioExecutor) executeStep(ctx context.Context, step *config.StepCon
 	stepStart := time.Now()
 
 	// Resolve URL with variables
-	url := resolveVariables(step.URL, variables)
+	url := resolveVariables(step.URL, variables, e.functions)
 
 	// Prepare body
-	body, err := prepareStepBody(step, variables)
+	body, err := e.prepareStepBody(step, variables)
 	if err != nil {
 		result.Success = false
 		result.Error = err.Error()
+		result.ResponseTime = time.Since(stepStart)
 		e.stats.IncrementFailure()
 		e.stats.AddError(err.Error())
+		e.recordStepFailure(step, &result, result.Error)
 		return result
 	}
 
 	// Create request
-	reqCtx, cancel := context.WithTimeout(ctx, time.Duration(e.timeoutSec)*time.Second)
+	reqCtx, cancel := context.WithTimeout(ctx, e.timeout)
 	defer cancel()
 
-	var req *http.Request
-	if body != "" {
-		req, err = http.NewRequestWithContext(reqCtx, step.Method, url, bytes.NewBufferString(body))
-	} else {
-		req, err = http.NewRequestWithContext(reqCtx, step.Method, url, nil)
-	}
-
+	req, err := newBodyRequest(reqCtx, step.Method, url, body)
 	if err != nil {
 		result.Success = false
 		result.Error = err.Error()
+		result.ResponseTime = time.Since(stepStart)
 		e.stats.IncrementFailure()
 		e.stats.AddError(err.Error())
+		e.recordStepFailure(step, &result, result.Error)
 		return result
 	}
 
 	// Add headers
 	e.addStepHeaders(req, step, variables, body)
+	result.RequestID = setRequestID(req, &e.config.Settings)
+
+	// Let the jsRequest function, then library hooks change the request
+	if step.JSRequest != "" {
+		var vars map[string]string
+		if body, vars, err = e.js.request(e.vu, step.JSRequest, req, body, variables); err != nil {
+			result.Success = false
+			result.Error = "jsRequest: " + err.Error()
+			result.ResponseTime = time.Since(stepStart)
+			e.stats.IncrementFailure()
+			e.stats.AddError(fmt.Sprintf("[%s] %s", step.Name, result.Error))
+			e.recordStepFailure(step, &result, result.Error)
+			return result
+		}
+		for k, v := range vars {
+			result.ExtractedVars[k] = v
+		}
+		url = req.URL.String()
+	}
+	if err := e.hooks.request(req); err != nil {
+		result.Success = false
+		result.Error = "request hook: " + err.Error()
+		result.ResponseTime = time.Since(stepStart)
+		e.stats.IncrementFailure()
+		e.stats.AddError(fmt.Sprintf("[%s] %s", step.Name, result.Error))
+		e.recordStepFailure(step, &result, result.Error)
+		return result
+	}
 
 	// Verbose logging
 	if e.verboseMode {
-		fmt.Printf("[scenario] Step %d: %s %s\n", stepIndex+1, step.Method, url)
+		fmt.Fprintf(e.log, "[scenario] Step %d: %s %s\n", stepIndex+1, step.Method, url)
 	}
 
 	// Send request
@@ -164,36 +616,76 @@ func (e *ScenarioExecutor) executeStep(ctx context.Context, step *config.StepCon
 		result.Success = false
 		result.Error = err.Error()
 		result.ResponseTime = time.Since(stepStart)
+		if ctx.Err() != nil {
+			return e.cancelStep(result)
+		}
 		e.stats.IncrementFailure()
 		if !strings.Contains(err.Error(), "context") {
 			e.stats.AddError(err.Error())
+			e.stats.AddErrorSample(err.Error(), req, 0, nil)
 		}
+		e.recordStepFailure(step, &result, categorizeError(err))
+		e.capture.Capture(categorizeError(err), err.Error(), req, body, nil, nil)
+		e.hooks.response(&Response{Request: req, Latency: result.ResponseTime, Err: err})
 		return result
 	}
 	defer resp.Body.Close()
+	e.addTTFB(time.Since(stepStart).Microseconds())
+	e.stats.RecordRedirects(redirectHops(resp))
+	e.stats.RecordServerTiming(resp.Header)
 
 	result.StatusCode = resp.StatusCode
-	result.ResponseTime = time.Since(stepStart)
 
-	// Read response body
-	respBody, err := io.ReadAll(resp.Body)
+	// Read response body. Binary steps, and steps that neither check nor extract
+	// anything from it, stream it through size/checksum counters instead of
+	// keeping it as text; when only the failure capture or the sampler may need
+	// it, just its start is kept.
+	sampled := e.sampler.Sample()
+	var respBody []byte
+	var digest *bodyDigest
+	switch {
+	case step.Binary || (!e.needsBody(step) && e.capture == nil && !sampled && !e.hooks.hasResponseHooks()):
+		digest, err = readBinaryBody(resp.Body, step.Validate)
+	case !e.needsBody(step):
+		var size int64
+		respBody, size, err = readBodyPrefix(resp.Body, resp.ContentLength, maxKeptBody)
+		digest = &bodyDigest{size: size}
+	default:
+		respBody, err = readBody(resp.Body, resp.ContentLength)
+	}
+	// Response time includes the full transfer of the body
+	result.ResponseTime = time.Since(stepStart)
 	if err != nil {
 		result.Success = false
 		result.Error = err.Error()
+		if ctx.Err() != nil {
+			return e.cancelStep(result)
+		}
 		e.stats.IncrementFailure()
+		e.recordStepFailure(step, &result, categorizeError(err))
+		e.hooks.response(&Response{Request: req, StatusCode: resp.StatusCode, Header: resp.Header, Latency: result.ResponseTime, Err: err})
 		return result
 	}
+	if digest == nil {
+		digest = digestBytes(respBody, step.Validate)
+	}
 
 	respBodyStr := string(respBody)
 
 	// Record stats
 	e.stats.AddStatusCode(resp.StatusCode)
-	e.stats.AddBytes(int64(len(respBody)))
-	e.stats.AddResponseTime(result.ResponseTime.Microseconds())
+	e.stats.AddBytes(digest.size)
+
+	doc := &lazyDocument{body: respBodyStr, contentType: resp.Header.Get("Content-Type")}
 
 	// Validate response
 	if step.Validate != nil {
-		validationErrs := e.validateResponse(resp, respBodyStr, step.Validate, result.ResponseTime)
+		validationErrs := e.validateResponse(resp, respBodyStr, doc, step.Validate, result.ResponseTime)
+		if digestErrs := validateDigest(digest, step.Validate); len(digestErrs) > 0 {
+			// Counted on their own too, so corrupt payloads stand out from HTTP errors
+			e.stats.IncrementBodyMismatch()
+			validationErrs = append(validationErrs, digestErrs...)
+		}
 		result.ValidationErrs = validationErrs
 		if len(validationErrs) > 0 {
 			result.Success = false
@@ -203,58 +695,128 @@ func (e *ScenarioExecutor) executeStep(ctx context.Context, step *config.StepCon
 		}
 	}
 
+	// A response that doesn't echo the request ID fails like a validation
+	if idErr := requestIDMismatch(resp, &e.config.Settings, result.RequestID); idErr != "" {
+		result.Success = false
+		result.ValidationErrs = append(result.ValidationErrs, idErr)
+		e.stats.AddError(fmt.Sprintf("[%s] %s", step.Name, idErr))
+	}
+
 	// Extract variables from response
-	if step.Extract != nil {
-		for varName, jsonPath := range step.Extract {
-			value := extractValue(respBodyStr, jsonPath, resp.Header)
-			if value != "" {
-				result.ExtractedVars[varName] = value
-				if e.verboseMode {
-					fmt.Printf("[scenario] Extracted %s = %s\n", varName, truncateString(value, 50))
-				}
-			}
+	e.extractStepVariables(step, respBodyStr, resp.Header, doc, &result)
+
+	// The jsCheck function fails the step like a validation, and may set variables
+	if step.JSCheck != "" {
+		vars := copyVariables(variables)
+		for k, v := range result.ExtractedVars {
+			vars[k] = v
+		}
+		msg, changed := e.js.check(e.vu, step.JSCheck, resp, respBody, result.ResponseTime, vars)
+		for k, v := range changed {
+			result.ExtractedVars[k] = v
+		}
+		if msg != "" {
+			result.Success = false
+			result.ValidationErrs = append(result.ValidationErrs, msg)
+			e.stats.AddError(fmt.Sprintf("[%s] %s", step.Name, msg))
+		}
+	}
+
+	// Check the poll condition (validation failures end polling regardless)
+	if step.Poll != nil {
+		result.untilMet = step.Poll.Until == nil ||
+			len(e.validateResponse(resp, respBodyStr, doc, step.Poll.Until, result.ResponseTime)) == 0
+	}
+
+	// Library hooks may overrule the status check and validation
+	statusOK := resp.StatusCode >= 200 && resp.StatusCode < 300
+	if e.hooks.hasResponseHooks() {
+		success, hookErr := e.hooks.response(&Response{Request: req, StatusCode: resp.StatusCode, Header: resp.Header,
+			Body: respBody, Latency: result.ResponseTime, Success: result.Success && statusOK})
+		if success {
+			result.Success, statusOK = true, true
+		} else if hookErr != "" {
+			statusOK = false
+			result.ValidationErrs = append(result.ValidationErrs, hookErr)
+			e.stats.AddError(fmt.Sprintf("[%s] %s", step.Name, hookErr))
 		}
 	}
 
+	// The last attempt of a poll that gives up fails like a validation
+	gaveUp := poll != nil && result.Success && statusOK && !result.untilMet && poll.last()
+	if gaveUp {
+		statusOK = false
+		result.ValidationErrs = append(result.ValidationErrs, "poll condition not met")
+		e.stats.AddError(fmt.Sprintf("[%s] poll condition not met", step.Name))
+	}
+
 	// Update per-request stats
-	reqStats := e.stats.GetOrCreateRequestStats(step.Name, step.URL, step.Method)
-	reqStats.Mutex.Lock()
-	reqStats.RequestCount++
-	reqStats.TotalLatency += result.ResponseTime.Microseconds()
-	if result.Success && resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		reqStats.SuccessCount++
-		e.stats.IncrementSuccess()
-	} else {
-		reqStats.FailureCount++
-		if result.Success { // Only increment if not already failed
-			e.stats.IncrementFailure()
-			result.Success = false
+	e.recordStepStats(step, &result, statusOK)
+	if gaveUp {
+		result.Error = fmt.Sprintf("poll condition not met after %d attempts (%s)", poll.number, time.Since(poll.start).Round(time.Millisecond))
+	}
+	e.stats.RecordCache(step.Name, resp.Header, result.ResponseTime.Microseconds())
+
+	var errMsg string
+	if !result.Success {
+		category := failureCategory(resp.StatusCode, "")
+		errMsg = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		if len(result.ValidationErrs) > 0 {
+			category = "validation " + step.Name
+			errMsg = strings.Join(result.ValidationErrs, "; ")
+		}
+		e.capture.Capture(category, errMsg, req, body, resp, respBody)
+		for _, verr := range result.ValidationErrs {
+			e.stats.AddErrorSample(fmt.Sprintf("[%s] %s", step.Name, verr), req, resp.StatusCode, respBody)
 		}
 	}
-	reqStats.Mutex.Unlock()
+	if sampled {
+		e.sampler.Save(errMsg, req, body, resp, respBody)
+	}
 
 	if e.verboseMode {
 		status := "✓"
 		if !result.Success {
 			status = "✗"
 		}
-		fmt.Printf("[scenario] %s Step %d: %s -> %d (%s)\n", status, stepIndex+1, step.Name, resp.StatusCode, result.ResponseTime)
+		fmt.Fprintf(e.log, "[scenario] %s Step %d: %s -> %d (%s)\n", status, stepIndex+1, step.Name, resp.StatusCode, result.ResponseTime)
 	}
 
 	return result
 }
 
+// cancelStep marks a step whose request was cut off by the end of the run. It
+// is counted as cancelled rather than failed, and its response time is dropped.
+func (e *ScenarioExecutor) cancelStep(result StepResult) StepResult {
+	result.Success = false
+	result.Cancelled = true
+	e.stats.IncrementCancelled()
+	return result
+}
+
+// needsBody reports whether a step's whole response body must be kept: for
+// validation, extraction, poll conditions or a jsCheck function
+func (e *ScenarioExecutor) needsBody(step *config.StepConfig) bool {
+	return step.Validate != nil || len(step.Extract) > 0 || step.Poll != nil || step.JSCheck != ""
+}
+
 // addStepHeaders adds headers to the request
 func (e *ScenarioExecutor) addStepHeaders(req *http.Request, step *config.StepConfig, variables map[string]string, body string) {
 	// Add default headers
 	for key, value := range e.config.DefaultHeaders {
-		req.Header.Set(key, resolveVariables(value, variables))
+		req.Header.Set(key, resolveVariables(value, variables, e.functions))
+	}
+
+	resolve := func(value string) string {
+		return resolveVariables(value, variables, e.functions)
 	}
+	setRotatingHeaders(req, e.config.RotateHeaders, resolve)
 
 	// Add step-specific headers
 	for key, value := range step.Headers {
-		req.Header.Set(key, resolveVariables(value, variables))
+		req.Header.Set(key, resolveVariables(value, variables, e.functions))
 	}
+	setRotatingHeaders(req, step.RotateHeaders, resolve)
 
 	// Set default content type for body
 	if body != "" && req.Header.Get("Content-Type") == "" {
@@ -268,7 +830,7 @@ func (e *ScenarioExecutor) addStepHeaders(req *http.Request, step *config.StepCo
 }
 
 // validateResponse validates the response against the validation config
-func (e *ScenarioExecutor) validateResponse(resp *http.Response, body string, validate *config.ValidateConfig, responseTime time.Duration) []string {
+func (e *ScenarioExecutor) validateResponse(resp *http.Response, body string, doc *lazyDocument, validate *config.ValidateConfig, responseTime time.Duration) []string {
 	var errors []string
 
 	// Validate status code
@@ -303,13 +865,42 @@ func (e *ScenarioExecutor) validateResponse(resp *http.Response, body string, va
 	// Validate JSONPath assertions
 	if validate.JSONPath != nil {
 		for path, expected := range validate.JSONPath {
-			actual := gjson.Get(body, strings.TrimPrefix(path, "$."))
+			actual := gjson.Get(body, toGJSONPath(path))
 			if !matchJSONValue(actual, expected) {
 				errors = append(errors, fmt.Sprintf("JSONPath %s: expected %v, got %v", path, expected, actual.Value()))
 			}
 		}
 	}
 
+	// Validate boolean expressions over the JSON body
+	for _, expr := range validate.Expr {
+		ok, err := evaluateExpression(expr, body)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("expression %s: %v", expr, err))
+		} else if !ok {
+			errors = append(errors, fmt.Sprintf("expression %s: evaluated to false", expr))
+		}
+	}
+
+	// Validate XPath assertions (XML/SOAP or HTML)
+	if validate.XPath != nil {
+		root, err := doc.get()
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("XPath: failed to parse response: %v", err))
+		} else {
+			for path, expected := range validate.XPath {
+				value, found, err := xpathValue(root, path)
+				if err != nil {
+					errors = append(errors, fmt.Sprintf("XPath %s: %v", path, err))
+					continue
+				}
+				if !matchJSONValue(xpathResult(value, found), expected) {
+					errors = append(errors, fmt.Sprintf("XPath %s: expected %v, got %v", path, expected, value))
+				}
+			}
+		}
+	}
+
 	// Validate response headers
 	if validate.Headers != nil {
 		for key, expected := range validate.Headers {
@@ -375,29 +966,11 @@ func matchJSONValue(actual gjson.Result, expected interface{}) bool {
 		}
 		return actual.Float() == v
 	case string:
-		// Check for comparison operators
-		if strings.HasPrefix(v, "> ") {
-			num, err := strconv.ParseFloat(strings.TrimPrefix(v, "> "), 64)
-			if err == nil {
-				return actual.Float() > num
-			}
-		}
-		if strings.HasPrefix(v, ">= ") {
-			num, err := strconv.ParseFloat(strings.TrimPrefix(v, ">= "), 64)
-			if err == nil {
-				return actual.Float() >= num
-			}
-		}
-		if strings.HasPrefix(v, "< ") {
-			num, err := strconv.ParseFloat(strings.TrimPrefix(v, "< "), 64)
-			if err == nil {
-				return actual.Float() < num
-			}
-		}
-		if strings.HasPrefix(v, "<= ") {
-			num, err := strconv.ParseFloat(strings.TrimPrefix(v, "<= "), 64)
-			if err == nil {
-				return actual.Float() <= num
+		// Values starting with an operator are expressions about the actual
+		// value, e.g. "> 0" or ">= 1 && < 100"
+		if isValueExpression(v) {
+			if ok, err := matchExpression(v, actual); err == nil {
+				return ok
 			}
 		}
 		return actual.String() == v
@@ -406,18 +979,52 @@ func matchJSONValue(actual gjson.Result, expected interface{}) bool {
 	}
 }
 
+// xpathResult wraps an XPath string value so it can be compared with matchJSONValue
+func xpathResult(value string, found bool) gjson.Result {
+	if !found {
+		return gjson.Result{}
+	}
+	return gjson.Result{Type: gjson.String, Str: value, Raw: strconv.Quote(value)}
+}
+
 // extractValue extracts a value from response body or headers
-func extractValue(body string, pathOrExpr string, headers http.Header) string {
+func extractValue(body string, pathOrExpr string, headers http.Header, doc *lazyDocument) string {
 	// Check if it's a header extraction (header:HeaderName)
 	if strings.HasPrefix(pathOrExpr, "header:") {
 		headerName := strings.TrimPrefix(pathOrExpr, "header:")
 		return headers.Get(headerName)
 	}
 
+	// Check if it's an XPath extraction from HTML or XML (xpath://input[@name='csrf']/@value)
+	if strings.HasPrefix(pathOrExpr, "xpath:") {
+		root, err := doc.get()
+		if err != nil {
+			return ""
+		}
+		value, _, err := xpathValue(root, strings.TrimPrefix(pathOrExpr, "xpath:"))
+		if err != nil {
+			return ""
+		}
+		return value
+	}
+
+	// Check if it's a CSS selector extraction (css:form#login@action)
+	if strings.HasPrefix(pathOrExpr, "css:") {
+		root, err := doc.get()
+		if err != nil {
+			return ""
+		}
+		value, _, err := cssValue(root, strings.TrimPrefix(pathOrExpr, "css:"))
+		if err != nil {
+			return ""
+		}
+		return value
+	}
+
 	// Check if it's a regex extraction (regex:pattern)
 	if strings.HasPrefix(pathOrExpr, "regex:") {
 		pattern := strings.TrimPrefix(pathOrExpr, "regex:")
-		re, err := regexp.Compile(pattern)
+		re, err := compilePattern(pattern)
 		if err != nil {
 			return ""
 		}
@@ -431,27 +1038,119 @@ func extractValue(body string, pathOrExpr string, headers http.Header) string {
 	}
 
 	// Default: JSONPath extraction using gjson
-	// Remove $. prefix if present (gjson doesn't use it)
-	jsonPath := strings.TrimPrefix(pathOrExpr, "$.")
-	result := gjson.Get(body, jsonPath)
+	// Arrays (e.g. "$.items[*].id") are captured as JSON array text
+	result := gjson.Get(body, toGJSONPath(pathOrExpr))
 	if result.Exists() {
 		return result.String()
 	}
 	return ""
 }
 
+// lazyDocument parses a response body into a document tree on first use,
+// so several xpath/css lookups on one response share a single parse.
+// XML responses (by Content-Type or <?xml prolog) use the XML parser, everything else HTML.
+type lazyDocument struct {
+	body        string
+	contentType string
+	root        *docNode
+	err         error
+	parsed      bool
+}
+
+// get returns the parsed document
+func (d *lazyDocument) get() (*docNode, error) {
+	if !d.parsed {
+		if isXMLContent(d.contentType, d.body) {
+			d.root, d.err = parseXMLDocument(d.body)
+		} else {
+			d.root, d.err = parseHTMLDocument(d.body)
+		}
+		d.parsed = true
+	}
+	return d.root, d.err
+}
+
+// jsonIndexRegex matches JSONPath array indexes and wildcards ("[0]", "[*]")
+var jsonIndexRegex = regexp.MustCompile(`\[(\d+|\*)\]`)
+
+// toGJSONPath converts a JSONPath-style expression to gjson syntax:
+// "$.items[*].id" becomes "items.#.id" and "$.items[0]" becomes "items.0"
+func toGJSONPath(path string) string {
+	path = strings.TrimPrefix(path, "$.")
+	path = jsonIndexRegex.ReplaceAllStringFunc(path, func(m string) string {
+		index := m[1 : len(m)-1]
+		if index == "*" {
+			return ".#"
+		}
+		return "." + index
+	})
+	return strings.TrimPrefix(path, ".")
+}
+
+// jsonArrayElements returns the elements of a JSON array variable as strings,
+// limited to the first limit elements when limit > 0
+func jsonArrayElements(value string, limit int) []string {
+	parsed := gjson.Parse(value)
+	if !parsed.IsArray() {
+		return nil
+	}
+	var elements []string
+	for _, item := range parsed.Array() {
+		if limit > 0 && len(elements) >= limit {
+			break
+		}
+		elements = append(elements, item.String())
+	}
+	return elements
+}
+
+// indexedVarRegex matches indexed array variables like {{items[0]}} or {{items[itemIndex]}}
+var indexedVarRegex = regexp.MustCompile(`\{\{([A-Za-z_]\w*)\[([^\]]+)\]\}\}`)
+
+// resolveIndexedVariables replaces {{name[i]}} with element i of the JSON array variable name.
+// The index may be a number or the name of a variable holding a number.
+func resolveIndexedVariables(input string, variables map[string]string) string {
+	if !strings.Contains(input, "]}}") {
+		return input
+	}
+	return indexedVarRegex.ReplaceAllStringFunc(input, func(m string) string {
+		parts := indexedVarRegex.FindStringSubmatch(m)
+		array, ok := variables[parts[1]]
+		if !ok {
+			return m
+		}
+		index := parts[2]
+		if v, ok := variables[index]; ok {
+			index = v
+		}
+		if _, err := strconv.Atoi(index); err != nil {
+			return m
+		}
+		element := gjson.Get(array, index)
+		if !element.Exists() {
+			return m
+		}
+		return element.String()
+	})
+}
+
 // resolveVariables replaces {{varName}} placeholders with values
-// Also supports dynamic functions:
+// Also supports dynamic functions (see functions.go for registering more):
 //   - {{$uuid}} - generates a random UUID
 //   - {{$randomInt}} - generates a random integer (0-999999)
 //   - {{$timestamp}} - current Unix timestamp in milliseconds
 //   - {{$iteration}} - current iteration number (globally unique)
 //   - {{$randomUser}} - generates a unique user ID like "user-abc123"
-func resolveVariables(input string, variables map[string]string) string {
+//
+// Array variables can be indexed with {{items[0]}} or {{items[itemIndex]}}.
+func resolveVariables(input string, variables map[string]string, functions templateFunctions) string {
 	result := input
 
 	// Handle dynamic functions first
-	result = resolveDynamicFunctions(result)
+	result = resolveDynamicFunctions(result, functions)
+
+	// Resolve indexed array elements before plain variables
+	result = resolveIndexedVariables(result, variables)
 
 	// Then resolve static variables
 	for key, value := range variables {
@@ -460,39 +1159,6 @@ func resolveVariables(input string, variables map[string]string) string {
 	return result
 }
 
-// resolveDynamicFunctions replaces dynamic function placeholders with generated values
-func resolveDynamicFunctions(input string) string {
-	result := input
-
-	// Replace all occurrences of {{$uuid}}
-	for strings.Contains(result, "{{$uuid}}") {
-		result = strings.Replace(result, "{{$uuid}}", generateUUID(), 1)
-	}
-
-	// Replace all occurrences of {{$randomInt}}
-	for strings.Contains(result, "{{$randomInt}}") {
-		result = strings.Replace(result, "{{$randomInt}}", generateRandomInt(), 1)
-	}
-
-	// Replace all occurrences of {{$timestamp}}
-	for strings.Contains(result, "{{$timestamp}}") {
-		result = strings.Replace(result, "{{$timestamp}}", fmt.Sprintf("%d", time.Now().UnixMilli()), 1)
-	}
-
-	// Replace all occurrences of {{$iteration}}
-	for strings.Contains(result, "{{$iteration}}") {
-		iteration := atomic.AddInt64(&iterationCounter, 1)
-		result = strings.Replace(result, "{{$iteration}}", fmt.Sprintf("%d", iteration), 1)
-	}
-
-	// Replace all occurrences of {{$randomUser}}
-	for strings.Contains(result, "{{$randomUser}}") {
-		result = strings.Replace(result, "{{$randomUser}}", generateRandomUser(), 1)
-	}
-
-	return result
-}
-
 // generateUUID generates a random UUID v4
 func generateUUID() string {
 	uuid := make([]byte, 16)
@@ -533,30 +1199,29 @@ func generateRandomUser() string {
 	return "user-" + hex.EncodeToString(bytes)
 }
 
-// prepareStepBody prepares the request body with variable substitution
-func prepareStepBody(step *config.StepConfig, variables map[string]string) (string, error) {
-	if step.BodyFile != "" {
-		// For now, just read the file - file handling is done in config package
-		return "", nil
-	}
-
-	if step.Body != nil {
-		var bodyStr string
-		switch v := step.Body.(type) {
-		case string:
-			bodyStr = v
-		default:
-			data, err := json.Marshal(v)
-			if err != nil {
-				return "", fmt.Errorf("failed to marshal body: %w", err)
-			}
-			bodyStr = string(data)
+// prepareStepBody prepares the request body with variable substitution. The body
+// is read or marshalled once per user; only bodies with placeholders are resolved
+// again for every request, and a bodyTemplate is executed for every request.
+func (e *ScenarioExecutor) prepareStepBody(step *config.StepConfig, variables map[string]string) (string, error) {
+	prepared, ok := e.bodies[step]
+	if !ok {
+		if step.BodyTemplate != "" {
+			prepared = newTemplateBody(step.Name, step.BodyTemplate)
+		} else {
+			prepared = newPreparedBody(config.PrepareStepBody(step))
 		}
-		// Resolve variables in body
-		return resolveVariables(bodyStr, variables), nil
+		e.bodies[step] = prepared
 	}
-
-	return "", nil
+	if prepared.err != nil {
+		return "", prepared.err
+	}
+	if prepared.tmpl != nil {
+		return prepared.render(&bodyTemplateData{Vars: variables, Worker: e.vu, functions: e.functions})
+	}
+	if !prepared.templated {
+		return prepared.text, nil
+	}
+	return resolveVariables(prepared.text, variables, e.functions), nil
 }
 
 // copyVariables creates a copy of the variables map
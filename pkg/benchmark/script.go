@@ -0,0 +1,567 @@
+package benchmark
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/benchmarking_go/pkg/config"
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// wrkLib is the Lua side of wrk's scripting API. wrk.format is wrk's own, so
+// requests built by existing wrk scripts come out the same.
+const wrkLib = `
+wrk = {
+   scheme  = "http",
+   host    = "localhost",
+   port    = nil,
+   method  = "GET",
+   path    = "/",
+   headers = {},
+   body    = nil,
+   thread  = nil,
+}
+
+function wrk.format(method, path, headers, body)
+   local method  = method  or wrk.method
+   local path    = path    or wrk.path
+   local headers = headers or wrk.headers
+   local body    = body    or wrk.body
+   local s       = {}
+
+   if not headers["Host"] then
+      headers["Host"] = wrk.headers["Host"]
+   end
+
+   headers["Content-Length"] = body and string.len(body)
+
+   s[1] = string.format("%s %s HTTP/1.1", method, path)
+   for name, value in pairs(headers) do
+      s[#s+1] = string.format("%s: %s", name, value)
+   end
+
+   s[#s+1] = ""
+   s[#s+1] = body or ""
+
+   return table.concat(s, "\r\n")
+end
+`
+
+// maxCopyDepth limits how deeply nested tables passed between Lua states may be
+const maxCopyDepth = 32
+
+// script runs a wrk-style Lua script. As in wrk, where every thread has its
+// own Lua state, every worker has one: setup and done run in a main state,
+// init, delay, request and response in the worker's. Requests go to the
+// configured URL's host; the script shapes the method, path, headers and body.
+type script struct {
+	main    *lua.LState
+	threads []*scriptThread
+	target  *url.URL
+	log     io.Writer
+	err     error // Why the script could not be loaded; fails every request
+
+	hasDelay, hasRequest, hasResponse bool
+}
+
+// scriptThread is the Lua state of one worker
+type scriptThread struct {
+	state  *lua.LState
+	static *scriptRequest // The request wrk.format() built after init, used when there is no request()
+}
+
+// scriptRequest is a request parsed from the raw HTTP the script produced
+type scriptRequest struct {
+	method string
+	uri    string
+	host   string
+	header http.Header
+	body   string
+}
+
+// loadScript loads the configured Lua script for every worker and runs its
+// setup and init functions. If that fails, every request fails with the error.
+func (r *Runner) loadScript() {
+	if r.Config.Settings.Script == "" {
+		return
+	}
+	s, err := r.newScript()
+	if err != nil {
+		s.close()
+		s = &script{err: fmt.Errorf("script: %w", err)}
+		if !r.QuietMode {
+			fmt.Fprintf(r.Log, "[warn] %v\n", s.err)
+		}
+	}
+	r.script = s
+}
+
+// newScript compiles the script and prepares one Lua state per worker
+func (r *Runner) newScript() (*script, error) {
+	path := r.Config.Settings.Script
+	s := &script{log: r.Log}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return s, err
+	}
+	chunk, err := parse.Parse(file, path)
+	file.Close()
+	if err != nil {
+		return s, err
+	}
+	proto, err := lua.Compile(chunk, path)
+	if err != nil {
+		return s, err
+	}
+
+	// The request the script starts from, as wrk.method, wrk.path, wrk.headers and wrk.body
+	reqConfig := &r.Config.Requests[0]
+	s.target, err = url.Parse(config.ResolveVariables(reqConfig.URL, r.Config.Variables))
+	if err != nil {
+		return s, err
+	}
+	body, err := r.requestBody(reqConfig, 0)
+	if err != nil {
+		return s, err
+	}
+	headers := make(map[string]string)
+	for key, value := range r.Config.DefaultHeaders {
+		headers[key] = config.ResolveVariables(value, r.Config.Variables)
+	}
+	for key, value := range reqConfig.Headers {
+		headers[key] = config.ResolveVariables(value, r.Config.Variables)
+	}
+	if body != "" && headers["Content-Type"] == "" {
+		headers["Content-Type"] = "application/json"
+	}
+	headers["Host"] = s.target.Host
+	method := reqConfig.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	newState := func() (*lua.LState, error) {
+		return s.newState(proto, method, headers, body)
+	}
+	if s.main, err = newState(); err != nil {
+		return s, err
+	}
+	s.hasDelay = isLuaFunction(s.main.GetGlobal("delay"))
+	s.hasRequest = isLuaFunction(s.main.GetGlobal("request"))
+	s.hasResponse = isLuaFunction(s.main.GetGlobal("response"))
+
+	// setup(thread) runs in the main state for every thread before any starts
+	setup := s.main.GetGlobal("setup")
+	for i := 0; i < r.Config.WorkerCount(); i++ {
+		state, err := newState()
+		if err != nil {
+			return s, err
+		}
+		s.threads = append(s.threads, &scriptThread{state: state})
+		state.GetGlobal("wrk").(*lua.LTable).RawSetString("thread", s.threadObject(state, state))
+		if isLuaFunction(setup) {
+			if err := s.main.CallByParam(lua.P{Fn: setup, Protect: true}, s.threadObject(s.main, state)); err != nil {
+				return s, luaError(err)
+			}
+		}
+	}
+
+	for _, thread := range s.threads {
+		if err := thread.init(r.Config.Settings.ScriptArgs, s.hasRequest); err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// newState creates a Lua state with the wrk library and runs the script in it
+func (s *script) newState(proto *lua.FunctionProto, method string, headers map[string]string, body string) (*lua.LState, error) {
+	L := lua.NewState()
+	L.SetGlobal("print", L.NewFunction(s.print))
+	if err := L.DoString(wrkLib); err != nil {
+		L.Close()
+		return nil, err
+	}
+
+	wrk := L.GetGlobal("wrk").(*lua.LTable)
+	wrk.RawSetString("scheme", lua.LString(s.target.Scheme))
+	wrk.RawSetString("host", lua.LString(s.target.Hostname()))
+	if port := s.target.Port(); port != "" {
+		wrk.RawSetString("port", lua.LString(port))
+	}
+	wrk.RawSetString("method", lua.LString(method))
+	wrk.RawSetString("path", lua.LString(s.target.RequestURI()))
+	headerTable := L.NewTable()
+	for key, value := range headers {
+		headerTable.RawSetString(key, lua.LString(value))
+	}
+	wrk.RawSetString("headers", headerTable)
+	if body != "" {
+		wrk.RawSetString("body", lua.LString(body))
+	}
+
+	L.Push(L.NewFunctionFromProto(proto))
+	if err := L.PCall(0, lua.MultRet, nil); err != nil {
+		L.Close()
+		return nil, luaError(err)
+	}
+	return L, nil
+}
+
+// threadObject returns the Lua value standing for a thread in state L: wrk's
+// thread with addr, get, set and stop
+func (s *script) threadObject(L *lua.LState, thread *lua.LState) *lua.LTable {
+	object := L.NewTable()
+	object.RawSetString("addr", lua.LString(s.target.Host))
+	L.SetFuncs(object, map[string]lua.LGFunction{
+		"get": func(L *lua.LState) int {
+			value, err := copyLuaValue(L, thread.GetGlobal(L.CheckString(2)), 0)
+			if err != nil {
+				L.RaiseError("thread:get: %v", err)
+			}
+			L.Push(value)
+			return 1
+		},
+		"set": func(L *lua.LState) int {
+			value, err := copyLuaValue(thread, L.Get(3), 0)
+			if err != nil {
+				L.RaiseError("thread:set: %v", err)
+			}
+			thread.SetGlobal(L.CheckString(2), value)
+			return 0
+		},
+		"stop": func(L *lua.LState) int {
+			L.RaiseError("thread:stop() is not supported")
+			return 0
+		},
+	})
+	return object
+}
+
+// init runs the script's init(args) and builds the static request if the script has no request()
+func (t *scriptThread) init(args []string, hasRequest bool) error {
+	if fn := t.state.GetGlobal("init"); isLuaFunction(fn) {
+		argTable := t.state.NewTable()
+		for _, arg := range args {
+			argTable.Append(lua.LString(arg))
+		}
+		if err := t.state.CallByParam(lua.P{Fn: fn, Protect: true}, argTable); err != nil {
+			return luaError(err)
+		}
+	}
+	if hasRequest {
+		return nil
+	}
+
+	wrk := t.state.GetGlobal("wrk").(*lua.LTable)
+	raw, err := t.call(t.state.GetField(wrk, "format"))
+	if err != nil {
+		return err
+	}
+	t.static, err = parseScriptRequest(lua.LVAsString(raw))
+	return err
+}
+
+// call calls a Lua function and returns its result
+func (t *scriptThread) call(fn lua.LValue, args ...lua.LValue) (lua.LValue, error) {
+	if err := t.state.CallByParam(lua.P{Fn: fn, NRet: 1, Protect: true}, args...); err != nil {
+		return nil, luaError(err)
+	}
+	result := t.state.Get(-1)
+	t.state.Pop(1)
+	return result, nil
+}
+
+// thread returns the Lua state of a worker
+func (s *script) thread(worker int) *scriptThread {
+	return s.threads[worker%len(s.threads)]
+}
+
+// wait sleeps for the milliseconds the script's delay() asks for before a
+// worker's next request. It returns false if ctx ends first.
+func (s *script) wait(ctx context.Context, worker int) bool {
+	if s == nil || !s.hasDelay || s.err != nil {
+		return true
+	}
+	thread := s.thread(worker)
+	result, err := thread.call(thread.state.GetGlobal("delay"))
+	if err != nil {
+		return true
+	}
+	ms := float64(lua.LVAsNumber(result))
+	if ms <= 0 {
+		return true
+	}
+	timer := time.NewTimer(time.Duration(ms * float64(time.Millisecond)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// request builds a worker's next request from request() or the static request
+func (s *script) request(ctx context.Context, worker int) (*http.Request, string, error) {
+	if s.err != nil {
+		return nil, "", s.err
+	}
+	thread := s.thread(worker)
+	parsed := thread.static
+	if s.hasRequest {
+		raw, err := thread.call(thread.state.GetGlobal("request"))
+		if err != nil {
+			return nil, "", fmt.Errorf("script: %w", err)
+		}
+		if parsed, err = parseScriptRequest(lua.LVAsString(raw)); err != nil {
+			return nil, "", err
+		}
+	}
+
+	req, err := newBodyRequest(ctx, parsed.method, s.target.Scheme+"://"+s.target.Host+parsed.uri, parsed.body)
+	if err != nil {
+		return nil, "", err
+	}
+	for key, values := range parsed.header {
+		req.Header[key] = values
+	}
+	if parsed.host != "" {
+		req.Host = parsed.host
+	}
+	return req, parsed.body, nil
+}
+
+// needsBody reports whether response bodies must be kept for response()
+func (s *script) needsBody() bool {
+	return s != nil && s.hasResponse
+}
+
+// response calls the script's response(status, headers, body)
+func (s *script) response(worker int, resp *http.Response, body []byte) error {
+	if !s.needsBody() {
+		return nil
+	}
+	L := s.thread(worker).state
+	headers := L.NewTable()
+	for key := range resp.Header {
+		headers.RawSetString(key, lua.LString(resp.Header.Get(key)))
+	}
+	err := L.CallByParam(lua.P{Fn: L.GetGlobal("response"), Protect: true},
+		lua.LNumber(resp.StatusCode), headers, lua.LString(body))
+	if err != nil {
+		return fmt.Errorf("script: %w", luaError(err))
+	}
+	return nil
+}
+
+// done calls the script's done(summary, latency, requests) with the results
+// of the run, then closes the Lua states
+func (s *script) done(stats *Stats) {
+	if s == nil {
+		return
+	}
+	defer s.close()
+	if s.err != nil {
+		return
+	}
+	fn := s.main.GetGlobal("done")
+	if !isLuaFunction(fn) {
+		return
+	}
+
+	L := s.main
+	summary := L.NewTable()
+	summary.RawSetString("duration", lua.LNumber(math.Round(stats.TotalDuration*1e6)))
+	summary.RawSetString("requests", lua.LNumber(stats.TotalRequests))
+	summary.RawSetString("bytes", lua.LNumber(stats.TotalBytes))
+	summary.RawSetString("errors", scriptErrors(L, stats))
+
+	latency := luaDistribution(L, float64(stats.MinResponseTime()), float64(stats.MaxResponseTime()),
+		stats.AverageResponseTime(), stats.StandardDeviation(),
+		func(p float64) float64 { return float64(stats.GetLatencyPercentile(p)) })
+
+	// wrk samples each thread's request rate; here it is each worker's average rate
+	var rates []float64
+	for _, worker := range stats.Workers() {
+		if stats.TotalDuration > 0 {
+			rates = append(rates, float64(worker.Requests)/stats.TotalDuration)
+		}
+	}
+	sort.Float64s(rates)
+	requests := luaDistribution(L, sortedPercentileOf(rates, 0), sortedPercentileOf(rates, 100),
+		meanOf(rates), stdDevOf(rates),
+		func(p float64) float64 { return sortedPercentileOf(rates, p) })
+
+	if err := L.CallByParam(lua.P{Fn: fn, Protect: true}, summary, latency, requests); err != nil {
+		fmt.Fprintf(s.log, "[warn] script: %v\n", luaError(err))
+	}
+}
+
+// close closes the Lua states
+func (s *script) close() {
+	if s.main != nil {
+		s.main.Close()
+	}
+	for _, thread := range s.threads {
+		thread.state.Close()
+	}
+}
+
+// print writes the values passed to Lua's print to the runner's log
+func (s *script) print(L *lua.LState) int {
+	values := make([]string, L.GetTop())
+	for i := range values {
+		values[i] = L.ToStringMeta(L.Get(i + 1)).String()
+	}
+	fmt.Fprintln(s.log, strings.Join(values, "\t"))
+	return 0
+}
+
+// scriptErrors counts failures in wrk's categories: connect, read, write,
+// status (responses with an unexpected status) and timeout
+func scriptErrors(L *lua.LState, stats *Stats) *lua.LTable {
+	var connect, read, write, timeout int
+	for category, count := range stats.GetErrors() {
+		switch category {
+		case "Connection refused", "DNS lookup failed", "Network unreachable", "TLS handshake error", "Certificate error":
+			connect += count
+		case "Connection reset by peer", "Connection closed (EOF)":
+			read += count
+		case "Broken pipe":
+			write += count
+		case "Request timeout", "I/O timeout":
+			timeout += count
+		}
+	}
+	errors := L.NewTable()
+	errors.RawSetString("connect", lua.LNumber(connect))
+	errors.RawSetString("read", lua.LNumber(read))
+	errors.RawSetString("write", lua.LNumber(write))
+	errors.RawSetString("status", lua.LNumber(stats.FailureCount-stats.OtherCount))
+	errors.RawSetString("timeout", lua.LNumber(timeout))
+	return errors
+}
+
+// luaDistribution returns wrk's statistics object: min, max, mean, stdev and percentile(p)
+func luaDistribution(L *lua.LState, min, max, mean, stdev float64, percentile func(float64) float64) *lua.LTable {
+	object := L.NewTable()
+	object.RawSetString("min", lua.LNumber(min))
+	object.RawSetString("max", lua.LNumber(max))
+	object.RawSetString("mean", lua.LNumber(mean))
+	object.RawSetString("stdev", lua.LNumber(stdev))
+	object.RawSetString("percentile", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LNumber(percentile(float64(L.CheckNumber(2)))))
+		return 1
+	}))
+	return object
+}
+
+// parseScriptRequest parses the raw HTTP request a script returned
+func parseScriptRequest(raw string) (*scriptRequest, error) {
+	req, err := http.ReadRequest(bufio.NewReader(strings.NewReader(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("script: invalid request: %w", err)
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("script: invalid request body: %w", err)
+	}
+	req.Header.Del("Content-Length") // Set from the body when sending
+	return &scriptRequest{
+		method: req.Method,
+		uri:    req.RequestURI,
+		host:   req.Host,
+		header: req.Header,
+		body:   string(body),
+	}, nil
+}
+
+// copyLuaValue copies a value into another Lua state. Only nil, booleans,
+// numbers, strings and tables of them can be copied.
+func copyLuaValue(L *lua.LState, value lua.LValue, depth int) (lua.LValue, error) {
+	switch v := value.(type) {
+	case *lua.LNilType, lua.LBool, lua.LNumber, lua.LString:
+		return v, nil
+	case *lua.LTable:
+		if depth >= maxCopyDepth {
+			return nil, fmt.Errorf("table nested too deeply")
+		}
+		copied := L.NewTable()
+		var err error
+		v.ForEach(func(key, val lua.LValue) {
+			if err != nil {
+				return
+			}
+			var k, c lua.LValue
+			if k, err = copyLuaValue(L, key, depth+1); err != nil {
+				return
+			}
+			if c, err = copyLuaValue(L, val, depth+1); err != nil {
+				return
+			}
+			copied.RawSet(k, c)
+		})
+		return copied, err
+	default:
+		return nil, fmt.Errorf("cannot pass a %s between threads", value.Type())
+	}
+}
+
+// isLuaFunction reports whether a value is a function
+func isLuaFunction(value lua.LValue) bool {
+	return value.Type() == lua.LTFunction
+}
+
+// luaError drops the stack trace from a Lua error, keeping its message on one line
+func luaError(err error) error {
+	if apiErr, ok := err.(*lua.ApiError); ok {
+		return fmt.Errorf("%s", apiErr.Object.String())
+	}
+	return err
+}
+
+// sortedPercentileOf returns a percentile of sorted values (0 if there are none)
+func sortedPercentileOf(sorted []float64, percentile float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(math.Ceil(percentile/100*float64(len(sorted)))) - 1
+	return sorted[max(0, min(len(sorted)-1, index))]
+}
+
+// meanOf returns the mean of values (0 if there are none)
+func meanOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// stdDevOf returns the standard deviation of values
+func stdDevOf(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := meanOf(values)
+	var sum float64
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
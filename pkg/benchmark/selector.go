@@ -3,18 +3,27 @@ package benchmark
 
 import (
 	"context"
+	"math"
 	"math/rand"
+	"sync/atomic"
 	"time"
 
 	"github.com/benchmarking_go/pkg/config"
 )
 
-// RateLimiter controls the rate of requests using a token bucket algorithm
+// rateLimiterBurst is how far (in time) requests may catch up after falling
+// behind the schedule, which absorbs scheduling jitter without bursts
+const rateLimiterBurst = 10 * time.Millisecond
+
+// RateLimiter spaces requests evenly at a fixed rate. Each request reserves the
+// next slot of an absolute schedule (one slot every 1/rate seconds) and waits
+// until it, so the rate holds without a refill goroutine and without the
+// resolution limits of a ticker, also at hundreds of thousands per second.
 type RateLimiter struct {
-	rate   int           // requests per second
-	tokens chan struct{} // token bucket
-	done   chan struct{}
-	ticker *time.Ticker
+	start    time.Time
+	interval float64       // Nanoseconds between slots
+	burst    float64       // Nanoseconds the schedule may lag behind now
+	next     atomic.Uint64 // Float64 bits of the next free slot, in nanoseconds since start
 }
 
 // NewRateLimiter creates a new rate limiter
@@ -22,68 +31,70 @@ func NewRateLimiter(ratePerSecond int) *RateLimiter {
 	if ratePerSecond <= 0 {
 		return nil
 	}
-
-	rl := &RateLimiter{
-		rate:   ratePerSecond,
-		tokens: make(chan struct{}, ratePerSecond*2), // Buffer for burst
-		done:   make(chan struct{}),
+	interval := float64(time.Second) / float64(ratePerSecond)
+	return &RateLimiter{
+		start:    time.Now(),
+		interval: interval,
+		burst:    max(interval, float64(rateLimiterBurst)),
 	}
+}
 
-	// Fill initial tokens
-	for i := 0; i < ratePerSecond; i++ {
-		select {
-		case rl.tokens <- struct{}{}:
-		default:
+// reserve takes the next slot and returns when it is due, in nanoseconds since
+// start. Without wait it only takes a slot that is already due.
+func (rl *RateLimiter) reserve(wait bool) (float64, bool) {
+	for {
+		now := float64(time.Since(rl.start))
+		current := rl.next.Load()
+		slot := max(math.Float64frombits(current), now-rl.burst)
+		if !wait && slot > now {
+			return 0, false
 		}
-	}
-
-	// Start token refill goroutine
-	interval := time.Second / time.Duration(ratePerSecond)
-	if interval < time.Millisecond {
-		interval = time.Millisecond
-	}
-	rl.ticker = time.NewTicker(interval)
-
-	go func() {
-		for {
-			select {
-			case <-rl.done:
-				return
-			case <-rl.ticker.C:
-				select {
-				case rl.tokens <- struct{}{}:
-				default:
-					// Token bucket full, discard
-				}
-			}
+		if rl.next.CompareAndSwap(current, math.Float64bits(slot+rl.interval)) {
+			return slot, true
 		}
-	}()
-
-	return rl
+	}
 }
 
-// Wait waits for a token to become available
+// Wait waits for the next slot. It returns false if ctx is done first.
 func (rl *RateLimiter) Wait(ctx context.Context) bool {
 	if rl == nil {
 		return true
 	}
+	slot, _ := rl.reserve(true)
+	delay := time.Duration(slot) - time.Since(rl.start)
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
 	select {
 	case <-ctx.Done():
 		return false
-	case <-rl.tokens:
+	case <-timer.C:
 		return true
 	}
 }
 
-// Stop stops the rate limiter
-func (rl *RateLimiter) Stop() {
+// TryAcquire takes a slot if one is due without waiting
+func (rl *RateLimiter) TryAcquire() bool {
 	if rl == nil {
-		return
+		return true
 	}
-	close(rl.done)
-	rl.ticker.Stop()
+	_, ok := rl.reserve(false)
+	return ok
 }
 
+// NamedRateLimiters holds per-request or per-step rate limiters keyed by name
+type NamedRateLimiters map[string]*RateLimiter
+
+// Get returns the limiter for a name, or nil (unlimited) if it has none
+func (l NamedRateLimiters) Get(name string) *RateLimiter {
+	return l[name]
+}
+
+// maxLimitedPicks is how many times a rate-limited request is re-picked before waiting
+const maxLimitedPicks = 10
+
 // WeightedRequestSelector selects requests based on their weights
 type WeightedRequestSelector struct {
 	requests          []config.RequestConfig
@@ -123,3 +134,42 @@ func (s *WeightedRequestSelector) Select() *config.RequestConfig {
 	return &s.requests[len(s.requests)-1]
 }
 
+// WeightedScenarioSelector selects scenarios based on their weights
+type WeightedScenarioSelector struct {
+	scenarios         []config.ScenarioConfig
+	totalWeight       int
+	cumulativeWeights []int
+}
+
+// NewWeightedScenarioSelector creates a new weighted scenario selector
+func NewWeightedScenarioSelector(scenarios []config.ScenarioConfig) *WeightedScenarioSelector {
+	selector := &WeightedScenarioSelector{
+		scenarios:         scenarios,
+		cumulativeWeights: make([]int, len(scenarios)),
+	}
+
+	cumulative := 0
+	for i, sc := range scenarios {
+		cumulative += sc.GetWeight()
+		selector.cumulativeWeights[i] = cumulative
+	}
+	selector.totalWeight = cumulative
+
+	return selector
+}
+
+// Select returns a random scenario based on weights. Scenarios with a weight
+// of 0 are never picked.
+func (s *WeightedScenarioSelector) Select() *config.ScenarioConfig {
+	if len(s.scenarios) == 1 {
+		return &s.scenarios[0]
+	}
+
+	r := rand.Intn(s.totalWeight)
+	for i, cumWeight := range s.cumulativeWeights {
+		if r < cumWeight {
+			return &s.scenarios[i]
+		}
+	}
+	return &s.scenarios[len(s.scenarios)-1]
+}
@@ -0,0 +1,191 @@
+package benchmark
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxServerTimingMetrics bounds the metric names tracked, in case a server
+// puts IDs or other unbounded values into them
+const maxServerTimingMetrics = 64
+
+// serverTimingEntry is one metric of a Server-Timing header
+type serverTimingEntry struct {
+	name        string
+	description string
+	durMicros   int64
+}
+
+// parseServerTiming parses Server-Timing header values such as
+// `db;dur=53, cache;desc="Cache Read";dur=23.2`. Durations are given in
+// milliseconds; metrics without one are skipped.
+func parseServerTiming(values []string) []serverTimingEntry {
+	var entries []serverTimingEntry
+	for _, value := range values {
+		for _, metric := range splitQuoted(value, ',') {
+			params := splitQuoted(metric, ';')
+			name := strings.TrimSpace(params[0])
+			if name == "" {
+				continue
+			}
+			entry := serverTimingEntry{name: name, durMicros: -1}
+			for _, param := range params[1:] {
+				key, val, _ := strings.Cut(param, "=")
+				val = strings.Trim(strings.TrimSpace(val), `"`)
+				switch strings.ToLower(strings.TrimSpace(key)) {
+				case "dur":
+					if ms, err := strconv.ParseFloat(val, 64); err == nil && ms >= 0 {
+						entry.durMicros = int64(ms * 1000)
+					}
+				case "desc":
+					entry.description = val
+				}
+			}
+			if entry.durMicros >= 0 {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+// splitQuoted splits s at sep, except inside double-quoted strings
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"' && (i == 0 || s[i-1] != '\\'):
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// serverTimingTracker aggregates the durations of each Server-Timing metric
+type serverTimingTracker struct {
+	mu      sync.Mutex
+	metrics map[string]*serverTimingMetric
+}
+
+// serverTimingMetric is the distribution of one named Server-Timing metric
+type serverTimingMetric struct {
+	description string
+	series      latencySeries
+}
+
+// metric returns the named metric, creating it unless the bound is reached.
+// The caller must hold st.mu.
+func (st *serverTimingTracker) metric(name, description string, useHdr bool) *serverTimingMetric {
+	metric := st.metrics[name]
+	if metric == nil {
+		if len(st.metrics) >= maxServerTimingMetrics {
+			return nil
+		}
+		if st.metrics == nil {
+			st.metrics = make(map[string]*serverTimingMetric)
+		}
+		metric = &serverTimingMetric{series: newLatencySeries(useHdr)}
+		st.metrics[name] = metric
+	}
+	if metric.description == "" {
+		metric.description = description
+	}
+	return metric
+}
+
+// RecordServerTiming records the metrics of a response's Server-Timing headers
+func (s *Stats) RecordServerTiming(header http.Header) {
+	values := header.Values("Server-Timing")
+	if len(values) == 0 {
+		return
+	}
+	entries := parseServerTiming(values)
+	if len(entries) == 0 {
+		return
+	}
+
+	st := &s.serverTiming
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for _, entry := range entries {
+		if metric := st.metric(entry.name, entry.description, s.useHdr); metric != nil {
+			metric.series.record(entry.durMicros)
+		}
+	}
+}
+
+// ServerTimingMetric summarizes the durations a server reported for one component
+type ServerTimingMetric struct {
+	Name        string
+	Description string
+	Latency     LatencySummary
+}
+
+// ServerTiming returns the durations of each Server-Timing metric with the
+// given percentiles, ordered by name (nil when no response had the header)
+func (s *Stats) ServerTiming(percentiles []float64) []ServerTimingMetric {
+	st := &s.serverTiming
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	var metrics []ServerTimingMetric
+	for name, metric := range st.metrics {
+		metrics = append(metrics, ServerTimingMetric{Name: name, Description: metric.description, Latency: metric.series.summary(percentiles)})
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+	return metrics
+}
+
+// ServerTimingSnapshot is a serializable copy of one Server-Timing metric
+type ServerTimingSnapshot struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Latency     *LatencySeriesSnapshot `json:"latency"`
+}
+
+// snapshot copies the tracker
+func (st *serverTimingTracker) snapshot() []ServerTimingSnapshot {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	var snaps []ServerTimingSnapshot
+	for name, metric := range st.metrics {
+		snaps = append(snaps, ServerTimingSnapshot{Name: name, Description: metric.description, Latency: metric.series.snapshot()})
+	}
+	return snaps
+}
+
+// merge adds snapshots taken in another process
+func (st *serverTimingTracker) merge(snaps []ServerTimingSnapshot, useHdr bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for _, snap := range snaps {
+		if metric := st.metric(snap.Name, snap.Description, useHdr); metric != nil {
+			metric.series.merge(snap.Latency)
+		}
+	}
+}
+
+// downsample lowers the raw samples kept per metric to capacity, reporting
+// whether samples were dropped
+func (st *serverTimingTracker) downsample(capacity int) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	dropped := false
+	for _, metric := range st.metrics {
+		if metric.series.samples.shrink(capacity) {
+			dropped = true
+		}
+	}
+	return dropped
+}
@@ -0,0 +1,144 @@
+package benchmark
+
+import (
+	"math"
+	"math/bits"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+)
+
+// maxLatencyShards caps the shard count, since every shard has its own histogram
+const maxLatencyShards = 32
+
+// latencyShard collects response times recorded since the last flush
+type latencyShard struct {
+	mutex    sync.Mutex
+	min      int64
+	outcomes [2]shardLatencies // Indexed by outcome (success, failure)
+
+	_ [64]byte // Keeps neighbouring shards off the same cache line
+}
+
+// shardLatencies holds the response times of one outcome in a shard
+type shardLatencies struct {
+	sum     int64
+	count   int64
+	max     int64
+	hdr     *HdrStats // nil in legacy mode
+	samples []float64
+}
+
+// latencyShards spreads response time recording over several independently
+// locked shards, so concurrent workers rarely wait for each other. Readers flush
+// the shards into the Stats totals before using them.
+type latencyShards struct {
+	shards []latencyShard
+	mask   uint32
+}
+
+// newLatencyShards creates one shard per CPU, rounded up to a power of two
+func newLatencyShards(useHdr bool) *latencyShards {
+	count := 1 << bits.Len(uint(runtime.GOMAXPROCS(0)-1))
+	count = min(count, maxLatencyShards)
+
+	ls := &latencyShards{shards: make([]latencyShard, count), mask: uint32(count - 1)}
+	for i := range ls.shards {
+		ls.shards[i].min = math.MaxInt64
+		if useHdr {
+			for outcome := range ls.shards[i].outcomes {
+				if hdr, err := NewHdrStats(1, 60000000, 3); err == nil {
+					ls.shards[i].outcomes[outcome].hdr = hdr
+				}
+			}
+		}
+	}
+	return ls
+}
+
+// record adds a response time of the given outcome to a shard
+func (ls *latencyShards) record(responseTimeMicros int64, outcome int) {
+	shard := ls.acquire()
+	shard.add(responseTimeMicros, outcome)
+	shard.mutex.Unlock()
+}
+
+// recordBatch adds several response times of the same outcome to one shard
+func (ls *latencyShards) recordBatch(responseTimesMicros []int64, outcome int) {
+	shard := ls.acquire()
+	for _, value := range responseTimesMicros {
+		shard.add(value, outcome)
+	}
+	shard.mutex.Unlock()
+}
+
+// acquire locks and returns a shard that isn't in use, starting at a random one
+func (ls *latencyShards) acquire() *latencyShard {
+	i := rand.Uint32()
+	shard := &ls.shards[i&ls.mask]
+	for attempt := uint32(1); !shard.mutex.TryLock(); attempt++ {
+		if attempt > ls.mask {
+			// Every shard is busy; wait for one
+			shard.mutex.Lock()
+			break
+		}
+		shard = &ls.shards[(i+attempt)&ls.mask]
+	}
+	return shard
+}
+
+// add records a response time. The caller must hold shard.mutex.
+func (shard *latencyShard) add(responseTimeMicros int64, outcome int) {
+	if responseTimeMicros < shard.min {
+		shard.min = responseTimeMicros
+	}
+	part := &shard.outcomes[outcome]
+	part.sum += responseTimeMicros
+	part.count++
+	if responseTimeMicros > part.max {
+		part.max = responseTimeMicros
+	}
+	if part.hdr != nil {
+		part.hdr.RecordValue(responseTimeMicros)
+	} else {
+		part.samples = append(part.samples, float64(responseTimeMicros))
+	}
+}
+
+// flushLatencies moves the response times recorded by workers and in the shards
+// into the totals and the per-outcome series. The caller must hold s.mutex.
+func (s *Stats) flushLatencies() {
+	s.flushWorkers()
+	for i := range s.shards.shards {
+		shard := &s.shards.shards[i]
+		shard.mutex.Lock()
+		for outcome := range shard.outcomes {
+			part := &shard.outcomes[outcome]
+			if part.count == 0 {
+				continue
+			}
+			series := &s.outcomes[outcome]
+			s.totalResponseTime += part.sum
+			s.responseCount += part.count
+			s.maxResponseTime = max(s.maxResponseTime, part.max)
+			series.total += part.sum
+			series.count += part.count
+			series.max = max(series.max, part.max)
+			if s.hdrStats != nil && part.hdr != nil {
+				s.hdrStats.Merge(part.hdr)
+				series.hdr.Merge(part.hdr)
+				part.hdr.Reset()
+			} else {
+				for _, value := range part.samples {
+					s.responseTimes.add(value)
+					series.samples.add(value)
+				}
+				part.samples = part.samples[:0]
+			}
+			part.sum, part.count, part.max = 0, 0, 0
+		}
+		s.minResponseTime = min(s.minResponseTime, shard.min)
+		shard.min = math.MaxInt64
+		shard.mutex.Unlock()
+	}
+}
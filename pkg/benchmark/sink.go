@@ -0,0 +1,103 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// sinkInterval is how often metrics sinks get a snapshot of the totals
+const sinkInterval = time.Second
+
+// MetricsSink receives the measurements of a run as the runner records them.
+// Export targets (see pkg/metrics) implement it, so adding one needs no
+// changes to the runner or the report writers.
+type MetricsSink interface {
+	// RecordRequest is called for every request (or scenario step) that
+	// completed or failed, concurrently from all users
+	RecordRequest(m RequestMetric)
+
+	// RecordError is called with the message of every failed request,
+	// concurrently from all users
+	RecordError(name, message string)
+
+	// Snapshot is called every second with the totals so far, and once more
+	// with final set when the run has ended. Calls never overlap.
+	Snapshot(snap *StatsSnapshot, final bool) error
+}
+
+// RequestMetric is one request as seen by a metrics sink
+type RequestMetric struct {
+	Name    string // Request or scenario step name
+	Method  string
+	Status  int // 0 when no response was received
+	Latency time.Duration
+	Success bool
+
+	RequestID string // Value of the injected request ID header ("" without requestIdHeader)
+	Error     string // Why the request failed ("" on success)
+}
+
+// metricsSinks fans the measurements out to every sink
+type metricsSinks []MetricsSink
+
+// request records a request in every sink, and its error if it failed
+func (s metricsSinks) request(m RequestMetric, errMsg string) {
+	if !m.Success {
+		m.Error = errMsg
+	}
+	for _, sink := range s {
+		sink.RecordRequest(m)
+		if !m.Success && errMsg != "" {
+			sink.RecordError(m.Name, errMsg)
+		}
+	}
+}
+
+// startSinks sends the sinks a snapshot every second until the returned
+// function is called; finishSinks sends the final one
+func (r *Runner) startSinks(ctx context.Context) func() {
+	if len(r.Sinks) == 0 {
+		return func() {}
+	}
+	r.sinkFailed = make([]bool, len(r.Sinks))
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(sinkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			r.snapshotSinks(r.interimSnapshot(), false)
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// finishSinks sends the sinks the final results
+func (r *Runner) finishSinks() {
+	if len(r.Sinks) > 0 {
+		r.snapshotSinks(r.Stats.Snapshot(), true)
+	}
+}
+
+// snapshotSinks hands a snapshot to every sink, warning once per failing sink
+func (r *Runner) snapshotSinks(snap *StatsSnapshot, final bool) {
+	for i, sink := range r.Sinks {
+		if err := sink.Snapshot(snap, final); err != nil && !r.sinkFailed[i] {
+			r.sinkFailed[i] = true
+			if !r.QuietMode {
+				fmt.Fprintf(r.Log, "\n[warn] metrics sink: %v\n", err)
+			}
+		}
+	}
+}
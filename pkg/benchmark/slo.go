@@ -0,0 +1,89 @@
+package benchmark
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// sloTracker counts good requests against a service level objective
+type sloTracker struct {
+	target  float64
+	latency int64 // Latency objective in microseconds (0 = any latency is good)
+	period  time.Duration
+	good    int64
+}
+
+// SLOReport describes how the benchmark run measured up against the SLO
+type SLOReport struct {
+	Target          float64       // Required share of good requests
+	Latency         int64         // Latency objective in microseconds (0 = none)
+	Period          time.Duration // Period the error budget covers
+	TotalRequests   int64
+	GoodRequests    int64
+	BadRequests     int64   // Failed requests plus successful ones slower than the objective
+	Compliance      float64 // Share of good requests
+	BurnRate        float64 // Bad share relative to the allowed bad share (1 = budget lasts exactly the period)
+	BudgetConsumed  float64 // Share of the period's error budget used by this run at its request rate
+	BudgetRemaining float64 // 1 - BudgetConsumed (negative when exhausted)
+}
+
+// SetSLO enables SLO tracking. An invalid objective leaves tracking disabled;
+// it is reported when the configuration is validated.
+func (s *Stats) SetSLO(slo *config.SLOConfig) {
+	if slo == nil {
+		return
+	}
+	latency, period, err := slo.Objective()
+	if err != nil {
+		return
+	}
+	s.slo = &sloTracker{target: slo.Target, latency: latency, period: period}
+}
+
+// recordGood counts a successful request if it also met the latency objective
+func (s *Stats) recordGood(responseTimeMicros int64) {
+	if s.slo == nil {
+		return
+	}
+	if s.slo.latency == 0 || responseTimeMicros <= s.slo.latency {
+		atomic.AddInt64(&s.slo.good, 1)
+	}
+}
+
+// SLOReport computes compliance, burn rate and error budget usage, or nil when no SLO is configured
+func (s *Stats) SLOReport() *SLOReport {
+	if s.slo == nil {
+		return nil
+	}
+	total := atomic.LoadInt64(&s.SuccessCount) + atomic.LoadInt64(&s.FailureCount)
+	return s.slo.report(total, atomic.LoadInt64(&s.slo.good), s.TotalDuration)
+}
+
+// report evaluates the objective for a number of requests made over the given seconds
+func (t *sloTracker) report(total, good int64, seconds float64) *SLOReport {
+	report := &SLOReport{
+		Target:          t.target,
+		Latency:         t.latency,
+		Period:          t.period,
+		TotalRequests:   total,
+		GoodRequests:    good,
+		BadRequests:     total - good,
+		Compliance:      1,
+		BudgetRemaining: 1,
+	}
+	if total == 0 {
+		return report
+	}
+
+	badShare := float64(report.BadRequests) / float64(total)
+	report.Compliance = 1 - badShare
+	report.BurnRate = badShare / (1 - t.target)
+	if seconds > 0 {
+		// Burning at this rate for the run's duration uses this share of the period's budget
+		report.BudgetConsumed = report.BurnRate * seconds / t.period.Seconds()
+	}
+	report.BudgetRemaining = 1 - report.BudgetConsumed
+	return report
+}
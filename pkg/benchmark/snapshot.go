@@ -0,0 +1,281 @@
+package benchmark
+
+import (
+	"sync/atomic"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// StatsSnapshot is a serializable copy of Stats, used to combine the results
+// of several benchmark processes into one report
+type StatsSnapshot struct {
+	TotalRequests  int64    `json:"totalRequests"`
+	SuccessCount   int64    `json:"successCount"`
+	FailureCount   int64    `json:"failureCount"`
+	SkippedCount   int64    `json:"skippedCount,omitempty"`
+	CancelledCount int64    `json:"cancelledCount,omitempty"`
+	BodyMismatches int64    `json:"bodyMismatches,omitempty"`
+	TotalDuration  float64  `json:"totalDuration"`
+	TotalBytes     int64    `json:"totalBytes"`
+	StatusCodes    [6]int64 `json:"statusCodes"` // 1xx, 2xx, 3xx, 4xx, 5xx, other
+
+	TotalResponseTime int64                    `json:"totalResponseTime"`
+	ResponseCount     int64                    `json:"responseCount"`
+	MinResponseTime   int64                    `json:"minResponseTime"`
+	MaxResponseTime   int64                    `json:"maxResponseTime"`
+	MaxRequestRate    float64                  `json:"maxRequestRate"`
+	Histogram         *hdrhistogram.Snapshot   `json:"histogram,omitempty"` // HdrHistogram mode
+	Samples           []float64                `json:"samples,omitempty"`   // Legacy mode
+	Outcomes          []*LatencySeriesSnapshot `json:"outcomes,omitempty"`  // Success, failure
+	TTFB              *LatencySeriesSnapshot   `json:"ttfb,omitempty"`
+	Errors            map[string]int           `json:"errors,omitempty"`
+	ErrorSamples      map[string][]ErrorSample `json:"errorSamples,omitempty"`
+	SLOGood           int64                    `json:"sloGood,omitempty"`
+
+	Requests     []*RequestStatsSnapshot `json:"requests,omitempty"`
+	Transactions []*RequestStatsSnapshot `json:"transactions,omitempty"`
+	Scenarios    []*RequestStatsSnapshot `json:"scenarios,omitempty"`
+	Polls        []*RequestStatsSnapshot `json:"polls,omitempty"`
+	Addresses    []*RequestStatsSnapshot `json:"addresses,omitempty"`
+
+	Cache     *CacheSnapshot    `json:"cache,omitempty"` // With cache tracking
+	Redirects *RedirectSnapshot `json:"redirects,omitempty"`
+
+	ServerTiming []ServerTimingSnapshot `json:"serverTiming,omitempty"`
+}
+
+// RequestStatsSnapshot is a serializable copy of RequestStats
+type RequestStatsSnapshot struct {
+	Name         string                 `json:"name"`
+	URL          string                 `json:"url,omitempty"`
+	Method       string                 `json:"method,omitempty"`
+	RequestCount int64                  `json:"requestCount"`
+	SuccessCount int64                  `json:"successCount"`
+	FailureCount int64                  `json:"failureCount"`
+	TotalLatency int64                  `json:"totalLatency"`
+	Errors       map[string]int         `json:"errors,omitempty"`
+	Histogram    *hdrhistogram.Snapshot `json:"histogram,omitempty"`
+	Samples      []float64              `json:"samples,omitempty"`
+}
+
+// Snapshot returns a serializable copy of the stats
+func (s *Stats) Snapshot() *StatsSnapshot {
+	snap := &StatsSnapshot{
+		TotalRequests:  atomic.LoadInt64(&s.TotalRequests),
+		SuccessCount:   atomic.LoadInt64(&s.SuccessCount),
+		FailureCount:   atomic.LoadInt64(&s.FailureCount),
+		SkippedCount:   atomic.LoadInt64(&s.SkippedCount),
+		CancelledCount: atomic.LoadInt64(&s.CancelledCount),
+		BodyMismatches: atomic.LoadInt64(&s.BodyMismatches),
+		TotalDuration:  s.TotalDuration,
+		TotalBytes:     atomic.LoadInt64(&s.TotalBytes),
+		StatusCodes: [6]int64{
+			atomic.LoadInt64(&s.Http1xxCount),
+			atomic.LoadInt64(&s.Http2xxCount),
+			atomic.LoadInt64(&s.Http3xxCount),
+			atomic.LoadInt64(&s.Http4xxCount),
+			atomic.LoadInt64(&s.Http5xxCount),
+			atomic.LoadInt64(&s.OtherCount),
+		},
+	}
+	if s.slo != nil {
+		snap.SLOGood = atomic.LoadInt64(&s.slo.good)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.flushLatencies()
+
+	snap.TotalResponseTime = s.totalResponseTime
+	snap.ResponseCount = s.responseCount
+	snap.MinResponseTime = s.minResponseTime
+	snap.MaxResponseTime = s.maxResponseTime
+	snap.MaxRequestRate = s.maxRequestRate
+	if s.useHdr && s.hdrStats != nil {
+		snap.Histogram = s.hdrStats.Export()
+	} else {
+		snap.Samples = append([]float64(nil), s.responseTimes.values()...)
+	}
+	for outcome := range s.outcomes {
+		snap.Outcomes = append(snap.Outcomes, s.outcomes[outcome].snapshot())
+	}
+	snap.TTFB = s.snapshotTTFB()
+	snap.Errors = make(map[string]int, len(s.errors))
+	for msg, count := range s.errors {
+		snap.Errors[msg] = count
+	}
+	snap.ErrorSamples = s.copyErrorSamples()
+	if s.cache != nil {
+		snap.Cache = s.cache.snapshot()
+	}
+	snap.Redirects = s.redirects.snapshot()
+	snap.ServerTiming = s.serverTiming.snapshot()
+
+	snap.Requests = snapshotGroup(s.RequestStats)
+	snap.Transactions = snapshotGroup(s.TransactionStats)
+	snap.Scenarios = snapshotGroup(s.ScenarioStats)
+	snap.Polls = snapshotGroup(s.PollStats)
+	snap.Addresses = snapshotGroup(s.AddressStats)
+	return snap
+}
+
+// snapshotGroup copies one of the grouped stats maps. The caller must hold s.mutex.
+func snapshotGroup(group map[string]*RequestStats) []*RequestStatsSnapshot {
+	snaps := make([]*RequestStatsSnapshot, 0, len(group))
+	for _, rs := range group {
+		rs.Mutex.Lock()
+		snap := &RequestStatsSnapshot{
+			Name:         rs.Name,
+			URL:          rs.URL,
+			Method:       rs.Method,
+			RequestCount: rs.RequestCount,
+			SuccessCount: rs.SuccessCount,
+			FailureCount: rs.FailureCount,
+			TotalLatency: rs.TotalLatency,
+			Errors:       make(map[string]int, len(rs.Errors)),
+		}
+		for msg, count := range rs.Errors {
+			snap.Errors[msg] = count
+		}
+		if rs.hdrStats != nil {
+			snap.Histogram = rs.hdrStats.Export()
+		} else {
+			snap.Samples = append([]float64(nil), rs.responseTimes.values()...)
+		}
+		rs.Mutex.Unlock()
+		snaps = append(snaps, snap)
+	}
+	return snaps
+}
+
+// Merge adds a snapshot taken in another process to these stats. Counters and
+// latency distributions are summed; the duration is the longest of the runs,
+// since the processes ran side by side.
+func (s *Stats) Merge(snap *StatsSnapshot) {
+	atomic.AddInt64(&s.TotalRequests, snap.TotalRequests)
+	atomic.AddInt64(&s.SuccessCount, snap.SuccessCount)
+	atomic.AddInt64(&s.FailureCount, snap.FailureCount)
+	atomic.AddInt64(&s.SkippedCount, snap.SkippedCount)
+	atomic.AddInt64(&s.CancelledCount, snap.CancelledCount)
+	atomic.AddInt64(&s.BodyMismatches, snap.BodyMismatches)
+	atomic.AddInt64(&s.TotalBytes, snap.TotalBytes)
+	atomic.AddInt64(&s.Http1xxCount, snap.StatusCodes[0])
+	atomic.AddInt64(&s.Http2xxCount, snap.StatusCodes[1])
+	atomic.AddInt64(&s.Http3xxCount, snap.StatusCodes[2])
+	atomic.AddInt64(&s.Http4xxCount, snap.StatusCodes[3])
+	atomic.AddInt64(&s.Http5xxCount, snap.StatusCodes[4])
+	atomic.AddInt64(&s.OtherCount, snap.StatusCodes[5])
+	if s.slo != nil {
+		atomic.AddInt64(&s.slo.good, snap.SLOGood)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if snap.TotalDuration > s.TotalDuration {
+		s.TotalDuration = snap.TotalDuration
+	}
+	if s.TotalDuration > 0 {
+		s.RequestsPerSecond = float64(s.TotalRequests) / s.TotalDuration
+	}
+
+	s.totalResponseTime += snap.TotalResponseTime
+	s.responseCount += snap.ResponseCount
+	if snap.ResponseCount > 0 && snap.MinResponseTime < s.minResponseTime {
+		s.minResponseTime = snap.MinResponseTime
+	}
+	if snap.MaxResponseTime > s.maxResponseTime {
+		s.maxResponseTime = snap.MaxResponseTime
+	}
+	// The processes peak at roughly the same time, so their peaks add up
+	s.maxRequestRate += snap.MaxRequestRate
+	mergeLatencies(s.hdrStats, &s.responseTimes, snap.Histogram, snap.Samples)
+	for outcome, series := range snap.Outcomes {
+		if outcome < len(s.outcomes) {
+			s.outcomes[outcome].merge(series)
+		}
+	}
+	s.mergeTTFB(snap.TTFB)
+	for msg, count := range snap.Errors {
+		s.errors[msg] += count
+	}
+	s.mergeErrorSamples(snap.ErrorSamples)
+	if snap.Cache != nil {
+		if s.cache == nil {
+			s.cache = newCacheTracker(s.useHdr)
+		}
+		s.cache.merge(snap.Cache)
+	}
+	if snap.Redirects != nil {
+		s.redirects.merge(snap.Redirects)
+	}
+	s.serverTiming.merge(snap.ServerTiming, s.useHdr)
+
+	s.mergeGroup(s.RequestStats, snap.Requests)
+	s.mergeGroup(s.TransactionStats, snap.Transactions)
+	s.mergeGroup(s.ScenarioStats, snap.Scenarios)
+	s.mergeGroup(s.PollStats, snap.Polls)
+	s.mergeGroup(s.AddressStats, snap.Addresses)
+}
+
+// mergeGroup adds snapshots to one of the grouped stats maps. The caller must hold s.mutex.
+func (s *Stats) mergeGroup(group map[string]*RequestStats, snaps []*RequestStatsSnapshot) {
+	for _, snap := range snaps {
+		rs, ok := group[snap.Name]
+		if !ok {
+			rs = s.newRequestStats(snap.Name, snap.URL, snap.Method)
+			group[snap.Name] = rs
+		}
+		rs.Mutex.Lock()
+		rs.RequestCount += snap.RequestCount
+		rs.SuccessCount += snap.SuccessCount
+		rs.FailureCount += snap.FailureCount
+		rs.TotalLatency += snap.TotalLatency
+		for msg, count := range snap.Errors {
+			rs.Errors[msg] += count
+		}
+		mergeLatencies(rs.hdrStats, &rs.responseTimes, snap.Histogram, snap.Samples)
+		rs.Mutex.Unlock()
+	}
+}
+
+// mergeLatencies adds a latency distribution to a histogram (HdrHistogram mode)
+// or a sample reservoir (legacy mode). Every process of a run uses the same mode.
+func mergeLatencies(hdr *HdrStats, samples *sampleReservoir, histogram *hdrhistogram.Snapshot, raw []float64) {
+	if hdr == nil {
+		for _, value := range raw {
+			samples.add(value)
+		}
+		return
+	}
+	if histogram != nil {
+		hdr.MergeSnapshot(histogram)
+	}
+	for _, value := range raw {
+		hdr.RecordValue(int64(value))
+	}
+}
+
+// InterimStats returns a copy of a running benchmark's stats, including the
+// totals that are otherwise only set once it finishes
+func (r *Runner) InterimStats() *Stats {
+	stats := NewStatsWithOptions(r.Stats.useHdr, r.Stats.ShowHistogram)
+	stats.SetSLO(r.Config.SLO)
+	stats.SetErrorSamples(r.Config.Settings.ErrorSamples)
+	stats.SetHistogramBuckets(r.Stats.histogramBounds, r.Stats.logHistogram)
+	stats.Merge(r.interimSnapshot())
+	return stats
+}
+
+// interimSnapshot snapshots the stats of a running benchmark with its request total and duration so far
+func (r *Runner) interimSnapshot() *StatsSnapshot {
+	snap := r.Stats.Snapshot()
+	snap.TotalRequests = snap.SuccessCount + snap.FailureCount
+	if r.Config.IsScenarioMode() {
+		snap.TotalRequests = atomic.LoadInt64(&r.executedSteps)
+	}
+	if started := r.startedAt.Load(); started != nil {
+		snap.TotalDuration = r.activeElapsed(*started).Seconds()
+	}
+	return snap
+}
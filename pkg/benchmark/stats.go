@@ -6,6 +6,7 @@ import (
 	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // Stats tracks statistics for the benchmark
@@ -13,6 +14,9 @@ type Stats struct {
 	TotalRequests     int64
 	SuccessCount      int64
 	FailureCount      int64
+	SkippedCount      int64 // Scenario steps skipped by their `when` condition
+	CancelledCount    int64 // Requests cut off by the end of the run before completing
+	BodyMismatches    int64 // Responses whose body failed a size or checksum check (also counted as failures)
 	TotalDuration     float64
 	RequestsPerSecond float64
 
@@ -33,25 +37,83 @@ type Stats struct {
 	minResponseTime   int64
 	maxResponseTime   int64
 
-	// For standard deviation calculation (legacy mode)
-	responseTimes []float64
+	// Raw samples for percentiles and standard deviation (legacy mode)
+	responseTimes sampleReservoir
+	sampleLimit   int // Samples kept per series after downsampling for the memory budget (0 = not downsampled)
 
 	// HdrHistogram for memory-efficient statistics
 	hdrStats    *HdrStats
 	useHdr      bool
 
+	// Response times not yet flushed into the fields above
+	shards *latencyShards
+
+	// Latencies of successful and failed requests, indexed by outcome
+	outcomes [2]latencySeries
+
+	// Times to first byte, with their own lock since workers flush into them directly
+	ttfbMutex sync.Mutex
+	ttfb      latencySeries
+
+	// Per-worker stats by worker index
+	workers map[int]*WorkerStats
+
+	// Streams sent over each pooled HTTP/2 connection (nil without a pool)
+	connections []ConnectionSummary
+
+	// Connections opened over IPv4 and over IPv6, and how many were closed
+	families AddressFamilies
+	churn    ConnectionChurn
+
+	// Lock-free lookup of RequestStats entries, so the hot path skips s.mutex
+	requestIndex sync.Map
+
 	// For request rate statistics
 	requestRates   []float64
 	maxRequestRate float64
 
 	// For error tracking
-	errors map[string]int
+	errors       map[string]int
+	recentErrors []RecentError // Newest errors, oldest first (bounded by maxRecentErrors)
+
+	// Examples of failed requests per error message (see SetErrorSamples)
+	errorSamples     map[string][]ErrorSample
+	errorSampleLimit int
 
 	// Per-request stats (for multi-URL benchmarks)
 	RequestStats map[string]*RequestStats
 
+	// Per-transaction stats (scenario step groups timed together)
+	TransactionStats map[string]*RequestStats
+
+	// Per-scenario iteration stats (when several weighted scenarios run)
+	ScenarioStats map[string]*RequestStats
+
+	// Per-poll-step total wait times (success = condition met in time)
+	PollStats map[string]*RequestStats
+
+	// Per-server-IP request stats (with roundRobinIPs)
+	AddressStats map[string]*RequestStats
+
+	// Service level objective tracking (nil when no SLO is configured)
+	slo *sloTracker
+
+	// Cache hit/miss tracking (nil unless enabled with SetCacheTracking)
+	cache *cacheTracker
+
+	// Redirect chain lengths and hop latencies
+	redirects redirectTracker
+
+	// Durations of the metrics servers report in Server-Timing headers
+	serverTiming serverTimingTracker
+
 	// Histogram display option
 	ShowHistogram bool
+
+	// Upper bounds of the histogram buckets in microseconds (nil for the
+	// defaults), or log-scale buckets fitted to the latencies
+	histogramBounds []int64
+	logHistogram    bool
 }
 
 // RequestStats tracks statistics for individual request types
@@ -65,6 +127,31 @@ type RequestStats struct {
 	TotalLatency int64
 	Errors       map[string]int // Per-endpoint error tracking
 	Mutex        sync.Mutex
+
+	// Latency distribution for per-request percentiles (HdrHistogram or raw samples)
+	hdrStats      *HdrStats
+	responseTimes sampleReservoir
+}
+
+// RecordLatency records a latency sample for percentile queries.
+// The caller must hold rs.Mutex.
+func (rs *RequestStats) RecordLatency(responseTimeMicros int64) {
+	if rs.hdrStats != nil {
+		rs.hdrStats.RecordValue(responseTimeMicros)
+		return
+	}
+	rs.responseTimes.add(float64(responseTimeMicros))
+}
+
+// LatencyPercentile returns the latency percentile for this request type
+func (rs *RequestStats) LatencyPercentile(percentile float64) int64 {
+	rs.Mutex.Lock()
+	defer rs.Mutex.Unlock()
+
+	if rs.hdrStats != nil {
+		return rs.hdrStats.Percentile(percentile)
+	}
+	return rs.responseTimes.percentile(percentile)
 }
 
 // NewStats creates a new Stats instance
@@ -77,13 +164,17 @@ func NewStats() *Stats {
 // showHistogram: display ASCII histogram in output
 func NewStatsWithOptions(useHdr bool, showHistogram bool) *Stats {
 	stats := &Stats{
-		minResponseTime: math.MaxInt64,
-		errors:          make(map[string]int),
-		responseTimes:   make([]float64, 0),
-		requestRates:    make([]float64, 0),
-		RequestStats:    make(map[string]*RequestStats),
-		useHdr:          useHdr,
-		ShowHistogram:   showHistogram,
+		minResponseTime:  math.MaxInt64,
+		errors:           make(map[string]int),
+		responseTimes:    sampleReservoir{windowed: true},
+		requestRates:     make([]float64, 0),
+		RequestStats:     make(map[string]*RequestStats),
+		TransactionStats: make(map[string]*RequestStats),
+		ScenarioStats:    make(map[string]*RequestStats),
+		PollStats:        make(map[string]*RequestStats),
+		AddressStats:     make(map[string]*RequestStats),
+		useHdr:           useHdr,
+		ShowHistogram:    showHistogram,
 	}
 
 	if useHdr {
@@ -97,49 +188,107 @@ func NewStatsWithOptions(useHdr bool, showHistogram bool) *Stats {
 			stats.useHdr = false
 		}
 	}
+	stats.shards = newLatencyShards(stats.useHdr)
+	for outcome := range stats.outcomes {
+		stats.outcomes[outcome] = newLatencySeries(stats.useHdr)
+	}
+	stats.ttfb = newLatencySeries(stats.useHdr)
+	stats.redirects.hopLatency = newLatencySeries(stats.useHdr)
 
 	return stats
 }
 
 // GetOrCreateRequestStats gets or creates stats for a specific request
 func (s *Stats) GetOrCreateRequestStats(name, url, method string) *RequestStats {
+	if stats, ok := s.requestIndex.Load(name); ok {
+		return stats.(*RequestStats)
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	if stats, ok := s.RequestStats[name]; ok {
-		return stats
+	stats, ok := s.RequestStats[name]
+	if !ok {
+		stats = s.newRequestStats(name, url, method)
+		s.RequestStats[name] = stats
 	}
+	s.requestIndex.Store(name, stats)
+	return stats
+}
 
+// RecordTransaction records one completed transaction with its combined duration
+func (s *Stats) RecordTransaction(name string, durationMicros int64, success bool) {
+	s.recordIteration(s.TransactionStats, name, durationMicros, success)
+}
+
+// RecordScenario records one completed iteration of a named scenario
+func (s *Stats) RecordScenario(name string, durationMicros int64, success bool) {
+	s.recordIteration(s.ScenarioStats, name, durationMicros, success)
+}
+
+// RecordPoll records the total wait of one poll step and whether its condition was met
+func (s *Stats) RecordPoll(name string, waitMicros int64, success bool) {
+	s.recordIteration(s.PollStats, name, waitMicros, success)
+}
+
+// RecordAddress records one request sent to a server IP
+func (s *Stats) RecordAddress(ip string, latencyMicros int64, success bool) {
+	s.recordIteration(s.AddressStats, ip, latencyMicros, success)
+}
+
+// recordIteration records a duration and outcome in one of the grouped stats maps
+func (s *Stats) recordIteration(group map[string]*RequestStats, name string, durationMicros int64, success bool) {
+	s.mutex.Lock()
+	rs, ok := group[name]
+	if !ok {
+		rs = s.newRequestStats(name, "", "")
+		group[name] = rs
+	}
+	s.mutex.Unlock()
+
+	rs.Mutex.Lock()
+	defer rs.Mutex.Unlock()
+	rs.RequestCount++
+	rs.TotalLatency += durationMicros
+	rs.RecordLatency(durationMicros)
+	if success {
+		rs.SuccessCount++
+	} else {
+		rs.FailureCount++
+	}
+}
+
+// newRequestStats creates an empty RequestStats with a latency histogram when enabled
+func (s *Stats) newRequestStats(name, url, method string) *RequestStats {
 	stats := &RequestStats{
 		Name:   name,
 		URL:    url,
 		Method: method,
 		Errors: make(map[string]int),
 	}
-	s.RequestStats[name] = stats
+	stats.responseTimes.capacity = s.sampleLimit
+	if s.useHdr {
+		if hdr, err := NewHdrStats(1, 60000000, 3); err == nil {
+			stats.hdrStats = hdr
+		}
+	}
 	return stats
 }
 
-// AddResponseTime adds a response time measurement
-func (s *Stats) AddResponseTime(responseTimeMicros int64) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+// AddResponseTime adds a response time measurement of a successful or failed
+// request. It records into a shard without taking s.mutex; readers flush the
+// shards first.
+func (s *Stats) AddResponseTime(responseTimeMicros int64, success bool) {
+	s.shards.record(responseTimeMicros, outcomeOf(success))
+}
 
-	s.totalResponseTime += responseTimeMicros
-	s.responseCount++
-	if responseTimeMicros < s.minResponseTime {
-		s.minResponseTime = responseTimeMicros
-	}
-	if responseTimeMicros > s.maxResponseTime {
-		s.maxResponseTime = responseTimeMicros
-	}
+// maxRecentErrors bounds the error log kept for live displays
+const maxRecentErrors = 20
 
-	// Use HdrHistogram if available
-	if s.useHdr && s.hdrStats != nil {
-		s.hdrStats.RecordValue(responseTimeMicros)
-	} else {
-		s.responseTimes = append(s.responseTimes, float64(responseTimeMicros))
-	}
+// RecentError is one entry of the live error log
+type RecentError struct {
+	Time    time.Time
+	Message string
 }
 
 // AddError tracks an error
@@ -148,6 +297,19 @@ func (s *Stats) AddError(errorMessage string) {
 	defer s.mutex.Unlock()
 
 	s.errors[errorMessage]++
+	if len(s.recentErrors) == maxRecentErrors {
+		s.recentErrors = append(s.recentErrors[:0], s.recentErrors[1:]...)
+	}
+	s.recentErrors = append(s.recentErrors, RecentError{Time: time.Now(), Message: errorMessage})
+}
+
+// RecentErrors returns up to n of the newest errors, oldest first
+func (s *Stats) RecentErrors(n int) []RecentError {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	start := max(0, len(s.recentErrors)-n)
+	return append([]RecentError(nil), s.recentErrors[start:]...)
 }
 
 // GetErrors returns a copy of the error map
@@ -163,27 +325,37 @@ func (s *Stats) GetErrors() map[string]int {
 }
 
 // GetLatencyPercentile calculates the percentile of response times
-func (s *Stats) GetLatencyPercentile(percentile int) int64 {
+func (s *Stats) GetLatencyPercentile(percentile float64) int64 {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	s.flushLatencies()
 
 	// Use HdrHistogram if available
 	if s.useHdr && s.hdrStats != nil {
-		return s.hdrStats.Percentile(float64(percentile))
+		return s.hdrStats.Percentile(percentile)
 	}
 
 	// Fallback to legacy method
-	if len(s.responseTimes) == 0 {
-		return 0
-	}
+	return s.responseTimes.percentile(percentile)
+}
 
+// percentileOf calculates a percentile from raw samples
+func percentileOf(samples []float64, percentile float64) int64 {
 	// Create a copy and sort
-	times := make([]float64, len(s.responseTimes))
-	copy(times, s.responseTimes)
+	times := make([]float64, len(samples))
+	copy(times, samples)
 	sort.Float64s(times)
+	return sortedPercentile(times, percentile)
+}
+
+// sortedPercentile returns a percentile of sorted samples
+func sortedPercentile(times []float64, percentile float64) int64 {
+	if len(times) == 0 {
+		return 0
+	}
 
 	// Calculate the index for the percentile
-	index := int(math.Ceil(float64(percentile)/100.0*float64(len(times)))) - 1
+	index := int(math.Ceil(percentile/100.0*float64(len(times)))) - 1
 
 	// Ensure index is within bounds
 	index = int(math.Max(0, math.Min(float64(len(times)-1), float64(index))))
@@ -195,6 +367,7 @@ func (s *Stats) GetLatencyPercentile(percentile int) int64 {
 func (s *Stats) AverageResponseTime() float64 {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	s.flushLatencies()
 
 	if s.responseCount > 0 {
 		return float64(s.totalResponseTime) / float64(s.responseCount)
@@ -206,6 +379,7 @@ func (s *Stats) AverageResponseTime() float64 {
 func (s *Stats) MinResponseTime() int64 {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	s.flushLatencies()
 
 	if s.minResponseTime == math.MaxInt64 {
 		return 0
@@ -217,6 +391,7 @@ func (s *Stats) MinResponseTime() int64 {
 func (s *Stats) MaxResponseTime() int64 {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	s.flushLatencies()
 
 	return s.maxResponseTime
 }
@@ -225,6 +400,7 @@ func (s *Stats) MaxResponseTime() int64 {
 func (s *Stats) StandardDeviation() float64 {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	s.flushLatencies()
 
 	// Use HdrHistogram if available
 	if s.useHdr && s.hdrStats != nil {
@@ -232,17 +408,18 @@ func (s *Stats) StandardDeviation() float64 {
 	}
 
 	// Fallback to legacy method
-	if len(s.responseTimes) <= 1 {
+	samples := s.responseTimes.values()
+	if len(samples) <= 1 {
 		return 0
 	}
 
 	avg := float64(s.totalResponseTime) / float64(s.responseCount)
 	var sum float64
-	for _, time := range s.responseTimes {
+	for _, time := range samples {
 		sum += math.Pow(time-avg, 2)
 	}
 
-	return math.Sqrt(sum / float64(len(s.responseTimes)-1))
+	return math.Sqrt(sum / float64(len(samples)-1))
 }
 
 // ThroughputMBps calculates the throughput in MB/s
@@ -329,9 +506,25 @@ func (s *Stats) IncrementFailure() {
 	atomic.AddInt64(&s.FailureCount, 1)
 }
 
-// Lock locks the stats mutex
+// IncrementCancelled counts a request cancelled because the benchmark stopped
+func (s *Stats) IncrementCancelled() {
+	atomic.AddInt64(&s.CancelledCount, 1)
+}
+
+// IncrementBodyMismatch counts a response whose body failed a size or checksum check
+func (s *Stats) IncrementBodyMismatch() {
+	atomic.AddInt64(&s.BodyMismatches, 1)
+}
+
+// IncrementSkipped increments the skipped scenario step counter
+func (s *Stats) IncrementSkipped() {
+	atomic.AddInt64(&s.SkippedCount, 1)
+}
+
+// Lock locks the stats mutex, with all recorded response times flushed
 func (s *Stats) Lock() {
 	s.mutex.Lock()
+	s.flushLatencies()
 }
 
 // Unlock unlocks the stats mutex
@@ -343,24 +536,35 @@ func (s *Stats) Unlock() {
 func (s *Stats) GetHistogramBuckets() []HistogramBucket {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	s.flushLatencies()
 
 	if s.useHdr && s.hdrStats != nil {
-		return s.hdrStats.GetHistogramBuckets()
+		return s.hdrStats.GetCustomBuckets(s.histogramBoundsFor(s.hdrStats.Min(), s.hdrStats.Max()))
 	}
 
 	// Fallback: create buckets from raw data
-	if len(s.responseTimes) == 0 {
+	samples := s.responseTimes.values()
+	if len(samples) == 0 {
 		return nil
 	}
 
-	boundaries := []int64{1000, 5000, 10000, 25000, 50000, 100000, 250000, 500000, 1000000, 2500000, 5000000, 10000000}
+	min, max := int64(samples[0]), int64(samples[0])
+	for _, t := range samples {
+		if int64(t) < min {
+			min = int64(t)
+		}
+		if int64(t) > max {
+			max = int64(t)
+		}
+	}
+	boundaries := s.histogramBoundsFor(min, max)
 	buckets := make([]HistogramBucket, 0)
-	totalCount := int64(len(s.responseTimes))
+	totalCount := int64(len(samples))
 
 	var prevBoundary int64 = 0
 	for _, boundary := range boundaries {
 		count := int64(0)
-		for _, t := range s.responseTimes {
+		for _, t := range samples {
 			if int64(t) >= prevBoundary && int64(t) < boundary {
 				count++
 			}
@@ -378,7 +582,7 @@ func (s *Stats) GetHistogramBuckets() []HistogramBucket {
 
 	// Overflow bucket
 	overflowCount := int64(0)
-	for _, t := range s.responseTimes {
+	for _, t := range samples {
 		if int64(t) >= prevBoundary {
 			overflowCount++
 		}
@@ -406,3 +610,98 @@ func (s *Stats) IsUsingHdr() bool {
 	return s.useHdr && s.hdrStats != nil
 }
 
+// downsample lowers the raw latency samples kept per series (overall, for
+// every request, transaction, scenario and poll, and the times to first byte,
+// redirect hops and Server-Timing metrics) to capacity. It reports whether samples were dropped.
+// HdrHistogram mode keeps no raw samples.
+func (s *Stats) downsample(capacity int) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.useHdr {
+		return false
+	}
+	s.flushLatencies()
+	s.sampleLimit = capacity
+
+	dropped := s.responseTimes.shrink(capacity)
+	for outcome := range s.outcomes {
+		if s.outcomes[outcome].samples.shrink(capacity) {
+			dropped = true
+		}
+	}
+	s.ttfbMutex.Lock()
+	if s.ttfb.samples.shrink(capacity) {
+		dropped = true
+	}
+	s.ttfbMutex.Unlock()
+	s.redirects.mu.Lock()
+	if s.redirects.hopLatency.samples.shrink(capacity) {
+		dropped = true
+	}
+	s.redirects.mu.Unlock()
+	if s.serverTiming.downsample(capacity) {
+		dropped = true
+	}
+	for _, group := range []map[string]*RequestStats{s.RequestStats, s.TransactionStats, s.ScenarioStats, s.PollStats, s.AddressStats} {
+		for _, rs := range group {
+			rs.Mutex.Lock()
+			if rs.responseTimes.shrink(capacity) {
+				dropped = true
+			}
+			rs.Mutex.Unlock()
+		}
+	}
+	return dropped
+}
+
+// SetConnections records the traffic of the pooled HTTP/2 connections
+func (s *Stats) SetConnections(connections []ConnectionSummary) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.connections = connections
+}
+
+// Connections returns the traffic of each pooled HTTP/2 connection
+func (s *Stats) Connections() []ConnectionSummary {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.connections
+}
+
+// SetAddressFamilies records how many connections were opened over each IP version
+func (s *Stats) SetAddressFamilies(families AddressFamilies) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.families = families
+}
+
+// AddressFamilies returns how many connections were opened over each IP version
+func (s *Stats) AddressFamilies() AddressFamilies {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.families
+}
+
+// SetConnectionChurn records how many connections were opened and closed
+func (s *Stats) SetConnectionChurn(churn ConnectionChurn) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.churn = churn
+}
+
+// ConnectionChurn returns how many connections were opened and closed
+func (s *Stats) ConnectionChurn() ConnectionChurn {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.churn
+}
+
+// SampleLimit returns how many raw latency samples per series were kept after
+// downsampling for the memory budget, or 0 if all samples up to the usual
+// bound were kept
+func (s *Stats) SampleLimit() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.sampleLimit
+}
+
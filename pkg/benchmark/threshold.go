@@ -4,6 +4,7 @@ package benchmark
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"github.com/benchmarking_go/pkg/config"
 )
@@ -23,6 +24,54 @@ type ThresholdResults struct {
 	Passed  bool // Overall pass/fail
 }
 
+// thresholdMetrics holds the measurements a set of thresholds is evaluated against.
+// It lets the same checks run over the global stats and over a single step's stats.
+type thresholdMetrics struct {
+	successCount      int64
+	failureCount      int64
+	avgLatency        float64
+	requestsPerSecond float64
+	percentile        func(percentile float64) int64
+	bytesTracked      bool // Byte counts are only kept for the benchmark as a whole
+	totalBytes        int64
+	throughputMBps    float64
+	slo               *SLOReport // Only available for the benchmark as a whole when an SLO is configured
+}
+
+// globalMetrics builds threshold metrics from the overall benchmark stats
+func globalMetrics(stats *Stats) *thresholdMetrics {
+	return &thresholdMetrics{
+		successCount:      stats.SuccessCount,
+		failureCount:      stats.FailureCount,
+		avgLatency:        stats.AverageResponseTime(),
+		requestsPerSecond: stats.RequestsPerSecond,
+		percentile:        stats.GetLatencyPercentile,
+		bytesTracked:      true,
+		totalBytes:        atomic.LoadInt64(&stats.TotalBytes),
+		throughputMBps:    stats.ThroughputMBps(),
+		slo:               stats.SLOReport(),
+	}
+}
+
+// requestMetrics builds threshold metrics from a single request's (or step's) stats
+func requestMetrics(rs *RequestStats, totalDuration float64) *thresholdMetrics {
+	rs.Mutex.Lock()
+	defer rs.Mutex.Unlock()
+
+	metrics := &thresholdMetrics{
+		successCount: rs.SuccessCount,
+		failureCount: rs.FailureCount,
+		percentile:   rs.LatencyPercentile,
+	}
+	if rs.RequestCount > 0 {
+		metrics.avgLatency = float64(rs.TotalLatency) / float64(rs.RequestCount)
+	}
+	if totalDuration > 0 {
+		metrics.requestsPerSecond = float64(rs.RequestCount) / totalDuration
+	}
+	return metrics
+}
+
 // EvaluateThresholds checks if the benchmark results meet the defined thresholds
 func EvaluateThresholds(stats *Stats, thresholds *config.ThresholdConfig) (*ThresholdResults, error) {
 	results := &ThresholdResults{
@@ -34,102 +83,149 @@ func EvaluateThresholds(stats *Stats, thresholds *config.ThresholdConfig) (*Thre
 		return results, nil
 	}
 
-	// Check error rate
-	if thresholds.MaxErrorRate > 0 {
-		result := checkErrorRate(stats, thresholds.MaxErrorRate)
-		results.Results = append(results.Results, result)
-		if !result.Passed {
-			results.Passed = false
-		}
+	if err := results.evaluate(globalMetrics(stats), thresholds, ""); err != nil {
+		return nil, err
 	}
+	return results, nil
+}
 
-	// Check average latency
-	if thresholds.MaxAvgLatency != "" {
-		result, err := checkAvgLatency(stats, thresholds.MaxAvgLatency)
-		if err != nil {
-			return nil, err
-		}
-		results.Results = append(results.Results, result)
-		if !result.Passed {
-			results.Passed = false
-		}
+// EvaluateConfigThresholds checks the global thresholds and every per-step and per-transaction threshold block
+func EvaluateConfigThresholds(stats *Stats, cfg *config.Config) (*ThresholdResults, error) {
+	results, err := EvaluateThresholds(stats, &cfg.Thresholds)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check P50 latency
-	if thresholds.MaxP50Latency != "" {
-		result, err := checkPercentileLatency(stats, 50, thresholds.MaxP50Latency)
-		if err != nil {
-			return nil, err
+	steps := cfg.AllSteps()
+	for i := range steps {
+		step := &steps[i]
+		if step.Thresholds == nil || !step.Thresholds.HasThresholds() {
+			continue
 		}
-		results.Results = append(results.Results, result)
-		if !result.Passed {
-			results.Passed = false
+
+		stats.Lock()
+		rs, ok := stats.RequestStats[step.Name]
+		stats.Unlock()
+		if !ok {
+			// The step never ran (e.g. skipped by its condition) - nothing to check
+			continue
+		}
+
+		if err := results.evaluate(requestMetrics(rs, stats.TotalDuration), step.Thresholds, step.Name); err != nil {
+			return nil, fmt.Errorf("step %s: %w", step.Name, err)
 		}
 	}
 
-	// Check P75 latency
-	if thresholds.MaxP75Latency != "" {
-		result, err := checkPercentileLatency(stats, 75, thresholds.MaxP75Latency)
-		if err != nil {
-			return nil, err
+	for i := range cfg.Transactions {
+		txn := &cfg.Transactions[i]
+		if txn.Thresholds == nil || !txn.Thresholds.HasThresholds() {
+			continue
 		}
-		results.Results = append(results.Results, result)
-		if !result.Passed {
-			results.Passed = false
+
+		stats.Lock()
+		ts, ok := stats.TransactionStats[txn.Name]
+		stats.Unlock()
+		if !ok {
+			continue
+		}
+
+		if err := results.evaluate(requestMetrics(ts, stats.TotalDuration), txn.Thresholds, txn.Name); err != nil {
+			return nil, fmt.Errorf("transaction %s: %w", txn.Name, err)
 		}
 	}
 
-	// Check P90 latency
-	if thresholds.MaxP90Latency != "" {
-		result, err := checkPercentileLatency(stats, 90, thresholds.MaxP90Latency)
+	return results, nil
+}
+
+// evaluate runs every configured check against the metrics and appends the results.
+// A non-empty scope (e.g. a step name) prefixes the result names and messages.
+func (r *ThresholdResults) evaluate(metrics *thresholdMetrics, thresholds *config.ThresholdConfig, scope string) error {
+	var checks []ThresholdResult
+
+	// Check error rate
+	if thresholds.MaxErrorRate > 0 {
+		checks = append(checks, checkErrorRate(metrics, thresholds.MaxErrorRate))
+	}
+
+	// Check average latency
+	if thresholds.MaxAvgLatency != "" {
+		result, err := checkAvgLatency(metrics, thresholds.MaxAvgLatency)
 		if err != nil {
-			return nil, err
-		}
-		results.Results = append(results.Results, result)
-		if !result.Passed {
-			results.Passed = false
+			return err
 		}
+		checks = append(checks, result)
 	}
 
-	// Check P99 latency
-	if thresholds.MaxP99Latency != "" {
-		result, err := checkPercentileLatency(stats, 99, thresholds.MaxP99Latency)
-		if err != nil {
-			return nil, err
+	// Check percentile latencies
+	percentileThresholds := []struct {
+		percentile float64
+		maxLatency string
+	}{
+		{50, thresholds.MaxP50Latency},
+		{75, thresholds.MaxP75Latency},
+		{90, thresholds.MaxP90Latency},
+		{95, thresholds.MaxP95Latency},
+		{99, thresholds.MaxP99Latency},
+		{99.9, thresholds.MaxP999Latency},
+	}
+	for _, pt := range percentileThresholds {
+		if pt.maxLatency == "" {
+			continue
 		}
-		results.Results = append(results.Results, result)
-		if !result.Passed {
-			results.Passed = false
+		result, err := checkPercentileLatency(metrics, pt.percentile, pt.maxLatency)
+		if err != nil {
+			return err
 		}
+		checks = append(checks, result)
 	}
 
 	// Check minimum requests per second
 	if thresholds.MinRequestsPerSecond > 0 {
-		result := checkMinRPS(stats, thresholds.MinRequestsPerSecond)
-		results.Results = append(results.Results, result)
-		if !result.Passed {
-			results.Passed = false
-		}
+		checks = append(checks, checkMinRPS(metrics, thresholds.MinRequestsPerSecond))
 	}
 
 	// Check maximum requests per second
 	if thresholds.MaxRequestsPerSecond > 0 {
-		result := checkMaxRPS(stats, thresholds.MaxRequestsPerSecond)
-		results.Results = append(results.Results, result)
-		if !result.Passed {
-			results.Passed = false
+		checks = append(checks, checkMaxRPS(metrics, thresholds.MaxRequestsPerSecond))
+	}
+
+	// Check data-plane throughput (skipped for steps and transactions, which have no byte counts)
+	if thresholds.MinThroughputMBps > 0 && metrics.bytesTracked {
+		checks = append(checks, checkMinThroughput(metrics, thresholds.MinThroughputMBps))
+	}
+	if thresholds.MinTotalBytes > 0 && metrics.bytesTracked {
+		checks = append(checks, checkMinTotalBytes(metrics, thresholds.MinTotalBytes))
+	}
+
+	// Check SLO error budget (skipped where no SLO report is available)
+	if metrics.slo != nil {
+		if thresholds.MinSLOCompliance > 0 {
+			checks = append(checks, checkSLOCompliance(metrics, thresholds.MinSLOCompliance))
+		}
+		if thresholds.MaxBurnRate > 0 {
+			checks = append(checks, checkBurnRate(metrics, thresholds.MaxBurnRate))
+		}
+		if thresholds.MaxBudgetConsumed > 0 {
+			checks = append(checks, checkBudgetConsumed(metrics, thresholds.MaxBudgetConsumed))
 		}
 	}
 
-	return results, nil
+	for _, result := range checks {
+		if scope != "" {
+			result.Name = fmt.Sprintf("[%s] %s", scope, result.Name)
+			result.Message = strings.Replace(result.Message, ": ", fmt.Sprintf(": [%s] ", scope), 1)
+		}
+		r.add(result)
+	}
+	return nil
 }
 
 // checkErrorRate checks if error rate is within threshold
-func checkErrorRate(stats *Stats, maxErrorRate float64) ThresholdResult {
-	totalRequests := stats.SuccessCount + stats.FailureCount
+func checkErrorRate(metrics *thresholdMetrics, maxErrorRate float64) ThresholdResult {
+	totalRequests := metrics.successCount + metrics.failureCount
 	var actualErrorRate float64
 	if totalRequests > 0 {
-		actualErrorRate = float64(stats.FailureCount) / float64(totalRequests)
+		actualErrorRate = float64(metrics.failureCount) / float64(totalRequests)
 	}
 
 	passed := actualErrorRate <= maxErrorRate
@@ -143,13 +239,13 @@ func checkErrorRate(stats *Stats, maxErrorRate float64) ThresholdResult {
 }
 
 // checkAvgLatency checks if average latency is within threshold
-func checkAvgLatency(stats *Stats, maxLatencyStr string) (ThresholdResult, error) {
+func checkAvgLatency(metrics *thresholdMetrics, maxLatencyStr string) (ThresholdResult, error) {
 	maxLatencyMicros, err := config.ParseLatency(maxLatencyStr)
 	if err != nil {
 		return ThresholdResult{}, err
 	}
 
-	avgLatencyMicros := stats.AverageResponseTime()
+	avgLatencyMicros := metrics.avgLatency
 	passed := int64(avgLatencyMicros) <= maxLatencyMicros
 
 	return ThresholdResult{
@@ -162,28 +258,28 @@ func checkAvgLatency(stats *Stats, maxLatencyStr string) (ThresholdResult, error
 }
 
 // checkPercentileLatency checks if a specific percentile latency is within threshold
-func checkPercentileLatency(stats *Stats, percentile int, maxLatencyStr string) (ThresholdResult, error) {
+func checkPercentileLatency(metrics *thresholdMetrics, percentile float64, maxLatencyStr string) (ThresholdResult, error) {
 	maxLatencyMicros, err := config.ParseLatency(maxLatencyStr)
 	if err != nil {
 		return ThresholdResult{}, err
 	}
 
-	actualLatencyMicros := stats.GetLatencyPercentile(percentile)
+	actualLatencyMicros := metrics.percentile(percentile)
 	passed := actualLatencyMicros <= maxLatencyMicros
 
-	name := fmt.Sprintf("Max P%d Latency", percentile)
+	name := fmt.Sprintf("Max P%g Latency", percentile)
 	return ThresholdResult{
 		Name:     name,
 		Passed:   passed,
 		Expected: fmt.Sprintf("≤ %s", maxLatencyStr),
 		Actual:   formatMicroseconds(actualLatencyMicros),
-		Message:  formatResultMessage(fmt.Sprintf("P%d Latency", percentile), passed, formatMicroseconds(actualLatencyMicros), "≤ "+maxLatencyStr),
+		Message:  formatResultMessage(fmt.Sprintf("P%g Latency", percentile), passed, formatMicroseconds(actualLatencyMicros), "≤ "+maxLatencyStr),
 	}, nil
 }
 
 // checkMinRPS checks if requests per second meets minimum threshold
-func checkMinRPS(stats *Stats, minRPS float64) ThresholdResult {
-	actualRPS := stats.RequestsPerSecond
+func checkMinRPS(metrics *thresholdMetrics, minRPS float64) ThresholdResult {
+	actualRPS := metrics.requestsPerSecond
 	passed := actualRPS >= minRPS
 
 	return ThresholdResult{
@@ -196,8 +292,8 @@ func checkMinRPS(stats *Stats, minRPS float64) ThresholdResult {
 }
 
 // checkMaxRPS checks if requests per second is within maximum threshold
-func checkMaxRPS(stats *Stats, maxRPS float64) ThresholdResult {
-	actualRPS := stats.RequestsPerSecond
+func checkMaxRPS(metrics *thresholdMetrics, maxRPS float64) ThresholdResult {
+	actualRPS := metrics.requestsPerSecond
 	passed := actualRPS <= maxRPS
 
 	return ThresholdResult{
@@ -209,6 +305,89 @@ func checkMaxRPS(stats *Stats, maxRPS float64) ThresholdResult {
 	}
 }
 
+// checkMinThroughput checks if response throughput meets the minimum in MB/s
+func checkMinThroughput(metrics *thresholdMetrics, minMBps float64) ThresholdResult {
+	actual := metrics.throughputMBps
+	passed := actual >= minMBps
+
+	return ThresholdResult{
+		Name:     "Min Throughput",
+		Passed:   passed,
+		Expected: fmt.Sprintf("≥ %.2fMB/s", minMBps),
+		Actual:   fmt.Sprintf("%.2fMB/s", actual),
+		Message:  formatResultMessage("Throughput", passed, fmt.Sprintf("%.2fMB/s", actual), fmt.Sprintf("≥ %.2fMB/s", minMBps)),
+	}
+}
+
+// checkMinTotalBytes checks if the total response bytes meet the minimum
+func checkMinTotalBytes(metrics *thresholdMetrics, minBytes int64) ThresholdResult {
+	actual := metrics.totalBytes
+	passed := actual >= minBytes
+
+	return ThresholdResult{
+		Name:     "Min Total Bytes",
+		Passed:   passed,
+		Expected: fmt.Sprintf("≥ %s", formatBytes(minBytes)),
+		Actual:   formatBytes(actual),
+		Message:  formatResultMessage("Total Bytes", passed, formatBytes(actual), "≥ "+formatBytes(minBytes)),
+	}
+}
+
+// checkSLOCompliance checks if the share of good requests meets the minimum
+func checkSLOCompliance(metrics *thresholdMetrics, minCompliance float64) ThresholdResult {
+	actual := metrics.slo.Compliance
+	passed := actual >= minCompliance
+
+	return ThresholdResult{
+		Name:     "Min SLO Compliance",
+		Passed:   passed,
+		Expected: fmt.Sprintf("≥ %.3f%%", minCompliance*100),
+		Actual:   fmt.Sprintf("%.3f%%", actual*100),
+		Message:  formatResultMessage("SLO Compliance", passed, fmt.Sprintf("%.3f%%", actual*100), fmt.Sprintf("≥ %.3f%%", minCompliance*100)),
+	}
+}
+
+// checkBurnRate checks if the error budget burn rate is within threshold
+func checkBurnRate(metrics *thresholdMetrics, maxBurnRate float64) ThresholdResult {
+	actual := metrics.slo.BurnRate
+	passed := actual <= maxBurnRate
+
+	return ThresholdResult{
+		Name:     "Max Burn Rate",
+		Passed:   passed,
+		Expected: fmt.Sprintf("≤ %.2fx", maxBurnRate),
+		Actual:   fmt.Sprintf("%.2fx", actual),
+		Message:  formatResultMessage("Burn Rate", passed, fmt.Sprintf("%.2fx", actual), fmt.Sprintf("≤ %.2fx", maxBurnRate)),
+	}
+}
+
+// checkBudgetConsumed checks if the share of the error budget used is within threshold
+func checkBudgetConsumed(metrics *thresholdMetrics, maxConsumed float64) ThresholdResult {
+	actual := metrics.slo.BudgetConsumed
+	passed := actual <= maxConsumed
+
+	return ThresholdResult{
+		Name:     "Max Budget Consumed",
+		Passed:   passed,
+		Expected: fmt.Sprintf("≤ %.4f%%", maxConsumed*100),
+		Actual:   fmt.Sprintf("%.4f%%", actual*100),
+		Message:  formatResultMessage("Error Budget Consumed", passed, fmt.Sprintf("%.4f%%", actual*100), fmt.Sprintf("≤ %.4f%%", maxConsumed*100)),
+	}
+}
+
+// formatBytes formats a byte count with a binary unit
+func formatBytes(bytes int64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.2fGB", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.2fMB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%.2fKB", float64(bytes)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", bytes)
+}
+
 // formatMicroseconds formats microseconds into a human-readable duration
 func formatMicroseconds(micros int64) string {
 	if micros < 1000 {
@@ -253,6 +432,23 @@ func (r *ThresholdResults) FormatResults() string {
 	return sb.String()
 }
 
+// RecordAbort adds a failed result for a run that rolling thresholds stopped early
+func (r *ThresholdResults) RecordAbort(reason string) {
+	r.RecordFailure("Rolling Thresholds", "aborted: "+reason, "no violation")
+}
+
+// RecordFailure adds a failed check that isn't a threshold, such as a failed post-run hook
+func (r *ThresholdResults) RecordFailure(name, actual, expected string) {
+	r.Results = append(r.Results, ThresholdResult{
+		Name:     name,
+		Passed:   false,
+		Expected: expected,
+		Actual:   actual,
+		Message:  formatResultMessage(name, false, actual, expected),
+	})
+	r.Passed = false
+}
+
 // FailedCount returns the number of failed thresholds
 func (r *ThresholdResults) FailedCount() int {
 	count := 0
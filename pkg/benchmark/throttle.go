@@ -0,0 +1,99 @@
+package benchmark
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// throttleSlice is the longest a throttled read or write runs ahead of its
+// bandwidth, so transfers are paced in small steps instead of bursts
+const throttleSlice = 50 * time.Millisecond
+
+// bandwidth paces one direction of a connection to a number of bytes per
+// second, like a token bucket refilled continuously with no room for bursts
+type bandwidth struct {
+	rate  float64 // Bytes per second
+	chunk int     // Bytes per step, what the rate allows in throttleSlice
+	mutex sync.Mutex
+	next  time.Time // When the next step may start
+}
+
+// newBandwidth creates a pacer for bytesPerSecond (nil when unlimited)
+func newBandwidth(bytesPerSecond int64) *bandwidth {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	chunk := int(float64(bytesPerSecond) * throttleSlice.Seconds())
+	return &bandwidth{rate: float64(bytesPerSecond), chunk: max(chunk, 1)}
+}
+
+// wait blocks until the next step may start
+func (b *bandwidth) wait() {
+	b.mutex.Lock()
+	next := b.next
+	b.mutex.Unlock()
+	if delay := time.Until(next); delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// spend accounts for n bytes transferred, pushing back the next step
+func (b *bandwidth) spend(n int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if now := time.Now(); b.next.Before(now) {
+		b.next = now
+	}
+	b.next = b.next.Add(time.Duration(float64(n) / b.rate * float64(time.Second)))
+}
+
+// throttledConn caps the upload and download bandwidth of a connection, to
+// simulate constrained clients such as mobile networks. Reading slowly leaves
+// data in the socket buffers, so the server sees the back-pressure.
+type throttledConn struct {
+	net.Conn
+	upload   *bandwidth // nil = unlimited
+	download *bandwidth // nil = unlimited
+}
+
+// Read reads at most one step's worth of data once the download allows it
+func (c *throttledConn) Read(p []byte) (int, error) {
+	if c.download == nil {
+		return c.Conn.Read(p)
+	}
+	c.download.wait()
+	if len(p) > c.download.chunk {
+		p = p[:c.download.chunk]
+	}
+	n, err := c.Conn.Read(p)
+	c.download.spend(n)
+	return n, err
+}
+
+// Write writes p in steps paced by the upload bandwidth
+func (c *throttledConn) Write(p []byte) (int, error) {
+	if c.upload == nil {
+		return c.Conn.Write(p)
+	}
+	written := 0
+	for written < len(p) {
+		c.upload.wait()
+		end := min(written+c.upload.chunk, len(p))
+		n, err := c.Conn.Write(p[written:end])
+		written += n
+		c.upload.spend(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// throttle wraps conn when a bandwidth cap is configured
+func (d *sourceDialer) throttle(conn net.Conn) net.Conn {
+	if d.upload <= 0 && d.download <= 0 {
+		return conn
+	}
+	return &throttledConn{Conn: conn, upload: newBandwidth(d.upload), download: newBandwidth(d.download)}
+}
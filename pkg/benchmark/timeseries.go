@@ -0,0 +1,78 @@
+package benchmark
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TimePoint summarizes the traffic of one sampling interval
+type TimePoint struct {
+	Elapsed           float64          `json:"elapsed"` // Seconds since collection started
+	RequestsPerSecond float64          `json:"rps"`
+	ErrorRate         float64          `json:"errorRate"` // Failed share of the interval's requests
+	AvgLatency        float64          `json:"avgLatencyUs"`
+	Percentiles       map[string]int64 `json:"percentilesUs"` // Latency in microseconds by percentile ("p99.9")
+}
+
+// TimeSeries holds one point per interval of a running benchmark
+type TimeSeries struct {
+	mutex  sync.Mutex
+	points []TimePoint
+}
+
+// CollectTimeSeries samples the stats every interval until ctx is done
+func (s *Stats) CollectTimeSeries(ctx context.Context, interval time.Duration, percentiles []float64) *TimeSeries {
+	series := &TimeSeries{}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		start := s.snapshotWindow()
+		last := start
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			now := s.snapshotWindow()
+			metrics := s.windowMetrics(last, now)
+			point := TimePoint{
+				Elapsed:           now.at.Sub(start.at).Seconds(),
+				RequestsPerSecond: metrics.requestsPerSecond,
+				AvgLatency:        metrics.avgLatency,
+				Percentiles:       make(map[string]int64, len(percentiles)),
+			}
+			if total := metrics.successCount + metrics.failureCount; total > 0 {
+				point.ErrorRate = float64(metrics.failureCount) / float64(total)
+			}
+			for _, p := range percentiles {
+				point.Percentiles["p"+strconv.FormatFloat(p, 'f', -1, 64)] = metrics.percentile(p)
+			}
+
+			series.mutex.Lock()
+			series.points = append(series.points, point)
+			series.mutex.Unlock()
+			last = now
+		}
+	}()
+	return series
+}
+
+// Points returns a copy of the points collected so far
+func (t *TimeSeries) Points() []TimePoint {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return append([]TimePoint(nil), t.points...)
+}
+
+// Last returns a copy of up to n of the newest points, oldest first
+func (t *TimeSeries) Last(n int) []TimePoint {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	start := max(0, len(t.points)-n)
+	return append([]TimePoint(nil), t.points[start:]...)
+}
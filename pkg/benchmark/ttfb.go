@@ -0,0 +1,59 @@
+package benchmark
+
+// RecordTTFB records the time to first byte of one response: from the start of
+// the request until its response headers arrived. Unlike the response time it
+// leaves out the body download, so on large responses the two tell server
+// think time and transfer time apart. (The fasthttp engine reads the body
+// along with the headers, so there it is included.)
+func (s *Stats) RecordTTFB(ttfbMicros int64) {
+	s.ttfbMutex.Lock()
+	s.ttfb.record(ttfbMicros)
+	s.ttfbMutex.Unlock()
+}
+
+// recordTTFBBatch records the times to first byte buffered by a worker
+func (s *Stats) recordTTFBBatch(ttfbMicros []int64) {
+	s.ttfbMutex.Lock()
+	for _, value := range ttfbMicros {
+		s.ttfb.record(value)
+	}
+	s.ttfbMutex.Unlock()
+}
+
+// AddTTFB adds a time to first byte of this worker
+func (w *WorkerStats) AddTTFB(ttfbMicros int64) {
+	w.mutex.Lock()
+	w.ttfb = append(w.ttfb, ttfbMicros)
+	if len(w.ttfb) == workerBufferSize {
+		w.stats.recordTTFBBatch(w.ttfb)
+		w.ttfb = w.ttfb[:0]
+	}
+	w.mutex.Unlock()
+}
+
+// TTFB returns the count, average, maximum and the given percentiles of the
+// times to first byte of all responses
+func (s *Stats) TTFB(percentiles []float64) LatencySummary {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.flushWorkers()
+
+	s.ttfbMutex.Lock()
+	defer s.ttfbMutex.Unlock()
+	return s.ttfb.summary(percentiles)
+}
+
+// snapshotTTFB copies the times to first byte. The caller must hold s.mutex
+// with the workers flushed.
+func (s *Stats) snapshotTTFB() *LatencySeriesSnapshot {
+	s.ttfbMutex.Lock()
+	defer s.ttfbMutex.Unlock()
+	return s.ttfb.snapshot()
+}
+
+// mergeTTFB adds times to first byte from a snapshot taken in another process
+func (s *Stats) mergeTTFB(snap *LatencySeriesSnapshot) {
+	s.ttfbMutex.Lock()
+	defer s.ttfbMutex.Unlock()
+	s.ttfb.merge(snap)
+}
@@ -0,0 +1,46 @@
+// Package benchmark provides benchmarking functionality
+package benchmark
+
+import "sync"
+
+// GlobalVariables holds scenario variables shared by all virtual users,
+// such as an auth token obtained once and reused by everyone
+type GlobalVariables struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// NewGlobalVariables creates an empty global variable store
+func NewGlobalVariables() *GlobalVariables {
+	return &GlobalVariables{values: make(map[string]string)}
+}
+
+// Set stores a global variable
+func (g *GlobalVariables) Set(name, value string) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	g.values[name] = value
+	g.mu.Unlock()
+}
+
+// Delete removes a global variable
+func (g *GlobalVariables) Delete(name string) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	delete(g.values, name)
+	g.mu.Unlock()
+}
+
+// Snapshot returns a copy of the current global variables
+func (g *GlobalVariables) Snapshot() map[string]string {
+	if g == nil {
+		return nil
+	}
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return copyVariables(g.values)
+}
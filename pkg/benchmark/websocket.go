@@ -0,0 +1,179 @@
+// Package benchmark provides benchmarking functionality
+package benchmark
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/benchmarking_go/pkg/config"
+	"golang.org/x/net/websocket"
+)
+
+// executeWebSocketStep opens a WebSocket, sends the step's message and waits for a reply.
+// A reply is awaited when the step validates, extracts or matches one; the step's
+// response time covers the handshake, send and reply.
+func (e *ScenarioExecutor) executeWebSocketStep(ctx context.Context, step *config.StepConfig, variables map[string]string, stepIndex int) StepResult {
+	result := StepResult{
+		StepName:      step.Name,
+		Success:       true,
+		ExtractedVars: make(map[string]string),
+	}
+	wsStep := step.WebSocket
+	stepStart := time.Now()
+
+	fail := func(err error) StepResult {
+		result.Success = false
+		result.Error = err.Error()
+		result.ResponseTime = time.Since(stepStart)
+		if ctx.Err() != nil {
+			return e.cancelStep(result)
+		}
+		e.stats.IncrementFailure()
+		e.stats.AddError(err.Error())
+		e.recordStepFailure(step, &result, err.Error())
+		return result
+	}
+
+	timeout := e.timeout
+	if wsStep.Timeout != "" {
+		d, err := time.ParseDuration(wsStep.Timeout)
+		if err != nil {
+			return fail(fmt.Errorf("invalid websocket timeout: %w", err))
+		}
+		timeout = d
+	}
+
+	wsURL := resolveVariables(step.URL, variables, e.functions)
+	wsConfig, err := websocket.NewConfig(wsURL, websocketOrigin(wsURL))
+	if err != nil {
+		return fail(fmt.Errorf("invalid websocket url: %w", err))
+	}
+	for key, value := range step.Headers {
+		wsConfig.Header.Set(key, resolveVariables(value, variables, e.functions))
+	}
+	if transport, ok := e.client.Transport.(*http.Transport); ok {
+		wsConfig.TlsConfig = transport.TLSClientConfig
+	}
+
+	if e.verboseMode {
+		fmt.Fprintf(e.log, "[scenario] Step %d: WS %s\n", stepIndex+1, wsURL)
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	conn, err := wsConfig.DialContext(dialCtx)
+	if err != nil {
+		return fail(fmt.Errorf("websocket connect failed: %w", err))
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	// Close the connection if the benchmark ends while waiting for a reply
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if wsStep.Send != nil {
+		message, err := websocketMessage(wsStep.Send, variables, e.functions)
+		if err != nil {
+			return fail(err)
+		}
+		if err := websocket.Message.Send(conn, message); err != nil {
+			return fail(fmt.Errorf("websocket send failed: %w", err))
+		}
+	}
+
+	var reply string
+	if wsStep.Match != nil || step.Validate != nil || len(step.Extract) > 0 {
+		reply, err = e.awaitWebSocketReply(conn, wsStep.Match, timeout)
+		if err != nil {
+			return fail(err)
+		}
+	}
+	result.ResponseTime = time.Since(stepStart)
+	result.StatusCode = http.StatusSwitchingProtocols
+
+	e.stats.AddStatusCode(result.StatusCode)
+	e.stats.AddBytes(int64(len(reply)))
+
+	// The handshake stands in for the HTTP response in validation
+	handshake := &http.Response{StatusCode: result.StatusCode, Header: http.Header{}}
+	doc := &lazyDocument{body: reply}
+
+	if step.Validate != nil {
+		result.ValidationErrs = e.validateResponse(handshake, reply, doc, step.Validate, result.ResponseTime)
+		if len(result.ValidationErrs) > 0 {
+			result.Success = false
+			for _, verr := range result.ValidationErrs {
+				e.stats.AddError(fmt.Sprintf("[%s] %s", step.Name, verr))
+			}
+		}
+	}
+	e.extractStepVariables(step, reply, handshake.Header, doc, &result)
+	e.recordStepStats(step, &result, true)
+
+	if e.verboseMode {
+		status := "✓"
+		if !result.Success {
+			status = "✗"
+		}
+		fmt.Fprintf(e.log, "[scenario] %s Step %d: %s -> WS reply %s (%s)\n", status, stepIndex+1, step.Name, truncateString(reply, 50), result.ResponseTime)
+	}
+
+	return result
+}
+
+// awaitWebSocketReply reads messages until one satisfies match (or the first one when match is nil)
+func (e *ScenarioExecutor) awaitWebSocketReply(conn *websocket.Conn, match *config.ValidateConfig, timeout time.Duration) (string, error) {
+	handshake := &http.Response{StatusCode: http.StatusSwitchingProtocols, Header: http.Header{}}
+	for {
+		var reply string
+		if err := websocket.Message.Receive(conn, &reply); err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				if match != nil {
+					return "", fmt.Errorf("websocket: no matching reply within %s", timeout)
+				}
+				return "", fmt.Errorf("websocket: no reply within %s", timeout)
+			}
+			return "", fmt.Errorf("websocket receive failed: %w", err)
+		}
+		if match == nil {
+			return reply, nil
+		}
+		doc := &lazyDocument{body: reply}
+		if len(e.validateResponse(handshake, reply, doc, match, 0)) == 0 {
+			return reply, nil
+		}
+	}
+}
+
+// websocketMessage renders a message to send, resolving variables
+func websocketMessage(send interface{}, variables map[string]string, functions templateFunctions) (string, error) {
+	switch v := send.(type) {
+	case string:
+		return resolveVariables(v, variables, functions), nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal websocket message: %w", err)
+		}
+		return resolveVariables(string(data), variables, functions), nil
+	}
+}
+
+// websocketOrigin derives the Origin header (scheme and host) for a ws:// or wss:// URL
+func websocketOrigin(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	scheme := "http"
+	if u.Scheme == "wss" {
+		scheme = "https"
+	}
+	return scheme + "://" + u.Host
+}
@@ -0,0 +1,124 @@
+package benchmark
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// workerBufferSize is how many response times a worker collects before moving
+// them to the shared latency shards
+const workerBufferSize = 256
+
+// WorkerStats holds the statistics of one worker (virtual user). Only its own
+// worker records into it, so recording doesn't contend with other workers:
+// response times are buffered locally and merged into the Stats in batches,
+// and readers of the Stats merge what is left first.
+type WorkerStats struct {
+	id    int
+	stats *Stats
+
+	requests     int64
+	failures     int64
+	totalLatency int64
+	maxLatency   int64
+
+	mutex   sync.Mutex // Only contended while a reader flushes the buffers
+	buffers [2][]int64 // Indexed by outcome
+	ttfb    []int64    // Times to first byte
+}
+
+// WorkerSummary is the breakdown of one worker's requests
+type WorkerSummary struct {
+	ID           int
+	Requests     int64
+	Failures     int64
+	AvgLatencyUs float64
+	MaxLatencyUs int64
+}
+
+// NewWorker returns the stats of the worker with the given index, creating them
+// on first use
+func (s *Stats) NewWorker(id int) *WorkerStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.workers == nil {
+		s.workers = make(map[int]*WorkerStats)
+	}
+	w, ok := s.workers[id]
+	if !ok {
+		w = &WorkerStats{id: id, stats: s}
+		for outcome := range w.buffers {
+			w.buffers[outcome] = make([]int64, 0, workerBufferSize)
+		}
+		w.ttfb = make([]int64, 0, workerBufferSize)
+		s.workers[id] = w
+	}
+	return w
+}
+
+// AddResponseTime adds a response time measurement of this worker
+func (w *WorkerStats) AddResponseTime(responseTimeMicros int64, success bool) {
+	outcome := outcomeOf(success)
+	w.mutex.Lock()
+	w.buffers[outcome] = append(w.buffers[outcome], responseTimeMicros)
+	if len(w.buffers[outcome]) == workerBufferSize {
+		w.stats.shards.recordBatch(w.buffers[outcome], outcome)
+		w.buffers[outcome] = w.buffers[outcome][:0]
+	}
+	w.mutex.Unlock()
+}
+
+// RecordRequest counts one completed request of this worker for the per-worker breakdown
+func (w *WorkerStats) RecordRequest(responseTimeMicros int64, success bool) {
+	atomic.AddInt64(&w.requests, 1)
+	if !success {
+		atomic.AddInt64(&w.failures, 1)
+	}
+	atomic.AddInt64(&w.totalLatency, responseTimeMicros)
+	if responseTimeMicros > atomic.LoadInt64(&w.maxLatency) {
+		atomic.StoreInt64(&w.maxLatency, responseTimeMicros)
+	}
+}
+
+// flushWorkers moves the buffered response times of every worker into the
+// shards, and their times to first byte into the TTFB series. The caller must hold s.mutex.
+func (s *Stats) flushWorkers() {
+	for _, w := range s.workers {
+		w.mutex.Lock()
+		for outcome, buffer := range w.buffers {
+			if len(buffer) > 0 {
+				s.shards.recordBatch(buffer, outcome)
+				w.buffers[outcome] = buffer[:0]
+			}
+		}
+		if len(w.ttfb) > 0 {
+			s.recordTTFBBatch(w.ttfb)
+			w.ttfb = w.ttfb[:0]
+		}
+		w.mutex.Unlock()
+	}
+}
+
+// Workers returns the per-worker breakdown, ordered by worker index
+func (s *Stats) Workers() []WorkerSummary {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	summaries := make([]WorkerSummary, 0, len(s.workers))
+	for _, w := range s.workers {
+		summary := WorkerSummary{
+			ID:           w.id,
+			Requests:     atomic.LoadInt64(&w.requests),
+			Failures:     atomic.LoadInt64(&w.failures),
+			MaxLatencyUs: atomic.LoadInt64(&w.maxLatency),
+		}
+		if summary.Requests > 0 {
+			summary.AvgLatencyUs = float64(atomic.LoadInt64(&w.totalLatency)) / float64(summary.Requests)
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].ID < summaries[j].ID })
+	return summaries
+}
@@ -0,0 +1,375 @@
+// Package benchmark provides benchmarking functionality
+package benchmark
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// xpathStep is one location step of an XPath expression
+type xpathStep struct {
+	descendant bool     // "//" axis instead of "/"
+	test       string   // node test: name, "*", "text()", "@attr", ".", ".."
+	predicates []string // raw predicate expressions without brackets
+}
+
+// evaluateXPath evaluates an XPath expression against a document and returns
+// the matching nodes in document order. A practical subset is supported:
+//   - absolute and relative paths with "/" and "//"
+//   - node tests: name, *, text(), @attr, @*, ., ..
+//   - predicates: [n], [last()], [@attr], [@attr='v'], [@attr!='v'],
+//     [text()='v'], [child='v'], [contains(x,'v')], [starts-with(x,'v')],
+//     combined with "and"
+func evaluateXPath(root *docNode, expr string) ([]*docNode, error) {
+	steps, err := parseXPath(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	context := []*docNode{root}
+	for _, step := range steps {
+		var next []*docNode
+		seen := make(map[*docNode]bool)
+		for _, node := range context {
+			matches, err := step.apply(node)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range matches {
+				// Attribute pseudo-nodes are fresh per lookup, so only dedupe real nodes
+				if m.isAttr || !seen[m] {
+					seen[m] = true
+					next = append(next, m)
+				}
+			}
+		}
+		context = next
+		if len(context) == 0 {
+			break
+		}
+	}
+	return context, nil
+}
+
+// xpathValue returns the string value of the first node matching the expression
+func xpathValue(root *docNode, expr string) (string, bool, error) {
+	nodes, err := evaluateXPath(root, expr)
+	if err != nil {
+		return "", false, err
+	}
+	if len(nodes) == 0 {
+		return "", false, nil
+	}
+	return nodes[0].value(), true, nil
+}
+
+// parseXPath splits an expression into location steps
+func parseXPath(expr string) ([]xpathStep, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("empty xpath expression")
+	}
+
+	var steps []xpathStep
+	i := 0
+	for i < len(expr) {
+		step := xpathStep{}
+		if strings.HasPrefix(expr[i:], "//") {
+			step.descendant = true
+			i += 2
+		} else if expr[i] == '/' {
+			i++
+		}
+
+		// Read until the next unbracketed, unquoted slash
+		start := i
+		depth := 0
+		var quote byte
+		for i < len(expr) {
+			c := expr[i]
+			if quote != 0 {
+				if c == quote {
+					quote = 0
+				}
+			} else if c == '\'' || c == '"' {
+				quote = c
+			} else if c == '[' {
+				depth++
+			} else if c == ']' {
+				depth--
+			} else if c == '/' && depth == 0 {
+				break
+			}
+			i++
+		}
+		raw := strings.TrimSpace(expr[start:i])
+		if raw == "" {
+			if i >= len(expr) {
+				break
+			}
+			return nil, fmt.Errorf("invalid xpath expression: %s", expr)
+		}
+
+		test, predicates, err := splitPredicates(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid xpath expression %s: %w", expr, err)
+		}
+		step.test = test
+		step.predicates = predicates
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// splitPredicates splits "name[p1][p2]" into the node test and its predicates
+func splitPredicates(raw string) (string, []string, error) {
+	open := strings.Index(raw, "[")
+	if open == -1 {
+		return raw, nil, nil
+	}
+
+	test := strings.TrimSpace(raw[:open])
+	var predicates []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := open; i < len(raw); i++ {
+		c := raw[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '[':
+			if depth == 0 {
+				start = i + 1
+			}
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				predicates = append(predicates, strings.TrimSpace(raw[start:i]))
+			}
+		}
+	}
+	if depth != 0 {
+		return "", nil, fmt.Errorf("unbalanced brackets")
+	}
+	return test, predicates, nil
+}
+
+// apply evaluates this step relative to a context node
+func (s xpathStep) apply(node *docNode) ([]*docNode, error) {
+	var candidates []*docNode
+	if s.descendant {
+		candidates = append([]*docNode{node}, node.descendants()...)
+	} else {
+		candidates = []*docNode{node}
+	}
+
+	var matches []*docNode
+	for _, c := range candidates {
+		matches = append(matches, s.selectFrom(c)...)
+	}
+
+	for _, pred := range s.predicates {
+		filtered, err := filterPredicate(matches, pred)
+		if err != nil {
+			return nil, err
+		}
+		matches = filtered
+	}
+	return matches, nil
+}
+
+// selectFrom applies the node test along the child (or attribute, self, parent) axis
+func (s xpathStep) selectFrom(node *docNode) []*docNode {
+	switch {
+	case s.test == ".":
+		return []*docNode{node}
+	case s.test == "..":
+		if node.parent != nil {
+			return []*docNode{node.parent}
+		}
+		return nil
+	case s.test == "text()":
+		var texts []*docNode
+		for _, c := range node.children {
+			if c.name == "" && !c.isAttr {
+				texts = append(texts, c)
+			}
+		}
+		return texts
+	case strings.HasPrefix(s.test, "@"):
+		name := strings.TrimPrefix(s.test, "@")
+		if idx := strings.LastIndexByte(name, ':'); idx != -1 {
+			name = name[idx+1:]
+		}
+		return attributeNodes(node, name)
+	}
+
+	// Namespace prefixes (soap:Body) are ignored; names match on the local part
+	name := s.test
+	if idx := strings.LastIndexByte(name, ':'); idx != -1 {
+		name = name[idx+1:]
+	}
+
+	var elems []*docNode
+	for _, c := range node.elements() {
+		if name == "*" || strings.EqualFold(c.name, name) {
+			elems = append(elems, c)
+		}
+	}
+	return elems
+}
+
+// attributeNodes returns attribute pseudo-nodes for an element ("*" for all attributes)
+func attributeNodes(node *docNode, name string) []*docNode {
+	if !node.isElement() {
+		return nil
+	}
+	var attrs []*docNode
+	for key, value := range node.attrs {
+		if name == "*" || strings.EqualFold(key, name) {
+			attrs = append(attrs, &docNode{name: "@" + key, text: value, isAttr: true, parent: node})
+		}
+	}
+	return attrs
+}
+
+// filterPredicate filters nodes by a single predicate expression
+func filterPredicate(nodes []*docNode, pred string) ([]*docNode, error) {
+	// Positional predicates
+	if pred == "last()" {
+		if len(nodes) == 0 {
+			return nil, nil
+		}
+		return nodes[len(nodes)-1:], nil
+	}
+	if n, err := strconv.Atoi(pred); err == nil {
+		if n < 1 || n > len(nodes) {
+			return nil, nil
+		}
+		return nodes[n-1 : n], nil
+	}
+
+	var filtered []*docNode
+	for _, node := range nodes {
+		ok, err := matchPredicate(node, pred)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered, nil
+}
+
+// matchPredicate evaluates a boolean predicate against a node
+func matchPredicate(node *docNode, pred string) (bool, error) {
+	if parts := splitOutsideQuotes(pred, " and "); len(parts) > 1 {
+		for _, part := range parts {
+			ok, err := matchPredicate(node, strings.TrimSpace(part))
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	for _, fn := range []string{"contains", "starts-with"} {
+		if strings.HasPrefix(pred, fn+"(") && strings.HasSuffix(pred, ")") {
+			args := splitOutsideQuotes(pred[len(fn)+1:len(pred)-1], ",")
+			if len(args) != 2 {
+				return false, fmt.Errorf("%s() takes two arguments: %s", fn, pred)
+			}
+			subject, _ := predicateOperand(node, strings.TrimSpace(args[0]))
+			needle := unquote(strings.TrimSpace(args[1]))
+			if fn == "contains" {
+				return strings.Contains(subject, needle), nil
+			}
+			return strings.HasPrefix(subject, needle), nil
+		}
+	}
+
+	for _, op := range []string{"!=", "="} {
+		if parts := splitOutsideQuotes(pred, op); len(parts) == 2 {
+			left, _ := predicateOperand(node, strings.TrimSpace(parts[0]))
+			right := unquote(strings.TrimSpace(parts[1]))
+			if op == "=" {
+				return left == right, nil
+			}
+			return left != right, nil
+		}
+	}
+
+	// Existence test: [@attr] or [child]
+	_, exists := predicateOperand(node, pred)
+	return exists, nil
+}
+
+// predicateOperand resolves the left side of a predicate relative to a node
+func predicateOperand(node *docNode, operand string) (string, bool) {
+	switch {
+	case operand == "." || operand == "text()":
+		return node.value(), true
+	case strings.HasPrefix(operand, "@"):
+		value, ok := node.attrs[strings.ToLower(strings.TrimPrefix(operand, "@"))]
+		if !ok {
+			// XML attribute names are case-sensitive
+			value, ok = node.attrs[strings.TrimPrefix(operand, "@")]
+		}
+		return value, ok
+	}
+
+	if strings.HasPrefix(operand, "'") || strings.HasPrefix(operand, "\"") {
+		return unquote(operand), true
+	}
+
+	// Relative path to a child element
+	nodes, err := evaluateXPath(node, operand)
+	if err != nil || len(nodes) == 0 {
+		return "", false
+	}
+	return nodes[0].value(), true
+}
+
+// splitOutsideQuotes splits s on sep, ignoring separators inside quotes or brackets
+func splitOutsideQuotes(s, sep string) []string {
+	var parts []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+			continue
+		case '[', '(':
+			depth++
+			continue
+		case ']', ')':
+			depth--
+			continue
+		}
+		if depth == 0 && strings.HasPrefix(s[i:], sep) {
+			parts = append(parts, s[start:i])
+			i += len(sep) - 1
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
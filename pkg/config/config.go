@@ -4,6 +4,9 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"math/rand"
+	"net"
 	"os"
 	"strconv"
 	"strings"
@@ -12,30 +15,529 @@ import (
 
 // Config represents the root JSON configuration
 type Config struct {
-	Schema         string            `json:"$schema,omitempty"`
-	Name           string            `json:"name,omitempty"`
-	Description    string            `json:"description,omitempty"`
-	BaseURL        string            `json:"baseUrl,omitempty"` // Base URL for scenario mode
-	Settings       Settings          `json:"settings,omitempty"`
-	Variables      map[string]string `json:"variables,omitempty"`
-	DefaultHeaders map[string]string `json:"defaultHeaders,omitempty"`
-	Requests       []RequestConfig   `json:"requests,omitempty"`
-	Steps          []StepConfig      `json:"steps,omitempty"` // Scenario mode: sequential steps
-	Output         OutputConfig      `json:"output,omitempty"`
-	Thresholds     ThresholdConfig   `json:"thresholds,omitempty"`
+	Schema         string              `json:"$schema,omitempty"`
+	Name           string              `json:"name,omitempty"`
+	Description    string              `json:"description,omitempty"`
+	Metadata       map[string]string   `json:"metadata,omitempty"` // Run labels such as gitSha, environment or build, copied into results and metric exporters
+	Tags           []string            `json:"tags,omitempty"`     // Free-form run tags, e.g. "nightly" or "canary"
+	BaseURL        string              `json:"baseUrl,omitempty"`  // Base URL for scenario mode
+	Settings       Settings            `json:"settings,omitempty"`
+	Variables      map[string]string   `json:"variables,omitempty"`
+	DefaultHeaders map[string]string   `json:"defaultHeaders,omitempty"`
+	RotateHeaders  HeaderPools         `json:"rotateHeaders,omitempty"` // Header values picked per request from a list
+	Requests       []RequestConfig     `json:"requests,omitempty"`
+	Steps          []StepConfig        `json:"steps,omitempty"`        // Scenario mode: sequential steps
+	VUInit         []StepConfig        `json:"vuInit,omitempty"`       // Scenario mode: steps run once per virtual user (e.g. login)
+	Transactions   []TransactionConfig `json:"transactions,omitempty"` // Scenario mode: named groups of steps timed together
+	Scenarios      []ScenarioConfig    `json:"scenarios,omitempty"`    // Scenario mode: several step lists with a traffic split
+	Persist        *PersistConfig      `json:"persist,omitempty"`      // Scenario mode: variables kept across a user's iterations
+	Output         OutputConfig        `json:"output,omitempty"`
+	Thresholds     ThresholdConfig     `json:"thresholds,omitempty"`
+	ExitCodes      *ExitCodeConfig     `json:"exitCodes,omitempty"` // Process exit code per outcome (for CI pipelines)
+
+	RollingThresholds  *RollingThresholdConfig   `json:"rollingThresholds,omitempty"`  // Thresholds checked on a sliding window during the run
+	SLO                *SLOConfig                `json:"slo,omitempty"`                // Service level objective for error budget reporting
+	BaselineThresholds *BaselineThresholdConfig  `json:"baselineThresholds,omitempty"` // Allowed regression against a stored run (--check-baseline)
+	Targets            []TargetConfig            `json:"targets,omitempty"`            // Deployments benchmarked side by side with identical load
+	Functions          map[string]FunctionConfig `json:"functions,omitempty"`          // Custom {{$name}} template functions
+	Hooks              *RunHooksConfig           `json:"hooks,omitempty"`              // Commands run before and after the benchmark
+}
+
+// TargetConfig is one deployment in a comparison run. The whole config runs
+// against each target with its baseUrl and variables in place of the top-level ones.
+type TargetConfig struct {
+	Name      string            `json:"name"`
+	BaseURL   string            `json:"baseUrl"`
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// ValidateTargets checks that a comparison run has at least two uniquely named targets
+func (c *Config) ValidateTargets() error {
+	if len(c.Targets) == 0 {
+		return nil
+	}
+	if len(c.Targets) < 2 {
+		return fmt.Errorf("targets need at least two entries to compare")
+	}
+	names := make(map[string]bool)
+	for i, target := range c.Targets {
+		if target.Name == "" || target.BaseURL == "" {
+			return fmt.Errorf("target %d needs a name and a baseUrl", i+1)
+		}
+		if names[target.Name] {
+			return fmt.Errorf("duplicate target name %q", target.Name)
+		}
+		names[target.Name] = true
+	}
+	return nil
+}
+
+// ForTarget returns a copy of the config aimed at target, without the target list
+func (c *Config) ForTarget(target TargetConfig) (*Config, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	copied := &Config{}
+	if err := json.Unmarshal(data, copied); err != nil {
+		return nil, fmt.Errorf("failed to copy config: %w", err)
+	}
+
+	copied.Targets = nil
+	copied.BaseURL = target.BaseURL
+	if copied.Variables == nil {
+		copied.Variables = make(map[string]string)
+	}
+	copied.Variables["baseUrl"] = target.BaseURL
+	for name, value := range target.Variables {
+		copied.Variables[name] = value
+	}
+	return copied, nil
+}
+
+// BaselineThresholdConfig defines how far results may regress relative to a
+// baseline run. It is only evaluated when a baseline is supplied.
+type BaselineThresholdConfig struct {
+	MaxLatencyRegression map[string]float64 `json:"maxLatencyRegression,omitempty"` // Allowed increase per latency metric ("avg", "p95", "p99.9"; 0.1 = 10%)
+	MaxRPSDrop           float64            `json:"maxRpsDrop,omitempty"`           // Allowed drop in requests per second (0.05 = 5%)
+	MaxErrorRateIncrease float64            `json:"maxErrorRateIncrease,omitempty"` // Allowed rise of the error rate in absolute terms (0.01 = 1 point)
+}
+
+// DefaultBaselineThresholds is used when a baseline is supplied without baselineThresholds
+func DefaultBaselineThresholds() *BaselineThresholdConfig {
+	return &BaselineThresholdConfig{
+		MaxLatencyRegression: map[string]float64{"avg": 0.10, "p99": 0.10},
+		MaxRPSDrop:           0.05,
+	}
+}
+
+// SLOConfig defines a service level objective: the share of requests that must be
+// good, where a good request succeeds within the latency objective
+type SLOConfig struct {
+	Target  float64 `json:"target"`            // Required share of good requests (0.999 = 99.9%)
+	Latency string  `json:"latency,omitempty"` // Slowest response still counted as good (e.g. "300ms"; empty = any)
+	Period  string  `json:"period,omitempty"`  // SLO period the error budget covers (default "30d")
+}
+
+// Objective returns the latency objective in microseconds (0 = none) and the SLO period
+func (s *SLOConfig) Objective() (int64, time.Duration, error) {
+	if s.Target <= 0 || s.Target >= 1 {
+		return 0, 0, fmt.Errorf("slo target must be between 0 and 1 (got %g)", s.Target)
+	}
+	latency, err := ParseLatency(s.Latency)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid slo latency: %w", err)
+	}
+	period := 30 * 24 * time.Hour
+	if s.Period != "" {
+		period, err = parsePeriod(s.Period)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid slo period: %w", err)
+		}
+	}
+	return latency, period, nil
+}
+
+// WorkerCount returns how many workers (virtual users in scenario mode) send requests
+func (c *Config) WorkerCount() int {
+	if c.Settings.Workers > 0 {
+		return c.Settings.Workers
+	}
+	return c.Settings.ConcurrentUsers
+}
+
+// InFlightLimit returns how many requests may be in flight at once, which is
+// also how many connections per host are opened
+func (c *Config) InFlightLimit() int {
+	workers := c.WorkerCount()
+	if c.Settings.MaxInFlight > 0 && c.Settings.MaxInFlight < workers {
+		return c.Settings.MaxInFlight
+	}
+	return workers
+}
+
+// Validate checks the settings that are only read once a benchmark starts, so
+// that a bad value is reported before any request is sent
+func (c *Config) Validate() error {
+	if len(c.Requests) == 0 && !c.IsScenarioMode() {
+		return fmt.Errorf("no requests or steps to benchmark")
+	}
+	for _, req := range c.Requests {
+		if req.URL == "" {
+			return fmt.Errorf("request %q has no url", req.Name)
+		}
+	}
+	if _, err := c.GetDurationSeconds(); err != nil {
+		return err
+	}
+	if err := c.ValidateSLO(); err != nil {
+		return err
+	}
+	if _, err := c.GetGracePeriod(0); err != nil {
+		return err
+	}
+	if _, err := c.GetCheckpointInterval(); err != nil {
+		return err
+	}
+	if _, err := c.GetReportInterval(); err != nil {
+		return err
+	}
+	if err := c.ValidateTargets(); err != nil {
+		return err
+	}
+	if err := c.ValidateScenarios(); err != nil {
+		return err
+	}
+	if err := c.ValidateSteps(); err != nil {
+		return err
+	}
+	if err := c.ValidateEngine(); err != nil {
+		return err
+	}
+	if err := c.ValidateConcurrency(); err != nil {
+		return err
+	}
+	if _, _, err := c.GetDNSCache(); err != nil {
+		return err
+	}
+	if _, _, err := c.GetHistogramBuckets(); err != nil {
+		return err
+	}
+	if err := c.ValidateLocalAddresses(); err != nil {
+		return err
+	}
+	if err := c.ValidateConnectionPool(); err != nil {
+		return err
+	}
+	if c.Settings.VerifyRequestID && c.Settings.RequestIDHeader == "" {
+		return fmt.Errorf("verifyRequestId requires requestIdHeader")
+	}
+	if rc := c.Settings.CaptureResponses; rc != nil && (rc.Rate <= 0 || rc.Rate > 1) {
+		return fmt.Errorf("captureResponses rate must be between 0 and 1, got %g", rc.Rate)
+	}
+	if err := c.ValidatePlugins(); err != nil {
+		return err
+	}
+	if err := c.ValidateScript(); err != nil {
+		return err
+	}
+	if err := c.ValidateJavaScript(); err != nil {
+		return err
+	}
+	if err := c.ValidateFunctions(); err != nil {
+		return err
+	}
+	if err := c.ValidateBodyTemplates(); err != nil {
+		return err
+	}
+	if err := c.ValidateRunHooks(); err != nil {
+		return err
+	}
+	if err := c.ValidateSinks(); err != nil {
+		return err
+	}
+	if deadline, err := c.GetUntil(); err != nil {
+		return err
+	} else if !deadline.IsZero() && !deadline.After(time.Now()) {
+		return fmt.Errorf("deadline %s has already passed", c.Settings.Until)
+	}
+	return nil
+}
+
+// ValidateScenarios checks that named scenarios replace the top-level steps
+// rather than adding to them, and that their weights can pick a scenario
+func (c *Config) ValidateScenarios() error {
+	if len(c.Scenarios) == 0 {
+		return nil
+	}
+	if len(c.Steps) > 0 {
+		return fmt.Errorf("steps and scenarios cannot both be set; move the steps into a scenario")
+	}
+	total := 0
+	for _, sc := range c.Scenarios {
+		weight := sc.GetWeight()
+		if weight < 0 {
+			return fmt.Errorf("scenario %q has a negative weight (%d)", sc.Name, weight)
+		}
+		total += weight
+	}
+	if total == 0 {
+		return fmt.Errorf("scenario weights add up to 0; give at least one scenario a positive weight")
+	}
+	return nil
+}
+
+// ValidateSteps checks the settings of every step, naming the step that is wrong
+func (c *Config) ValidateSteps() error {
+	for _, step := range c.AllStepsWithInit() {
+		if step.Delay != "" {
+			if _, err := ParseDelay(step.Delay); err != nil {
+				return fmt.Errorf("step %s: %w", step.Name, err)
+			}
+		}
+		switch step.OnFailure {
+		case "", OnFailureContinue, OnFailureAbort, OnFailureSkipRemaining:
+		default:
+			return fmt.Errorf("step %s: unknown onFailure %q (expected %s, %s or %s)",
+				step.Name, step.OnFailure, OnFailureContinue, OnFailureAbort, OnFailureSkipRemaining)
+		}
+		if step.Poll != nil {
+			if err := step.Poll.Validate(); err != nil {
+				return fmt.Errorf("step %s: %w", step.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateConcurrency checks the worker and in-flight settings
+func (c *Config) ValidateConcurrency() error {
+	if c.Settings.Workers < 0 {
+		return fmt.Errorf("workers must not be negative")
+	}
+	if c.Settings.MaxInFlight < 0 {
+		return fmt.Errorf("maxInFlight must not be negative")
+	}
+	return nil
+}
+
+// ValidateSLO checks the SLO definition and that SLO thresholds have one to refer to
+func (c *Config) ValidateSLO() error {
+	if c.SLO != nil {
+		_, _, err := c.SLO.Objective()
+		return err
+	}
+	usesSLO := func(t *ThresholdConfig) bool {
+		return t.MinSLOCompliance > 0 || t.MaxBurnRate > 0 || t.MaxBudgetConsumed > 0
+	}
+	if usesSLO(&c.Thresholds) || (c.RollingThresholds != nil && usesSLO(&c.RollingThresholds.ThresholdConfig)) {
+		return fmt.Errorf("slo thresholds require an \"slo\" definition")
+	}
+	return nil
+}
+
+// parsePeriod parses a duration that may also be given in whole days (e.g. "7d")
+func parsePeriod(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid number of days %q", days)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	period, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if period <= 0 {
+		return 0, fmt.Errorf("period must be positive")
+	}
+	return period, nil
+}
+
+// ExitCodeConfig maps benchmark outcomes to process exit codes so CI pipelines can
+// tell a performance regression from a broken environment. A zero code ignores the
+// condition. When several apply, the first in field order wins.
+type ExitCodeConfig struct {
+	Interrupted      int `json:"interrupted,omitempty"`      // Benchmark stopped with Ctrl+C (e.g. 130)
+	ServerErrors     int `json:"serverErrors,omitempty"`     // Any 5xx response
+	ClientErrors     int `json:"clientErrors,omitempty"`     // Any 4xx response
+	AnyFailure       int `json:"anyFailure,omitempty"`       // Any failed request (errors, bad status, validation)
+	ThresholdFailure int `json:"thresholdFailure,omitempty"` // Thresholds failed (default 1)
 }
 
 // StepConfig represents a single step in a scenario sequence
 type StepConfig struct {
-	Name     string            `json:"name"`
-	URL      string            `json:"url"`
-	Method   string            `json:"method,omitempty"`
-	Headers  map[string]string `json:"headers,omitempty"`
-	Body     interface{}       `json:"body,omitempty"`
-	BodyFile string            `json:"bodyFile,omitempty"`
-	Extract  map[string]string `json:"extract,omitempty"`  // Variable extraction: {"varName": "$.jsonpath"}
-	Validate *ValidateConfig   `json:"validate,omitempty"` // Response validation
-	Delay    string            `json:"delay,omitempty"`    // Delay before this step (e.g., "500ms")
+	Name          string            `json:"name"`
+	URL           string            `json:"url"`
+	Method        string            `json:"method,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	RotateHeaders HeaderPools       `json:"rotateHeaders,omitempty"` // Header values picked per request from a list
+	Body          interface{}       `json:"body,omitempty"`
+	BodyFile      string            `json:"bodyFile,omitempty"`
+	BodyTemplate  string            `json:"bodyTemplate,omitempty"` // text/template rendered per request ("@path" reads a file)
+	Extract       map[string]string `json:"extract,omitempty"`      // Variable extraction: {"varName": "$.jsonpath"}
+	Validate      *ValidateConfig   `json:"validate,omitempty"`     // Response validation
+	Delay         string            `json:"delay,omitempty"`        // Delay before this step (e.g., "500ms", "uniform(200ms,800ms)", "normal(500ms,100ms)")
+	When          string            `json:"when,omitempty"`         // Condition to run this step (e.g., "{{status}} == 'pending'")
+	Thresholds    *ThresholdConfig  `json:"thresholds,omitempty"`   // Pass/fail criteria for this step alone
+	Critical      bool              `json:"critical,omitempty"`     // Shorthand for onFailure: "abort"
+	OnFailure     string            `json:"onFailure,omitempty"`    // What to do when this step fails: continue (default), abort, skipRemaining
+	ForEach       string            `json:"foreach,omitempty"`      // Run once per element of this JSON array variable
+	As            string            `json:"as,omitempty"`           // Variable holding the current element (default "item")
+	ForEachLimit  int               `json:"foreachLimit,omitempty"` // Maximum number of elements to iterate (0 = all)
+	Binary        bool              `json:"binary,omitempty"`       // Stream the response without buffering it as text (downloads)
+	RateLimit     int               `json:"rateLimit,omitempty"`    // Requests per second cap for this step across all users
+	Reset         []string          `json:"reset,omitempty"`        // Variables to clear after this step succeeds (e.g. a cart ID after checkout)
+	Scope         map[string]string `json:"scope,omitempty"`        // Scope of extracted variables: {"varName": "global|vu|iteration"}
+	Poll          *PollConfig       `json:"poll,omitempty"`         // Repeat this step until a condition holds (async jobs)
+	WebSocket     *WebSocketConfig  `json:"websocket,omitempty"`    // Run this step over a WebSocket (url is ws:// or wss://)
+	OnStatus      StatusBranches    `json:"onStatus,omitempty"`     // Steps to run for a response status: {"401": {...}, "5xx": {...}}
+	JSRequest     string            `json:"jsRequest,omitempty"`    // jsScript function that changes the request before it is sent
+	JSCheck       string            `json:"jsCheck,omitempty"`      // jsScript function that checks the response, like a validation
+}
+
+// StatusBranches maps a response status ("401") or status class ("5xx") to a branch
+type StatusBranches map[string]StatusBranch
+
+// StatusBranch is run when a step gets a matching response status, e.g. refreshing
+// a token on 401 and retrying. A failed step still fails the iteration and its
+// onFailure action applies after the branch (to the retry's result with retry).
+type StatusBranch struct {
+	Steps []StepConfig `json:"steps,omitempty"` // Steps to run before continuing
+	Retry bool         `json:"retry,omitempty"` // Run the original step once more after the branch steps
+}
+
+// StatusBranch returns the branch for a response status, matching an exact code
+// ("401") before a status class ("4xx")
+func (s *StepConfig) StatusBranch(status int) (StatusBranch, bool) {
+	if status == 0 || len(s.OnStatus) == 0 {
+		return StatusBranch{}, false
+	}
+	if branch, ok := s.OnStatus[strconv.Itoa(status)]; ok {
+		return branch, true
+	}
+	branch, ok := s.OnStatus[fmt.Sprintf("%dxx", status/100)]
+	return branch, ok
+}
+
+// WebSocketConfig describes a WebSocket step: connect, optionally send a message and
+// wait for a reply. The reply is checked by the step's validate and extract rules
+// like an HTTP response body; the handshake is reported as status 101.
+type WebSocketConfig struct {
+	Send    interface{}     `json:"send,omitempty"`    // Message to send after connecting (string or JSON)
+	Match   *ValidateConfig `json:"match,omitempty"`   // Ignore replies until one matches (e.g. skip subscription acks)
+	Timeout string          `json:"timeout,omitempty"` // How long to wait for a reply (default: request timeout)
+}
+
+// PollConfig repeats a step until its response matches a condition,
+// e.g. polling a job status until $.state == "done"
+type PollConfig struct {
+	Until       *ValidateConfig `json:"until,omitempty"`       // Condition that ends polling (nil = first successful response)
+	Interval    string          `json:"interval,omitempty"`    // Wait between attempts (default "1s")
+	Timeout     string          `json:"timeout,omitempty"`     // Give up after this long (default "30s")
+	MaxAttempts int             `json:"maxAttempts,omitempty"` // Give up after this many attempts (0 = until timeout)
+}
+
+// Validate checks that the interval, timeout and attempt limit can end polling
+func (p *PollConfig) Validate() error {
+	interval, timeout, err := p.Durations()
+	if err != nil {
+		return err
+	}
+	if interval <= 0 {
+		return fmt.Errorf("poll interval must be positive, got %s", p.Interval)
+	}
+	if timeout <= 0 {
+		return fmt.Errorf("poll timeout must be positive, got %s", p.Timeout)
+	}
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("poll maxAttempts cannot be negative, got %d", p.MaxAttempts)
+	}
+	return nil
+}
+
+// Durations returns the poll interval and timeout, applying the defaults
+func (p *PollConfig) Durations() (time.Duration, time.Duration, error) {
+	interval, timeout := time.Second, 30*time.Second
+	if p.Interval != "" {
+		d, err := time.ParseDuration(p.Interval)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid poll interval: %w", err)
+		}
+		interval = d
+	}
+	if p.Timeout != "" {
+		d, err := time.ParseDuration(p.Timeout)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid poll timeout: %w", err)
+		}
+		timeout = d
+	}
+	return interval, timeout, nil
+}
+
+// Variable scopes
+const (
+	ScopeIteration = "iteration" // Visible for the rest of the current iteration (default)
+	ScopeVU        = "vu"        // Kept for all later iterations of the same virtual user
+	ScopeGlobal    = "global"    // Shared by all virtual users
+)
+
+// VariableScope returns the scope of a variable extracted by this step
+func (s *StepConfig) VariableScope(name string) string {
+	if scope := s.Scope[name]; scope != "" {
+		return scope
+	}
+	return ScopeIteration
+}
+
+// ForEachVariable returns the name of the variable holding the current foreach element.
+// The element index is available as the same name with an "Index" suffix.
+func (s *StepConfig) ForEachVariable() string {
+	if s.As != "" {
+		return s.As
+	}
+	return "item"
+}
+
+// ScenarioConfig is one of several scenarios run concurrently. Each iteration
+// picks a scenario at random in proportion to its weight.
+type ScenarioConfig struct {
+	Name   string       `json:"name"`
+	Weight *int         `json:"weight,omitempty"` // Relative share of iterations (default 1, 0 = never picked)
+	Steps  []StepConfig `json:"steps"`
+}
+
+// GetWeight returns the scenario's relative share of iterations
+func (sc *ScenarioConfig) GetWeight() int {
+	if sc.Weight == nil {
+		return 1
+	}
+	return *sc.Weight
+}
+
+// PersistConfig controls which variables carry over between a virtual user's
+// scenario iterations instead of starting each iteration from a fresh copy
+type PersistConfig struct {
+	Variables      []string `json:"variables"`                // Variable names to keep ("*" = all)
+	ResetEvery     int      `json:"resetEvery,omitempty"`     // Clear persisted variables every N iterations
+	ResetOnFailure bool     `json:"resetOnFailure,omitempty"` // Clear persisted variables when an iteration fails
+}
+
+// Keeps reports whether the named variable should persist across iterations
+func (p *PersistConfig) Keeps(name string) bool {
+	for _, v := range p.Variables {
+		if v == "*" || v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// TransactionConfig groups scenario steps whose combined duration is measured as one unit
+type TransactionConfig struct {
+	Name       string           `json:"name"`
+	Steps      []string         `json:"steps"`                // Names of the steps in this transaction
+	Thresholds *ThresholdConfig `json:"thresholds,omitempty"` // Pass/fail criteria for the transaction duration
+}
+
+// Step failure actions
+const (
+	OnFailureContinue      = "continue"
+	OnFailureAbort         = "abort"
+	OnFailureSkipRemaining = "skipRemaining"
+)
+
+// FailureAction returns what the scenario should do when this step fails
+func (s *StepConfig) FailureAction() string {
+	if s.OnFailure != "" {
+		return s.OnFailure
+	}
+	if s.Critical {
+		return OnFailureAbort
+	}
+	return OnFailureContinue
 }
 
 // ValidateConfig defines response validation rules
@@ -45,8 +547,15 @@ type ValidateConfig struct {
 	BodyContains    string                 `json:"bodyContains,omitempty"`    // Body must contain this string
 	BodyNotContains string                 `json:"bodyNotContains,omitempty"` // Body must NOT contain this string
 	JSONPath        map[string]interface{} `json:"jsonPath,omitempty"`        // JSONPath assertions
+	XPath           map[string]interface{} `json:"xpath,omitempty"`           // XPath assertions for XML/HTML responses
+	Expr            []string               `json:"expr,omitempty"`            // Boolean expressions (e.g., "$.count > 0 && $.items[0].price <= 100")
 	Headers         map[string]string      `json:"headers,omitempty"`         // Expected response headers
 	ResponseTime    string                 `json:"responseTime,omitempty"`    // Max response time (e.g., "500ms")
+	Size            int64                  `json:"size,omitempty"`            // Exact body size in bytes
+	MinSize         int64                  `json:"minSize,omitempty"`         // Minimum body size in bytes
+	MaxSize         int64                  `json:"maxSize,omitempty"`         // Maximum body size in bytes
+	SHA256          string                 `json:"sha256,omitempty"`          // Expected hex SHA-256 of the body
+	MD5             string                 `json:"md5,omitempty"`             // Expected hex MD5 of the body
 }
 
 // StatusRange defines a range of acceptable status codes
@@ -55,21 +564,166 @@ type StatusRange struct {
 	Max int `json:"max"`
 }
 
+// StatusList lists response statuses: codes (404), classes ("4xx") and ranges
+// ("400-403"). In JSON it is one of those or an array of them.
+type StatusList []StatusRange
+
+// Contains reports whether status is in the list
+func (l StatusList) Contains(status int) bool {
+	for _, r := range l {
+		if status >= r.Min && status <= r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalJSON accepts a single status or an array of them
+func (l *StatusList) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		items = []json.RawMessage{data}
+	}
+	list := make(StatusList, 0, len(items))
+	for _, item := range items {
+		var code int
+		if err := json.Unmarshal(item, &code); err == nil {
+			list = append(list, StatusRange{Min: code, Max: code})
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(item, &value); err != nil {
+			return fmt.Errorf("status must be a code such as 404, a class such as \"4xx\" or a range such as \"400-403\"")
+		}
+		r, err := parseStatus(value)
+		if err != nil {
+			return err
+		}
+		list = append(list, r)
+	}
+	*l = list
+	return nil
+}
+
+// MarshalJSON writes the list in the form UnmarshalJSON reads
+func (l StatusList) MarshalJSON() ([]byte, error) {
+	items := make([]interface{}, len(l))
+	for i, r := range l {
+		switch {
+		case r.Min == r.Max:
+			items[i] = r.Min
+		case r.Min%100 == 0 && r.Max == r.Min+99:
+			items[i] = fmt.Sprintf("%dxx", r.Min/100)
+		default:
+			items[i] = fmt.Sprintf("%d-%d", r.Min, r.Max)
+		}
+	}
+	return json.Marshal(items)
+}
+
+// ParseStatusList parses comma-separated statuses such as "404,5xx,400-403"
+func ParseStatusList(value string) (StatusList, error) {
+	var list StatusList
+	for _, part := range strings.Split(value, ",") {
+		r, err := parseStatus(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+	return list, nil
+}
+
+// parseStatus parses one status code, class or range
+func parseStatus(value string) (StatusRange, error) {
+	invalid := fmt.Errorf("invalid status %q: expected a code such as 404, a class such as 4xx or a range such as 400-403", value)
+	if len(value) == 3 && strings.HasSuffix(strings.ToLower(value), "xx") {
+		class, err := strconv.Atoi(value[:1])
+		if err != nil || class < 1 || class > 5 {
+			return StatusRange{}, invalid
+		}
+		return StatusRange{Min: class * 100, Max: class*100 + 99}, nil
+	}
+	low, high, isRange := strings.Cut(value, "-")
+	min, err := strconv.Atoi(strings.TrimSpace(low))
+	if err != nil {
+		return StatusRange{}, invalid
+	}
+	max := min
+	if isRange {
+		if max, err = strconv.Atoi(strings.TrimSpace(high)); err != nil || max < min {
+			return StatusRange{}, invalid
+		}
+	}
+	if min < 100 || max > 999 {
+		return StatusRange{}, invalid
+	}
+	return StatusRange{Min: min, Max: max}, nil
+}
+
 // IsScenarioMode returns true if the config defines a scenario (steps) rather than simple requests
 func (c *Config) IsScenarioMode() bool {
-	return len(c.Steps) > 0
+	return len(c.Steps) > 0 || len(c.Scenarios) > 0
+}
+
+// AllSteps returns the top-level steps followed by the steps of every named scenario,
+// including the steps of status branches
+func (c *Config) AllSteps() []StepConfig {
+	steps := appendWithBranches(nil, c.Steps)
+	for _, sc := range c.Scenarios {
+		steps = appendWithBranches(steps, sc.Steps)
+	}
+	return steps
+}
+
+// AllStepsWithInit returns AllSteps followed by the vuInit steps and their branches
+func (c *Config) AllStepsWithInit() []StepConfig {
+	return appendWithBranches(c.AllSteps(), c.VUInit)
+}
+
+// visitSteps calls fn with every step of the config so it can change them: the
+// top-level, scenario and vuInit steps and, recursively, their status branches
+func (c *Config) visitSteps(fn func(step *StepConfig)) {
+	visitStepList(c.Steps, fn)
+	for i := range c.Scenarios {
+		visitStepList(c.Scenarios[i].Steps, fn)
+	}
+	visitStepList(c.VUInit, fn)
+}
+
+// visitStepList calls fn with each step and the steps of its status branches
+func visitStepList(steps []StepConfig, fn func(step *StepConfig)) {
+	for i := range steps {
+		fn(&steps[i])
+		for _, branch := range steps[i].OnStatus {
+			visitStepList(branch.Steps, fn)
+		}
+	}
+}
+
+// appendWithBranches appends steps and, recursively, the steps of their status branches
+func appendWithBranches(dst, steps []StepConfig) []StepConfig {
+	for _, step := range steps {
+		dst = append(dst, step)
+		for _, branch := range step.OnStatus {
+			dst = appendWithBranches(dst, branch.Steps)
+		}
+	}
+	return dst
 }
 
 // ToRequestConfig converts a StepConfig to a RequestConfig for processing
 func (s *StepConfig) ToRequestConfig() *RequestConfig {
 	return &RequestConfig{
-		Name:     s.Name,
-		URL:      s.URL,
-		Method:   s.Method,
-		Headers:  s.Headers,
-		Body:     s.Body,
-		BodyFile: s.BodyFile,
-		Weight:   1,
+		Name:          s.Name,
+		URL:           s.URL,
+		Method:        s.Method,
+		Headers:       s.Headers,
+		RotateHeaders: s.RotateHeaders,
+		Body:          s.Body,
+		BodyFile:      s.BodyFile,
+		BodyTemplate:  s.BodyTemplate,
+		Weight:        1,
 	}
 }
 
@@ -80,9 +734,40 @@ type ThresholdConfig struct {
 	MaxP50Latency        string  `json:"maxP50Latency,omitempty"`        // Maximum P50 latency
 	MaxP75Latency        string  `json:"maxP75Latency,omitempty"`        // Maximum P75 latency
 	MaxP90Latency        string  `json:"maxP90Latency,omitempty"`        // Maximum P90 latency
+	MaxP95Latency        string  `json:"maxP95Latency,omitempty"`        // Maximum P95 latency
 	MaxP99Latency        string  `json:"maxP99Latency,omitempty"`        // Maximum P99 latency
+	MaxP999Latency       string  `json:"maxP999Latency,omitempty"`       // Maximum P99.9 latency
 	MinRequestsPerSecond float64 `json:"minRequestsPerSecond,omitempty"` // Minimum requests per second
 	MaxRequestsPerSecond float64 `json:"maxRequestsPerSecond,omitempty"` // Maximum requests per second (for rate limiting validation)
+	MinThroughputMBps    float64 `json:"minThroughputMBps,omitempty"`    // Minimum response throughput in MB/s (global thresholds only)
+	MinTotalBytes        int64   `json:"minTotalBytes,omitempty"`        // Minimum response bytes received (global thresholds only)
+	MinSLOCompliance     float64 `json:"minSloCompliance,omitempty"`     // Minimum share of good requests (requires slo, global thresholds only)
+	MaxBurnRate          float64 `json:"maxBurnRate,omitempty"`          // Maximum error budget burn rate (requires slo, global thresholds only)
+	MaxBudgetConsumed    float64 `json:"maxBudgetConsumed,omitempty"`    // Maximum share of the period's error budget used by the run (requires slo)
+}
+
+// RollingThresholdConfig checks thresholds over a sliding window while the benchmark
+// runs, optionally aborting it as soon as the service is clearly failing
+type RollingThresholdConfig struct {
+	ThresholdConfig
+	Window       string `json:"window,omitempty"`       // Sliding window length (default "10s"), evaluated every second
+	Abort        bool   `json:"abort,omitempty"`        // Stop the benchmark when a window violates the thresholds
+	GraceWindows int    `json:"graceWindows,omitempty"` // Windows a violation may last before aborting (default 0)
+}
+
+// WindowDuration returns the sliding window length
+func (r *RollingThresholdConfig) WindowDuration() (time.Duration, error) {
+	if r.Window == "" {
+		return 10 * time.Second, nil
+	}
+	window, err := time.ParseDuration(r.Window)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rolling threshold window: %w", err)
+	}
+	if window < time.Second {
+		return 0, fmt.Errorf("rolling threshold window must be at least 1s")
+	}
+	return window, nil
 }
 
 // HasThresholds returns true if any thresholds are defined
@@ -92,9 +777,34 @@ func (t *ThresholdConfig) HasThresholds() bool {
 		t.MaxP50Latency != "" ||
 		t.MaxP75Latency != "" ||
 		t.MaxP90Latency != "" ||
+		t.MaxP95Latency != "" ||
 		t.MaxP99Latency != "" ||
+		t.MaxP999Latency != "" ||
 		t.MinRequestsPerSecond > 0 ||
-		t.MaxRequestsPerSecond > 0
+		t.MaxRequestsPerSecond > 0 ||
+		t.MinThroughputMBps > 0 ||
+		t.MinTotalBytes > 0 ||
+		t.MinSLOCompliance > 0 ||
+		t.MaxBurnRate > 0 ||
+		t.MaxBudgetConsumed > 0
+}
+
+// HasThresholds returns true if global or any per-step thresholds are defined
+func (c *Config) HasThresholds() bool {
+	if c.Thresholds.HasThresholds() {
+		return true
+	}
+	for _, step := range c.AllSteps() {
+		if step.Thresholds != nil && step.Thresholds.HasThresholds() {
+			return true
+		}
+	}
+	for _, txn := range c.Transactions {
+		if txn.Thresholds != nil && txn.Thresholds.HasThresholds() {
+			return true
+		}
+	}
+	return false
 }
 
 // ParseLatency parses a latency string (e.g., "500ms", "1s") and returns microseconds
@@ -109,40 +819,160 @@ func ParseLatency(latencyStr string) (int64, error) {
 	return dur.Microseconds(), nil
 }
 
+// ByteSize is a number of bytes. In JSON it is either a number or a string with a
+// unit, such as "512KB" or "50GB".
+type ByteSize int64
+
+// UnmarshalJSON accepts a byte count or a size string
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	var n int64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*b = ByteSize(n)
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("size must be a number of bytes or a string such as \"50GB\"")
+	}
+	size, err := ParseByteSize(value)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// ParseByteSize parses a size such as "1048576", "512KB" or "50GB". Units (B, KB,
+// MB, GB, TB) are powers of 1024 and case-insensitive.
+func ParseByteSize(value string) (ByteSize, error) {
+	units := []struct {
+		suffix string
+		scale  float64
+	}{{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+
+	number, scale := strings.ToUpper(strings.TrimSpace(value)), 1.0
+	for _, unit := range units {
+		if trimmed, ok := strings.CutSuffix(number, unit.suffix); ok {
+			number, scale = strings.TrimSpace(trimmed), unit.scale
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return ByteSize(n * scale), nil
+}
+
 // Settings contains global benchmark settings
 type Settings struct {
-	ConcurrentUsers  int    `json:"concurrentUsers,omitempty"`
-	Duration         string `json:"duration,omitempty"`
-	RequestsPerUser  int    `json:"requestsPerUser,omitempty"`
-	Timeout          string `json:"timeout,omitempty"`
-	Insecure         bool   `json:"insecure,omitempty"`
-	KeepAlive        *bool  `json:"keepAlive,omitempty"`        // Pointer to distinguish unset from false
-	DisableKeepAlive bool   `json:"disableKeepAlive,omitempty"` // Alternative way to disable
-	MaxConnections   int    `json:"maxConnections,omitempty"`
-	RateLimit        int    `json:"rateLimit,omitempty"`     // Requests per second limit
-	RampUp           string `json:"rampUp,omitempty"`        // Ramp-up duration (e.g., "10s")
-	Percentiles      []int  `json:"percentiles,omitempty"`   // Custom percentiles to report
-	ShowHistogram    bool   `json:"showHistogram,omitempty"` // Show ASCII histogram in output
-	DisableHdr       bool   `json:"disableHdr,omitempty"`    // Disable HdrHistogram
-	HTTP2            bool   `json:"http2,omitempty"`         // Enable HTTP/2
-	ShowLiveStats    bool   `json:"showLiveStats,omitempty"` // Show real-time stats during benchmark
+	ConcurrentUsers    int       `json:"concurrentUsers,omitempty"`
+	Duration           string    `json:"duration,omitempty"`
+	RequestsPerUser    int       `json:"requestsPerUser,omitempty"`
+	Workers            int       `json:"workers,omitempty"`     // Goroutines sending requests (default: concurrentUsers)
+	MaxInFlight        int       `json:"maxInFlight,omitempty"` // Requests in flight at once across all workers (default: one per worker)
+	Timeout            string    `json:"timeout,omitempty"`
+	Insecure           bool      `json:"insecure,omitempty"`
+	KeepAlive          *bool     `json:"keepAlive,omitempty"`           // Pointer to distinguish unset from false
+	DisableKeepAlive   bool      `json:"disableKeepAlive,omitempty"`    // Alternative way to disable
+	MaxIdleConns       int       `json:"maxIdleConns,omitempty"`        // Idle connections kept across all hosts (default: one per in-flight request)
+	MaxIdlePerHost     int       `json:"maxIdleConnsPerHost,omitempty"` // Idle connections kept per host (default: one per in-flight request)
+	IdleConnTimeout    string    `json:"idleConnTimeout,omitempty"`     // Close connections idle for this long (default: keep them)
+	TCPKeepAlive       string    `json:"tcpKeepAlive,omitempty"`        // Interval of TCP keep-alive probes (default 30s, "off" to disable)
+	ConnectionPerUser  bool      `json:"connectionPerUser,omitempty"`   // Give every user a dedicated connection instead of a shared pool
+	UploadBandwidth    ByteSize  `json:"uploadBandwidth,omitempty"`     // Cap on bytes sent per second per connection (e.g. "256KB")
+	DownloadBandwidth  ByteSize  `json:"downloadBandwidth,omitempty"`   // Cap on bytes received per second per connection (e.g. "1MB")
+	MaxConnections     int       `json:"maxConnections,omitempty"`
+	RateLimit          int       `json:"rateLimit,omitempty"`          // Requests per second limit
+	RampUp             string    `json:"rampUp,omitempty"`             // Ramp-up duration (e.g., "10s")
+	GracePeriod        string    `json:"gracePeriod,omitempty"`        // Time in-flight requests get to finish when stopping (default: timeout)
+	Percentiles        []float64 `json:"percentiles,omitempty"`        // Custom percentiles to report (e.g. 99.9)
+	ShowHistogram      bool      `json:"showHistogram,omitempty"`      // Show ASCII histogram in output
+	HistogramBuckets   string    `json:"histogramBuckets,omitempty"`   // Upper bounds of the histogram buckets ("100us,1ms,30s") or "log" for log-scale buckets (default: 1ms to 10s)
+	DisableHdr         bool      `json:"disableHdr,omitempty"`         // Disable HdrHistogram
+	HTTP2              bool      `json:"http2,omitempty"`              // Enable HTTP/2
+	HTTP2Connections   int       `json:"http2Connections,omitempty"`   // Spread HTTP/2 workers over this many connections per host (0 = one shared)
+	DNSCache           string    `json:"dnsCache,omitempty"`           // Resolve hosts up front and dial by IP: "once" or a TTL like "30s" (default: resolve per connection)
+	RoundRobinIPs      bool      `json:"roundRobinIPs,omitempty"`      // Spread connections over all resolved IPs of a host and report per-IP stats
+	LocalAddresses     []string  `json:"localAddresses,omitempty"`     // Local IPs to open connections from, round-robin (default: chosen by the OS)
+	IPVersion          int       `json:"ipVersion,omitempty"`          // Connect over IPv4 (4) or IPv6 (6) only (default: either)
+	Interface          string    `json:"interface,omitempty"`          // Network interface connections leave through (Linux only, e.g. "eth1")
+	Engine             string    `json:"engine,omitempty"`             // HTTP client engine: "nethttp" (default) or "fasthttp"
+	Prewarm            bool      `json:"prewarm,omitempty"`            // Open every user's connection to each host before measuring
+	ShowLiveStats      bool      `json:"showLiveStats,omitempty"`      // Show real-time stats during benchmark
+	CaptureFailures    int       `json:"captureFailures,omitempty"`    // Save the first N failing exchanges per error category
+	CaptureDir         string    `json:"captureDir,omitempty"`         // Directory for captured failures (default "failures")
+	RequestIDHeader    string    `json:"requestIdHeader,omitempty"`    // Header set to a unique ID on every request (e.g. "X-Request-ID")
+	VerifyRequestID    bool      `json:"verifyRequestId,omitempty"`    // Fail responses that don't echo the request ID in the same header
+	ErrorSamples       int       `json:"errorSamples,omitempty"`       // Example responses kept per error message for the JSON and HTML reports
+	CacheStats         bool      `json:"cacheStats,omitempty"`         // Report cache hit ratio and hit/miss latencies from CDN cache headers
+	Dashboard          string    `json:"dashboard,omitempty"`          // Address to serve the live web dashboard on (e.g. ":9090")
+	ReportInterval     string    `json:"reportInterval,omitempty"`     // Print interim stats this often during the run (e.g. "30s")
+	TUI                bool      `json:"tui,omitempty"`                // Full-screen terminal dashboard instead of the progress bar
+	Checkpoint         string    `json:"checkpoint,omitempty"`         // File to periodically save progress to, for --resume
+	CheckpointInterval string    `json:"checkpointInterval,omitempty"` // Time between checkpoints (default 30s)
+	StopAfterBytes     ByteSize  `json:"stopAfterBytes,omitempty"`     // Stop once this much response data is received (e.g. "50GB")
+	MaxMemory          ByteSize  `json:"maxMemory,omitempty"`          // Memory budget; latency samples are downsampled near it (e.g. "512MB")
+	Until              string    `json:"until,omitempty"`              // Wall-clock deadline to stop by (RFC 3339, e.g. "2024-07-01T06:00:00Z")
+	PluginsDir         string    `json:"pluginsDir,omitempty"`         // Directory of plugin manifests (protocols, auth, sinks)
+	AuthPlugin         string    `json:"authPlugin,omitempty"`         // Plugin supplying headers for every request
+	Script             string    `json:"script,omitempty"`             // wrk-style Lua script (setup/init/delay/request/response/done)
+	ScriptArgs         []string  `json:"scriptArgs,omitempty"`         // Arguments passed to the script's init function
+	JSScript           string    `json:"jsScript,omitempty"`           // JavaScript file with the functions of jsRequest, jsCheck and iteration
+
+	CaptureResponses *ResponseCaptureConfig `json:"captureResponses,omitempty"` // Save a random sample of exchanges to disk
+}
+
+// ResponseCaptureConfig samples request/response pairs to disk for spot checks
+type ResponseCaptureConfig struct {
+	Rate float64 `json:"rate"`          // Share of responses saved (e.g. 0.01 for 1%)
+	Dir  string  `json:"dir,omitempty"` // Directory for the samples (default "captures")
 }
 
 // RequestConfig represents a single request definition
 type RequestConfig struct {
-	Name     string            `json:"name"`
-	URL      string            `json:"url"`
-	Method   string            `json:"method,omitempty"`
-	Headers  map[string]string `json:"headers,omitempty"`
-	Body     interface{}       `json:"body,omitempty"`
-	BodyFile string            `json:"bodyFile,omitempty"`
-	Weight   int               `json:"weight,omitempty"`
+	Name           string            `json:"name"`
+	URL            string            `json:"url"`
+	Method         string            `json:"method,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	RotateHeaders  HeaderPools       `json:"rotateHeaders,omitempty"` // Header values picked per request from a list
+	Body           interface{}       `json:"body,omitempty"`
+	BodyFile       string            `json:"bodyFile,omitempty"`
+	BodyTemplate   string            `json:"bodyTemplate,omitempty"` // text/template rendered per request ("@path" reads a file)
+	Weight         int               `json:"weight,omitempty"`
+	RateLimit      int               `json:"rateLimit,omitempty"`      // Requests per second cap for this request (0 = only the global limit)
+	ExpectedStatus StatusList        `json:"expectedStatus,omitempty"` // Response statuses counted as success (default: 2xx)
+	SHA256         string            `json:"sha256,omitempty"`         // Expected hex SHA-256 of the body
+	Size           int64             `json:"size,omitempty"`           // Expected body size in bytes
+	JSRequest      string            `json:"jsRequest,omitempty"`      // jsScript function that changes the request before it is sent
+	JSCheck        string            `json:"jsCheck,omitempty"`        // jsScript function that checks the response; failing it fails the request
+}
+
+// BodyChecks returns the body size and checksum checks of the request as
+// validation rules, or nil when it has none
+func (r *RequestConfig) BodyChecks() *ValidateConfig {
+	if r.SHA256 == "" && r.Size == 0 {
+		return nil
+	}
+	return &ValidateConfig{SHA256: r.SHA256, Size: r.Size}
+}
+
+// IsExpectedStatus reports whether a response status counts as success
+func (r *RequestConfig) IsExpectedStatus(status int) bool {
+	if len(r.ExpectedStatus) == 0 {
+		return status >= 200 && status < 300
+	}
+	return r.ExpectedStatus.Contains(status)
 }
 
 // OutputConfig defines output settings
 type OutputConfig struct {
-	Format string `json:"format,omitempty"`
-	File   string `json:"file,omitempty"`
+	Format     string   `json:"format,omitempty"`
+	File       string   `json:"file,omitempty"`
+	Plugins    []string `json:"plugins,omitempty"`    // Sink plugins that also receive the results
+	Sinks      []string `json:"sinks,omitempty"`      // Metrics sinks fed during the run (console, json:<file>, statsd:<host:port>, prometheus:<addr>)
+	Mergeable  bool     `json:"mergeable,omitempty"`  // Embed the raw stats in JSON results so `merge` can combine them
+	HistoryDir string   `json:"historyDir,omitempty"` // Directory each run's summary is appended to, for the `trend` subcommand
 }
 
 // Header represents an HTTP header (for CLI flags)
@@ -167,14 +997,109 @@ func (h *HeaderSliceFlag) Set(value string) error {
 	return nil
 }
 
-// IntSliceFlag is a custom flag type for handling multiple integers (percentiles)
-type IntSliceFlag []int
+// HeaderPool is a list of values for a single header, one of which is picked per request.
+// In JSON it is either an array of strings or a "@path" string naming a file with one value per line.
+type HeaderPool []string
+
+// HeaderPools maps header names to their rotating values
+type HeaderPools map[string]HeaderPool
+
+// UnmarshalJSON decodes the pools, rejecting a header without values
+func (p *HeaderPools) UnmarshalJSON(data []byte) error {
+	var pools map[string]HeaderPool
+	if err := json.Unmarshal(data, &pools); err != nil {
+		return err
+	}
+	for name, pool := range pools {
+		if len(pool) == 0 {
+			return fmt.Errorf("rotating header %s has no values", name)
+		}
+	}
+	*p = pools
+	return nil
+}
+
+// UnmarshalJSON accepts either a list of values or an "@path" file reference
+func (p *HeaderPool) UnmarshalJSON(data []byte) error {
+	var values []string
+	if err := json.Unmarshal(data, &values); err == nil {
+		*p = values
+		return nil
+	}
+
+	var ref string
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return fmt.Errorf("rotating header must be a list of strings or an \"@file\" reference")
+	}
+	if !strings.HasPrefix(ref, "@") {
+		*p = HeaderPool{ref}
+		return nil
+	}
+
+	pool, err := LoadHeaderPool(strings.TrimPrefix(ref, "@"))
+	if err != nil {
+		return err
+	}
+	*p = pool
+	return nil
+}
+
+// LoadHeaderPool reads header values from a file, one per line, skipping blank lines and # comments
+func LoadHeaderPool(filename string) (HeaderPool, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header file: %w", err)
+	}
+
+	var pool HeaderPool
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pool = append(pool, line)
+	}
+	if len(pool) == 0 {
+		return nil, fmt.Errorf("header file %s contains no values", filename)
+	}
+	return pool, nil
+}
+
+// Pick returns a random value from the pool
+func (p HeaderPool) Pick() string {
+	switch len(p) {
+	case 0:
+		return ""
+	case 1:
+		return p[0]
+	}
+	return p[rand.Intn(len(p))]
+}
+
+// HeaderFileFlag is a custom flag type for rotating headers loaded from files (format: 'key:path')
+type HeaderFileFlag []Header
+
+func (h *HeaderFileFlag) String() string {
+	return fmt.Sprintf("%v", *h)
+}
+
+func (h *HeaderFileFlag) Set(value string) error {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("header file must be in format 'key:path'")
+	}
+	*h = append(*h, Header{Key: strings.TrimSpace(parts[0]), Value: strings.TrimSpace(parts[1])})
+	return nil
+}
+
+// FloatSliceFlag is a custom flag type for handling multiple numbers (percentiles such as 99.9)
+type FloatSliceFlag []float64
 
-func (i *IntSliceFlag) String() string {
-	return fmt.Sprintf("%v", *i)
+func (f *FloatSliceFlag) String() string {
+	return fmt.Sprintf("%v", *f)
 }
 
-func (i *IntSliceFlag) Set(value string) error {
+func (f *FloatSliceFlag) Set(value string) error {
 	// Parse comma-separated values
 	parts := strings.Split(value, ",")
 	for _, p := range parts {
@@ -182,15 +1107,58 @@ func (i *IntSliceFlag) Set(value string) error {
 		if p == "" {
 			continue
 		}
-		val, err := strconv.Atoi(p)
+		val, err := strconv.ParseFloat(p, 64)
 		if err != nil {
 			return fmt.Errorf("invalid percentile value: %s", p)
 		}
 		if val < 0 || val > 100 {
-			return fmt.Errorf("percentile must be between 0 and 100: %d", val)
+			return fmt.Errorf("percentile must be between 0 and 100: %s", p)
+		}
+		*f = append(*f, val)
+	}
+	return nil
+}
+
+// StdinBody is the bodyFile value (and --body argument) that reads the request body from stdin
+const StdinBody = "-"
+
+// ReadStdinBody reads stdin once and uses it as the body for every request or step
+// whose bodyFile is "-", including scenario, vuInit and status branch steps.
+// Stdin is only consumed if at least one body references it.
+func (c *Config) ReadStdinBody() error {
+	needsStdin := false
+	for i := range c.Requests {
+		if c.Requests[i].BodyFile == StdinBody {
+			needsStdin = true
 		}
-		*i = append(*i, val)
 	}
+	c.visitSteps(func(step *StepConfig) {
+		if step.BodyFile == StdinBody {
+			needsStdin = true
+		}
+	})
+	if !needsStdin {
+		return nil
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read body from stdin: %w", err)
+	}
+	body := string(data)
+
+	for i := range c.Requests {
+		if c.Requests[i].BodyFile == StdinBody {
+			c.Requests[i].BodyFile = ""
+			c.Requests[i].Body = body
+		}
+	}
+	c.visitSteps(func(step *StepConfig) {
+		if step.BodyFile == StdinBody {
+			step.BodyFile = ""
+			step.Body = body
+		}
+	})
 	return nil
 }
 
@@ -201,9 +1169,18 @@ func Load(filename string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	config, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return config, nil
+}
+
+// Parse parses a JSON configuration and applies the defaults
+func Parse(data []byte) (*Config, error) {
 	var config Config
 	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+		return nil, err
 	}
 
 	// Set defaults
@@ -226,7 +1203,7 @@ func (c *Config) SetDefaults() {
 
 	// Set default percentiles if not specified
 	if len(c.Settings.Percentiles) == 0 {
-		c.Settings.Percentiles = []int{50, 75, 90, 99}
+		c.Settings.Percentiles = []float64{50, 75, 90, 99}
 	}
 
 	// Initialize variables map if nil
@@ -261,6 +1238,50 @@ func (c *Config) SetDefaults() {
 			c.Steps[i].Name = fmt.Sprintf("Step %d", i+1)
 		}
 	}
+	for i := range c.Scenarios {
+		sc := &c.Scenarios[i]
+		if sc.Name == "" {
+			sc.Name = fmt.Sprintf("Scenario %d", i+1)
+		}
+		for j := range sc.Steps {
+			if sc.Steps[j].Method == "" {
+				sc.Steps[j].Method = "GET"
+			}
+			if sc.Steps[j].Name == "" {
+				sc.Steps[j].Name = fmt.Sprintf("%s: Step %d", sc.Name, j+1)
+			}
+		}
+	}
+	for i := range c.VUInit {
+		if c.VUInit[i].Method == "" {
+			c.VUInit[i].Method = "GET"
+		}
+		if c.VUInit[i].Name == "" {
+			c.VUInit[i].Name = fmt.Sprintf("Init Step %d", i+1)
+		}
+	}
+	setBranchDefaults(c.Steps)
+	setBranchDefaults(c.VUInit)
+	for _, sc := range c.Scenarios {
+		setBranchDefaults(sc.Steps)
+	}
+}
+
+// setBranchDefaults names the steps of status branches after the step that owns them
+func setBranchDefaults(steps []StepConfig) {
+	for i := range steps {
+		for status, branch := range steps[i].OnStatus {
+			for j := range branch.Steps {
+				if branch.Steps[j].Method == "" {
+					branch.Steps[j].Method = "GET"
+				}
+				if branch.Steps[j].Name == "" {
+					branch.Steps[j].Name = fmt.Sprintf("%s (on %s): Step %d", steps[i].Name, status, j+1)
+				}
+			}
+			setBranchDefaults(branch.Steps)
+		}
+	}
 }
 
 // GetDurationSeconds parses the duration string and returns seconds
@@ -275,16 +1296,16 @@ func (c *Config) GetDurationSeconds() (int, error) {
 	return int(dur.Seconds()), nil
 }
 
-// GetTimeoutSeconds parses the timeout string and returns seconds
-func (c *Config) GetTimeoutSeconds() int {
+// GetTimeout parses the per-request timeout, defaulting to 30 seconds
+func (c *Config) GetTimeout() time.Duration {
 	if c.Settings.Timeout == "" {
-		return 30
+		return 30 * time.Second
 	}
 	dur, err := time.ParseDuration(c.Settings.Timeout)
-	if err != nil {
-		return 30
+	if err != nil || dur <= 0 {
+		return 30 * time.Second
 	}
-	return int(dur.Seconds())
+	return dur
 }
 
 // GetRampUpSeconds parses the ramp-up string and returns seconds
@@ -299,6 +1320,178 @@ func (c *Config) GetRampUpSeconds() int {
 	return int(dur.Seconds())
 }
 
+// GetGracePeriod parses the grace period, returning defaultGrace when none is set.
+// "0" stops immediately, cancelling in-flight requests.
+func (c *Config) GetGracePeriod(defaultGrace time.Duration) (time.Duration, error) {
+	if c.Settings.GracePeriod == "" {
+		return defaultGrace, nil
+	}
+	grace, err := time.ParseDuration(c.Settings.GracePeriod)
+	if err != nil {
+		return 0, fmt.Errorf("invalid grace period format: %w", err)
+	}
+	if grace < 0 {
+		return 0, fmt.Errorf("grace period must not be negative")
+	}
+	return grace, nil
+}
+
+// GetUntil parses the wall-clock deadline, returning the zero time when there is none
+func (c *Config) GetUntil() (time.Time, error) {
+	if c.Settings.Until == "" {
+		return time.Time{}, nil
+	}
+	deadline, err := time.Parse(time.RFC3339, c.Settings.Until)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid deadline %q: expected RFC 3339 time such as 2024-07-01T06:00:00Z", c.Settings.Until)
+	}
+	return deadline, nil
+}
+
+// GetReportInterval parses the interim report interval, returning 0 when reports are off
+func (c *Config) GetReportInterval() (time.Duration, error) {
+	if c.Settings.ReportInterval == "" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(c.Settings.ReportInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid report interval format: %w", err)
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("report interval must be positive")
+	}
+	return interval, nil
+}
+
+// GetCheckpointInterval parses the checkpoint interval, defaulting to 30 seconds
+func (c *Config) GetCheckpointInterval() (time.Duration, error) {
+	if c.Settings.CheckpointInterval == "" {
+		return 30 * time.Second, nil
+	}
+	interval, err := time.ParseDuration(c.Settings.CheckpointInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid checkpoint interval format: %w", err)
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("checkpoint interval must be positive")
+	}
+	return interval, nil
+}
+
+// GetDNSCache parses the DNS cache setting. ttl is 0 when hosts are resolved
+// only once; "off" (or no setting) disables the cache.
+func (c *Config) GetDNSCache() (enabled bool, ttl time.Duration, err error) {
+	switch c.Settings.DNSCache {
+	case "", "off":
+		return false, 0, nil
+	case "once":
+		return true, 0, nil
+	}
+	ttl, err = time.ParseDuration(c.Settings.DNSCache)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid DNS cache %q: expected once, off or a TTL such as 30s", c.Settings.DNSCache)
+	}
+	if ttl <= 0 {
+		return false, 0, fmt.Errorf("DNS cache TTL must be positive")
+	}
+	return true, ttl, nil
+}
+
+// GetHistogramBuckets returns the configured upper bounds of the latency
+// histogram's buckets in microseconds, or logScale for buckets on a 1-2-5 log
+// scale spanning the recorded latencies. Both are empty for the default buckets.
+func (c *Config) GetHistogramBuckets() (bounds []int64, logScale bool, err error) {
+	value := strings.TrimSpace(c.Settings.HistogramBuckets)
+	switch strings.ToLower(value) {
+	case "", "default":
+		return nil, false, nil
+	case "log":
+		return nil, true, nil
+	}
+	for _, part := range strings.Split(value, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || d.Microseconds() < 1 {
+			return nil, false, fmt.Errorf("invalid histogram bucket %q: expected log or durations such as 100us,1ms,30s", strings.TrimSpace(part))
+		}
+		bound := d.Microseconds()
+		if len(bounds) > 0 && bound <= bounds[len(bounds)-1] {
+			return nil, false, fmt.Errorf("histogram buckets must be in ascending order")
+		}
+		bounds = append(bounds, bound)
+	}
+	return bounds, false, nil
+}
+
+// ValidateLocalAddresses checks the IP version and that every local address
+// is an IP of that version
+func (c *Config) ValidateLocalAddresses() error {
+	version := c.Settings.IPVersion
+	if version != 0 && version != 4 && version != 6 {
+		return fmt.Errorf("invalid ipVersion %d: expected 4 or 6", version)
+	}
+	for _, addr := range c.Settings.LocalAddresses {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			return fmt.Errorf("invalid local address %q: expected an IP address", addr)
+		}
+		if (version == 4 && ip.To4() == nil) || (version == 6 && ip.To4() != nil) {
+			return fmt.Errorf("local address %s is not an IPv%d address", addr, version)
+		}
+	}
+	return nil
+}
+
+// GetNetwork returns the network to dial: "tcp4" or "tcp6" when an IP version
+// is forced, otherwise "tcp"
+func (c *Config) GetNetwork() string {
+	switch c.Settings.IPVersion {
+	case 4:
+		return "tcp4"
+	case 6:
+		return "tcp6"
+	}
+	return "tcp"
+}
+
+// GetLocalAddresses returns the local IPs to open connections from (nil to
+// let the OS choose). Invalid entries are skipped; Validate reports them.
+func (c *Config) GetLocalAddresses() []net.IP {
+	var ips []net.IP
+	for _, addr := range c.Settings.LocalAddresses {
+		if ip := net.ParseIP(addr); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// HTTP client engines
+const (
+	EngineNetHTTP  = "nethttp"  // Go's net/http client (default)
+	EngineFastHTTP = "fasthttp" // fasthttp client, HTTP/1.1 only
+)
+
+// ValidateEngine checks the HTTP client engine and connection settings
+func (c *Config) ValidateEngine() error {
+	if c.Settings.HTTP2Connections < 0 {
+		return fmt.Errorf("http2Connections must not be negative")
+	}
+	if c.Settings.HTTP2Connections > 0 && !c.Settings.HTTP2 {
+		return fmt.Errorf("http2Connections requires http2")
+	}
+	switch c.Settings.Engine {
+	case "", EngineNetHTTP:
+		return nil
+	case EngineFastHTTP:
+		if c.Settings.HTTP2 {
+			return fmt.Errorf("the %s engine only supports HTTP/1.1, disable http2 or use the %s engine", EngineFastHTTP, EngineNetHTTP)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown engine %q (expected %s or %s)", c.Settings.Engine, EngineNetHTTP, EngineFastHTTP)
+	}
+}
+
 // IsKeepAliveDisabled returns true if keep-alive should be disabled
 func (c *Config) IsKeepAliveDisabled() bool {
 	if c.Settings.DisableKeepAlive {
@@ -339,16 +1532,26 @@ func ResolveVariables(input string, variables map[string]string) string {
 
 // PrepareRequestBody prepares the request body from config
 func PrepareRequestBody(reqConfig *RequestConfig) (string, error) {
-	if reqConfig.BodyFile != "" {
-		data, err := os.ReadFile(reqConfig.BodyFile)
+	return prepareBody(reqConfig.Body, reqConfig.BodyFile)
+}
+
+// PrepareStepBody prepares a scenario step's body from config, before variables are resolved
+func PrepareStepBody(step *StepConfig) (string, error) {
+	return prepareBody(step.Body, step.BodyFile)
+}
+
+// prepareBody reads a body file or serializes an inline body
+func prepareBody(body interface{}, bodyFile string) (string, error) {
+	if bodyFile != "" {
+		data, err := os.ReadFile(bodyFile)
 		if err != nil {
 			return "", fmt.Errorf("failed to read body file: %w", err)
 		}
 		return string(data), nil
 	}
 
-	if reqConfig.Body != nil {
-		switch v := reqConfig.Body.(type) {
+	if body != nil {
+		switch v := body.(type) {
 		case string:
 			return v, nil
 		default:
@@ -372,9 +1575,9 @@ func (c *Config) ResolveRequestVariables() {
 
 // NewFromCLI creates a Config from command-line arguments
 func NewFromCLI(url, method string, headers HeaderSliceFlag, body, contentType string,
-	concurrentUsers, requestsPerUser, durationSeconds int, insecure bool,
-	outputFormat, outputFile string, rateLimit, rampUpSeconds int,
-	disableKeepAlive bool, percentiles []int, showHistogram, disableHdr bool,
+	concurrentUsers, requestsPerUser int, duration string, insecure bool,
+	outputFormat, outputFile string, rateLimit int, rampUp string,
+	disableKeepAlive bool, percentiles []float64, showHistogram, disableHdr bool,
 	http2, showLiveStats bool) *Config {
 
 	config := &Config{
@@ -411,8 +1614,10 @@ func NewFromCLI(url, method string, headers HeaderSliceFlag, body, contentType s
 		}
 	}
 
-	// Add body from CLI
-	if body != "" {
+	// Add body from CLI ("-" reads the body from stdin)
+	if body == StdinBody {
+		config.Requests[0].BodyFile = StdinBody
+	} else if body != "" {
 		config.Requests[0].Body = body
 	}
 
@@ -424,19 +1629,13 @@ func NewFromCLI(url, method string, headers HeaderSliceFlag, body, contentType s
 		config.Requests[0].Headers["Content-Type"] = contentType
 	}
 
-	// Set duration
-	if durationSeconds > 0 {
-		config.Settings.Duration = fmt.Sprintf("%ds", durationSeconds)
-	}
-
-	// Set ramp-up
-	if rampUpSeconds > 0 {
-		config.Settings.RampUp = fmt.Sprintf("%ds", rampUpSeconds)
-	}
+	// Set duration and ramp-up (Go durations such as "90s")
+	config.Settings.Duration = duration
+	config.Settings.RampUp = rampUp
 
 	// Set default percentiles if empty
 	if len(config.Settings.Percentiles) == 0 {
-		config.Settings.Percentiles = []int{50, 75, 90, 99}
+		config.Settings.Percentiles = []float64{50, 75, 90, 99}
 	}
 
 	return config
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultTCPKeepAlive is the interval of TCP keep-alive probes unless configured
+const defaultTCPKeepAlive = 30 * time.Second
+
+// GetIdleConnTimeout returns how long a pooled connection may stay idle before
+// it is closed (0 = no limit)
+func (c *Config) GetIdleConnTimeout() (time.Duration, error) {
+	if c.Settings.IdleConnTimeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(c.Settings.IdleConnTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid idleConnTimeout: %w", err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("idleConnTimeout must be positive")
+	}
+	return timeout, nil
+}
+
+// GetTCPKeepAlive returns the interval of TCP keep-alive probes on new
+// connections; it is negative when they are turned off with "off"
+func (c *Config) GetTCPKeepAlive() (time.Duration, error) {
+	switch c.Settings.TCPKeepAlive {
+	case "":
+		return defaultTCPKeepAlive, nil
+	case "off":
+		return -1, nil
+	}
+	interval, err := time.ParseDuration(c.Settings.TCPKeepAlive)
+	if err != nil {
+		return 0, fmt.Errorf("invalid tcpKeepAlive %q: expected off or an interval such as 15s", c.Settings.TCPKeepAlive)
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("tcpKeepAlive must be positive (use off to disable it)")
+	}
+	return interval, nil
+}
+
+// GetMaxIdleConns returns how many idle connections are kept in total and per
+// host, defaulting to one per in-flight request
+func (c *Config) GetMaxIdleConns() (total, perHost int) {
+	total, perHost = c.InFlightLimit(), c.InFlightLimit()
+	if c.Settings.MaxIdleConns > 0 {
+		total = c.Settings.MaxIdleConns
+	}
+	if c.Settings.MaxIdlePerHost > 0 {
+		perHost = c.Settings.MaxIdlePerHost
+	}
+	return total, perHost
+}
+
+// ValidateConnectionPool checks the connection pool, TCP keep-alive and bandwidth settings
+func (c *Config) ValidateConnectionPool() error {
+	if c.Settings.MaxIdleConns < 0 || c.Settings.MaxIdlePerHost < 0 {
+		return fmt.Errorf("maxIdleConns and maxIdleConnsPerHost must not be negative")
+	}
+	if c.Settings.UploadBandwidth < 0 || c.Settings.DownloadBandwidth < 0 {
+		return fmt.Errorf("uploadBandwidth and downloadBandwidth must not be negative")
+	}
+	if c.Settings.ConnectionPerUser && c.Settings.HTTP2Connections > 0 {
+		return fmt.Errorf("connectionPerUser cannot be combined with http2Connections")
+	}
+	if _, err := c.GetIdleConnTimeout(); err != nil {
+		return err
+	}
+	_, err := c.GetTCPKeepAlive()
+	return err
+}
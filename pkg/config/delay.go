@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Delay is a distribution of durations, used for step think times and the
+// test server's response latency
+type Delay struct {
+	kind string        // "", "uniform", "normal" or "exponential"
+	a, b time.Duration // fixed: a; uniform: a to b; normal: mean a, stddev b; exponential: mean a
+}
+
+// ParseDelay parses a delay specification. Supported forms:
+//   - "500ms" - fixed delay
+//   - "uniform(200ms,800ms)" - uniformly distributed between min and max
+//   - "normal(500ms,100ms)" - normally distributed with mean and standard deviation
+//   - "exponential(500ms)" - exponentially distributed with the given mean
+func ParseDelay(spec string) (Delay, error) {
+	spec = strings.TrimSpace(spec)
+
+	open := strings.Index(spec, "(")
+	if open == -1 {
+		d, err := time.ParseDuration(spec)
+		if err != nil {
+			return Delay{}, fmt.Errorf("invalid delay %q: %w", spec, err)
+		}
+		if d < 0 {
+			return Delay{}, fmt.Errorf("invalid delay %q: must not be negative", spec)
+		}
+		return Delay{a: d}, nil
+	}
+	if !strings.HasSuffix(spec, ")") {
+		return Delay{}, fmt.Errorf("invalid delay distribution: %s", spec)
+	}
+
+	name := strings.ToLower(strings.TrimSpace(spec[:open]))
+	args := strings.Split(spec[open+1:len(spec)-1], ",")
+	params := make([]time.Duration, 0, len(args))
+	for _, arg := range args {
+		d, err := time.ParseDuration(strings.TrimSpace(arg))
+		if err != nil {
+			return Delay{}, fmt.Errorf("invalid delay distribution %s: %w", spec, err)
+		}
+		if d < 0 {
+			return Delay{}, fmt.Errorf("invalid delay distribution %s: durations must not be negative", spec)
+		}
+		params = append(params, d)
+	}
+
+	switch {
+	case name == "uniform" && len(params) == 2:
+		min, max := params[0], params[1]
+		if max < min {
+			min, max = max, min
+		}
+		return Delay{kind: name, a: min, b: max}, nil
+	case name == "normal" && len(params) == 2:
+		return Delay{kind: name, a: params[0], b: params[1]}, nil
+	case name == "exponential" && len(params) == 1:
+		return Delay{kind: name, a: params[0]}, nil
+	}
+	return Delay{}, fmt.Errorf("unsupported delay distribution: %s (expected uniform, normal or exponential)", spec)
+}
+
+// Sample returns one duration drawn from the distribution. Sampled values are
+// never negative.
+func (d Delay) Sample() time.Duration {
+	var delay time.Duration
+	switch d.kind {
+	case "uniform":
+		delay = d.a
+		if d.b > d.a {
+			delay += time.Duration(rand.Int63n(int64(d.b - d.a + 1)))
+		}
+	case "normal":
+		delay = time.Duration(float64(d.a) + rand.NormFloat64()*float64(d.b))
+	case "exponential":
+		delay = time.Duration(rand.ExpFloat64() * float64(d.a))
+	default:
+		delay = d.a
+	}
+	return time.Duration(math.Max(0, float64(delay)))
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// FunctionConfig declares a custom {{$name}} template function for scenario
+// steps. It either picks one of a list of values at random or prints the
+// output of a command:
+//
+//	"functions": {
+//	  "region": {"values": ["eu", "us", "ap"]},
+//	  "token":  {"exec": ["./new-token.sh", "--scope", "api"], "every": "5m"}
+//	}
+type FunctionConfig struct {
+	Values []string `json:"values,omitempty"` // Picked at random on every use
+	Exec   []string `json:"exec,omitempty"`   // Command and arguments; its trimmed output is the value
+	Every  string   `json:"every,omitempty"`  // How long a command's output is reused (default: the whole run)
+}
+
+// functionName matches the names usable as {{$name}}
+var functionName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidFunctionName reports whether name can be used as a {{$name}} function
+func ValidFunctionName(name string) bool {
+	return functionName.MatchString(name)
+}
+
+// GetEvery returns how long a command's output is reused (0 = for the whole run)
+func (f FunctionConfig) GetEvery() (time.Duration, error) {
+	if f.Every == "" {
+		return 0, nil
+	}
+	every, err := time.ParseDuration(f.Every)
+	if err != nil {
+		return 0, fmt.Errorf("invalid every: %w", err)
+	}
+	if every <= 0 {
+		return 0, fmt.Errorf("every must be positive")
+	}
+	return every, nil
+}
+
+// ValidateFunctions checks the declared template functions
+func (c *Config) ValidateFunctions() error {
+	names := make([]string, 0, len(c.Functions))
+	for name := range c.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fn := c.Functions[name]
+		if !ValidFunctionName(name) {
+			return fmt.Errorf("invalid function name %q (use letters, digits and underscores)", name)
+		}
+		if (len(fn.Values) > 0) == (len(fn.Exec) > 0) {
+			return fmt.Errorf("function %q needs either values or exec", name)
+		}
+		if fn.Every != "" && len(fn.Exec) == 0 {
+			return fmt.Errorf("function %q: every only applies to exec functions", name)
+		}
+		if _, err := fn.GetEvery(); err != nil {
+			return fmt.Errorf("function %q: %w", name, err)
+		}
+	}
+	return nil
+}
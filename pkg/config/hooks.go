@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultHookTimeout is how long a run hook may take unless configured
+const defaultHookTimeout = 5 * time.Minute
+
+// RunHooksConfig holds commands run around the benchmark, e.g. to seed a
+// database, fetch a token or notify a channel:
+//
+//	"hooks": {"preRun": ["./seed.sh", "--orders", "1000"], "postRun": ["./notify.sh"]}
+type RunHooksConfig struct {
+	PreRun               []string `json:"preRun,omitempty"`               // Command and arguments run before the benchmark; a failure aborts it
+	PostRun              []string `json:"postRun,omitempty"`              // Command and arguments run after it; a failure fails the run
+	Timeout              string   `json:"timeout,omitempty"`              // Per command (default 5m)
+	IgnorePostRunFailure bool     `json:"ignorePostRunFailure,omitempty"` // Only warn when postRun fails
+}
+
+// GetTimeout returns how long each hook command may run
+func (h *RunHooksConfig) GetTimeout() (time.Duration, error) {
+	if h.Timeout == "" {
+		return defaultHookTimeout, nil
+	}
+	timeout, err := time.ParseDuration(h.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hooks timeout: %w", err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("hooks timeout must be positive")
+	}
+	return timeout, nil
+}
+
+// ValidateRunHooks checks the pre-run and post-run hooks
+func (c *Config) ValidateRunHooks() error {
+	if c.Hooks == nil {
+		return nil
+	}
+	if len(c.Hooks.PreRun) == 0 && len(c.Hooks.PostRun) == 0 {
+		return fmt.Errorf("hooks need a preRun or postRun command")
+	}
+	if (len(c.Hooks.PreRun) > 0 && c.Hooks.PreRun[0] == "") || (len(c.Hooks.PostRun) > 0 && c.Hooks.PostRun[0] == "") {
+		return fmt.Errorf("hook commands must not be empty")
+	}
+	_, err := c.Hooks.GetTimeout()
+	return err
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dop251/goja"
+)
+
+// JavaScriptFunctions returns the script functions that requests and steps
+// reference through jsRequest and jsCheck, each once
+func (c *Config) JavaScriptFunctions() []string {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, req := range c.Requests {
+		add(req.JSRequest)
+		add(req.JSCheck)
+	}
+	for _, step := range c.AllStepsWithInit() {
+		add(step.JSRequest)
+		add(step.JSCheck)
+	}
+	return names
+}
+
+// ValidateJavaScript checks that the JavaScript file compiles and that
+// requests and steps only reference script functions when there is one
+func (c *Config) ValidateJavaScript() error {
+	path := c.Settings.JSScript
+	if path == "" {
+		if names := c.JavaScriptFunctions(); len(names) > 0 {
+			return fmt.Errorf("%s refers to a script function, but there is no jsScript", names[0])
+		}
+		return nil
+	}
+	if c.Settings.Script != "" {
+		return fmt.Errorf("jsScript cannot be combined with a Lua script")
+	}
+	for _, step := range c.AllStepsWithInit() {
+		if step.WebSocket != nil && (step.JSRequest != "" || step.JSCheck != "") {
+			return fmt.Errorf("step %s: jsRequest and jsCheck are not supported on websocket steps", step.Name)
+		}
+	}
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read jsScript: %w", err)
+	}
+	if _, err := goja.Compile(path, string(source), false); err != nil {
+		return fmt.Errorf("invalid jsScript: %s", strings.TrimSpace(err.Error()))
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RunMetadata returns the metadata of the run with variables and {{env "NAME"}}
+// references resolved, so a config can pick up e.g. the git SHA from CI
+func (c *Config) RunMetadata() map[string]string {
+	if len(c.Metadata) == 0 {
+		return nil
+	}
+	metadata := make(map[string]string, len(c.Metadata))
+	for key, value := range c.Metadata {
+		metadata[key] = ResolveVariables(value, c.Variables)
+	}
+	return metadata
+}
+
+// RunLabels returns the metadata and tags of the run as labels for metric
+// exporters: every metadata entry, plus "tags" with the tags comma-separated
+func (c *Config) RunLabels() map[string]string {
+	labels := c.RunMetadata()
+	if len(c.Tags) > 0 {
+		if labels == nil {
+			labels = make(map[string]string, 1)
+		}
+		labels["tags"] = strings.Join(c.Tags, ",")
+	}
+	return labels
+}
+
+// SortedKeys returns the keys of a metadata map in order, for stable columns
+func SortedKeys(metadata map[string]string) []string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MetadataFlag is a custom flag type for run metadata entries (format: 'key=value')
+type MetadataFlag []Header
+
+func (m *MetadataFlag) String() string {
+	return fmt.Sprintf("%v", *m)
+}
+
+func (m *MetadataFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("metadata must be in format 'key=value'")
+	}
+	*m = append(*m, Header{Key: strings.TrimSpace(key), Value: strings.TrimSpace(val)})
+	return nil
+}
+
+// TagsFlag is a custom flag type for run tags (repeatable and comma-separated)
+type TagsFlag []string
+
+func (t *TagsFlag) String() string {
+	return strings.Join(*t, ",")
+}
+
+func (t *TagsFlag) Set(value string) error {
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			*t = append(*t, tag)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/benchmarking_go/pkg/plugin"
+)
+
+// Plugins returns the plugins found in the plugins directory (nil without one)
+func (c *Config) Plugins() (plugin.Registry, error) {
+	if c.Settings.PluginsDir == "" {
+		return nil, nil
+	}
+	return plugin.Discover(c.Settings.PluginsDir)
+}
+
+// ValidatePlugins checks the plugin manifests and that the plugins named in
+// the config exist and are of the right kind
+func (c *Config) ValidatePlugins() error {
+	if c.Settings.PluginsDir == "" {
+		if c.Settings.AuthPlugin != "" || len(c.Output.Plugins) > 0 {
+			return fmt.Errorf("plugins require a plugins directory (pluginsDir)")
+		}
+		return nil
+	}
+	registry, err := c.Plugins()
+	if err != nil {
+		return err
+	}
+	if c.Settings.AuthPlugin != "" {
+		if _, err := registry.Get(c.Settings.AuthPlugin, plugin.KindAuth); err != nil {
+			return err
+		}
+	}
+	for _, name := range c.Output.Plugins {
+		if _, err := registry.Get(name, plugin.KindSink); err != nil {
+			return err
+		}
+	}
+	return nil
+}
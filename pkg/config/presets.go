@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Preset is a bundle of settings for a common kind of benchmark
+type Preset struct {
+	Description     string
+	ConcurrentUsers int
+	Duration        string
+	RampUp          string
+	Thresholds      ThresholdConfig
+}
+
+// presetNames lists the built-in presets from lightest to longest
+var presetNames = []string{"smoke", "load", "stress", "soak"}
+
+// Presets are the built-in presets by name
+var Presets = map[string]Preset{
+	"smoke": {
+		Description:     "a few users for a short time, to check the target works",
+		ConcurrentUsers: 2,
+		Duration:        "30s",
+		Thresholds:      ThresholdConfig{MaxErrorRate: 0.01, MaxP95Latency: "1s"},
+	},
+	"load": {
+		Description:     "the expected production load, ramped up gradually",
+		ConcurrentUsers: 50,
+		Duration:        "5m",
+		RampUp:          "30s",
+		Thresholds:      ThresholdConfig{MaxErrorRate: 0.01, MaxP95Latency: "500ms", MaxP99Latency: "1s"},
+	},
+	"stress": {
+		Description:     "well beyond the expected load, to find the breaking point",
+		ConcurrentUsers: 200,
+		Duration:        "10m",
+		RampUp:          "2m",
+		Thresholds:      ThresholdConfig{MaxErrorRate: 0.05, MaxP99Latency: "2s"},
+	},
+	"soak": {
+		Description:     "moderate load for hours, to find leaks and slow degradation",
+		ConcurrentUsers: 30,
+		Duration:        "2h",
+		RampUp:          "1m",
+		Thresholds:      ThresholdConfig{MaxErrorRate: 0.01, MaxP95Latency: "500ms", MaxP99Latency: "1s"},
+	},
+}
+
+// PresetNames returns the names of the built-in presets
+func PresetNames() []string {
+	return presetNames
+}
+
+// ApplyPreset sets the concurrency, duration and ramp-up of a preset, and its
+// thresholds unless the config defines thresholds of its own. Command-line
+// flags given explicitly are applied afterwards and override the preset.
+func (c *Config) ApplyPreset(name string) error {
+	preset, ok := Presets[name]
+	if !ok {
+		return fmt.Errorf("unknown preset %q (expected %s)", name, strings.Join(presetNames, ", "))
+	}
+	c.Settings.ConcurrentUsers = preset.ConcurrentUsers
+	c.Settings.Duration = preset.Duration
+	c.Settings.RampUp = preset.RampUp
+	if !c.Thresholds.HasThresholds() {
+		c.Thresholds = preset.Thresholds
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// ValidateScript checks that the Lua script parses and that the benchmark is
+// one a wrk-style script can drive: a single request, not a scenario
+func (c *Config) ValidateScript() error {
+	if c.Settings.Script == "" {
+		if len(c.Settings.ScriptArgs) > 0 {
+			return fmt.Errorf("scriptArgs require a script")
+		}
+		return nil
+	}
+	if c.IsScenarioMode() {
+		return fmt.Errorf("scripts are not supported in scenario mode")
+	}
+	if len(c.Requests) != 1 {
+		return fmt.Errorf("a script needs exactly one request, got %d", len(c.Requests))
+	}
+	if c.Requests[0].BodyTemplate != "" {
+		return fmt.Errorf("a script cannot use a bodyTemplate; set wrk.body instead")
+	}
+
+	file, err := os.Open(c.Settings.Script)
+	if err != nil {
+		return fmt.Errorf("failed to read script: %w", err)
+	}
+	defer file.Close()
+	if _, err := parse.Parse(file, c.Settings.Script); err != nil {
+		return fmt.Errorf("invalid script: %s", strings.TrimSpace(err.Error()))
+	}
+	return nil
+}
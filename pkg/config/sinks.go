@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Metrics sink kinds
+const (
+	SinkConsole    = "console"    // A line of totals per second on stderr
+	SinkJSON       = "json"       // A JSON object of totals per second, appended to a file
+	SinkRequests   = "requests"   // A JSON object per request, appended to a file
+	SinkStatsD     = "statsd"     // Counters and timings sent to a StatsD server over UDP
+	SinkPrometheus = "prometheus" // A /metrics endpoint served while the benchmark runs
+)
+
+// ParseSink splits a metrics sink spec such as "statsd:localhost:8125" into
+// its kind and target
+func ParseSink(spec string) (kind, target string, err error) {
+	kind, target, _ = strings.Cut(spec, ":")
+	switch kind {
+	case SinkConsole:
+		if target != "" {
+			return "", "", fmt.Errorf("sink %q takes no target", spec)
+		}
+	case SinkJSON, SinkRequests, SinkStatsD, SinkPrometheus:
+		if target == "" {
+			return "", "", fmt.Errorf("sink %q needs a target (e.g. %s:%s)", spec, kind, sinkExamples[kind])
+		}
+	default:
+		return "", "", fmt.Errorf("unknown sink %q (use console, json:<file>, requests:<file>, statsd:<host:port> or prometheus:<addr>)", spec)
+	}
+	return kind, target, nil
+}
+
+// sinkExamples are example targets for error messages
+var sinkExamples = map[string]string{
+	SinkJSON:       "metrics.jsonl",
+	SinkRequests:   "requests.jsonl",
+	SinkStatsD:     "localhost:8125",
+	SinkPrometheus: ":9102",
+}
+
+// ValidateSinks checks the metrics sink specs
+func (c *Config) ValidateSinks() error {
+	for _, spec := range c.Output.Sinks {
+		if _, _, err := ParseSink(spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
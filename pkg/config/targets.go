@@ -0,0 +1,179 @@
+// Package config handles JSON configuration loading and parsing
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LoadTargets loads requests from a vegeta-style plain-text targets file.
+//
+// Each target starts with a "METHOD URL" line, optionally followed by
+// "body=<text>" and "weight=<n>" tokens on the same line. Subsequent
+// "Key: Value" lines add headers and an "@path" line reads the body from a
+// file. Targets are separated by blank lines; "#" starts a comment line.
+// Identical targets are merged, so a file listing one URL three times gives
+// it weight 3.
+func LoadTargets(filename string) ([]RequestConfig, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read targets file: %w", err)
+	}
+	defer file.Close()
+
+	var requests []RequestConfig
+	index := make(map[string]int) // target key -> position in requests
+	var current *RequestConfig
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		key := targetKey(current)
+		if i, ok := index[key]; ok {
+			requests[i].Weight += current.Weight
+		} else {
+			index[key] = len(requests)
+			requests = append(requests, *current)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "#"):
+			continue
+		case current == nil:
+			req, err := parseTargetLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("targets file line %d: %w", lineNum, err)
+			}
+			current = req
+		case strings.HasPrefix(line, "@"):
+			current.BodyFile = strings.TrimPrefix(line, "@")
+		case strings.Contains(line, ":") && !isTargetLine(line):
+			parts := strings.SplitN(line, ":", 2)
+			if current.Headers == nil {
+				current.Headers = make(map[string]string)
+			}
+			current.Headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		default:
+			// A new target without a blank separator line
+			flush()
+			req, err := parseTargetLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("targets file line %d: %w", lineNum, err)
+			}
+			current = req
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read targets file: %w", err)
+	}
+	flush()
+
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("targets file %s contains no targets", filename)
+	}
+
+	return requests, nil
+}
+
+// parseTargetLine parses a "METHOD URL [body=...] [weight=N]" line
+func parseTargetLine(line string) (*RequestConfig, error) {
+	if !isTargetLine(line) {
+		return nil, fmt.Errorf("expected 'METHOD URL', got %q", line)
+	}
+
+	fields := strings.Fields(line)
+	req := &RequestConfig{
+		Method: strings.ToUpper(fields[0]),
+		URL:    fields[1],
+		Weight: 1,
+	}
+
+	rest := strings.TrimSpace(line[len(fields[0]):])
+	rest = strings.TrimSpace(rest[len(fields[1]):])
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "weight="):
+			value := strings.TrimPrefix(rest, "weight=")
+			end := strings.IndexByte(value, ' ')
+			if end == -1 {
+				end = len(value)
+			}
+			weight, err := strconv.Atoi(value[:end])
+			if err != nil || weight <= 0 {
+				return nil, fmt.Errorf("invalid weight: %s", value[:end])
+			}
+			req.Weight = weight
+			rest = strings.TrimSpace(value[end:])
+		case strings.HasPrefix(rest, "body="):
+			// The body extends to the end of the line
+			req.Body = strings.TrimPrefix(rest, "body=")
+			rest = ""
+		default:
+			return nil, fmt.Errorf("unexpected token: %s", rest)
+		}
+	}
+
+	return req, nil
+}
+
+// isTargetLine reports whether a line looks like "METHOD URL". The method may
+// be in any case; parseTargetLine upper-cases it.
+func isTargetLine(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return false
+	}
+	for _, c := range fields[0] {
+		if (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') {
+			return false
+		}
+	}
+	return strings.Contains(fields[1], "://")
+}
+
+// targetKey identifies identical targets so they can be merged into one weighted request
+func targetKey(req *RequestConfig) string {
+	var sb strings.Builder
+	sb.WriteString(req.Method)
+	sb.WriteString(" ")
+	sb.WriteString(req.URL)
+	if body, ok := req.Body.(string); ok {
+		sb.WriteString("\x00")
+		sb.WriteString(body)
+	}
+	sb.WriteString("\x00")
+	sb.WriteString(req.BodyFile)
+	for _, key := range sortedKeys(req.Headers) {
+		sb.WriteString("\x00")
+		sb.WriteString(key)
+		sb.WriteString(":")
+		sb.WriteString(req.Headers[key])
+	}
+	return sb.String()
+}
+
+// sortedKeys returns the keys of a string map in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ParseBodyTemplate parses a bodyTemplate, reading it from a file when it
+// starts with "@". Templates get the helpers of TemplateFuncs.
+func ParseBodyTemplate(name, source string) (*template.Template, error) {
+	if strings.HasPrefix(source, "@") {
+		data, err := os.ReadFile(source[1:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read body template: %w", err)
+		}
+		source = string(data)
+	}
+	tmpl, err := template.New(name).Funcs(TemplateFuncs()).Option("missingkey=zero").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// TemplateFuncs returns the helpers available in body templates, named after
+// their sprig counterparts
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Numbers
+		"add":   func(a, b int) int { return a + b },
+		"sub":   func(a, b int) int { return a - b },
+		"mul":   func(a, b int) int { return a * b },
+		"div":   func(a, b int) int { return a / b },
+		"mod":   func(a, b int) int { return a % b },
+		"atoi":  func(s string) int { n, _ := strconv.Atoi(s); return n },
+		"until": until,
+		"seq":   seq,
+		"randInt": func(min, max int) int {
+			if max <= min {
+				return min
+			}
+			return min + rand.Intn(max-min)
+		},
+		"randChoice": func(choices ...interface{}) interface{} {
+			if len(choices) == 0 {
+				return nil
+			}
+			return choices[rand.Intn(len(choices))]
+		},
+
+		// Strings
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+		"trim":     strings.TrimSpace,
+		"repeat":   func(count int, s string) string { return strings.Repeat(s, count) },
+		"replace":  func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains": func(substr, s string) bool { return strings.Contains(s, substr) },
+		"split":    func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":     join,
+		"quote":    strconv.Quote,
+		"default": func(def, value interface{}) interface{} {
+			if value == nil || value == "" || value == 0 || value == false {
+				return def
+			}
+			return value
+		},
+
+		// Collections and encoding
+		"list": func(items ...interface{}) []interface{} { return items },
+		"dict": dict,
+		"toJson": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"fromJson": func(s string) (interface{}, error) {
+			var v interface{}
+			err := json.Unmarshal([]byte(s), &v)
+			return v, err
+		},
+
+		// Time
+		"now":       time.Now,
+		"unixMilli": func() int64 { return time.Now().UnixMilli() },
+	}
+}
+
+// until returns 0..n-1, for ranging n times
+func until(n int) []int {
+	if n < 0 {
+		n = 0
+	}
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i
+	}
+	return items
+}
+
+// seq returns start..end inclusive
+func seq(start, end int) []int {
+	if end < start {
+		return nil
+	}
+	items := make([]int, 0, end-start+1)
+	for i := start; i <= end; i++ {
+		items = append(items, i)
+	}
+	return items
+}
+
+// join joins a list of any element type
+func join(sep string, items interface{}) string {
+	switch v := items.(type) {
+	case []string:
+		return strings.Join(v, sep)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, sep)
+	case []int:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = strconv.Itoa(item)
+		}
+		return strings.Join(parts, sep)
+	default:
+		return fmt.Sprint(items)
+	}
+}
+
+// dict builds a map from key/value pairs
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict needs key/value pairs")
+	}
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict keys must be strings")
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// ValidateBodyTemplates checks that body templates parse and aren't combined
+// with another body
+func (c *Config) ValidateBodyTemplates() error {
+	for _, req := range c.Requests {
+		if err := validateBodyTemplate(req.Name, req.BodyTemplate, req.Body, req.BodyFile); err != nil {
+			return err
+		}
+	}
+	for _, step := range c.AllStepsWithInit() {
+		if err := validateBodyTemplate(step.Name, step.BodyTemplate, step.Body, step.BodyFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateBodyTemplate checks the bodyTemplate of one request or step
+func validateBodyTemplate(name, source string, body interface{}, bodyFile string) error {
+	if source == "" {
+		return nil
+	}
+	if body != nil || bodyFile != "" {
+		return fmt.Errorf("%q: bodyTemplate cannot be combined with body or bodyFile", name)
+	}
+	if _, err := ParseBodyTemplate(name, source); err != nil {
+		return fmt.Errorf("%q: %w", name, err)
+	}
+	return nil
+}
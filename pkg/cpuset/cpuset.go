@@ -0,0 +1,93 @@
+// Package cpuset pins the benchmark process to CPUs, so load-generator jitter
+// on shared hosts doesn't end up in the latency measurements
+package cpuset
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// maxCPUs is the highest CPU count an affinity mask covers (CPU_SETSIZE)
+const maxCPUs = 1024
+
+// reserved is the CPU kept for stats and progress goroutines (-1 = none)
+var reserved atomic.Int32
+
+func init() {
+	reserved.Store(-1)
+}
+
+// Parse parses a CPU list such as "0-3,6" into sorted, unique CPU numbers
+func Parse(list string) ([]int, error) {
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU %q", part)
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(last); err != nil || to < from {
+				return nil, fmt.Errorf("invalid CPU range %q", part)
+			}
+		}
+		if from < 0 || to >= maxCPUs {
+			return nil, fmt.Errorf("CPU %q out of range (0-%d)", part, maxCPUs-1)
+		}
+		for cpu := from; cpu <= to; cpu++ {
+			seen[cpu] = true
+		}
+	}
+	if len(seen) == 0 {
+		return nil, fmt.Errorf("empty CPU list %q", list)
+	}
+
+	cpus := make([]int, 0, len(seen))
+	for cpu := range seen {
+		cpus = append(cpus, cpu)
+	}
+	sort.Ints(cpus)
+	return cpus, nil
+}
+
+// Pin restricts the process to the given CPUs. With reserveCore the last of them
+// is kept for the goroutines that call PinToReserved, and the rest run the
+// workers. GOMAXPROCS is set to the number of worker CPUs.
+func Pin(cpus []int, reserveCore bool) error {
+	if reserveCore {
+		if len(cpus) < 2 {
+			return fmt.Errorf("reserving a core needs at least 2 CPUs, got %d", len(cpus))
+		}
+		reserved.Store(int32(cpus[len(cpus)-1]))
+		cpus = cpus[:len(cpus)-1]
+	}
+	if err := setProcessAffinity(cpus); err != nil {
+		reserved.Store(-1)
+		return err
+	}
+	runtime.GOMAXPROCS(len(cpus))
+	return nil
+}
+
+// PinToReserved moves the calling goroutine onto the reserved CPU, if one was
+// reserved. The goroutine keeps its OS thread until it exits, after which the
+// thread is discarded instead of running other goroutines.
+func PinToReserved() {
+	cpu := reserved.Load()
+	if cpu < 0 {
+		return
+	}
+	// Lock first: threads the runtime starts for a locked goroutine are cloned
+	// from a template thread, so they don't inherit the reserved CPU
+	runtime.LockOSThread()
+	setThreadAffinity(int(cpu))
+}
@@ -0,0 +1,65 @@
+//go:build linux
+
+package cpuset
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// cpuMask is a kernel cpu_set_t
+type cpuMask [maxCPUs / 64]uint64
+
+// newCPUMask returns a mask of the given CPUs
+func newCPUMask(cpus ...int) *cpuMask {
+	var mask cpuMask
+	for _, cpu := range cpus {
+		mask[cpu/64] |= 1 << (cpu % 64)
+	}
+	return &mask
+}
+
+// setAffinity sets the CPU mask of one thread (0 = the calling thread)
+func setAffinity(tid int, mask *cpuMask) error {
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, uintptr(tid), unsafe.Sizeof(*mask), uintptr(unsafe.Pointer(mask)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// setProcessAffinity sets the CPU mask of every thread of the process. Threads
+// started meanwhile inherit the old mask, so it repeats until none are new.
+func setProcessAffinity(cpus []int) error {
+	mask := newCPUMask(cpus...)
+	done := make(map[int]bool)
+	for {
+		entries, err := os.ReadDir("/proc/self/task")
+		if err != nil {
+			return fmt.Errorf("failed to list threads: %w", err)
+		}
+		changed := false
+		for _, entry := range entries {
+			tid, err := strconv.Atoi(entry.Name())
+			if err != nil || done[tid] {
+				continue
+			}
+			if err := setAffinity(tid, mask); err != nil && err != syscall.ESRCH {
+				return fmt.Errorf("failed to pin to CPUs %v: %w", cpus, err)
+			}
+			done[tid] = true
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+	}
+}
+
+// setThreadAffinity pins the calling thread to one CPU
+func setThreadAffinity(cpu int) {
+	setAffinity(0, newCPUMask(cpu))
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package cpuset
+
+import "fmt"
+
+// setProcessAffinity is only implemented on Linux
+func setProcessAffinity(cpus []int) error {
+	return fmt.Errorf("pinning to CPUs is only supported on Linux")
+}
+
+// setThreadAffinity is only implemented on Linux
+func setThreadAffinity(cpu int) {}
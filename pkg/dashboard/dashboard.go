@@ -0,0 +1,172 @@
+// Package dashboard serves a local web page with live charts and tables of a
+// running benchmark
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// Server serves the dashboard page and the live data it polls
+type Server struct {
+	name        string
+	stats       *benchmark.Stats
+	series      *benchmark.TimeSeries
+	percentiles []float64
+	started     time.Time
+	listener    net.Listener
+}
+
+// Data is the live state returned by /data
+type Data struct {
+	Name              string                `json:"name"`
+	Elapsed           float64               `json:"elapsed"`
+	Requests          int64                 `json:"requests"`
+	SuccessCount      int64                 `json:"successCount"`
+	FailureCount      int64                 `json:"failureCount"`
+	ErrorRate         float64               `json:"errorRate"`
+	RequestsPerSecond float64               `json:"rps"`
+	AvgLatency        float64               `json:"avgLatencyUs"`
+	Percentiles       map[string]int64      `json:"percentilesUs"`
+	Series            []benchmark.TimePoint `json:"series"`
+	Endpoints         []EndpointData        `json:"endpoints"`
+	Errors            map[string]int        `json:"errors"`
+}
+
+// EndpointData is one row of the per-endpoint table
+type EndpointData struct {
+	Name         string           `json:"name"`
+	Method       string           `json:"method"`
+	URL          string           `json:"url"`
+	Requests     int64            `json:"requests"`
+	SuccessCount int64            `json:"successCount"`
+	FailureCount int64            `json:"failureCount"`
+	AvgLatency   float64          `json:"avgLatencyUs"`
+	Percentiles  map[string]int64 `json:"percentilesUs"`
+}
+
+// Start listens on addr and serves the dashboard for stats until ctx is done
+func Start(ctx context.Context, addr string, stats *benchmark.Stats, cfg *config.Config) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("dashboard failed to listen: %w", err)
+	}
+
+	percentiles := cfg.Settings.Percentiles
+	if len(percentiles) == 0 {
+		percentiles = []float64{50, 75, 90, 99}
+	}
+	s := &Server{
+		name:        cfg.Name,
+		stats:       stats,
+		series:      stats.CollectTimeSeries(ctx, time.Second, percentiles),
+		percentiles: percentiles,
+		started:     time.Now(),
+		listener:    listener,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handlePage)
+	mux.HandleFunc("GET /data", s.handleData)
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	return s, nil
+}
+
+// URL returns the address to open in a browser
+func (s *Server) URL() string {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	if addr.IP.IsUnspecified() {
+		return fmt.Sprintf("http://localhost:%d/", addr.Port)
+	}
+	return fmt.Sprintf("http://%s/", addr)
+}
+
+// handlePage serves the dashboard page
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, pageHTML)
+}
+
+// handleData serves the current totals, the time series and the per-endpoint table
+func (s *Server) handleData(w http.ResponseWriter, r *http.Request) {
+	data := &Data{
+		Name:         s.name,
+		Elapsed:      time.Since(s.started).Seconds(),
+		SuccessCount: atomic.LoadInt64(&s.stats.SuccessCount),
+		FailureCount: atomic.LoadInt64(&s.stats.FailureCount),
+		AvgLatency:   s.stats.AverageResponseTime(),
+		Percentiles:  make(map[string]int64, len(s.percentiles)),
+		Series:       s.series.Points(),
+		Endpoints:    s.endpoints(),
+		Errors:       s.stats.GetErrors(),
+	}
+	data.Requests = data.SuccessCount + data.FailureCount
+	if data.Requests > 0 {
+		data.ErrorRate = float64(data.FailureCount) / float64(data.Requests)
+	}
+	if data.Elapsed > 0 {
+		data.RequestsPerSecond = float64(data.Requests) / data.Elapsed
+	}
+	for _, p := range s.percentiles {
+		data.Percentiles[percentileKey(p)] = s.stats.GetLatencyPercentile(p)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(data)
+}
+
+// endpoints builds the per-endpoint table, sorted by name
+func (s *Server) endpoints() []EndpointData {
+	s.stats.Lock()
+	group := make([]*benchmark.RequestStats, 0, len(s.stats.RequestStats))
+	for _, rs := range s.stats.RequestStats {
+		group = append(group, rs)
+	}
+	s.stats.Unlock()
+
+	endpoints := make([]EndpointData, 0, len(group))
+	for _, rs := range group {
+		rs.Mutex.Lock()
+		endpoint := EndpointData{
+			Name:         rs.Name,
+			Method:       rs.Method,
+			URL:          rs.URL,
+			Requests:     rs.RequestCount,
+			SuccessCount: rs.SuccessCount,
+			FailureCount: rs.FailureCount,
+			Percentiles:  make(map[string]int64, len(s.percentiles)),
+		}
+		if rs.RequestCount > 0 {
+			endpoint.AvgLatency = float64(rs.TotalLatency) / float64(rs.RequestCount)
+		}
+		rs.Mutex.Unlock()
+
+		for _, p := range s.percentiles {
+			endpoint.Percentiles[percentileKey(p)] = rs.LatencyPercentile(p)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].Name < endpoints[j].Name })
+	return endpoints
+}
+
+// percentileKey names a percentile like the reports do ("p99.9")
+func percentileKey(percentile float64) string {
+	return "p" + strconv.FormatFloat(percentile, 'f', -1, 64)
+}
@@ -0,0 +1,262 @@
+package dashboard
+
+// pageHTML is the dashboard page. It polls /data every second and draws the
+// charts on canvases, so it works without network access.
+const pageHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Benchmark Dashboard</title>
+    <style>
+        :root {
+            --bg-primary: #0d1117;
+            --bg-secondary: #161b22;
+            --bg-tertiary: #21262d;
+            --text-primary: #c9d1d9;
+            --text-secondary: #8b949e;
+            --accent: #58a6ff;
+            --success: #3fb950;
+            --warning: #d29922;
+            --error: #f85149;
+            --border: #30363d;
+        }
+
+        * { margin: 0; padding: 0; box-sizing: border-box; }
+
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', 'Noto Sans', Helvetica, Arial, sans-serif;
+            background: var(--bg-primary);
+            color: var(--text-primary);
+            line-height: 1.6;
+            padding: 2rem;
+        }
+
+        .container { max-width: 1200px; margin: 0 auto; }
+
+        header {
+            display: flex;
+            justify-content: space-between;
+            align-items: baseline;
+            margin-bottom: 1.5rem;
+            padding-bottom: 1rem;
+            border-bottom: 1px solid var(--border);
+        }
+
+        h1 { font-size: 1.6rem; font-weight: 600; }
+        h2 { font-size: 1.1rem; font-weight: 600; margin-bottom: 0.75rem; }
+
+        .status { color: var(--text-secondary); font-size: 0.9rem; }
+        .status.stopped { color: var(--warning); }
+
+        .cards {
+            display: grid;
+            grid-template-columns: repeat(auto-fit, minmax(170px, 1fr));
+            gap: 1rem;
+            margin-bottom: 1.5rem;
+        }
+
+        .card, .panel {
+            background: var(--bg-secondary);
+            border: 1px solid var(--border);
+            border-radius: 6px;
+            padding: 1rem;
+        }
+
+        .card .label { color: var(--text-secondary); font-size: 0.8rem; text-transform: uppercase; }
+        .card .value { font-size: 1.5rem; font-weight: 600; }
+        .card .value.bad { color: var(--error); }
+
+        .charts {
+            display: grid;
+            grid-template-columns: repeat(auto-fit, minmax(360px, 1fr));
+            gap: 1rem;
+            margin-bottom: 1.5rem;
+        }
+
+        canvas { width: 100%; height: 200px; display: block; }
+
+        .panel { margin-bottom: 1.5rem; overflow-x: auto; }
+
+        table { width: 100%; border-collapse: collapse; font-size: 0.9rem; }
+        th, td { padding: 0.4rem 0.6rem; text-align: right; border-bottom: 1px solid var(--border); }
+        th { color: var(--text-secondary); font-weight: 500; }
+        th:first-child, td:first-child { text-align: left; }
+        td.bad { color: var(--error); }
+
+        .legend { color: var(--text-secondary); font-size: 0.8rem; margin-top: 0.4rem; }
+        .legend span { margin-right: 1rem; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <header>
+            <h1 id="title">Benchmark Dashboard</h1>
+            <div class="status" id="status">Connecting...</div>
+        </header>
+
+        <div class="cards">
+            <div class="card"><div class="label">Requests</div><div class="value" id="requests">-</div></div>
+            <div class="card"><div class="label">Requests/sec</div><div class="value" id="rps">-</div></div>
+            <div class="card"><div class="label">Error Rate</div><div class="value" id="errorRate">-</div></div>
+            <div class="card"><div class="label">Avg Latency</div><div class="value" id="avgLatency">-</div></div>
+            <div class="card"><div class="label">Elapsed</div><div class="value" id="elapsed">-</div></div>
+        </div>
+
+        <div class="charts">
+            <div class="panel">
+                <h2>Requests/sec</h2>
+                <canvas id="rpsChart"></canvas>
+            </div>
+            <div class="panel">
+                <h2>Latency</h2>
+                <canvas id="latencyChart"></canvas>
+                <div class="legend" id="latencyLegend"></div>
+            </div>
+            <div class="panel">
+                <h2>Error Rate</h2>
+                <canvas id="errorChart"></canvas>
+            </div>
+        </div>
+
+        <div class="panel">
+            <h2>Latency Percentiles</h2>
+            <table id="percentiles"></table>
+        </div>
+
+        <div class="panel">
+            <h2>Endpoints</h2>
+            <table id="endpoints"></table>
+        </div>
+
+        <div class="panel">
+            <h2>Errors</h2>
+            <table id="errors"></table>
+        </div>
+    </div>
+
+    <script>
+        const colors = ['#58a6ff', '#3fb950', '#d29922', '#f85149', '#bc8cff', '#39c5cf'];
+
+        function formatLatency(us) {
+            if (us >= 1000000) return (us / 1000000).toFixed(2) + 's';
+            if (us >= 1000) return (us / 1000).toFixed(2) + 'ms';
+            return us.toFixed(2) + 'us';
+        }
+
+        function escapeHTML(text) {
+            const div = document.createElement('div');
+            div.textContent = text;
+            return div.innerHTML;
+        }
+
+        function sortedKeys(map) {
+            return Object.keys(map || {}).sort((a, b) => parseFloat(a.slice(1)) - parseFloat(b.slice(1)));
+        }
+
+        // drawChart plots one or more series against elapsed seconds
+        function drawChart(canvas, series, format) {
+            const ratio = window.devicePixelRatio || 1;
+            const width = canvas.clientWidth, height = canvas.clientHeight;
+            canvas.width = width * ratio;
+            canvas.height = height * ratio;
+            const ctx = canvas.getContext('2d');
+            ctx.scale(ratio, ratio);
+            ctx.clearRect(0, 0, width, height);
+
+            const left = 70, bottom = 20, top = 10;
+            let maxX = 1, maxY = 0;
+            series.forEach(s => s.points.forEach(p => { maxX = Math.max(maxX, p[0]); maxY = Math.max(maxY, p[1]); }));
+            if (maxY === 0) maxY = 1;
+
+            ctx.strokeStyle = '#30363d';
+            ctx.fillStyle = '#8b949e';
+            ctx.font = '11px sans-serif';
+            for (let i = 0; i <= 4; i++) {
+                const y = top + (height - top - bottom) * i / 4;
+                ctx.beginPath();
+                ctx.moveTo(left, y);
+                ctx.lineTo(width, y);
+                ctx.stroke();
+                ctx.fillText(format(maxY * (4 - i) / 4), 4, y + 4);
+            }
+            ctx.fillText(maxX.toFixed(0) + 's', width - 30, height - 4);
+
+            series.forEach(s => {
+                ctx.strokeStyle = s.color;
+                ctx.lineWidth = 1.5;
+                ctx.beginPath();
+                s.points.forEach((p, i) => {
+                    const x = left + (width - left) * p[0] / maxX;
+                    const y = top + (height - top - bottom) * (1 - p[1] / maxY);
+                    if (i === 0) ctx.moveTo(x, y); else ctx.lineTo(x, y);
+                });
+                ctx.stroke();
+            });
+        }
+
+        function render(data) {
+            document.getElementById('title').textContent = data.name || 'Benchmark Dashboard';
+            document.getElementById('requests').textContent = data.requests.toLocaleString();
+            document.getElementById('rps').textContent = data.rps.toFixed(2);
+            const errorRate = document.getElementById('errorRate');
+            errorRate.textContent = (data.errorRate * 100).toFixed(2) + '%';
+            errorRate.className = data.errorRate > 0 ? 'value bad' : 'value';
+            document.getElementById('avgLatency').textContent = formatLatency(data.avgLatencyUs);
+            document.getElementById('elapsed').textContent = data.elapsed.toFixed(0) + 's';
+
+            const series = data.series || [];
+            drawChart(document.getElementById('rpsChart'),
+                [{ color: colors[0], points: series.map(p => [p.elapsed, p.rps]) }],
+                v => v.toFixed(0));
+            drawChart(document.getElementById('errorChart'),
+                [{ color: colors[3], points: series.map(p => [p.elapsed, p.errorRate * 100]) }],
+                v => v.toFixed(1) + '%');
+
+            const keys = sortedKeys(data.percentilesUs);
+            drawChart(document.getElementById('latencyChart'),
+                keys.map((key, i) => ({ color: colors[i % colors.length], points: series.map(p => [p.elapsed, p.percentilesUs[key] || 0]) })),
+                formatLatency);
+            document.getElementById('latencyLegend').innerHTML =
+                keys.map((key, i) => '<span style="color:' + colors[i % colors.length] + '">' + key + '</span>').join('');
+
+            document.getElementById('percentiles').innerHTML =
+                '<tr>' + keys.map(key => '<th>' + key + '</th>').join('') + '</tr>' +
+                '<tr>' + keys.map(key => '<td>' + formatLatency(data.percentilesUs[key]) + '</td>').join('') + '</tr>';
+
+            let rows = '<tr><th>Endpoint</th><th>Requests</th><th>Success</th><th>Failures</th><th>Error Rate</th><th>Avg</th>' +
+                keys.map(key => '<th>' + key + '</th>').join('') + '</tr>';
+            (data.endpoints || []).forEach(e => {
+                const rate = e.requests > 0 ? e.failureCount / e.requests : 0;
+                rows += '<tr><td>' + escapeHTML(e.method + ' ' + e.name) + '</td><td>' + e.requests + '</td><td>' + e.successCount +
+                    '</td><td>' + e.failureCount + '</td><td class="' + (rate > 0 ? 'bad' : '') + '">' + (rate * 100).toFixed(2) + '%</td><td>' +
+                    formatLatency(e.avgLatencyUs) + '</td>' + keys.map(key => '<td>' + formatLatency(e.percentilesUs[key] || 0) + '</td>').join('') + '</tr>';
+            });
+            document.getElementById('endpoints').innerHTML = rows;
+
+            const errors = Object.entries(data.errors || {}).sort((a, b) => b[1] - a[1]);
+            document.getElementById('errors').innerHTML = errors.length === 0
+                ? '<tr><td>No errors</td></tr>'
+                : '<tr><th>Error</th><th>Count</th></tr>' + errors.map(e => '<tr><td>' + escapeHTML(e[0]) + '</td><td>' + e[1] + '</td></tr>').join('');
+        }
+
+        async function poll() {
+            const status = document.getElementById('status');
+            try {
+                const response = await fetch('data', { cache: 'no-store' });
+                render(await response.json());
+                status.textContent = 'Live - updated ' + new Date().toLocaleTimeString();
+                status.className = 'status';
+            } catch (err) {
+                status.textContent = 'Benchmark finished or stopped';
+                status.className = 'status stopped';
+                return;
+            }
+            setTimeout(poll, 1000);
+        }
+
+        poll();
+    </script>
+</body>
+</html>
+`
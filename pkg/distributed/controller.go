@@ -0,0 +1,217 @@
+package distributed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Controller waits for a fixed number of workers, starts them together and
+// merges their results into one set of stats
+type Controller struct {
+	Log io.Writer // Receives progress (os.Stdout by default)
+
+	addr        string
+	workers     int
+	cfg         *config.Config
+	durationSec int
+	timeout     time.Duration
+	rampUpSec   int
+	quietMode   bool
+
+	mu          sync.Mutex
+	joined      int
+	abortReason string
+	ready       chan struct{} // Closed once every worker has joined
+	stop        chan struct{} // Closed to stop all workers early
+	stopOnce    sync.Once
+	reports     chan *Report
+}
+
+// NewController creates a controller that listens on addr for the given number of workers
+func NewController(addr string, workers int, cfg *config.Config, durationSec int, timeout time.Duration, rampUpSec int, quietMode bool) *Controller {
+	return &Controller{
+		Log:         os.Stdout,
+		addr:        addr,
+		workers:     workers,
+		cfg:         cfg,
+		durationSec: durationSec,
+		timeout:     timeout,
+		rampUpSec:   rampUpSec,
+		quietMode:   quietMode,
+		ready:       make(chan struct{}),
+		stop:        make(chan struct{}),
+		reports:     make(chan *Report, workers),
+	}
+}
+
+// Run serves the workers until all of them reported and returns the merged stats.
+// Cancelling ctx stops the workers; their partial results are still merged.
+func (c *Controller) Run(ctx context.Context) (*benchmark.Stats, error) {
+	if err := CheckWorkers(c.cfg, c.workers); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("controller failed to listen: %w", err)
+	}
+
+	server := grpc.NewServer(grpc.MaxRecvMsgSize(maxMessageSize), grpc.MaxSendMsgSize(maxMessageSize))
+	server.RegisterService(&serviceDesc, c)
+	go server.Serve(listener)
+	defer func() {
+		// Release the calls still waiting, then let the last replies go out
+		c.stopWorkers()
+		server.GracefulStop()
+	}()
+
+	c.logf("Controller listening on %s, waiting for %d worker(s)...\n", listener.Addr(), c.workers)
+
+	select {
+	case <-c.ready:
+		c.logf("All workers joined, benchmark started\n")
+	case <-ctx.Done():
+		return nil, fmt.Errorf("interrupted while waiting for workers (%d of %d joined)", c.joinedCount(), c.workers)
+	}
+
+	stats := benchmark.NewStatsWithOptions(!c.cfg.Settings.DisableHdr, c.cfg.Settings.ShowHistogram)
+	stats.SetSLO(c.cfg.SLO)
+	stats.SetErrorSamples(c.cfg.Settings.ErrorSamples)
+	stats.SetCacheTracking(c.cfg.Settings.CacheStats)
+	bounds, logScale, _ := c.cfg.GetHistogramBuckets() // Validated with the config
+	stats.SetHistogramBuckets(bounds, logScale)
+
+	// After an interrupt, give workers time to finish in-flight requests and report
+	var deadline <-chan time.Time
+	interrupted := ctx.Done()
+	for received := 0; received < c.workers; {
+		select {
+		case report := <-c.reports:
+			received++
+			stats.Merge(report.Stats)
+			c.logf("Worker %d finished: %d requests in %.2fs\n", report.WorkerID+1, report.Stats.TotalRequests, report.Stats.TotalDuration)
+			if report.AbortReason != "" {
+				c.setAbortReason(fmt.Sprintf("worker %d: %s", report.WorkerID+1, report.AbortReason))
+				c.stopWorkers()
+			}
+		case <-interrupted:
+			interrupted = nil
+			c.stopWorkers()
+			deadline = time.After(c.timeout + 5*time.Second)
+		case <-deadline:
+			return stats, fmt.Errorf("only %d of %d workers reported", received, c.workers)
+		}
+	}
+	return stats, nil
+}
+
+// AbortReason returns why a worker's rolling thresholds stopped the run, if they did
+func (c *Controller) AbortReason() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.abortReason
+}
+
+// join registers a worker and answers with its assignment once every worker has joined
+func (c *Controller) join(ctx context.Context) (*Assignment, error) {
+	c.mu.Lock()
+	if c.joined >= c.workers {
+		c.mu.Unlock()
+		return nil, status.Errorf(codes.ResourceExhausted, "controller already has %d workers", c.workers)
+	}
+	id := c.joined
+	c.joined++
+	if c.joined == c.workers {
+		close(c.ready)
+	}
+	c.mu.Unlock()
+
+	if p, ok := peer.FromContext(ctx); ok {
+		c.logf("Worker %d joined from %s\n", id+1, p.Addr)
+	} else {
+		c.logf("Worker %d joined\n", id+1)
+	}
+
+	select {
+	case <-c.ready:
+	case <-c.stop:
+		return nil, status.Error(codes.Unavailable, "controller stopped before every worker joined")
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	share, err := splitConfig(c.cfg, id, c.workers)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &Assignment{
+		WorkerID:    id,
+		Workers:     c.workers,
+		Config:      share,
+		DurationSec: c.durationSec,
+		Timeout:     c.timeout,
+		RampUpSec:   c.rampUpSec,
+	}, nil
+}
+
+// waitStop blocks until the run is stopped early, so workers can stop with it
+func (c *Controller) waitStop(ctx context.Context) error {
+	select {
+	case <-c.stop:
+		return nil
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// report receives a worker's results
+func (c *Controller) report(ctx context.Context, report *Report) error {
+	if report.Stats == nil {
+		return status.Error(codes.InvalidArgument, "report without stats")
+	}
+	select {
+	case c.reports <- report:
+		return nil
+	default:
+		return status.Error(codes.FailedPrecondition, "unexpected report")
+	}
+}
+
+// stopWorkers releases the workers' stop requests
+func (c *Controller) stopWorkers() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// joinedCount returns the number of workers that joined so far
+func (c *Controller) joinedCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.joined
+}
+
+// setAbortReason records the first abort reported by a worker
+func (c *Controller) setAbortReason(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.abortReason == "" {
+		c.abortReason = reason
+	}
+}
+
+// logf prints controller progress unless in quiet mode
+func (c *Controller) logf(format string, args ...interface{}) {
+	if !c.quietMode {
+		fmt.Fprintf(c.Log, format, args...)
+	}
+}
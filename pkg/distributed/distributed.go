@@ -0,0 +1,104 @@
+// Package distributed runs one benchmark across several processes: a controller
+// hands each worker its share of the load and merges the workers' results.
+// Workers call the controller's gRPC service (see service.go) to join, to wait
+// for an early stop and to send their report.
+package distributed
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// Assignment is the share of a benchmark the controller hands to one worker
+type Assignment struct {
+	WorkerID    int            `json:"workerId"`
+	Workers     int            `json:"workers"`
+	Config      *config.Config `json:"config"`
+	DurationSec int            `json:"durationSec"`
+	Timeout     time.Duration  `json:"timeout"`
+	RampUpSec   int            `json:"rampUpSec"`
+}
+
+// Report is what a worker sends back once its share of the benchmark is done
+type Report struct {
+	WorkerID    int                      `json:"workerId"`
+	Stats       *benchmark.StatsSnapshot `json:"stats"`
+	AbortReason string                   `json:"abortReason,omitempty"` // Set when rolling thresholds stopped the worker
+}
+
+// CheckWorkers returns an error if the benchmark cannot be split over the given
+// number of workers: every worker needs at least one virtual user
+func CheckWorkers(cfg *config.Config, workers int) error {
+	if users := cfg.Settings.ConcurrentUsers; users < workers {
+		return fmt.Errorf("%d concurrent users cannot be split over %d workers; every worker needs at least one user", users, workers)
+	}
+	return nil
+}
+
+// splitConfig returns a copy of the config carrying worker index's share of the
+// virtual users, rate limits and data volume limit. Request counts stay per user.
+func splitConfig(cfg *config.Config, index, workers int) (*config.Config, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	share := &config.Config{}
+	if err := json.Unmarshal(data, share); err != nil {
+		return nil, fmt.Errorf("failed to copy config: %w", err)
+	}
+
+	share.Settings.ConcurrentUsers = splitCount(cfg.Settings.ConcurrentUsers, index, workers)
+	share.Settings.Workers = splitRate(cfg.Settings.Workers, index, workers)
+	share.Settings.MaxInFlight = splitRate(cfg.Settings.MaxInFlight, index, workers)
+	share.Settings.RateLimit = splitRate(cfg.Settings.RateLimit, index, workers)
+	if cfg.Settings.StopAfterBytes > 0 {
+		share.Settings.StopAfterBytes = max(1, cfg.Settings.StopAfterBytes/config.ByteSize(workers))
+	}
+	for i := range share.Requests {
+		share.Requests[i].RateLimit = splitRate(share.Requests[i].RateLimit, index, workers)
+	}
+	splitStepRates(share.Steps, index, workers)
+	splitStepRates(share.VUInit, index, workers)
+	for i := range share.Scenarios {
+		splitStepRates(share.Scenarios[i].Steps, index, workers)
+	}
+
+	// Only the controller writes reports; workers run headless
+	share.Output = config.OutputConfig{}
+	share.Settings.Dashboard = ""
+	share.Settings.TUI = false
+	share.Settings.Checkpoint = ""
+	return share, nil
+}
+
+// splitStepRates divides the per-step rate limits, including those of status branches
+func splitStepRates(steps []config.StepConfig, index, workers int) {
+	for i := range steps {
+		steps[i].RateLimit = splitRate(steps[i].RateLimit, index, workers)
+		for status, branch := range steps[i].OnStatus {
+			splitStepRates(branch.Steps, index, workers)
+			steps[i].OnStatus[status] = branch
+		}
+	}
+}
+
+// splitCount returns worker index's share of total, spreading the remainder over the first workers
+func splitCount(total, index, workers int) int {
+	share := total / workers
+	if index < total%workers {
+		share++
+	}
+	return share
+}
+
+// splitRate splits a rate limit like splitCount. A share never drops to 0, which would mean unlimited.
+func splitRate(rate, index, workers int) int {
+	if rate <= 0 {
+		return rate
+	}
+	return max(1, splitCount(rate, index, workers))
+}
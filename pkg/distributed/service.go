@@ -0,0 +1,81 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// The controller's gRPC service. Its messages are the Go structs of this
+// package encoded as JSON, so the service is declared by hand instead of being
+// generated from a .proto file.
+const (
+	serviceName  = "benchmarking.Controller"
+	methodJoin   = "/" + serviceName + "/Join"
+	methodStop   = "/" + serviceName + "/Stop"
+	methodReport = "/" + serviceName + "/Report"
+)
+
+// maxMessageSize bounds assignments and reports, whose histograms and configs
+// outgrow gRPC's 4 MB default
+const maxMessageSize = 256 << 20
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// jsonCodec encodes gRPC messages as JSON
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return "json" }
+
+// empty is the message of calls without arguments or results
+type empty struct{}
+
+// controllerServer is the gRPC service a Controller provides to its workers
+type controllerServer interface {
+	join(ctx context.Context) (*Assignment, error)
+	waitStop(ctx context.Context) error
+	report(ctx context.Context, report *Report) error
+}
+
+// serviceDesc describes the controller service to the gRPC server
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*controllerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Join",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				if err := dec(&empty{}); err != nil {
+					return nil, err
+				}
+				return srv.(controllerServer).join(ctx)
+			},
+		},
+		{
+			MethodName: "Stop",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				if err := dec(&empty{}); err != nil {
+					return nil, err
+				}
+				return &empty{}, srv.(controllerServer).waitStop(ctx)
+			},
+		},
+		{
+			MethodName: "Report",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				report := &Report{}
+				if err := dec(report); err != nil {
+					return nil, err
+				}
+				return &empty{}, srv.(controllerServer).report(ctx, report)
+			},
+		},
+	},
+	Metadata: "pkg/distributed/service.go",
+}
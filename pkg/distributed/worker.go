@@ -0,0 +1,69 @@
+package distributed
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// RunWorker joins a controller, runs the assigned share of the benchmark and
+// sends the results back. It returns once the report is delivered. Progress
+// and the benchmark's own output go to log.
+func RunWorker(ctx context.Context, controller string, log io.Writer, quietMode bool) error {
+	conn, err := grpc.NewClient(controller,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(
+			grpc.CallContentSubtype(jsonCodec{}.Name()),
+			grpc.MaxCallRecvMsgSize(maxMessageSize),
+			grpc.MaxCallSendMsgSize(maxMessageSize),
+		))
+	if err != nil {
+		return fmt.Errorf("invalid controller address: %w", err)
+	}
+	defer conn.Close()
+
+	if !quietMode {
+		fmt.Fprintf(log, "Joining controller %s...\n", controller)
+	}
+	var assignment Assignment
+	if err := conn.Invoke(ctx, methodJoin, &empty{}, &assignment); err != nil {
+		return fmt.Errorf("failed to join controller: %s", status.Convert(err).Message())
+	}
+	if !quietMode {
+		fmt.Fprintf(log, "Joined as worker %d of %d with %d concurrent users\n",
+			assignment.WorkerID+1, assignment.Workers, assignment.Config.Settings.ConcurrentUsers)
+	}
+
+	// Stop early when the controller says so (Ctrl+C or a rolling threshold abort there)
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		if err := conn.Invoke(runCtx, methodStop, &empty{}, &empty{}); err == nil {
+			cancel()
+		}
+	}()
+
+	runner := benchmark.NewRunner(assignment.Config, assignment.DurationSec, assignment.Timeout, assignment.RampUpSec, quietMode, false)
+	runner.Log = log
+	stats := runner.Run(runCtx)
+	cancel()
+
+	// The report is sent even when ctx was cancelled, so partial results are kept
+	report := &Report{
+		WorkerID:    assignment.WorkerID,
+		Stats:       stats.Snapshot(),
+		AbortReason: runner.AbortReason(),
+	}
+	if err := conn.Invoke(context.Background(), methodReport, report, &empty{}); err != nil {
+		return fmt.Errorf("failed to send report: %s", status.Convert(err).Message())
+	}
+	if !quietMode {
+		fmt.Fprintf(log, "Report sent: %d requests in %.2fs\n", stats.TotalRequests, stats.TotalDuration)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Package k8s starts and removes benchmark worker pods on a Kubernetes cluster through kubectl
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+)
+
+// DefaultImage is the worker image used when none is given
+const DefaultImage = "benchmarking_go:latest"
+
+// DefaultManifest runs the workers as one Job with a pod per worker. The pods
+// join the controller and exit once their report is sent.
+const DefaultManifest = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: {{.Name}}
+  namespace: {{.Namespace}}
+  labels:
+    app: benchmarking-go-worker
+spec:
+  parallelism: {{.Workers}}
+  completions: {{.Workers}}
+  backoffLimit: 0
+  ttlSecondsAfterFinished: 600
+  template:
+    metadata:
+      labels:
+        app: benchmarking-go-worker
+        job-name: {{.Name}}
+    spec:
+      restartPolicy: Never
+      containers:
+        - name: worker
+          image: {{.Image}}
+          args: ["--worker", "--join", "{{.Controller}}", "-q"]
+`
+
+// ManifestData is the data available to a manifest template
+type ManifestData struct {
+	Name       string // Unique name of this run's workers
+	Namespace  string
+	Image      string
+	Workers    int
+	Controller string // Address the worker pods join, host:port
+}
+
+// Cluster applies and deletes worker manifests with kubectl
+type Cluster struct {
+	Kubectl   string // kubectl binary
+	Namespace string
+	Context   string // kubeconfig context, empty for the current one
+}
+
+// RenderManifest fills the manifest template (DefaultManifest when empty) with data
+func RenderManifest(manifest string, data ManifestData) ([]byte, error) {
+	if manifest == "" {
+		manifest = DefaultManifest
+	}
+	tmpl, err := template.New("manifest").Option("missingkey=error").Parse(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render manifest: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// LoadManifest reads a manifest template from a file
+func LoadManifest(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to read manifest template: %w", err)
+	}
+	return string(data), nil
+}
+
+// Apply creates the resources of the rendered manifest
+func (c *Cluster) Apply(ctx context.Context, manifest []byte) error {
+	return c.run(ctx, manifest, "apply", "-f", "-")
+}
+
+// Delete removes the resources of the rendered manifest along with their pods
+func (c *Cluster) Delete(ctx context.Context, manifest []byte) error {
+	return c.run(ctx, manifest, "delete", "--ignore-not-found", "--wait=false", "-f", "-")
+}
+
+// run runs a kubectl command with the manifest on stdin
+func (c *Cluster) run(ctx context.Context, manifest []byte, command string, args ...string) error {
+	args = append([]string{command}, args...)
+	if c.Namespace != "" {
+		args = append([]string{"--namespace", c.Namespace}, args...)
+	}
+	if c.Context != "" {
+		args = append([]string{"--context", c.Context}, args...)
+	}
+
+	kubectl := c.Kubectl
+	if kubectl == "" {
+		kubectl = "kubectl"
+	}
+	cmd := exec.CommandContext(ctx, kubectl, args...)
+	cmd.Stdin = bytes.NewReader(manifest)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("kubectl %s failed: %s", command, msg)
+		}
+		return fmt.Errorf("kubectl %s failed: %w", command, err)
+	}
+	return nil
+}
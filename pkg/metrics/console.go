@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+)
+
+// Console writes a line of totals per snapshot
+type Console struct {
+	w io.Writer
+}
+
+// NewConsole creates a console sink writing to w
+func NewConsole(w io.Writer) *Console {
+	return &Console{w: w}
+}
+
+// RecordRequest is a no-op; the console reports totals
+func (c *Console) RecordRequest(benchmark.RequestMetric) {}
+
+// RecordError is a no-op; the console reports totals
+func (c *Console) RecordError(string, string) {}
+
+// Snapshot writes the totals
+func (c *Console) Snapshot(snap *benchmark.StatsSnapshot, final bool) error {
+	s := summarize(snap, final)
+	label := fmt.Sprintf("%.0fs", s.Elapsed)
+	if final {
+		label = "final"
+	}
+	_, err := fmt.Fprintf(c.w, "[metrics] %s: %d requests (%d failed), %.1f req/s, avg %.2fms, p50 %.2fms, p90 %.2fms, p99 %.2fms\n",
+		label, s.Requests, s.Failures, s.RPS, s.AvgMs, s.P50Ms, s.P90Ms, s.P99Ms)
+	return err
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+)
+
+// JSON appends a JSON object of totals per snapshot to a file, one per line
+type JSON struct {
+	file   *os.File
+	enc    *json.Encoder
+	labels map[string]string
+}
+
+// jsonLine is one line of the JSON sink
+type jsonLine struct {
+	Time   time.Time         `json:"time"`
+	Labels map[string]string `json:"labels,omitempty"`
+	summary
+}
+
+// NewJSON creates a JSON sink appending to path, adding labels to every line
+func NewJSON(path string, labels map[string]string) (*JSON, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open metrics file: %w", err)
+	}
+	return &JSON{file: file, enc: json.NewEncoder(file), labels: labels}, nil
+}
+
+// RecordRequest is a no-op; the file holds totals
+func (j *JSON) RecordRequest(benchmark.RequestMetric) {}
+
+// RecordError is a no-op; the file holds totals
+func (j *JSON) RecordError(string, string) {}
+
+// Snapshot appends the totals
+func (j *JSON) Snapshot(snap *benchmark.StatsSnapshot, final bool) error {
+	return j.enc.Encode(jsonLine{Time: time.Now().UTC(), Labels: j.labels, summary: summarize(snap, final)})
+}
+
+// Close closes the file
+func (j *JSON) Close() error {
+	return j.file.Close()
+}
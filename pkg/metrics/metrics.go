@@ -0,0 +1,94 @@
+// Package metrics holds the built-in metrics sinks: export targets that the
+// benchmark runner feeds with every request and a snapshot of the totals each
+// second (see benchmark.MetricsSink).
+package metrics
+
+import (
+	"errors"
+	"io"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// Open creates the sink for a spec such as "statsd:localhost:8125". The
+// console sink writes to log. The StatsD, Prometheus and JSON sinks attach
+// labels (the run metadata and tags) to their metrics.
+func Open(spec string, labels map[string]string, log io.Writer) (benchmark.MetricsSink, error) {
+	kind, target, err := config.ParseSink(spec)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case config.SinkConsole:
+		return NewConsole(log), nil
+	case config.SinkJSON:
+		return NewJSON(target, labels)
+	case config.SinkRequests:
+		return NewRequests(target)
+	case config.SinkStatsD:
+		return NewStatsD(target, "benchmark", labels)
+	default:
+		return NewPrometheus(target, labels)
+	}
+}
+
+// OpenAll creates the sinks for specs. Close the returned sinks with CloseAll
+// once the results are final.
+func OpenAll(specs []string, labels map[string]string, log io.Writer) ([]benchmark.MetricsSink, error) {
+	sinks := make([]benchmark.MetricsSink, 0, len(specs))
+	for _, spec := range specs {
+		sink, err := Open(spec, labels, log)
+		if err != nil {
+			CloseAll(sinks)
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// CloseAll closes the sinks that hold resources (files, sockets, servers)
+func CloseAll(sinks []benchmark.MetricsSink) error {
+	var errs []error
+	for _, sink := range sinks {
+		if closer, ok := sink.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// summary is the totals a sink reports for a snapshot
+type summary struct {
+	Elapsed   float64 `json:"elapsed"` // Seconds
+	Requests  int64   `json:"requests"`
+	Successes int64   `json:"successes"`
+	Failures  int64   `json:"failures"`
+	RPS       float64 `json:"rps"`
+	AvgMs     float64 `json:"avgMs"`
+	P50Ms     float64 `json:"p50Ms"`
+	P90Ms     float64 `json:"p90Ms"`
+	P99Ms     float64 `json:"p99Ms"`
+	Final     bool    `json:"final,omitempty"`
+}
+
+// summarize computes the totals of a snapshot
+func summarize(snap *benchmark.StatsSnapshot, final bool) summary {
+	stats := benchmark.NewStatsWithOptions(snap.Histogram != nil, false)
+	stats.Merge(snap)
+	return summary{
+		Elapsed:   snap.TotalDuration,
+		Requests:  snap.TotalRequests,
+		Successes: snap.SuccessCount,
+		Failures:  snap.FailureCount,
+		RPS:       stats.RequestsPerSecond,
+		AvgMs:     stats.AverageResponseTime() / 1000,
+		P50Ms:     float64(stats.GetLatencyPercentile(50)) / 1000,
+		P90Ms:     float64(stats.GetLatencyPercentile(90)) / 1000,
+		P99Ms:     float64(stats.GetLatencyPercentile(99)) / 1000,
+		Final:     final,
+	}
+}
@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// Prometheus serves the totals of the latest snapshot at /metrics in the
+// Prometheus text format while the benchmark runs. Labels are added to
+// every series.
+type Prometheus struct {
+	server *http.Server
+	labels string // `key="value",...` of every series, or empty
+	mu     sync.Mutex
+	sum    summary
+	byName []*benchmark.RequestStatsSnapshot
+}
+
+// NewPrometheus creates a Prometheus sink listening on addr (e.g. ":9102")
+// with labels on every series
+func NewPrometheus(addr string, labels map[string]string) (*Prometheus, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for Prometheus: %w", err)
+	}
+	p := &Prometheus{labels: prometheusLabels(labels)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", p.serveMetrics)
+	p.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go p.server.Serve(listener)
+	return p, nil
+}
+
+// RecordRequest is a no-op; Prometheus scrapes the snapshot totals
+func (p *Prometheus) RecordRequest(benchmark.RequestMetric) {}
+
+// RecordError is a no-op; Prometheus scrapes the snapshot totals
+func (p *Prometheus) RecordError(string, string) {}
+
+// Snapshot keeps the totals for the next scrape
+func (p *Prometheus) Snapshot(snap *benchmark.StatsSnapshot, final bool) error {
+	sum := summarize(snap, final)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sum = sum
+	p.byName = snap.Requests
+	return nil
+}
+
+// Close stops serving
+func (p *Prometheus) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// serveMetrics writes the metrics in the Prometheus text format
+func (p *Prometheus) serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	p.mu.Lock()
+	sum := p.sum
+	byName := append([]*benchmark.RequestStatsSnapshot(nil), p.byName...)
+	p.mu.Unlock()
+	sort.Slice(byName, func(i, j int) bool { return byName[i].Name < byName[j].Name })
+
+	var b strings.Builder
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	// series names a series with its own labels followed by the run labels
+	series := func(name string, labels ...string) string {
+		if p.labels != "" {
+			labels = append(labels, p.labels)
+		}
+		if len(labels) == 0 {
+			return name
+		}
+		return name + "{" + strings.Join(labels, ",") + "}"
+	}
+	metric("benchmark_requests_total", "counter", "Requests completed or failed")
+	fmt.Fprintf(&b, "%s %d\n", series("benchmark_requests_total"), sum.Requests)
+	metric("benchmark_failures_total", "counter", "Failed requests")
+	fmt.Fprintf(&b, "%s %d\n", series("benchmark_failures_total"), sum.Failures)
+	metric("benchmark_requests_per_second", "gauge", "Average request rate so far")
+	fmt.Fprintf(&b, "%s %g\n", series("benchmark_requests_per_second"), sum.RPS)
+	metric("benchmark_latency_seconds", "summary", "Request latency")
+	for _, q := range []struct {
+		quantile string
+		ms       float64
+	}{{"0.5", sum.P50Ms}, {"0.9", sum.P90Ms}, {"0.99", sum.P99Ms}} {
+		fmt.Fprintf(&b, "%s %g\n", series("benchmark_latency_seconds", fmt.Sprintf("quantile=%q", q.quantile)), q.ms/1000)
+	}
+	fmt.Fprintf(&b, "%s %g\n", series("benchmark_latency_seconds_sum"), sum.AvgMs/1000*float64(sum.Requests))
+	fmt.Fprintf(&b, "%s %d\n", series("benchmark_latency_seconds_count"), sum.Requests)
+	if len(byName) > 0 {
+		metric("benchmark_request_count", "counter", "Requests per configured request or step")
+		for _, req := range byName {
+			name := fmt.Sprintf("name=%q", req.Name)
+			fmt.Fprintf(&b, "%s %d\n", series("benchmark_request_count", name, `result="success"`), req.SuccessCount)
+			fmt.Fprintf(&b, "%s %d\n", series("benchmark_request_count", name, `result="failure"`), req.FailureCount)
+		}
+	}
+	metric("benchmark_finished", "gauge", "1 once the run has ended")
+	finished := 0
+	if sum.Final {
+		finished = 1
+	}
+	fmt.Fprintf(&b, "%s %d\n", series("benchmark_finished"), finished)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(b.String()))
+}
+
+// prometheusLabels formats labels as `key="value",...`, turning characters
+// that are invalid in label names into underscores
+func prometheusLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, key := range config.SortedKeys(labels) {
+		name := strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+				return r
+			}
+			return '_'
+		}, key)
+		if name == "" || (name[0] >= '0' && name[0] <= '9') {
+			name = "_" + name
+		}
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, labels[key]))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+)
+
+// Requests appends a JSON object per request to a file, one per line, so
+// failures can be matched with the server's logs by request ID
+type Requests struct {
+	mu   sync.Mutex
+	file *os.File
+	buf  *bufio.Writer
+	enc  *json.Encoder
+}
+
+// requestLine is one line of the requests sink
+type requestLine struct {
+	Time      time.Time `json:"time"`
+	Name      string    `json:"name"`
+	Method    string    `json:"method"`
+	Status    int       `json:"status"`
+	LatencyMs float64   `json:"latencyMs"`
+	Success   bool      `json:"success"`
+	RequestID string    `json:"requestId,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// NewRequests creates a requests sink appending to path
+func NewRequests(path string) (*Requests, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open requests file: %w", err)
+	}
+	buf := bufio.NewWriter(file)
+	return &Requests{file: file, buf: buf, enc: json.NewEncoder(buf)}, nil
+}
+
+// RecordRequest appends the request
+func (s *Requests) RecordRequest(m benchmark.RequestMetric) {
+	line := requestLine{
+		Time:      time.Now().UTC(),
+		Name:      m.Name,
+		Method:    m.Method,
+		Status:    m.Status,
+		LatencyMs: float64(m.Latency) / float64(time.Millisecond),
+		Success:   m.Success,
+		RequestID: m.RequestID,
+		Error:     m.Error,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enc.Encode(line)
+}
+
+// RecordError is a no-op; failed requests carry their error
+func (s *Requests) RecordError(string, string) {}
+
+// Snapshot flushes the lines buffered since the last snapshot
+func (s *Requests) Snapshot(*benchmark.StatsSnapshot, bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Flush()
+}
+
+// Close flushes and closes the file
+func (s *Requests) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.buf.Flush(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
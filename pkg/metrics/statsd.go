@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// statsdPacketSize keeps StatsD packets below a typical MTU
+const statsdPacketSize = 1400
+
+// StatsD sends every request as counters and a timing to a StatsD server over
+// UDP, batched into packets, and the totals as gauges. Labels are sent as
+// DogStatsD tags.
+type StatsD struct {
+	conn   net.Conn
+	prefix string
+	tags   string // "|#key:value,..." suffix of every line, or empty
+	mu     sync.Mutex
+	buf    []byte
+}
+
+// NewStatsD creates a StatsD sink sending to addr, naming metrics prefix.*
+// and tagging them with labels
+func NewStatsD(addr, prefix string, labels map[string]string) (*StatsD, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach StatsD server: %w", err)
+	}
+	return &StatsD{conn: conn, prefix: prefix, tags: statsdTags(labels)}, nil
+}
+
+// RecordRequest sends the request's count, timing and status
+func (s *StatsD) RecordRequest(m benchmark.RequestMetric) {
+	name := s.prefix + "." + statsdName(m.Name)
+	ms := float64(m.Latency.Microseconds()) / 1000
+	s.write(fmt.Sprintf("%s.requests:1|c\n%s.latency:%.3f|ms\n%s.status.%d:1|c\n", name, name, ms, name, m.Status))
+	if !m.Success {
+		s.write(fmt.Sprintf("%s.failures:1|c\n", name))
+	}
+}
+
+// RecordError counts the error; failures are already counted per request
+func (s *StatsD) RecordError(name, message string) {
+	s.write(fmt.Sprintf("%s.errors:1|c\n", s.prefix))
+}
+
+// Snapshot sends the totals as gauges and flushes the batched metrics
+func (s *StatsD) Snapshot(snap *benchmark.StatsSnapshot, final bool) error {
+	sum := summarize(snap, final)
+	s.write(fmt.Sprintf("%s.rps:%.2f|g\n%s.p99:%.3f|g\n", s.prefix, sum.RPS, s.prefix, sum.P99Ms))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush()
+}
+
+// Close flushes the remaining metrics and closes the socket
+func (s *StatsD) Close() error {
+	s.mu.Lock()
+	err := s.flush()
+	s.mu.Unlock()
+	if closeErr := s.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// write adds lines to the batch, sending it when the next packet is full
+func (s *StatsD) write(lines string) {
+	if s.tags != "" {
+		lines = strings.ReplaceAll(lines, "\n", s.tags+"\n")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.buf)+len(lines) > statsdPacketSize {
+		s.flush() // UDP is fire and forget; errors surface on the next snapshot
+	}
+	s.buf = append(s.buf, lines...)
+}
+
+// flush sends the batch. The caller must hold s.mu.
+func (s *StatsD) flush() error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+	_, err := s.conn.Write(s.buf[:len(s.buf)-1]) // Without the trailing newline
+	s.buf = s.buf[:0]
+	return err
+}
+
+// statsdName makes a request name usable in a metric name
+func statsdName(name string) string {
+	if name == "" {
+		return "request"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// statsdTags formats labels as a DogStatsD tag suffix ("|#key:value,...")
+func statsdTags(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	tags := make([]string, 0, len(labels))
+	for _, key := range config.SortedKeys(labels) {
+		tags = append(tags, statsdTagReplacer.Replace(key+":"+labels[key]))
+	}
+	return "|#" + strings.Join(tags, ",")
+}
+
+// statsdTagReplacer replaces the characters that end a tag (','), a field
+// ('|') or a line (whitespace) in DogStatsD
+var statsdTagReplacer = strings.NewReplacer(",", "_", "|", "_", " ", "_", "\n", "_")
@@ -0,0 +1,49 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+)
+
+// LoadBaseline reads a JSON result file written with --output json as a baseline
+func LoadBaseline(filename string) (*benchmark.Baseline, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var result Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", filename, err)
+	}
+	if result.TotalRequests == 0 {
+		return nil, fmt.Errorf("baseline %s contains no requests", filename)
+	}
+
+	baseline := &benchmark.Baseline{
+		Source:            filename,
+		RequestsPerSecond: result.RequestsPerSec.Average,
+		Latency:           make(map[string]int64),
+	}
+	if total := result.SuccessCount + result.FailureCount; total > 0 {
+		baseline.ErrorRate = float64(result.FailureCount) / float64(total)
+	}
+
+	// Latencies are stored formatted (e.g. "1.20ms")
+	latencies := map[string]string{"avg": result.Latency.Average}
+	for key, value := range result.Latency.Percentiles {
+		latencies[key] = value
+	}
+	for metric, value := range latencies {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("baseline %s: invalid %s latency %q: %w", filename, metric, value, err)
+		}
+		baseline.Latency[metric] = d.Microseconds()
+	}
+	return baseline, nil
+}
@@ -0,0 +1,130 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// TargetStats holds the outcome of one target of a comparison run
+type TargetStats struct {
+	Target     config.TargetConfig
+	Stats      *benchmark.Stats
+	Thresholds *benchmark.ThresholdResults // nil when no thresholds are configured
+}
+
+// ComparisonResult represents the JSON output of a comparison run
+type ComparisonResult struct {
+	Name      string         `json:"name,omitempty"`
+	Timestamp string         `json:"timestamp"`
+	Targets   []TargetResult `json:"targets"`
+}
+
+// TargetResult contains the results of one target in a comparison run
+type TargetResult struct {
+	Target  string  `json:"target"`
+	BaseURL string  `json:"base_url"`
+	Result  *Result `json:"result"`
+}
+
+// WriteComparison outputs the results of a comparison run in the configured format
+func WriteComparison(targets []TargetStats, cfg *config.Config) error {
+	var output io.Writer = os.Stdout
+	if cfg.Output.File != "" {
+		file, err := os.Create(cfg.Output.File)
+		if err != nil {
+			return fmt.Errorf("error creating output file: %w", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	if cfg.Output.Format == "json" {
+		return WriteComparisonJSON(output, targets, cfg)
+	}
+	WriteComparisonTo(output, targets, cfg)
+	return nil
+}
+
+// WriteComparisonJSON writes the full results of every target as one JSON document
+func WriteComparisonJSON(w io.Writer, targets []TargetStats, cfg *config.Config) error {
+	comparison := ComparisonResult{
+		Name:      cfg.Name,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	for _, target := range targets {
+		result := ToJSONResult(target.Stats, cfg)
+		result.Thresholds = ToThresholdSummary(target.Thresholds)
+		comparison.Targets = append(comparison.Targets, TargetResult{
+			Target:  target.Target.Name,
+			BaseURL: target.Target.BaseURL,
+			Result:  result,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(comparison); err != nil {
+		return fmt.Errorf("error encoding JSON: %w", err)
+	}
+	return nil
+}
+
+// WriteComparisonTo writes the targets' headline metrics side by side to w.
+// Every target after the first also shows its change relative to the first.
+func WriteComparisonTo(w io.Writer, targets []TargetStats, cfg *config.Config) {
+	const labelWidth, columnWidth = 14, 22
+
+	percentiles := cfg.Settings.Percentiles
+	if len(percentiles) == 0 {
+		percentiles = []float64{50, 75, 90, 99}
+	}
+
+	row := func(label string, value func(stats *benchmark.Stats) float64, format func(float64) string) {
+		fmt.Fprintf(w, "  %-*s", labelWidth, label)
+		base := value(targets[0].Stats)
+		for i, target := range targets {
+			current := value(target.Stats)
+			cell := format(current)
+			if i > 0 {
+				cell += " " + formatChange(relativeChange(base, current))
+			}
+			fmt.Fprintf(w, " %-*s", columnWidth, cell)
+		}
+		fmt.Fprintln(w)
+	}
+	count := func(v float64) string { return fmt.Sprintf("%.0f", v) }
+	rate := func(v float64) string { return fmt.Sprintf("%.2f", v) }
+	percent := func(v float64) string { return fmt.Sprintf("%.2f%%", v) }
+	latency := func(v float64) string { return FormatLatency(v) }
+	megabytes := func(v float64) string { return fmt.Sprintf("%.2f MB/s", v) }
+
+	fmt.Fprintf(w, "\n  %-*s", labelWidth, "Comparison")
+	for _, target := range targets {
+		fmt.Fprintf(w, " %-*s", columnWidth, target.Target.Name)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  %s\n", strings.Repeat("-", labelWidth+(columnWidth+1)*len(targets)))
+
+	row("Requests", func(s *benchmark.Stats) float64 { return float64(s.TotalRequests) }, count)
+	row("Reqs/sec", func(s *benchmark.Stats) float64 { return s.RequestsPerSecond }, rate)
+	row("Latency avg", func(s *benchmark.Stats) float64 { return s.AverageResponseTime() }, latency)
+	for _, p := range percentiles {
+		row("Latency p"+FormatPercentile(p), func(s *benchmark.Stats) float64 { return float64(s.GetLatencyPercentile(p)) }, latency)
+	}
+	row("Latency max", func(s *benchmark.Stats) float64 { return float64(s.MaxResponseTime()) }, latency)
+	row("Errors", func(s *benchmark.Stats) float64 { return float64(s.FailureCount) }, count)
+	row("Error rate", func(s *benchmark.Stats) float64 {
+		if s.TotalRequests == 0 {
+			return 0
+		}
+		return float64(s.FailureCount) / float64(s.TotalRequests) * 100
+	}, percent)
+	row("Throughput", func(s *benchmark.Stats) float64 { return s.ThroughputMBps() }, megabytes)
+}
@@ -3,6 +3,8 @@ package output
 
 import (
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/benchmarking_go/pkg/benchmark"
 	"github.com/benchmarking_go/pkg/config"
@@ -10,9 +12,23 @@ import (
 
 // WriteConsole outputs results to console
 func WriteConsole(stats *benchmark.Stats, cfg *config.Config) {
-	fmt.Println("\nStatistics        Avg      Stdev        Max")
+	WriteConsoleTo(os.Stdout, stats, cfg)
+}
+
+// outcomeLatency formats a latency of one request outcome, or "-" when no
+// request had that outcome
+func outcomeLatency(summary benchmark.LatencySummary, microseconds float64) string {
+	if summary.Count == 0 {
+		return "-"
+	}
+	return FormatLatency(microseconds)
+}
 
-	fmt.Printf("  Reqs/sec    %10.2f   %8.2f   %9.2f\n",
+// WriteConsoleTo writes the console results to w
+func WriteConsoleTo(w io.Writer, stats *benchmark.Stats, cfg *config.Config) {
+	fmt.Fprintln(w, "\nStatistics        Avg      Stdev        Max")
+
+	fmt.Fprintf(w, "  Reqs/sec    %10.2f   %8.2f   %9.2f\n",
 		stats.RequestsPerSecond,
 		stats.RequestRateStdDev(),
 		stats.MaxRequestRate())
@@ -21,68 +37,253 @@ func WriteConsole(stats *benchmark.Stats, cfg *config.Config) {
 	stdevLatency := FormatLatency(stats.StandardDeviation())
 	maxLatency := FormatLatency(float64(stats.MaxResponseTime()))
 
-	fmt.Printf("  Latency      %8s   %8s    %7s\n", avgLatency, stdevLatency, maxLatency)
+	fmt.Fprintf(w, "  Latency      %8s   %8s    %7s\n", avgLatency, stdevLatency, maxLatency)
 
 	// Use custom percentiles from config
 	percentiles := cfg.Settings.Percentiles
 	if len(percentiles) == 0 {
-		percentiles = []int{50, 75, 90, 99}
+		percentiles = []float64{50, 75, 90, 99}
 	}
 
-	fmt.Println("  Latency Distribution")
+	fmt.Fprintln(w, "  Latency Distribution")
 	for _, p := range percentiles {
-		fmt.Printf("     %d%%    %s\n", p, FormatLatency(float64(stats.GetLatencyPercentile(p))))
+		fmt.Fprintf(w, "     %s%%    %s\n", FormatPercentile(p), FormatLatency(float64(stats.GetLatencyPercentile(p))))
+	}
+
+	// Time to first byte leaves out the body download, so server think time shows apart from transfer time
+	if ttfb := stats.TTFB(percentiles); ttfb.Count > 0 {
+		fmt.Fprintf(w, "  TTFB         %8s   %8s    %7s\n", FormatLatency(ttfb.AvgUs), "-", FormatLatency(float64(ttfb.MaxUs)))
+		fmt.Fprintln(w, "  TTFB Distribution")
+		for i, p := range percentiles {
+			fmt.Fprintf(w, "     %s%%    %s\n", FormatPercentile(p), FormatLatency(float64(ttfb.Percentiles[i])))
+		}
 	}
 
-	fmt.Println("  HTTP codes:")
-	fmt.Printf("    1xx - %d, 2xx - %d, 3xx - %d, 4xx - %d, 5xx - %d\n",
+	// With failures, show successes and failures apart so fast errors don't hide slow successes
+	if success, failure := stats.LatencyByOutcome(percentiles); failure.Count > 0 {
+		fmt.Fprintln(w, "  Latency by Outcome     Success      Failure")
+		fmt.Fprintf(w, "     Count         %11d  %11d\n", success.Count, failure.Count)
+		fmt.Fprintf(w, "     Avg           %11s  %11s\n", outcomeLatency(success, success.AvgUs), outcomeLatency(failure, failure.AvgUs))
+		for i, p := range percentiles {
+			fmt.Fprintf(w, "     %-6s        %11s  %11s\n", FormatPercentile(p)+"%",
+				outcomeLatency(success, float64(success.Percentiles[i])), outcomeLatency(failure, float64(failure.Percentiles[i])))
+		}
+		fmt.Fprintf(w, "     Max           %11s  %11s\n", outcomeLatency(success, float64(success.MaxUs)), outcomeLatency(failure, float64(failure.MaxUs)))
+	}
+
+	fmt.Fprintln(w, "  HTTP codes:")
+	fmt.Fprintf(w, "    1xx - %d, 2xx - %d, 3xx - %d, 4xx - %d, 5xx - %d\n",
 		stats.Http1xxCount, stats.Http2xxCount, stats.Http3xxCount, stats.Http4xxCount, stats.Http5xxCount)
-	fmt.Printf("    others - %d\n", stats.OtherCount)
+	fmt.Fprintf(w, "    others - %d\n", stats.OtherCount)
+
+	if stats.SkippedCount > 0 {
+		fmt.Fprintf(w, "  Skipped steps: %d\n", stats.SkippedCount)
+	}
+	if stats.CancelledCount > 0 {
+		fmt.Fprintf(w, "  Cancelled: %d (in flight when the run stopped)\n", stats.CancelledCount)
+	}
+	if stats.BodyMismatches > 0 {
+		fmt.Fprintf(w, "  Body mismatches: %d (wrong size or checksum)\n", stats.BodyMismatches)
+	}
 
 	errors := stats.GetErrors()
 	if len(errors) > 0 {
-		fmt.Println("  Errors:")
+		fmt.Fprintln(w, "  Errors:")
 		for errMsg, count := range errors {
-			fmt.Printf("    %s - %d\n", errMsg, count)
+			fmt.Fprintf(w, "    %s - %d\n", errMsg, count)
 		}
 	}
 
-	fmt.Printf("  Throughput:   %5.2fMB/s\n", stats.ThroughputMBps())
+	fmt.Fprintf(w, "  Throughput:   %5.2fMB/s\n", stats.ThroughputMBps())
+
+	if slo := stats.SLOReport(); slo != nil {
+		fmt.Fprintf(w, "  SLO: %s\n", FormatSLOObjective(slo))
+		fmt.Fprintf(w, "    Compliance: %.3f%% (%d good, %d bad)\n", slo.Compliance*100, slo.GoodRequests, slo.BadRequests)
+		fmt.Fprintf(w, "    Burn rate: %.2fx\n", slo.BurnRate)
+		fmt.Fprintf(w, "    Error budget: %.4f%% consumed, %.4f%% remaining\n", slo.BudgetConsumed*100, slo.BudgetRemaining*100)
+	}
 
 	// Show histogram if enabled
 	if stats.ShowHistogram {
-		fmt.Print(stats.RenderHistogram())
+		fmt.Fprint(w, stats.RenderHistogram())
 	}
 
 	// Show per-request stats if multiple URLs
 	stats.Lock()
 	if len(stats.RequestStats) > 1 {
-		fmt.Println("\n  Per-Request Statistics:")
+		fmt.Fprintln(w, "\n  Per-Request Statistics:")
 		for _, rs := range stats.RequestStats {
 			avgLatency := float64(0)
 			if rs.RequestCount > 0 {
 				avgLatency = float64(rs.TotalLatency) / float64(rs.RequestCount)
 			}
-			fmt.Printf("    %s (%s %s)\n", rs.Name, rs.Method, rs.URL)
-			fmt.Printf("      Requests: %d, Success: %d, Failed: %d, Avg Latency: %s\n",
+			fmt.Fprintf(w, "    %s (%s %s)\n", rs.Name, rs.Method, rs.URL)
+			fmt.Fprintf(w, "      Requests: %d, Success: %d, Failed: %d, Avg Latency: %s\n",
 				rs.RequestCount, rs.SuccessCount, rs.FailureCount, FormatLatency(avgLatency))
 			// Display per-endpoint errors if any
 			if len(rs.Errors) > 0 {
-				fmt.Println("      Errors:")
+				fmt.Fprintln(w, "      Errors:")
 				for errMsg, count := range rs.Errors {
-					fmt.Printf("        %s - %d\n", errMsg, count)
+					fmt.Fprintf(w, "        %s - %d\n", errMsg, count)
 				}
 			}
 		}
 	}
+
+	// Show per-scenario iteration stats when several scenarios ran
+	if len(stats.ScenarioStats) > 0 {
+		fmt.Fprintln(w, "\n  Scenarios:")
+		for _, ss := range stats.ScenarioStats {
+			avgDuration := float64(0)
+			if ss.RequestCount > 0 {
+				avgDuration = float64(ss.TotalLatency) / float64(ss.RequestCount)
+			}
+			fmt.Fprintf(w, "    %s\n", ss.Name)
+			fmt.Fprintf(w, "      Iterations: %d, Success: %d, Failed: %d, Avg Duration: %s\n",
+				ss.RequestCount, ss.SuccessCount, ss.FailureCount, FormatLatency(avgDuration))
+		}
+	}
+
+	// Show transaction (step group) durations for scenarios
+	if len(stats.TransactionStats) > 0 {
+		fmt.Fprintln(w, "\n  Transactions:")
+		for _, ts := range stats.TransactionStats {
+			avgDuration := float64(0)
+			if ts.RequestCount > 0 {
+				avgDuration = float64(ts.TotalLatency) / float64(ts.RequestCount)
+			}
+			fmt.Fprintf(w, "    %s\n", ts.Name)
+			fmt.Fprintf(w, "      Count: %d, Success: %d, Failed: %d, Avg Duration: %s\n",
+				ts.RequestCount, ts.SuccessCount, ts.FailureCount, FormatLatency(avgDuration))
+			for _, p := range percentiles {
+				fmt.Fprintf(w, "      %s%%: %s\n", FormatPercentile(p), FormatLatency(float64(ts.LatencyPercentile(p))))
+			}
+		}
+	}
+
+	// Show total wait times of poll steps
+	if len(stats.PollStats) > 0 {
+		fmt.Fprintln(w, "\n  Polls:")
+		for _, ps := range stats.PollStats {
+			avgWait := float64(0)
+			if ps.RequestCount > 0 {
+				avgWait = float64(ps.TotalLatency) / float64(ps.RequestCount)
+			}
+			fmt.Fprintf(w, "    %s\n", ps.Name)
+			fmt.Fprintf(w, "      Count: %d, Completed: %d, Failed: %d, Avg Wait: %s\n",
+				ps.RequestCount, ps.SuccessCount, ps.FailureCount, FormatLatency(avgWait))
+			for _, p := range percentiles {
+				fmt.Fprintf(w, "      %s%%: %s\n", FormatPercentile(p), FormatLatency(float64(ps.LatencyPercentile(p))))
+			}
+		}
+	}
+
+	// Show how requests spread over the server IPs
+	if len(stats.AddressStats) > 0 {
+		fmt.Fprintln(w, "\n  Per-IP Statistics:")
+		for _, as := range stats.AddressStats {
+			avgLatency := float64(0)
+			if as.RequestCount > 0 {
+				avgLatency = float64(as.TotalLatency) / float64(as.RequestCount)
+			}
+			fmt.Fprintf(w, "    %s\n", as.Name)
+			fmt.Fprintf(w, "      Requests: %d, Success: %d, Failed: %d, Avg Latency: %s\n",
+				as.RequestCount, as.SuccessCount, as.FailureCount, FormatLatency(avgLatency))
+			for _, p := range percentiles {
+				fmt.Fprintf(w, "      %s%%: %s\n", FormatPercentile(p), FormatLatency(float64(as.LatencyPercentile(p))))
+			}
+		}
+	}
 	stats.Unlock()
 
+	// Show how the streams spread over pooled HTTP/2 connections
+	if connections := stats.Connections(); len(connections) > 0 {
+		fmt.Fprintln(w, "\n  HTTP/2 Connections:")
+		for _, cs := range connections {
+			fmt.Fprintf(w, "    #%d  Streams: %d, Max Concurrent: %d\n", cs.ID+1, cs.Requests, cs.MaxStreams)
+		}
+	}
+
+	// Show how much the connection pool churned
+	if churn := stats.ConnectionChurn(); churn.Opened > 0 {
+		fmt.Fprintf(w, "\n  Connection Pool: %d opened, %d closed, %.1f requests per connection\n",
+			churn.Opened, churn.Closed, float64(stats.TotalRequests)/float64(churn.Opened))
+	}
+
+	// Show which IP versions the connections used
+	if families := stats.AddressFamilies(); families.IPv4+families.IPv6 > 0 {
+		fmt.Fprintf(w, "\n  Connections Opened: %d IPv4, %d IPv6\n", families.IPv4, families.IPv6)
+	}
+
+	// Show how often the responses came from a cache, and how much faster those were
+	if cache := stats.CacheReport(percentiles); cache != nil {
+		fmt.Fprintf(w, "\n  Cache: %.1f%% hit ratio (%d hits, %d misses, %d unknown)\n",
+			cache.HitRatio*100, cache.Hits, cache.Misses, cache.Unknown)
+		fmt.Fprintf(w, "  %-17s%11s  %11s\n", "Latency by Cache", "Hit", "Miss")
+		fmt.Fprintf(w, "     Avg           %11s  %11s\n", outcomeLatency(cache.Hit, cache.Hit.AvgUs), outcomeLatency(cache.Miss, cache.Miss.AvgUs))
+		for i, p := range percentiles {
+			fmt.Fprintf(w, "     %-6s        %11s  %11s\n", FormatPercentile(p)+"%",
+				outcomeLatency(cache.Hit, float64(cache.Hit.Percentiles[i])), outcomeLatency(cache.Miss, float64(cache.Miss.Percentiles[i])))
+		}
+		if len(cache.Endpoints) > 1 {
+			for _, endpoint := range cache.Endpoints {
+				if endpoint.Hits+endpoint.Misses == 0 {
+					fmt.Fprintf(w, "    %s: no cache headers (%d responses)\n", endpoint.Name, endpoint.Unknown)
+					continue
+				}
+				fmt.Fprintf(w, "    %s: %.1f%% hits (%d/%d), avg hit %s, avg miss %s\n", endpoint.Name, endpoint.HitRatio()*100,
+					endpoint.Hits, endpoint.Hits+endpoint.Misses, cacheAvg(endpoint.Hits, endpoint.AvgHitUs()), cacheAvg(endpoint.Misses, endpoint.AvgMissUs()))
+			}
+		}
+	}
+
+	// Show how many requests went through redirects, and what each hop cost
+	if redirects := stats.RedirectReport(percentiles); redirects != nil {
+		fmt.Fprintf(w, "\n  Redirects: %.1f%% of requests redirected (%d), %.2f hops per redirected request (max %d)\n",
+			redirects.RedirectedRate*100, redirects.Redirected, redirects.AvgHops, redirects.MaxHops)
+		if hop := redirects.HopLatency; hop.Count > 0 {
+			fmt.Fprintf(w, "    Hop latency: avg %s", FormatLatency(hop.AvgUs))
+			for i, p := range percentiles {
+				fmt.Fprintf(w, ", %s%% %s", FormatPercentile(p), FormatLatency(float64(hop.Percentiles[i])))
+			}
+			fmt.Fprintf(w, ", max %s\n", FormatLatency(float64(hop.MaxUs)))
+		}
+	}
+
+	// Attribute latency to server components by the durations they report
+	if metrics := stats.ServerTiming(percentiles); len(metrics) > 0 {
+		fmt.Fprintln(w, "\n  Server-Timing:")
+		for _, metric := range metrics {
+			name := metric.Name
+			if metric.Description != "" {
+				name += " (" + metric.Description + ")"
+			}
+			latency := metric.Latency
+			fmt.Fprintf(w, "    %s: %d responses, avg %s", name, latency.Count, FormatLatency(latency.AvgUs))
+			for i, p := range percentiles {
+				fmt.Fprintf(w, ", %s%% %s", FormatPercentile(p), FormatLatency(float64(latency.Percentiles[i])))
+			}
+			fmt.Fprintf(w, ", max %s\n", FormatLatency(float64(latency.MaxUs)))
+		}
+	}
+
 	// Show HdrHistogram info if used
 	if stats.IsUsingHdr() {
-		fmt.Println("\n  [Using HdrHistogram for memory-efficient statistics]")
+		fmt.Fprintln(w, "\n  [Using HdrHistogram for memory-efficient statistics]")
+	}
+	if limit := stats.SampleLimit(); limit > 0 {
+		fmt.Fprintf(w, "\n  [Downsampled for the memory budget: percentiles use up to %d latency samples per series]\n", limit)
 	}
 }
 
+// cacheAvg formats an average latency of cache hits or misses, or "-" without any
+func cacheAvg(count int64, avgUs float64) string {
+	if count == 0 {
+		return "-"
+	}
+	return FormatLatency(avgUs)
+}
+
 // WriteConsoleQuiet outputs minimal results to console (quiet mode)
 func WriteConsoleQuiet(stats *benchmark.Stats) {
 	fmt.Printf("Requests: %d, Duration: %.2fs, Req/s: %.2f, Avg Latency: %s, Errors: %d\n",
@@ -91,4 +292,7 @@ func WriteConsoleQuiet(stats *benchmark.Stats) {
 		stats.RequestsPerSecond,
 		FormatLatency(stats.AverageResponseTime()),
 		stats.FailureCount)
+	if stats.CancelledCount > 0 {
+		fmt.Printf("Cancelled: %d (in flight when the run stopped)\n", stats.CancelledCount)
+	}
 }
@@ -0,0 +1,262 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConvertFormats are the formats a saved JSON result can be converted to
+var ConvertFormats = []string{"html", "markdown", "csv", "junit"}
+
+// LoadResult reads a JSON result file written with --output json
+func LoadResult(filename string) (*Result, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result: %w", err)
+	}
+	var result Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse result %s: %w", filename, err)
+	}
+	return &result, nil
+}
+
+// ConvertResult renders a saved JSON result in another format, so a report
+// can be produced after the fact without rerunning the benchmark. Only what
+// the JSON result holds is available: the HTML report has no histogram or
+// configuration section.
+func ConvertResult(w io.Writer, result *Result, format string) error {
+	switch format {
+	case "html":
+		return renderHTML(w, resultHTMLReport(result))
+	case "markdown":
+		return WriteMarkdown(w, result)
+	case "csv":
+		return writeResultCSV(w, result)
+	case "junit":
+		return WriteJUnit(w, result)
+	}
+	return fmt.Errorf("unknown format %q (expected %s)", format, strings.Join(ConvertFormats, ", "))
+}
+
+// resultHTMLReport builds the HTML report data from a saved result
+func resultHTMLReport(result *Result) HTMLReport {
+	report := HTMLReport{
+		Title:           result.Name,
+		Timestamp:       result.Timestamp,
+		Metadata:        result.Metadata,
+		Tags:            result.Tags,
+		Duration:        fmt.Sprintf("%.2fs", result.Duration),
+		TotalRequests:   result.TotalRequests,
+		SuccessCount:    result.SuccessCount,
+		FailureCount:    result.FailureCount,
+		CancelledCount:  result.CancelledCount,
+		BodyMismatches:  result.BodyMismatches,
+		SuccessRate:     resultSuccessRate(result),
+		RequestsPerSec:  result.RequestsPerSec.Average,
+		ReqSecStdDev:    result.RequestsPerSec.StdDev,
+		ReqSecMax:       result.RequestsPerSec.Max,
+		AvgLatency:      result.Latency.Average,
+		MinLatency:      result.Latency.Min,
+		MaxLatency:      result.Latency.Max,
+		StdDevLatency:   result.Latency.StdDev,
+		OutcomeSplit:    result.Latency.Failure != nil,
+		HTTPCodes:       HTTPCodeData(result.HTTPCodes),
+		Throughput:      result.Throughput.MBPerSec,
+		ThroughputBytes: result.Throughput.TotalBytes,
+		Thresholds:      result.Thresholds,
+		Cache:           cacheHTMLData(result.Cache),
+		Redirects:       redirectHTMLData(result.Redirects),
+		ServerTiming:    serverTimingHTMLData(result.ServerTiming),
+		Converted:       true,
+	}
+
+	if ttfb := result.Latency.TTFB; ttfb != nil {
+		report.AvgTTFB = ttfb.Average
+	}
+	for _, p := range resultPercentiles(result.Latency.Percentiles) {
+		key := "p" + FormatPercentile(p)
+		report.Percentiles = append(report.Percentiles, PercentileData{
+			Percentile: FormatPercentile(p),
+			Value:      result.Latency.Percentiles[key],
+			Success:    outcomePercentile(result.Latency.Success, key),
+			Failure:    outcomePercentile(result.Latency.Failure, key),
+			TTFB:       outcomePercentile(result.Latency.TTFB, key),
+		})
+	}
+
+	for _, rs := range result.Requests {
+		endpointErrors := make([]ErrorData, 0, len(rs.Errors))
+		for msg, count := range rs.Errors {
+			endpointErrors = append(endpointErrors, ErrorData{Message: msg, Count: count})
+		}
+		report.PerRequestStats = append(report.PerRequestStats, PerRequestStatData{
+			Name:       rs.Name,
+			URL:        rs.URL,
+			Method:     rs.Method,
+			Requests:   rs.RequestCount,
+			Success:    rs.SuccessCount,
+			Failed:     rs.FailureCount,
+			AvgLatency: rs.AvgLatency,
+			Errors:     endpointErrors,
+		})
+	}
+	for msg, count := range result.Errors {
+		report.Errors = append(report.Errors, ErrorData{Message: msg, Count: count, Samples: result.ErrorSamples[msg]})
+	}
+
+	if slo := result.SLO; slo != nil {
+		report.SLO = &SLOData{
+			Objective:       resultSLOObjective(slo),
+			Compliance:      fmt.Sprintf("%.3f%%", slo.Compliance*100),
+			GoodRequests:    slo.GoodRequests,
+			BadRequests:     slo.BadRequests,
+			BurnRate:        fmt.Sprintf("%.2fx", slo.BurnRate),
+			BudgetConsumed:  fmt.Sprintf("%.4f%%", slo.BudgetConsumed*100),
+			BudgetRemaining: fmt.Sprintf("%.4f%%", slo.BudgetRemaining*100),
+			Exhausted:       slo.BurnRate > 1,
+		}
+	}
+	return report
+}
+
+// resultSuccessRate returns the percentage of processed requests that succeeded
+func resultSuccessRate(result *Result) float64 {
+	if processed := result.SuccessCount + result.FailureCount; processed > 0 {
+		return float64(result.SuccessCount) / float64(processed) * 100
+	}
+	return 0
+}
+
+// resultSLOObjective describes a saved SLO like FormatSLOObjective
+func resultSLOObjective(slo *SLOSummary) string {
+	if slo.Latency != "" {
+		return fmt.Sprintf("%.3f%% under %s (%s period)", slo.Target*100, slo.Latency, slo.Period)
+	}
+	return fmt.Sprintf("%.3f%% successful (%s period)", slo.Target*100, slo.Period)
+}
+
+// outcomePercentile returns one percentile of an outcome's latencies, or "-"
+// when no request had that outcome
+func outcomePercentile(outcome *OutcomeLatency, key string) string {
+	if outcome == nil || outcome.Percentiles[key] == "" {
+		return "-"
+	}
+	return outcome.Percentiles[key]
+}
+
+// resultMicros converts a formatted latency such as "1.20ms" back to
+// microseconds for CSV columns (empty when missing)
+func resultMicros(latency string, decimals int) string {
+	d, err := time.ParseDuration(latency)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatFloat(float64(d)/float64(time.Microsecond), 'f', decimals, 64)
+}
+
+// writeResultCSV writes a saved result with the columns of WriteCSV
+func writeResultCSV(w io.Writer, result *Result) error {
+	percentiles := resultPercentiles(result.Latency.Percentiles)
+	header := []string{
+		"timestamp", "name", "duration_seconds", "total_requests", "success_count", "failure_count", "cancelled_count",
+		"requests_per_second_avg", "requests_per_second_max",
+		"latency_avg_us", "latency_min_us", "latency_max_us", "latency_std_dev_us",
+	}
+	row := []string{
+		result.Timestamp,
+		result.Name,
+		strconv.FormatFloat(result.Duration, 'f', 3, 64),
+		strconv.FormatInt(result.TotalRequests, 10),
+		strconv.FormatInt(result.SuccessCount, 10),
+		strconv.FormatInt(result.FailureCount, 10),
+		strconv.FormatInt(result.CancelledCount, 10),
+		strconv.FormatFloat(result.RequestsPerSec.Average, 'f', 2, 64),
+		strconv.FormatFloat(result.RequestsPerSec.Max, 'f', 2, 64),
+		resultMicros(result.Latency.Average, 2),
+		resultMicros(result.Latency.Min, 0),
+		resultMicros(result.Latency.Max, 0),
+		resultMicros(result.Latency.StdDev, 2),
+	}
+
+	outcomes := []struct {
+		prefix    string
+		latencies map[string]string
+	}{{"latency_", result.Latency.Percentiles}, {"latency_success_", nil}, {"latency_failure_", nil}, {"ttfb_", nil}}
+	if result.Latency.Success != nil {
+		outcomes[1].latencies = result.Latency.Success.Percentiles
+	}
+	if result.Latency.Failure != nil {
+		outcomes[2].latencies = result.Latency.Failure.Percentiles
+	}
+	if result.Latency.TTFB != nil {
+		outcomes[3].latencies = result.Latency.TTFB.Percentiles
+	}
+	for _, outcome := range outcomes {
+		if outcome.prefix == "ttfb_" {
+			header = append(header, "ttfb_avg_us", "ttfb_max_us")
+			if ttfb := result.Latency.TTFB; ttfb != nil {
+				row = append(row, resultMicros(ttfb.Average, 2), resultMicros(ttfb.Max, 0))
+			} else {
+				row = append(row, "0", "0")
+			}
+		}
+		for _, p := range percentiles {
+			header = append(header, outcome.prefix+"p"+strings.ReplaceAll(FormatPercentile(p), ".", "_")+"_us")
+			value := "0" // As in WriteCSV when no request had the outcome
+			if outcome.latencies != nil {
+				value = resultMicros(outcome.latencies["p"+FormatPercentile(p)], 0)
+			}
+			row = append(row, value)
+		}
+	}
+
+	codes := result.HTTPCodes
+	header = append(header, "http_1xx", "http_2xx", "http_3xx", "http_4xx", "http_5xx", "http_other", "throughput_bytes", "throughput_mb_per_sec")
+	for _, count := range []int64{codes.Code1xx, codes.Code2xx, codes.Code3xx, codes.Code4xx, codes.Code5xx, codes.Other, result.Throughput.TotalBytes} {
+		row = append(row, strconv.FormatInt(count, 10))
+	}
+	row = append(row, strconv.FormatFloat(result.Throughput.MBPerSec, 'f', 4, 64))
+
+	if slo := result.SLO; slo != nil {
+		header = append(header, "slo_target", "slo_compliance", "slo_burn_rate", "slo_budget_consumed", "slo_budget_remaining")
+		row = append(row,
+			strconv.FormatFloat(slo.Target, 'f', -1, 64),
+			strconv.FormatFloat(slo.Compliance, 'f', 6, 64),
+			strconv.FormatFloat(slo.BurnRate, 'f', 4, 64),
+			strconv.FormatFloat(slo.BudgetConsumed, 'f', 8, 64),
+			strconv.FormatFloat(slo.BudgetRemaining, 'f', 8, 64),
+		)
+	}
+	if thresholds := result.Thresholds; thresholds != nil {
+		header = append(header, "thresholds_passed", "thresholds_failed")
+		row = append(row, strconv.FormatBool(thresholds.Passed), strconv.Itoa(thresholds.Failed))
+		for _, check := range thresholds.Results {
+			column := thresholdColumn(check.Name)
+			verdict := "pass"
+			if !check.Passed {
+				verdict = "fail"
+			}
+			header = append(header, column, column+"_actual")
+			row = append(row, verdict, check.Actual)
+		}
+	}
+	header = append(header, metadataColumns(result.Metadata, result.Tags)...)
+	row = append(row, metadataValues(result.Metadata, result.Tags)...)
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing CSV header: %w", err)
+	}
+	if err := writer.Write(row); err != nil {
+		return fmt.Errorf("error writing CSV data: %w", err)
+	}
+	writer.Flush()
+	return writer.Error()
+}
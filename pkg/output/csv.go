@@ -6,15 +6,18 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/benchmarking_go/pkg/benchmark"
 	"github.com/benchmarking_go/pkg/config"
 )
 
-// WriteCSV outputs results in CSV format
-func WriteCSV(stats *benchmark.Stats, cfg *config.Config) error {
+// WriteCSV outputs results in CSV format. Threshold verdicts are added as
+// columns when thresholds is non-nil.
+func WriteCSV(stats *benchmark.Stats, cfg *config.Config, thresholds *benchmark.ThresholdResults) error {
 	var output io.Writer = os.Stdout
 	if cfg.Output.File != "" {
 		file, err := os.Create(cfg.Output.File)
@@ -36,6 +39,7 @@ func WriteCSV(stats *benchmark.Stats, cfg *config.Config) error {
 		"total_requests",
 		"success_count",
 		"failure_count",
+		"cancelled_count",
 		"requests_per_second_avg",
 		"requests_per_second_max",
 		"latency_avg_us",
@@ -46,7 +50,16 @@ func WriteCSV(stats *benchmark.Stats, cfg *config.Config) error {
 
 	// Add percentile headers
 	for _, p := range cfg.Settings.Percentiles {
-		header = append(header, fmt.Sprintf("latency_p%d_us", p))
+		header = append(header, "latency_p"+strings.ReplaceAll(FormatPercentile(p), ".", "_")+"_us")
+	}
+	for _, outcome := range []string{"success", "failure"} {
+		for _, p := range cfg.Settings.Percentiles {
+			header = append(header, "latency_"+outcome+"_p"+strings.ReplaceAll(FormatPercentile(p), ".", "_")+"_us")
+		}
+	}
+	header = append(header, "ttfb_avg_us", "ttfb_max_us")
+	for _, p := range cfg.Settings.Percentiles {
+		header = append(header, "ttfb_p"+strings.ReplaceAll(FormatPercentile(p), ".", "_")+"_us")
 	}
 
 	header = append(header, []string{
@@ -60,6 +73,24 @@ func WriteCSV(stats *benchmark.Stats, cfg *config.Config) error {
 		"throughput_mb_per_sec",
 	}...)
 
+	// Add SLO headers when an objective is configured
+	slo := stats.SLOReport()
+	if slo != nil {
+		header = append(header, "slo_target", "slo_compliance", "slo_burn_rate", "slo_budget_consumed", "slo_budget_remaining")
+	}
+
+	// Add threshold headers: overall verdict, then a result and actual value per check
+	if thresholds != nil {
+		header = append(header, "thresholds_passed", "thresholds_failed")
+		for _, result := range thresholds.Results {
+			column := thresholdColumn(result.Name)
+			header = append(header, column, column+"_actual")
+		}
+	}
+
+	metadata := cfg.RunMetadata()
+	header = append(header, metadataColumns(metadata, cfg.Tags)...)
+
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("error writing CSV header: %w", err)
 	}
@@ -72,6 +103,7 @@ func WriteCSV(stats *benchmark.Stats, cfg *config.Config) error {
 		strconv.FormatInt(stats.TotalRequests, 10),
 		strconv.FormatInt(stats.SuccessCount, 10),
 		strconv.FormatInt(stats.FailureCount, 10),
+		strconv.FormatInt(stats.CancelledCount, 10),
 		strconv.FormatFloat(stats.RequestsPerSecond, 'f', 2, 64),
 		strconv.FormatFloat(stats.MaxRequestRate(), 'f', 2, 64),
 		strconv.FormatFloat(stats.AverageResponseTime(), 'f', 2, 64),
@@ -84,6 +116,17 @@ func WriteCSV(stats *benchmark.Stats, cfg *config.Config) error {
 	for _, p := range cfg.Settings.Percentiles {
 		row = append(row, strconv.FormatInt(stats.GetLatencyPercentile(p), 10))
 	}
+	success, failure := stats.LatencyByOutcome(cfg.Settings.Percentiles)
+	for _, summary := range []benchmark.LatencySummary{success, failure} {
+		for _, value := range summary.Percentiles {
+			row = append(row, strconv.FormatInt(value, 10))
+		}
+	}
+	ttfb := stats.TTFB(cfg.Settings.Percentiles)
+	row = append(row, strconv.FormatFloat(ttfb.AvgUs, 'f', 2, 64), strconv.FormatInt(ttfb.MaxUs, 10))
+	for _, value := range ttfb.Percentiles {
+		row = append(row, strconv.FormatInt(value, 10))
+	}
 
 	row = append(row, []string{
 		strconv.FormatInt(stats.Http1xxCount, 10),
@@ -96,6 +139,29 @@ func WriteCSV(stats *benchmark.Stats, cfg *config.Config) error {
 		strconv.FormatFloat(stats.ThroughputMBps(), 'f', 4, 64),
 	}...)
 
+	if slo != nil {
+		row = append(row,
+			strconv.FormatFloat(slo.Target, 'f', -1, 64),
+			strconv.FormatFloat(slo.Compliance, 'f', 6, 64),
+			strconv.FormatFloat(slo.BurnRate, 'f', 4, 64),
+			strconv.FormatFloat(slo.BudgetConsumed, 'f', 8, 64),
+			strconv.FormatFloat(slo.BudgetRemaining, 'f', 8, 64),
+		)
+	}
+
+	if thresholds != nil {
+		row = append(row, strconv.FormatBool(thresholds.Passed), strconv.Itoa(thresholds.FailedCount()))
+		for _, result := range thresholds.Results {
+			verdict := "pass"
+			if !result.Passed {
+				verdict = "fail"
+			}
+			row = append(row, verdict, result.Actual)
+		}
+	}
+
+	row = append(row, metadataValues(metadata, cfg.Tags)...)
+
 	if err := writer.Write(row); err != nil {
 		return fmt.Errorf("error writing CSV data: %w", err)
 	}
@@ -103,6 +169,40 @@ func WriteCSV(stats *benchmark.Stats, cfg *config.Config) error {
 	return nil
 }
 
+// metadataColumns returns the trailing columns of the run metadata and tags:
+// "tags" when there are tags, then "meta_<key>" per metadata key in order
+func metadataColumns(metadata map[string]string, tags []string) []string {
+	var columns []string
+	if len(tags) > 0 {
+		columns = append(columns, "tags")
+	}
+	for _, key := range config.SortedKeys(metadata) {
+		columns = append(columns, "meta_"+key)
+	}
+	return columns
+}
+
+// metadataValues returns the values of the columns of metadataColumns
+func metadataValues(metadata map[string]string, tags []string) []string {
+	var values []string
+	if len(tags) > 0 {
+		values = append(values, strings.Join(tags, ","))
+	}
+	for _, key := range config.SortedKeys(metadata) {
+		values = append(values, metadata[key])
+	}
+	return values
+}
+
+// nonColumnChars matches characters that are replaced in CSV column names
+var nonColumnChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// thresholdColumn turns a threshold name like "[checkout] Max P95 Latency" into
+// a column name like "threshold_checkout_max_p95_latency"
+func thresholdColumn(name string) string {
+	return "threshold_" + strings.Trim(nonColumnChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
+
 // WriteCSVPerRequest outputs per-request results in CSV format
 func WriteCSVPerRequest(stats *benchmark.Stats, cfg *config.Config) error {
 	var output io.Writer = os.Stdout
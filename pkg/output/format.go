@@ -3,6 +3,10 @@ package output
 
 import (
 	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
 )
 
 // FormatLatency formats latency values with appropriate units
@@ -16,3 +20,25 @@ func FormatLatency(microseconds float64) string {
 	}
 }
 
+
+// FormatPercentile formats a percentile without trailing zeros (e.g. "99", "99.9")
+func FormatPercentile(percentile float64) string {
+	return strconv.FormatFloat(percentile, 'f', -1, 64)
+}
+
+// FormatSLOObjective describes an SLO, e.g. "99.900% under 300.00ms (30d period)"
+func FormatSLOObjective(slo *benchmark.SLOReport) string {
+	objective := fmt.Sprintf("%.3f%% successful", slo.Target*100)
+	if slo.Latency > 0 {
+		objective = fmt.Sprintf("%.3f%% under %s", slo.Target*100, FormatLatency(float64(slo.Latency)))
+	}
+	return fmt.Sprintf("%s (%s period)", objective, FormatPeriod(slo.Period))
+}
+
+// FormatPeriod formats a duration in whole days when possible (e.g. "30d")
+func FormatPeriod(period time.Duration) string {
+	if day := 24 * time.Hour; period >= day && period%day == 0 {
+		return fmt.Sprintf("%dd", period/day)
+	}
+	return period.String()
+}
@@ -0,0 +1,117 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// HistoryFile is the file in a history directory that run summaries are appended to
+const HistoryFile = "history.jsonl"
+
+// HistoryEntry is the summary of one run kept in a history directory
+type HistoryEntry struct {
+	Name           string            `json:"name,omitempty"`
+	Timestamp      string            `json:"timestamp"`
+	Metadata       map[string]string `json:"metadata,omitempty"` // Run metadata such as the git SHA, to tell releases apart
+	Tags           []string          `json:"tags,omitempty"`
+	Duration       float64           `json:"duration_seconds"`
+	TotalRequests  int64             `json:"total_requests"`
+	SuccessCount   int64             `json:"success_count"`
+	FailureCount   int64             `json:"failure_count"`
+	RequestsPerSec float64           `json:"requests_per_second"`
+	AvgLatencyUs   float64           `json:"avg_latency_us"`
+	P50LatencyUs   int64             `json:"p50_latency_us"`
+	P99LatencyUs   int64             `json:"p99_latency_us"`
+	Passed         *bool             `json:"passed,omitempty"` // Threshold verdict, when thresholds were evaluated
+}
+
+// NewHistoryEntry summarizes a finished run; thresholds may be nil
+func NewHistoryEntry(stats *benchmark.Stats, cfg *config.Config, thresholds *benchmark.ThresholdResults) HistoryEntry {
+	entry := HistoryEntry{
+		Name:           cfg.Name,
+		Timestamp:      time.Now().UTC().Format(time.RFC3339),
+		Metadata:       cfg.RunMetadata(),
+		Tags:           cfg.Tags,
+		Duration:       stats.TotalDuration,
+		TotalRequests:  stats.TotalRequests,
+		SuccessCount:   stats.SuccessCount,
+		FailureCount:   stats.FailureCount,
+		RequestsPerSec: stats.RequestsPerSecond,
+		AvgLatencyUs:   stats.AverageResponseTime(),
+		P50LatencyUs:   stats.GetLatencyPercentile(50),
+		P99LatencyUs:   stats.GetLatencyPercentile(99),
+	}
+	if thresholds != nil {
+		entry.Passed = &thresholds.Passed
+	}
+	return entry
+}
+
+// AppendHistory appends the entry as one JSON line to the history file in dir, creating dir if needed
+func AppendHistory(dir string, entry HistoryEntry) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, HistoryFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return nil
+}
+
+// FormatComparison formats the change of the headline metrics from previous to current as a compact table
+func FormatComparison(previous, current *HistoryEntry) string {
+	var b strings.Builder
+	row := func(label, before, after string, change float64) {
+		fmt.Fprintf(&b, "  %-14s %10s -> %-10s %s\n", label, before, after, formatChange(change))
+	}
+
+	b.WriteString("Change vs previous run:\n")
+	row("Requests/sec", fmt.Sprintf("%.1f", previous.RequestsPerSec), fmt.Sprintf("%.1f", current.RequestsPerSec),
+		relativeChange(previous.RequestsPerSec, current.RequestsPerSec))
+	row("Avg latency", FormatLatency(previous.AvgLatencyUs), FormatLatency(current.AvgLatencyUs),
+		relativeChange(previous.AvgLatencyUs, current.AvgLatencyUs))
+	row("p50 latency", FormatLatency(float64(previous.P50LatencyUs)), FormatLatency(float64(current.P50LatencyUs)),
+		relativeChange(float64(previous.P50LatencyUs), float64(current.P50LatencyUs)))
+	row("p99 latency", FormatLatency(float64(previous.P99LatencyUs)), FormatLatency(float64(current.P99LatencyUs)),
+		relativeChange(float64(previous.P99LatencyUs), float64(current.P99LatencyUs)))
+	row("Errors", fmt.Sprintf("%d", previous.FailureCount), fmt.Sprintf("%d", current.FailureCount),
+		relativeChange(float64(previous.FailureCount), float64(current.FailureCount)))
+	return b.String()
+}
+
+// relativeChange returns the change from before to after as a fraction, or NaN when before is 0
+func relativeChange(before, after float64) float64 {
+	if before == 0 {
+		if after == 0 {
+			return 0
+		}
+		return math.NaN()
+	}
+	return (after - before) / before
+}
+
+// formatChange formats a relative change as a signed percentage
+func formatChange(change float64) string {
+	if math.IsNaN(change) {
+		return "(new)"
+	}
+	return fmt.Sprintf("(%+.1f%%)", change*100)
+}
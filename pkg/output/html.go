@@ -4,6 +4,7 @@ package output
 import (
 	"fmt"
 	"html/template"
+	"io"
 	"os"
 	"time"
 
@@ -15,10 +16,14 @@ import (
 type HTMLReport struct {
 	Title            string
 	Timestamp        string
+	Metadata         map[string]string // Run metadata such as the git SHA or environment
+	Tags             []string
 	Duration         string
 	TotalRequests    int64
 	SuccessCount     int64
 	FailureCount     int64
+	CancelledCount   int64 // Requests in flight when the run stopped
+	BodyMismatches   int64 // Responses with the wrong body size or checksum
 	SuccessRate      float64
 	RequestsPerSec   float64
 	ReqSecStdDev     float64
@@ -28,6 +33,8 @@ type HTMLReport struct {
 	MaxLatency       string
 	StdDevLatency    string
 	Percentiles      []PercentileData
+	OutcomeSplit     bool   // Percentiles include separate success and failure columns
+	AvgTTFB          string // Average time to first byte ("" when none was measured)
 	HTTPCodes        HTTPCodeData
 	Throughput       float64
 	ThroughputBytes  int64
@@ -35,12 +42,190 @@ type HTMLReport struct {
 	PerRequestStats  []PerRequestStatData
 	Errors           []ErrorData
 	Config           ConfigSummary
+	Thresholds       *ThresholdSummary // Threshold verdict (nil when none are configured)
+	SLO              *SLOData          // Error budget report (nil when no SLO is configured)
+	Cache            *CacheData        // Cache hit/miss analysis (nil unless cacheStats is set)
+	Redirects        *RedirectData     // Redirect chains (nil when no request was redirected)
+	ServerTiming     *ServerTimingData // Server-Timing metrics (nil when no response had the header)
+	Converted        bool              // Rendered from a saved JSON result, without the run's configuration
+}
+
+// SLOData holds the service level objective and the run's error budget usage
+type SLOData struct {
+	Objective       string
+	Compliance      string
+	GoodRequests    int64
+	BadRequests     int64
+	BurnRate        string
+	BudgetConsumed  string
+	BudgetRemaining string
+	Exhausted       bool // The run burns the budget faster than the SLO allows
+}
+
+// CacheData holds the cache hit ratio and the latencies of hits and misses
+type CacheData struct {
+	HitRatio  string
+	Hits      int64
+	Misses    int64
+	Unknown   int64
+	Latencies []CacheLatencyData
+	Endpoints []CacheEndpointData
+}
+
+// CacheLatencyData is one row of the hit/miss latency table
+type CacheLatencyData struct {
+	Label string
+	Hit   string
+	Miss  string
+}
+
+// CacheEndpointData holds the cache outcomes of one endpoint
+type CacheEndpointData struct {
+	Name     string
+	HitRatio string
+	Hits     int64
+	Misses   int64
+	Unknown  int64
+	AvgHit   string
+	AvgMiss  string
+}
+
+// cacheHTMLData builds the cache section of the report from the JSON result
+func cacheHTMLData(cache *CacheResult) *CacheData {
+	if cache == nil {
+		return nil
+	}
+	data := &CacheData{
+		HitRatio: fmt.Sprintf("%.1f%%", cache.HitRatio*100),
+		Hits:     cache.Hits,
+		Misses:   cache.Misses,
+		Unknown:  cache.Unknown,
+	}
+
+	// Hits and misses were measured at the same percentiles
+	var keys map[string]string
+	if cache.Hit != nil {
+		keys = cache.Hit.Percentiles
+	} else if cache.Miss != nil {
+		keys = cache.Miss.Percentiles
+	}
+	data.Latencies = append(data.Latencies, CacheLatencyData{"Avg", outcomeAverage(cache.Hit), outcomeAverage(cache.Miss)})
+	for _, p := range resultPercentiles(keys) {
+		key := "p" + FormatPercentile(p)
+		data.Latencies = append(data.Latencies, CacheLatencyData{key, outcomePercentile(cache.Hit, key), outcomePercentile(cache.Miss, key)})
+	}
+
+	for _, endpoint := range cache.Endpoints {
+		ed := CacheEndpointData{
+			Name:     endpoint.Name,
+			HitRatio: fmt.Sprintf("%.1f%%", endpoint.HitRatio*100),
+			Hits:     endpoint.Hits,
+			Misses:   endpoint.Misses,
+			Unknown:  endpoint.Unknown,
+			AvgHit:   endpoint.AvgHitLatency,
+			AvgMiss:  endpoint.AvgMissLatency,
+		}
+		if ed.AvgHit == "" {
+			ed.AvgHit = "-"
+		}
+		if ed.AvgMiss == "" {
+			ed.AvgMiss = "-"
+		}
+		data.Endpoints = append(data.Endpoints, ed)
+	}
+	return data
+}
+
+// RedirectData holds the share of redirected requests and the latency of redirect hops
+type RedirectData struct {
+	RedirectedRate string
+	Redirected     int64
+	AvgChainLength string
+	MaxChainLength int64
+	HopLatencies   []PercentileData // Avg, percentiles and max of single hops
+}
+
+// redirectHTMLData builds the redirect section of the report from the JSON result
+func redirectHTMLData(redirects *RedirectResult) *RedirectData {
+	if redirects == nil {
+		return nil
+	}
+	data := &RedirectData{
+		RedirectedRate: fmt.Sprintf("%.1f%%", redirects.RedirectedRate*100),
+		Redirected:     redirects.Redirected,
+		AvgChainLength: fmt.Sprintf("%.2f", redirects.AvgChainLength),
+		MaxChainLength: redirects.MaxChainLength,
+	}
+	if hop := redirects.HopLatency; hop != nil {
+		data.HopLatencies = append(data.HopLatencies, PercentileData{Percentile: "Avg", Value: hop.Average})
+		for _, p := range resultPercentiles(hop.Percentiles) {
+			key := "p" + FormatPercentile(p)
+			data.HopLatencies = append(data.HopLatencies, PercentileData{Percentile: key, Value: hop.Percentiles[key]})
+		}
+		data.HopLatencies = append(data.HopLatencies, PercentileData{Percentile: "Max", Value: hop.Max})
+	}
+	return data
+}
+
+// ServerTimingData holds the durations of the Server-Timing metrics
+type ServerTimingData struct {
+	Percentiles []string // Column labels, e.g. "p99"
+	Metrics     []ServerTimingMetricData
+}
+
+// ServerTimingMetricData is one row of the Server-Timing table
+type ServerTimingMetricData struct {
+	Name        string
+	Description string
+	Count       int64
+	Average     string
+	Percentiles []string
+	Max         string
+}
+
+// serverTimingHTMLData builds the Server-Timing section of the report from the JSON result
+func serverTimingHTMLData(metrics []ServerTimingResult) *ServerTimingData {
+	if len(metrics) == 0 {
+		return nil
+	}
+	// All metrics were measured at the same percentiles
+	data := &ServerTimingData{}
+	percentiles := resultPercentiles(metrics[0].Latency.Percentiles)
+	for _, p := range percentiles {
+		data.Percentiles = append(data.Percentiles, "p"+FormatPercentile(p))
+	}
+	for _, metric := range metrics {
+		md := ServerTimingMetricData{
+			Name:        metric.Name,
+			Description: metric.Description,
+			Count:       metric.Latency.Count,
+			Average:     metric.Latency.Average,
+			Max:         metric.Latency.Max,
+		}
+		for _, key := range data.Percentiles {
+			md.Percentiles = append(md.Percentiles, outcomePercentile(metric.Latency, key))
+		}
+		data.Metrics = append(data.Metrics, md)
+	}
+	return data
+}
+
+// outcomeAverage returns the average latency of an outcome, or "-" when no
+// request had that outcome
+func outcomeAverage(outcome *OutcomeLatency) string {
+	if outcome == nil {
+		return "-"
+	}
+	return outcome.Average
 }
 
 // PercentileData holds percentile information
 type PercentileData struct {
-	Percentile int
+	Percentile string
 	Value      string
+	Success    string // Successful requests only
+	Failure    string // Failed requests only
+	TTFB       string // Time to first byte
 }
 
 // HTTPCodeData holds HTTP status code counts
@@ -77,6 +262,7 @@ type PerRequestStatData struct {
 type ErrorData struct {
 	Message string
 	Count   int
+	Samples []benchmark.ErrorSample // Example failed requests (errorSamples setting)
 }
 
 // ConfigSummary holds configuration summary
@@ -90,8 +276,21 @@ type ConfigSummary struct {
 }
 
 // WriteHTML generates an HTML report from benchmark statistics
-func WriteHTML(stats *benchmark.Stats, cfg *config.Config) error {
+func WriteHTML(stats *benchmark.Stats, cfg *config.Config, thresholds *benchmark.ThresholdResults) error {
 	report := buildHTMLReport(stats, cfg)
+	report.Thresholds = ToThresholdSummary(thresholds)
+	if slo := stats.SLOReport(); slo != nil {
+		report.SLO = &SLOData{
+			Objective:       FormatSLOObjective(slo),
+			Compliance:      fmt.Sprintf("%.3f%%", slo.Compliance*100),
+			GoodRequests:    slo.GoodRequests,
+			BadRequests:     slo.BadRequests,
+			BurnRate:        fmt.Sprintf("%.2fx", slo.BurnRate),
+			BudgetConsumed:  fmt.Sprintf("%.4f%%", slo.BudgetConsumed*100),
+			BudgetRemaining: fmt.Sprintf("%.4f%%", slo.BudgetRemaining*100),
+			Exhausted:       slo.BurnRate > 1,
+		}
+	}
 
 	// Determine output destination
 	outputFile := cfg.Output.File
@@ -106,17 +305,24 @@ func WriteHTML(stats *benchmark.Stats, cfg *config.Config) error {
 	}
 	defer f.Close()
 
-	// Parse and execute template
+	if err := renderHTML(f, report); err != nil {
+		return err
+	}
+
+	fmt.Printf("HTML report saved to: %s\n", outputFile)
+	return nil
+}
+
+// renderHTML executes the report template into w
+func renderHTML(w io.Writer, report HTMLReport) error {
 	tmpl, err := template.New("report").Parse(htmlTemplate)
 	if err != nil {
 		return fmt.Errorf("error parsing HTML template: %w", err)
 	}
 
-	if err := tmpl.Execute(f, report); err != nil {
+	if err := tmpl.Execute(w, report); err != nil {
 		return fmt.Errorf("error executing HTML template: %w", err)
 	}
-
-	fmt.Printf("HTML report saved to: %s\n", outputFile)
 	return nil
 }
 
@@ -124,16 +330,25 @@ func buildHTMLReport(stats *benchmark.Stats, cfg *config.Config) HTMLReport {
 	// Build percentiles
 	percentiles := cfg.Settings.Percentiles
 	if len(percentiles) == 0 {
-		percentiles = []int{50, 75, 90, 99}
+		percentiles = []float64{50, 75, 90, 99}
 	}
 
+	success, failure := stats.LatencyByOutcome(percentiles)
+	ttfb := stats.TTFB(percentiles)
 	percData := make([]PercentileData, len(percentiles))
 	for i, p := range percentiles {
 		percData[i] = PercentileData{
-			Percentile: p,
+			Percentile: FormatPercentile(p),
 			Value:      FormatLatency(float64(stats.GetLatencyPercentile(p))),
+			Success:    FormatLatency(float64(success.Percentiles[i])),
+			Failure:    FormatLatency(float64(failure.Percentiles[i])),
+			TTFB:       FormatLatency(float64(ttfb.Percentiles[i])),
 		}
 	}
+	var avgTTFB string
+	if ttfb.Count > 0 {
+		avgTTFB = FormatLatency(ttfb.AvgUs)
+	}
 
 	// Build histogram buckets
 	buckets := stats.GetHistogramBuckets()
@@ -191,9 +406,10 @@ func buildHTMLReport(stats *benchmark.Stats, cfg *config.Config) HTMLReport {
 
 	// Build errors
 	errors := stats.GetErrors()
+	samples := stats.GetErrorSamples()
 	errData := make([]ErrorData, 0, len(errors))
 	for msg, count := range errors {
-		errData = append(errData, ErrorData{Message: msg, Count: count})
+		errData = append(errData, ErrorData{Message: msg, Count: count, Samples: samples[msg]})
 	}
 
 	// Calculate success rate based on processed requests (success + failure)
@@ -209,10 +425,14 @@ func buildHTMLReport(stats *benchmark.Stats, cfg *config.Config) HTMLReport {
 	return HTMLReport{
 		Title:           cfg.Name,
 		Timestamp:       time.Now().Format(time.RFC3339),
+		Metadata:        cfg.RunMetadata(),
+		Tags:            cfg.Tags,
 		Duration:        durationStr,
 		TotalRequests:   stats.TotalRequests,
 		SuccessCount:    stats.SuccessCount,
 		FailureCount:    stats.FailureCount,
+		CancelledCount:  stats.CancelledCount,
+		BodyMismatches:  stats.BodyMismatches,
 		SuccessRate:     successRate,
 		RequestsPerSec:  stats.RequestsPerSecond,
 		ReqSecStdDev:    stats.RequestRateStdDev(),
@@ -222,6 +442,8 @@ func buildHTMLReport(stats *benchmark.Stats, cfg *config.Config) HTMLReport {
 		MaxLatency:      FormatLatency(float64(stats.MaxResponseTime())),
 		StdDevLatency:   FormatLatency(stats.StandardDeviation()),
 		Percentiles:     percData,
+		OutcomeSplit:    failure.Count > 0,
+		AvgTTFB:         avgTTFB,
 		HTTPCodes: HTTPCodeData{
 			Code1xx: stats.Http1xxCount,
 			Code2xx: stats.Http2xxCount,
@@ -235,6 +457,9 @@ func buildHTMLReport(stats *benchmark.Stats, cfg *config.Config) HTMLReport {
 		HistogramBuckets: histData,
 		PerRequestStats:  perReqData,
 		Errors:           errData,
+		Cache:            cacheHTMLData(ToCacheResult(stats.CacheReport(percentiles), percentiles)),
+		Redirects:        redirectHTMLData(ToRedirectResult(stats.RedirectReport(percentiles), percentiles)),
+		ServerTiming:     serverTimingHTMLData(ToServerTimingResults(stats.ServerTiming(percentiles), percentiles)),
 		Config: ConfigSummary{
 			URLs:            len(cfg.Requests),
 			ConcurrentUsers: cfg.Settings.ConcurrentUsers,
@@ -303,6 +528,10 @@ const htmlTemplate = `<!DOCTYPE html>
             font-size: 0.9rem;
         }
         
+        .run-meta + .run-meta::before {
+            content: " · ";
+        }
+        
         .summary-grid {
             display: grid;
             grid-template-columns: repeat(auto-fit, minmax(200px, 1fr));
@@ -416,6 +645,20 @@ const htmlTemplate = `<!DOCTYPE html>
             border-left: 3px solid var(--error);
         }
         
+        .error-sample {
+            margin-top: 0.5rem;
+            color: var(--text-secondary);
+        }
+        
+        .error-sample pre {
+            margin: 0.25rem 0 0;
+            padding: 0.5rem;
+            background: var(--bg-secondary);
+            border-radius: 4px;
+            white-space: pre-wrap;
+            word-break: break-all;
+        }
+        
         .endpoint-errors {
             display: flex;
             flex-wrap: wrap;
@@ -437,6 +680,28 @@ const htmlTemplate = `<!DOCTYPE html>
             font-weight: 600;
         }
         
+        td.success {
+            color: var(--success);
+            font-weight: 600;
+        }
+        
+        .badge {
+            font-size: 0.75rem;
+            padding: 0.15rem 0.5rem;
+            border-radius: 3px;
+            vertical-align: middle;
+        }
+        
+        .badge.success {
+            background: rgba(34, 197, 94, 0.2);
+            color: var(--success);
+        }
+        
+        .badge.error {
+            background: rgba(239, 68, 68, 0.2);
+            color: var(--error);
+        }
+        
         .config-grid {
             display: grid;
             grid-template-columns: repeat(auto-fit, minmax(150px, 1fr));
@@ -466,6 +731,12 @@ const htmlTemplate = `<!DOCTYPE html>
         <header>
             <h1>{{if .Title}}{{.Title}}{{else}}Benchmark Report{{end}}</h1>
             <p class="timestamp">Generated: {{.Timestamp}}</p>
+            {{if or .Metadata .Tags}}
+            <p class="timestamp">
+                {{range $key, $value := .Metadata}}<span class="run-meta">{{$key}}: {{$value}}</span>{{end}}
+                {{if .Tags}}<span class="run-meta">Tags: {{range $i, $tag := .Tags}}{{if $i}}, {{end}}{{$tag}}{{end}}</span>{{end}}
+            </p>
+            {{end}}
         </header>
         
         <div class="summary-grid">
@@ -477,7 +748,8 @@ const htmlTemplate = `<!DOCTYPE html>
             <div class="summary-card">
                 <h3>Success Rate</h3>
                 <div class="value {{if ge .SuccessRate 99.0}}success{{else if ge .SuccessRate 95.0}}warning{{else}}error{{end}}">{{printf "%.1f" .SuccessRate}}%</div>
-                <div class="sub">{{.SuccessCount}} success / {{.FailureCount}} failed</div>
+                <div class="sub">{{.SuccessCount}} success / {{.FailureCount}} failed{{if .CancelledCount}} / {{.CancelledCount}} cancelled{{end}}</div>
+                {{if .BodyMismatches}}<div class="sub">{{.BodyMismatches}} body mismatches (size or checksum)</div>{{end}}
             </div>
             <div class="summary-card">
                 <h3>Requests/sec</h3>
@@ -488,6 +760,7 @@ const htmlTemplate = `<!DOCTYPE html>
                 <h3>Avg Latency</h3>
                 <div class="value">{{.AvgLatency}}</div>
                 <div class="sub">Min: {{.MinLatency}} / Max: {{.MaxLatency}}</div>
+                {{if .AvgTTFB}}<div class="sub">Avg TTFB: {{.AvgTTFB}}</div>{{end}}
             </div>
         </div>
         
@@ -498,6 +771,9 @@ const htmlTemplate = `<!DOCTYPE html>
                     <tr>
                         <th>Percentile</th>
                         <th>Latency</th>
+                        {{if .OutcomeSplit}}<th>Success</th>
+                        <th>Failure</th>{{end}}
+                        {{if .AvgTTFB}}<th>TTFB</th>{{end}}
                     </tr>
                 </thead>
                 <tbody>
@@ -505,6 +781,9 @@ const htmlTemplate = `<!DOCTYPE html>
                     <tr>
                         <td>p{{.Percentile}}</td>
                         <td>{{.Value}}</td>
+                        {{if $.OutcomeSplit}}<td>{{.Success}}</td>
+                        <td>{{.Failure}}</td>{{end}}
+                        {{if $.AvgTTFB}}<td>{{.TTFB}}</td>{{end}}
                     </tr>
                     {{end}}
                 </tbody>
@@ -585,6 +864,131 @@ const htmlTemplate = `<!DOCTYPE html>
         </section>
         {{end}}
         
+        {{if .SLO}}
+        <section>
+            <h2>SLO {{if .SLO.Exhausted}}<span class="badge error">BURNING</span>{{else}}<span class="badge success">WITHIN BUDGET</span>{{end}}</h2>
+            <table>
+                <tbody>
+                    <tr><td>Objective</td><td>{{.SLO.Objective}}</td></tr>
+                    <tr><td>Compliance</td><td>{{.SLO.Compliance}} ({{.SLO.GoodRequests}} good, {{.SLO.BadRequests}} bad)</td></tr>
+                    <tr><td>Burn Rate</td><td>{{.SLO.BurnRate}}</td></tr>
+                    <tr><td>Error Budget Consumed</td><td>{{.SLO.BudgetConsumed}}</td></tr>
+                    <tr><td>Error Budget Remaining</td><td>{{.SLO.BudgetRemaining}}</td></tr>
+                </tbody>
+            </table>
+        </section>
+        {{end}}
+
+        {{if .Cache}}
+        <section>
+            <h2>Cache <span class="badge">{{.Cache.HitRatio}} HITS</span></h2>
+            <p class="sub">{{.Cache.Hits}} hits, {{.Cache.Misses}} misses, {{.Cache.Unknown}} without a cache header</p>
+            <table>
+                <thead>
+                    <tr><th>Latency</th><th>Hit</th><th>Miss</th></tr>
+                </thead>
+                <tbody>
+                    {{range .Cache.Latencies}}
+                    <tr><td>{{.Label}}</td><td>{{.Hit}}</td><td>{{.Miss}}</td></tr>
+                    {{end}}
+                </tbody>
+            </table>
+            {{if .Cache.Endpoints}}
+            <table>
+                <thead>
+                    <tr><th>Endpoint</th><th>Hit Ratio</th><th>Hits</th><th>Misses</th><th>Unknown</th><th>Avg Hit</th><th>Avg Miss</th></tr>
+                </thead>
+                <tbody>
+                    {{range .Cache.Endpoints}}
+                    <tr>
+                        <td>{{.Name}}</td>
+                        <td>{{.HitRatio}}</td>
+                        <td>{{.Hits}}</td>
+                        <td>{{.Misses}}</td>
+                        <td>{{.Unknown}}</td>
+                        <td>{{.AvgHit}}</td>
+                        <td>{{.AvgMiss}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+            {{end}}
+        </section>
+        {{end}}
+
+        {{if .Redirects}}
+        <section>
+            <h2>Redirects <span class="badge">{{.Redirects.RedirectedRate}} REDIRECTED</span></h2>
+            <p class="sub">{{.Redirects.Redirected}} requests redirected, {{.Redirects.AvgChainLength}} hops on average (max {{.Redirects.MaxChainLength}})</p>
+            {{if .Redirects.HopLatencies}}
+            <table>
+                <thead>
+                    <tr><th>Hop Latency</th><th>Value</th></tr>
+                </thead>
+                <tbody>
+                    {{range .Redirects.HopLatencies}}
+                    <tr><td>{{.Percentile}}</td><td>{{.Value}}</td></tr>
+                    {{end}}
+                </tbody>
+            </table>
+            {{end}}
+        </section>
+        {{end}}
+
+        {{if .ServerTiming}}
+        <section>
+            <h2>Server-Timing</h2>
+            <table>
+                <thead>
+                    <tr>
+                        <th>Metric</th>
+                        <th>Count</th>
+                        <th>Avg</th>
+                        {{range .ServerTiming.Percentiles}}<th>{{.}}</th>{{end}}
+                        <th>Max</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .ServerTiming.Metrics}}
+                    <tr>
+                        <td>{{.Name}}{{if .Description}} <span class="sub">{{.Description}}</span>{{end}}</td>
+                        <td>{{.Count}}</td>
+                        <td>{{.Average}}</td>
+                        {{range .Percentiles}}<td>{{.}}</td>{{end}}
+                        <td>{{.Max}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </section>
+        {{end}}
+
+        {{if .Thresholds}}
+        <section>
+            <h2>Thresholds {{if .Thresholds.Passed}}<span class="badge success">PASSED</span>{{else}}<span class="badge error">FAILED ({{.Thresholds.Failed}})</span>{{end}}</h2>
+            <table>
+                <thead>
+                    <tr>
+                        <th>Threshold</th>
+                        <th>Expected</th>
+                        <th>Actual</th>
+                        <th>Result</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Thresholds.Results}}
+                    <tr>
+                        <td>{{.Name}}</td>
+                        <td>{{.Expected}}</td>
+                        <td>{{.Actual}}</td>
+                        <td class="{{if .Passed}}success{{else}}error{{end}}">{{if .Passed}}✓ PASS{{else}}✗ FAIL{{end}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </section>
+        {{end}}
+        
         {{if .Errors}}
         <section>
             <h2>Errors</h2>
@@ -592,6 +996,12 @@ const htmlTemplate = `<!DOCTYPE html>
                 {{range .Errors}}
                 <div class="error-item">
                     <strong>{{.Count}}x</strong> {{.Message}}
+                    {{range .Samples}}
+                    <div class="error-sample">
+                        {{if .Status}}HTTP {{.Status}} {{end}}{{.URL}}
+                        {{if .Body}}<pre>{{.Body}}</pre>{{end}}
+                    </div>
+                    {{end}}
                 </div>
                 {{end}}
             </div>
@@ -601,6 +1011,7 @@ const htmlTemplate = `<!DOCTYPE html>
         <section>
             <h2>Configuration</h2>
             <div class="config-grid">
+                {{if not .Converted}}
                 <div class="config-item">
                     <label>URLs</label>
                     <span>{{.Config.URLs}}</span>
@@ -609,6 +1020,7 @@ const htmlTemplate = `<!DOCTYPE html>
                     <label>Concurrent Users</label>
                     <span>{{.Config.ConcurrentUsers}}</span>
                 </div>
+                {{end}}
                 {{if .Config.Duration}}
                 <div class="config-item">
                     <label>Duration</label>
@@ -621,6 +1033,7 @@ const htmlTemplate = `<!DOCTYPE html>
                     <span>{{.Config.RateLimit}} req/s</span>
                 </div>
                 {{end}}
+                {{if not .Converted}}
                 <div class="config-item">
                     <label>HTTP/2</label>
                     <span>{{if .Config.HTTP2}}Enabled{{else}}Disabled{{end}}</span>
@@ -629,6 +1042,7 @@ const htmlTemplate = `<!DOCTYPE html>
                     <label>Keep-Alive</label>
                     <span>{{if .Config.KeepAlive}}Enabled{{else}}Disabled{{end}}</span>
                 </div>
+                {{end}}
                 <div class="config-item">
                     <label>Throughput</label>
                     <span>{{printf "%.2f" .Throughput}} MB/s</span>
@@ -14,18 +14,43 @@ import (
 
 // Result represents the JSON output format for benchmark results
 type Result struct {
-	Name           string              `json:"name,omitempty"`
-	Timestamp      string              `json:"timestamp"`
-	Duration       float64             `json:"duration_seconds"`
-	TotalRequests  int64               `json:"total_requests"`
-	SuccessCount   int64               `json:"success_count"`
-	FailureCount   int64               `json:"failure_count"`
-	RequestsPerSec RequestsPerSecStats `json:"requests_per_second"`
-	Latency        LatencyStats        `json:"latency"`
-	HTTPCodes      HTTPCodeStats       `json:"http_codes"`
-	Throughput     ThroughputStats     `json:"throughput"`
-	Errors         map[string]int      `json:"errors,omitempty"`
-	Requests       []RequestResult     `json:"requests,omitempty"`
+	Name           string               `json:"name,omitempty"`
+	Timestamp      string               `json:"timestamp"`
+	Metadata       map[string]string    `json:"metadata,omitempty"` // Run metadata such as the git SHA, environment or build
+	Tags           []string             `json:"tags,omitempty"`
+	Duration       float64              `json:"duration_seconds"`
+	TotalRequests  int64                `json:"total_requests"`
+	SuccessCount   int64                `json:"success_count"`
+	FailureCount   int64                `json:"failure_count"`
+	CancelledCount int64                `json:"cancelled_count,omitempty"`
+	BodyMismatches int64                `json:"body_mismatches,omitempty"`
+	RequestsPerSec RequestsPerSecStats  `json:"requests_per_second"`
+	Latency        LatencyStats         `json:"latency"`
+	HTTPCodes      HTTPCodeStats        `json:"http_codes"`
+	Throughput     ThroughputStats      `json:"throughput"`
+	Errors         map[string]int       `json:"errors,omitempty"`
+	Requests       []RequestResult      `json:"requests,omitempty"`
+	Transactions   []TransactionResult  `json:"transactions,omitempty"`
+	Addresses      []AddressResult      `json:"addresses,omitempty"`
+	Scenarios      []ScenarioResult     `json:"scenarios,omitempty"`
+	Polls          []PollResult         `json:"polls,omitempty"`
+	Workers        []WorkerResult       `json:"workers,omitempty"`
+	Connections    []ConnectionResult   `json:"connections,omitempty"`
+	AddressFamily  *AddressFamilies     `json:"address_families,omitempty"`
+	ConnectionPool *ConnectionPool      `json:"connection_pool,omitempty"`
+	Thresholds     *ThresholdSummary    `json:"thresholds,omitempty"`
+	SLO            *SLOSummary          `json:"slo,omitempty"`
+	Cache          *CacheResult         `json:"cache,omitempty"`
+	Redirects      *RedirectResult      `json:"redirects,omitempty"`
+	ServerTiming   []ServerTimingResult `json:"server_timing,omitempty"`
+	SampleLimit    int                  `json:"latency_sample_limit,omitempty"` // Latency samples kept per series after downsampling for the memory budget
+
+	// ErrorSamples holds example failed requests per error message
+	ErrorSamples map[string][]benchmark.ErrorSample `json:"error_samples,omitempty"`
+
+	// Raw holds the counters and latency histograms when written with
+	// --mergeable, so the merge subcommand can combine several results
+	Raw *benchmark.StatsSnapshot `json:"raw_stats,omitempty"`
 }
 
 // RequestsPerSecStats contains request rate statistics
@@ -42,6 +67,117 @@ type LatencyStats struct {
 	Min         string            `json:"min"`
 	Max         string            `json:"max"`
 	Percentiles map[string]string `json:"percentiles"`
+	Success     *OutcomeLatency   `json:"success,omitempty"` // Successful requests only
+	Failure     *OutcomeLatency   `json:"failure,omitempty"` // Failed requests only
+	TTFB        *OutcomeLatency   `json:"ttfb,omitempty"`    // Time to first byte (response headers received)
+}
+
+// OutcomeLatency contains the latency statistics of successful or of failed
+// requests, or their times to first byte
+type OutcomeLatency struct {
+	Count       int64             `json:"count"`
+	Average     string            `json:"average"`
+	Max         string            `json:"max"`
+	Percentiles map[string]string `json:"percentiles"`
+}
+
+// CacheResult contains the cache hit ratio and the latencies of hits and misses
+type CacheResult struct {
+	Hits      int64                 `json:"hits"`
+	Misses    int64                 `json:"misses"`
+	Unknown   int64                 `json:"unknown"` // Responses without a recognizable cache header
+	HitRatio  float64               `json:"hit_ratio"`
+	Hit       *OutcomeLatency       `json:"hit_latency,omitempty"`
+	Miss      *OutcomeLatency       `json:"miss_latency,omitempty"`
+	Endpoints []CacheEndpointResult `json:"endpoints,omitempty"`
+}
+
+// CacheEndpointResult contains the cache outcomes of one request or scenario step
+type CacheEndpointResult struct {
+	Name           string  `json:"name"`
+	Hits           int64   `json:"hits"`
+	Misses         int64   `json:"misses"`
+	Unknown        int64   `json:"unknown"`
+	HitRatio       float64 `json:"hit_ratio"`
+	AvgHitLatency  string  `json:"avg_hit_latency,omitempty"`
+	AvgMissLatency string  `json:"avg_miss_latency,omitempty"`
+}
+
+// RedirectResult contains how many requests were redirected and the latency of redirect hops
+type RedirectResult struct {
+	Redirected     int64           `json:"redirected_requests"`
+	RedirectedRate float64         `json:"redirected_rate"` // Share of the responses that were redirected
+	Hops           int64           `json:"hops"`
+	AvgChainLength float64         `json:"avg_chain_length"` // Hops per redirected request
+	MaxChainLength int64           `json:"max_chain_length"`
+	HopLatency     *OutcomeLatency `json:"hop_latency,omitempty"`
+}
+
+// ToRedirectResult converts a redirect report, or returns nil without one
+func ToRedirectResult(report *benchmark.RedirectReport, percentiles []float64) *RedirectResult {
+	if report == nil {
+		return nil
+	}
+	return &RedirectResult{
+		Redirected:     report.Redirected,
+		RedirectedRate: report.RedirectedRate,
+		Hops:           report.Hops,
+		AvgChainLength: report.AvgHops,
+		MaxChainLength: report.MaxHops,
+		HopLatency:     ToOutcomeLatency(report.HopLatency, percentiles),
+	}
+}
+
+// ServerTimingResult contains the durations a server reported for one Server-Timing metric
+type ServerTimingResult struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Latency     *OutcomeLatency `json:"latency"`
+}
+
+// ToServerTimingResults converts the Server-Timing metrics
+func ToServerTimingResults(metrics []benchmark.ServerTimingMetric, percentiles []float64) []ServerTimingResult {
+	var results []ServerTimingResult
+	for _, metric := range metrics {
+		results = append(results, ServerTimingResult{
+			Name:        metric.Name,
+			Description: metric.Description,
+			Latency:     ToOutcomeLatency(metric.Latency, percentiles),
+		})
+	}
+	return results
+}
+
+// ToCacheResult converts a cache report, or returns nil without one
+func ToCacheResult(report *benchmark.CacheReport, percentiles []float64) *CacheResult {
+	if report == nil {
+		return nil
+	}
+	result := &CacheResult{
+		Hits:     report.Hits,
+		Misses:   report.Misses,
+		Unknown:  report.Unknown,
+		HitRatio: report.HitRatio,
+		Hit:      ToOutcomeLatency(report.Hit, percentiles),
+		Miss:     ToOutcomeLatency(report.Miss, percentiles),
+	}
+	for _, endpoint := range report.Endpoints {
+		er := CacheEndpointResult{
+			Name:     endpoint.Name,
+			Hits:     endpoint.Hits,
+			Misses:   endpoint.Misses,
+			Unknown:  endpoint.Unknown,
+			HitRatio: endpoint.HitRatio(),
+		}
+		if endpoint.Hits > 0 {
+			er.AvgHitLatency = FormatLatency(endpoint.AvgHitUs())
+		}
+		if endpoint.Misses > 0 {
+			er.AvgMissLatency = FormatLatency(endpoint.AvgMissUs())
+		}
+		result.Endpoints = append(result.Endpoints, er)
+	}
+	return result
 }
 
 // HTTPCodeStats contains HTTP status code counts
@@ -72,27 +208,190 @@ type RequestResult struct {
 	Errors       map[string]int `json:"errors,omitempty"`
 }
 
+// TransactionResult contains statistics for a scenario transaction (group of steps)
+type TransactionResult struct {
+	Name         string            `json:"name"`
+	Count        int64             `json:"count"`
+	SuccessCount int64             `json:"success_count"`
+	FailureCount int64             `json:"failure_count"`
+	AvgDuration  string            `json:"avg_duration"`
+	Percentiles  map[string]string `json:"percentiles"`
+}
+
+// AddressResult contains the statistics of the requests sent to one server IP
+type AddressResult struct {
+	Address      string            `json:"address"`
+	RequestCount int64             `json:"request_count"`
+	SuccessCount int64             `json:"success_count"`
+	FailureCount int64             `json:"failure_count"`
+	AvgLatency   string            `json:"avg_latency"`
+	Percentiles  map[string]string `json:"percentiles"`
+}
+
+// ScenarioResult contains iteration statistics for one of several weighted scenarios
+type ScenarioResult struct {
+	Name         string `json:"name"`
+	Iterations   int64  `json:"iterations"`
+	SuccessCount int64  `json:"success_count"`
+	FailureCount int64  `json:"failure_count"`
+	AvgDuration  string `json:"avg_duration"`
+}
+
+// WorkerResult contains the statistics of one worker (virtual user)
+type WorkerResult struct {
+	Worker       int    `json:"worker"`
+	RequestCount int64  `json:"request_count"`
+	FailureCount int64  `json:"failure_count"`
+	AvgLatency   string `json:"avg_latency"`
+	MaxLatency   string `json:"max_latency"`
+}
+
+// ConnectionResult contains the streams sent over one pooled HTTP/2 connection
+type ConnectionResult struct {
+	Connection int   `json:"connection"`
+	Streams    int64 `json:"streams"`
+	MaxStreams int64 `json:"max_concurrent_streams"`
+}
+
+// AddressFamilies contains the connections opened over each IP version
+type AddressFamilies struct {
+	IPv4 int64 `json:"ipv4"`
+	IPv6 int64 `json:"ipv6"`
+}
+
+// ConnectionPool contains the connections opened and closed during the run
+type ConnectionPool struct {
+	Opened                int64   `json:"opened"`
+	Closed                int64   `json:"closed"`
+	RequestsPerConnection float64 `json:"requests_per_connection"`
+}
+
+// PollResult contains total wait statistics for a poll step
+type PollResult struct {
+	Name           string            `json:"name"`
+	Count          int64             `json:"count"`
+	CompletedCount int64             `json:"completed_count"`
+	FailureCount   int64             `json:"failure_count"`
+	AvgWait        string            `json:"avg_wait"`
+	Percentiles    map[string]string `json:"percentiles"`
+}
+
+// ThresholdSummary contains the threshold verdict and every individual check
+type ThresholdSummary struct {
+	Passed  bool             `json:"passed"`
+	Failed  int              `json:"failed_count"`
+	Results []ThresholdCheck `json:"results"`
+}
+
+// ThresholdCheck contains the outcome of a single threshold
+type ThresholdCheck struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// SLOSummary contains the service level objective and the run's error budget usage
+type SLOSummary struct {
+	Target          float64 `json:"target"`
+	Latency         string  `json:"latency_objective,omitempty"`
+	Period          string  `json:"period"`
+	GoodRequests    int64   `json:"good_requests"`
+	BadRequests     int64   `json:"bad_requests"`
+	Compliance      float64 `json:"compliance"`
+	BurnRate        float64 `json:"burn_rate"`
+	BudgetConsumed  float64 `json:"budget_consumed"`
+	BudgetRemaining float64 `json:"budget_remaining"`
+}
+
+// ToSLOSummary converts an SLO report for JSON output (nil when no SLO is configured)
+func ToSLOSummary(report *benchmark.SLOReport) *SLOSummary {
+	if report == nil {
+		return nil
+	}
+	summary := &SLOSummary{
+		Target:          report.Target,
+		Period:          FormatPeriod(report.Period),
+		GoodRequests:    report.GoodRequests,
+		BadRequests:     report.BadRequests,
+		Compliance:      report.Compliance,
+		BurnRate:        report.BurnRate,
+		BudgetConsumed:  report.BudgetConsumed,
+		BudgetRemaining: report.BudgetRemaining,
+	}
+	if report.Latency > 0 {
+		summary.Latency = FormatLatency(float64(report.Latency))
+	}
+	return summary
+}
+
+// ToThresholdSummary converts threshold results for JSON output (nil when there are none)
+func ToThresholdSummary(thresholds *benchmark.ThresholdResults) *ThresholdSummary {
+	if thresholds == nil || len(thresholds.Results) == 0 {
+		return nil
+	}
+	summary := &ThresholdSummary{
+		Passed:  thresholds.Passed,
+		Failed:  thresholds.FailedCount(),
+		Results: make([]ThresholdCheck, 0, len(thresholds.Results)),
+	}
+	for _, result := range thresholds.Results {
+		summary.Results = append(summary.Results, ThresholdCheck{
+			Name:     result.Name,
+			Passed:   result.Passed,
+			Expected: result.Expected,
+			Actual:   result.Actual,
+		})
+	}
+	return summary
+}
+
+// ToOutcomeLatency converts the latencies of one request outcome, or returns
+// nil when no request had that outcome
+func ToOutcomeLatency(summary benchmark.LatencySummary, percentiles []float64) *OutcomeLatency {
+	if summary.Count == 0 {
+		return nil
+	}
+	latency := &OutcomeLatency{
+		Count:       summary.Count,
+		Average:     FormatLatency(summary.AvgUs),
+		Max:         FormatLatency(float64(summary.MaxUs)),
+		Percentiles: make(map[string]string, len(percentiles)),
+	}
+	for i, p := range percentiles {
+		latency.Percentiles["p"+FormatPercentile(p)] = FormatLatency(float64(summary.Percentiles[i]))
+	}
+	return latency
+}
+
 // ToJSONResult converts Stats to Result for JSON output
 func ToJSONResult(stats *benchmark.Stats, cfg *config.Config) *Result {
 	// Build percentiles map using custom percentiles from config
 	percentiles := cfg.Settings.Percentiles
 	if len(percentiles) == 0 {
-		percentiles = []int{50, 75, 90, 99}
+		percentiles = []float64{50, 75, 90, 99}
 	}
 
 	percentilesMap := make(map[string]string)
 	for _, p := range percentiles {
-		key := fmt.Sprintf("p%d", p)
+		key := "p" + FormatPercentile(p)
 		percentilesMap[key] = FormatLatency(float64(stats.GetLatencyPercentile(p)))
 	}
 
+	success, failure := stats.LatencyByOutcome(percentiles)
+	ttfb := stats.TTFB(percentiles)
+
 	result := &Result{
-		Name:          cfg.Name,
-		Timestamp:     time.Now().UTC().Format(time.RFC3339),
-		Duration:      stats.TotalDuration,
-		TotalRequests: stats.TotalRequests,
-		SuccessCount:  stats.SuccessCount,
-		FailureCount:  stats.FailureCount,
+		Name:           cfg.Name,
+		Timestamp:      time.Now().UTC().Format(time.RFC3339),
+		Metadata:       cfg.RunMetadata(),
+		Tags:           cfg.Tags,
+		Duration:       stats.TotalDuration,
+		TotalRequests:  stats.TotalRequests,
+		SuccessCount:   stats.SuccessCount,
+		FailureCount:   stats.FailureCount,
+		CancelledCount: stats.CancelledCount,
+		BodyMismatches: stats.BodyMismatches,
 		RequestsPerSec: RequestsPerSecStats{
 			Average: stats.RequestsPerSecond,
 			StdDev:  stats.RequestRateStdDev(),
@@ -104,6 +403,9 @@ func ToJSONResult(stats *benchmark.Stats, cfg *config.Config) *Result {
 			Min:         FormatLatency(float64(stats.MinResponseTime())),
 			Max:         FormatLatency(float64(stats.MaxResponseTime())),
 			Percentiles: percentilesMap,
+			Success:     ToOutcomeLatency(success, percentiles),
+			Failure:     ToOutcomeLatency(failure, percentiles),
+			TTFB:        ToOutcomeLatency(ttfb, percentiles),
 		},
 		HTTPCodes: HTTPCodeStats{
 			Code1xx: stats.Http1xxCount,
@@ -117,7 +419,42 @@ func ToJSONResult(stats *benchmark.Stats, cfg *config.Config) *Result {
 			TotalBytes: stats.TotalBytes,
 			MBPerSec:   stats.ThroughputMBps(),
 		},
-		Errors: stats.GetErrors(),
+		Errors:       stats.GetErrors(),
+		ErrorSamples: stats.GetErrorSamples(),
+		SLO:          ToSLOSummary(stats.SLOReport()),
+		Cache:        ToCacheResult(stats.CacheReport(percentiles), percentiles),
+		Redirects:    ToRedirectResult(stats.RedirectReport(percentiles), percentiles),
+		ServerTiming: ToServerTimingResults(stats.ServerTiming(percentiles), percentiles),
+		SampleLimit:  stats.SampleLimit(),
+	}
+
+	for _, ws := range stats.Workers() {
+		result.Workers = append(result.Workers, WorkerResult{
+			Worker:       ws.ID + 1,
+			RequestCount: ws.Requests,
+			FailureCount: ws.Failures,
+			AvgLatency:   FormatLatency(ws.AvgLatencyUs),
+			MaxLatency:   FormatLatency(float64(ws.MaxLatencyUs)),
+		})
+	}
+
+	for _, cs := range stats.Connections() {
+		result.Connections = append(result.Connections, ConnectionResult{
+			Connection: cs.ID + 1,
+			Streams:    cs.Requests,
+			MaxStreams: cs.MaxStreams,
+		})
+	}
+
+	if churn := stats.ConnectionChurn(); churn.Opened > 0 {
+		result.ConnectionPool = &ConnectionPool{
+			Opened:                churn.Opened,
+			Closed:                churn.Closed,
+			RequestsPerConnection: float64(stats.TotalRequests) / float64(churn.Opened),
+		}
+	}
+	if families := stats.AddressFamilies(); families.IPv4+families.IPv6 > 0 {
+		result.AddressFamily = &AddressFamilies{IPv4: families.IPv4, IPv6: families.IPv6}
 	}
 
 	// Add per-request stats
@@ -146,14 +483,85 @@ func ToJSONResult(stats *benchmark.Stats, cfg *config.Config) *Result {
 			Errors:       endpointErrors,
 		})
 	}
+	for _, ts := range stats.TransactionStats {
+		avgDuration := float64(0)
+		if ts.RequestCount > 0 {
+			avgDuration = float64(ts.TotalLatency) / float64(ts.RequestCount)
+		}
+		txnPercentiles := make(map[string]string)
+		for _, p := range percentiles {
+			txnPercentiles["p"+FormatPercentile(p)] = FormatLatency(float64(ts.LatencyPercentile(p)))
+		}
+		result.Transactions = append(result.Transactions, TransactionResult{
+			Name:         ts.Name,
+			Count:        ts.RequestCount,
+			SuccessCount: ts.SuccessCount,
+			FailureCount: ts.FailureCount,
+			AvgDuration:  FormatLatency(avgDuration),
+			Percentiles:  txnPercentiles,
+		})
+	}
+	for _, ss := range stats.ScenarioStats {
+		avgDuration := float64(0)
+		if ss.RequestCount > 0 {
+			avgDuration = float64(ss.TotalLatency) / float64(ss.RequestCount)
+		}
+		result.Scenarios = append(result.Scenarios, ScenarioResult{
+			Name:         ss.Name,
+			Iterations:   ss.RequestCount,
+			SuccessCount: ss.SuccessCount,
+			FailureCount: ss.FailureCount,
+			AvgDuration:  FormatLatency(avgDuration),
+		})
+	}
+	for _, as := range stats.AddressStats {
+		avgLatency := float64(0)
+		if as.RequestCount > 0 {
+			avgLatency = float64(as.TotalLatency) / float64(as.RequestCount)
+		}
+		addressPercentiles := make(map[string]string)
+		for _, p := range percentiles {
+			addressPercentiles["p"+FormatPercentile(p)] = FormatLatency(float64(as.LatencyPercentile(p)))
+		}
+		result.Addresses = append(result.Addresses, AddressResult{
+			Address:      as.Name,
+			RequestCount: as.RequestCount,
+			SuccessCount: as.SuccessCount,
+			FailureCount: as.FailureCount,
+			AvgLatency:   FormatLatency(avgLatency),
+			Percentiles:  addressPercentiles,
+		})
+	}
+	for _, ps := range stats.PollStats {
+		avgWait := float64(0)
+		if ps.RequestCount > 0 {
+			avgWait = float64(ps.TotalLatency) / float64(ps.RequestCount)
+		}
+		pollPercentiles := make(map[string]string)
+		for _, p := range percentiles {
+			pollPercentiles["p"+FormatPercentile(p)] = FormatLatency(float64(ps.LatencyPercentile(p)))
+		}
+		result.Polls = append(result.Polls, PollResult{
+			Name:           ps.Name,
+			Count:          ps.RequestCount,
+			CompletedCount: ps.SuccessCount,
+			FailureCount:   ps.FailureCount,
+			AvgWait:        FormatLatency(avgWait),
+			Percentiles:    pollPercentiles,
+		})
+	}
 	stats.Unlock()
 
 	return result
 }
 
 // WriteJSON outputs results in JSON format
-func WriteJSON(stats *benchmark.Stats, cfg *config.Config) error {
+func WriteJSON(stats *benchmark.Stats, cfg *config.Config, thresholds *benchmark.ThresholdResults) error {
 	result := ToJSONResult(stats, cfg)
+	result.Thresholds = ToThresholdSummary(thresholds)
+	if cfg.Output.Mergeable {
+		result.Raw = stats.Snapshot()
+	}
 
 	var output io.Writer = os.Stdout
 	if cfg.Output.File != "" {
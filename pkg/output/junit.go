@@ -0,0 +1,137 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// junitSuites is the root of a JUnit XML report
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+// junitSuite is one benchmark run
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+// junitProperty is a headline metric of the run
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// junitCase is one check of the run
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+// junitFailure explains why a check failed
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit renders a result as a JUnit XML report for CI systems. Every
+// threshold and the SLO become a test case; without thresholds, every
+// endpoint is a test case that fails when any of its requests failed.
+func WriteJUnit(w io.Writer, result *Result) error {
+	name := result.Name
+	if name == "" {
+		name = "benchmark"
+	}
+	suite := junitSuite{
+		Name:      name,
+		Time:      strconv.FormatFloat(result.Duration, 'f', 3, 64),
+		Timestamp: result.Timestamp,
+		Properties: []junitProperty{
+			{"total_requests", strconv.FormatInt(result.TotalRequests, 10)},
+			{"failure_count", strconv.FormatInt(result.FailureCount, 10)},
+			{"requests_per_second", strconv.FormatFloat(result.RequestsPerSec.Average, 'f', 2, 64)},
+			{"latency_average", result.Latency.Average},
+		},
+	}
+	if len(result.Tags) > 0 {
+		suite.Properties = append(suite.Properties, junitProperty{"tags", strings.Join(result.Tags, ",")})
+	}
+	for _, key := range config.SortedKeys(result.Metadata) {
+		suite.Properties = append(suite.Properties, junitProperty{"meta_" + key, result.Metadata[key]})
+	}
+	for _, p := range resultPercentiles(result.Latency.Percentiles) {
+		key := "p" + FormatPercentile(p)
+		suite.Properties = append(suite.Properties, junitProperty{"latency_" + key, result.Latency.Percentiles[key]})
+	}
+	if ttfb := result.Latency.TTFB; ttfb != nil {
+		suite.Properties = append(suite.Properties, junitProperty{"ttfb_average", ttfb.Average})
+		for _, p := range resultPercentiles(ttfb.Percentiles) {
+			key := "p" + FormatPercentile(p)
+			suite.Properties = append(suite.Properties, junitProperty{"ttfb_" + key, ttfb.Percentiles[key]})
+		}
+	}
+
+	addCase := func(caseName, class, failure string) {
+		tc := junitCase{Name: caseName, ClassName: name + "." + class, Time: suite.Time}
+		if failure != "" {
+			tc.Failure = &junitFailure{Message: failure}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+	}
+
+	if result.Thresholds != nil {
+		for _, check := range result.Thresholds.Results {
+			failure := ""
+			if !check.Passed {
+				failure = fmt.Sprintf("expected %s, got %s", check.Expected, check.Actual)
+			}
+			addCase(check.Name, "thresholds", failure)
+		}
+	} else if len(result.Requests) > 0 {
+		for _, rs := range result.Requests {
+			failure := ""
+			if rs.FailureCount > 0 {
+				failure = fmt.Sprintf("%d of %d requests failed", rs.FailureCount, rs.RequestCount)
+			}
+			addCase(rs.Name, "requests", failure)
+		}
+	} else {
+		failure := ""
+		if result.FailureCount > 0 {
+			failure = fmt.Sprintf("%d of %d requests failed", result.FailureCount, result.TotalRequests)
+		}
+		addCase("requests", "requests", failure)
+	}
+	if slo := result.SLO; slo != nil {
+		failure := ""
+		if slo.BurnRate > 1 {
+			failure = fmt.Sprintf("error budget burning at %.2fx (compliance %.3f%%)", slo.BurnRate, slo.Compliance*100)
+		}
+		addCase(resultSLOObjective(slo), "slo", failure)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return fmt.Errorf("error encoding JUnit XML: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,182 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// WriteMarkdown renders a result as a Markdown report, e.g. for pull request
+// comments or wiki pages
+func WriteMarkdown(w io.Writer, result *Result) error {
+	var b strings.Builder
+	title := result.Name
+	if title == "" {
+		title = "Benchmark Report"
+	}
+	fmt.Fprintf(&b, "# %s\n\n", markdownCell(title))
+	fmt.Fprintf(&b, "Run at %s for %.2fs.\n\n", result.Timestamp, result.Duration)
+	if len(result.Tags) > 0 {
+		fmt.Fprintf(&b, "Tags: %s\n\n", markdownCell(strings.Join(result.Tags, ", ")))
+	}
+	if len(result.Metadata) > 0 {
+		for _, key := range config.SortedKeys(result.Metadata) {
+			fmt.Fprintf(&b, "- **%s**: %s\n", markdownCell(key), markdownCell(result.Metadata[key]))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("| Metric | Value |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Requests | %d (%d successful, %d failed", result.TotalRequests, result.SuccessCount, result.FailureCount)
+	if result.CancelledCount > 0 {
+		fmt.Fprintf(&b, ", %d cancelled", result.CancelledCount)
+	}
+	b.WriteString(") |\n")
+	if result.BodyMismatches > 0 {
+		fmt.Fprintf(&b, "| Body mismatches | %d (wrong size or checksum) |\n", result.BodyMismatches)
+	}
+	fmt.Fprintf(&b, "| Success rate | %.2f%% |\n", resultSuccessRate(result))
+	fmt.Fprintf(&b, "| Requests/sec | %.2f (max %.2f) |\n", result.RequestsPerSec.Average, result.RequestsPerSec.Max)
+	fmt.Fprintf(&b, "| Latency | avg %s, stdev %s, min %s, max %s |\n", result.Latency.Average, result.Latency.StdDev, result.Latency.Min, result.Latency.Max)
+	if ttfb := result.Latency.TTFB; ttfb != nil {
+		fmt.Fprintf(&b, "| Time to first byte | avg %s, max %s |\n", ttfb.Average, ttfb.Max)
+	}
+	fmt.Fprintf(&b, "| Throughput | %.2f MB/s (%d bytes) |\n", result.Throughput.MBPerSec, result.Throughput.TotalBytes)
+
+	split := result.Latency.Failure != nil
+	ttfb := result.Latency.TTFB
+	b.WriteString("\n## Latency Percentiles\n\n")
+	if split {
+		b.WriteString("| Percentile | All | Success | Failure |")
+	} else {
+		b.WriteString("| Percentile | Latency |")
+	}
+	if ttfb != nil {
+		b.WriteString(" TTFB |")
+	}
+	b.WriteString("\n|---|---|")
+	if split {
+		b.WriteString("---|---|")
+	}
+	if ttfb != nil {
+		b.WriteString("---|")
+	}
+	b.WriteString("\n")
+	for _, p := range resultPercentiles(result.Latency.Percentiles) {
+		key := "p" + FormatPercentile(p)
+		fmt.Fprintf(&b, "| p%s | %s |", FormatPercentile(p), result.Latency.Percentiles[key])
+		if split {
+			fmt.Fprintf(&b, " %s | %s |", outcomePercentile(result.Latency.Success, key), outcomePercentile(result.Latency.Failure, key))
+		}
+		if ttfb != nil {
+			fmt.Fprintf(&b, " %s |", outcomePercentile(ttfb, key))
+		}
+		b.WriteString("\n")
+	}
+
+	codes := result.HTTPCodes
+	b.WriteString("\n## HTTP Status Codes\n\n| 1xx | 2xx | 3xx | 4xx | 5xx | Other |\n|---|---|---|---|---|---|\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %d | %d | %d |\n", codes.Code1xx, codes.Code2xx, codes.Code3xx, codes.Code4xx, codes.Code5xx, codes.Other)
+
+	if len(result.Requests) > 0 {
+		b.WriteString("\n## Requests\n\n| Name | Method | URL | Requests | Success | Failed | Avg Latency |\n|---|---|---|---|---|---|---|\n")
+		for _, rs := range result.Requests {
+			fmt.Fprintf(&b, "| %s | %s | %s | %d | %d | %d | %s |\n", markdownCell(rs.Name), rs.Method, markdownCell(rs.URL),
+				rs.RequestCount, rs.SuccessCount, rs.FailureCount, rs.AvgLatency)
+		}
+	}
+
+	if cache := cacheHTMLData(result.Cache); cache != nil {
+		fmt.Fprintf(&b, "\n## Cache\n\n%s hit ratio (%d hits, %d misses, %d unknown)\n\n| Latency | Hit | Miss |\n|---|---|---|\n",
+			cache.HitRatio, cache.Hits, cache.Misses, cache.Unknown)
+		for _, row := range cache.Latencies {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", row.Label, row.Hit, row.Miss)
+		}
+		if len(cache.Endpoints) > 1 {
+			b.WriteString("\n| Endpoint | Hit Ratio | Hits | Misses | Avg Hit | Avg Miss |\n|---|---|---|---|---|---|\n")
+			for _, endpoint := range cache.Endpoints {
+				fmt.Fprintf(&b, "| %s | %s | %d | %d | %s | %s |\n", markdownCell(endpoint.Name), endpoint.HitRatio,
+					endpoint.Hits, endpoint.Misses, endpoint.AvgHit, endpoint.AvgMiss)
+			}
+		}
+	}
+
+	if redirects := result.Redirects; redirects != nil {
+		fmt.Fprintf(&b, "\n## Redirects\n\n%.1f%% of requests redirected (%d), %.2f hops on average (max %d)\n",
+			redirects.RedirectedRate*100, redirects.Redirected, redirects.AvgChainLength, redirects.MaxChainLength)
+		if hop := redirects.HopLatency; hop != nil {
+			b.WriteString("\n| Hop Latency | Value |\n|---|---|\n")
+			fmt.Fprintf(&b, "| Avg | %s |\n", hop.Average)
+			for _, p := range resultPercentiles(hop.Percentiles) {
+				key := "p" + FormatPercentile(p)
+				fmt.Fprintf(&b, "| %s | %s |\n", key, hop.Percentiles[key])
+			}
+			fmt.Fprintf(&b, "| Max | %s |\n", hop.Max)
+		}
+	}
+
+	if timing := serverTimingHTMLData(result.ServerTiming); timing != nil {
+		b.WriteString("\n## Server-Timing\n\n| Metric | Count | Avg |")
+		for _, label := range timing.Percentiles {
+			fmt.Fprintf(&b, " %s |", label)
+		}
+		b.WriteString(" Max |\n|---|---|---|" + strings.Repeat("---|", len(timing.Percentiles)) + "---|\n")
+		for _, metric := range timing.Metrics {
+			name := markdownCell(metric.Name)
+			if metric.Description != "" {
+				name += " (" + markdownCell(metric.Description) + ")"
+			}
+			fmt.Fprintf(&b, "| %s | %d | %s |", name, metric.Count, metric.Average)
+			for _, value := range metric.Percentiles {
+				fmt.Fprintf(&b, " %s |", value)
+			}
+			fmt.Fprintf(&b, " %s |\n", metric.Max)
+		}
+	}
+
+	if thresholds := result.Thresholds; thresholds != nil {
+		verdict := "PASSED"
+		if !thresholds.Passed {
+			verdict = fmt.Sprintf("FAILED (%d)", thresholds.Failed)
+		}
+		fmt.Fprintf(&b, "\n## Thresholds: %s\n\n| Check | Expected | Actual | Result |\n|---|---|---|---|\n", verdict)
+		for _, check := range thresholds.Results {
+			status := "✓ pass"
+			if !check.Passed {
+				status = "✗ fail"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", markdownCell(check.Name), markdownCell(check.Expected), markdownCell(check.Actual), status)
+		}
+	}
+
+	if slo := result.SLO; slo != nil {
+		b.WriteString("\n## SLO\n\n| Metric | Value |\n|---|---|\n")
+		fmt.Fprintf(&b, "| Objective | %s |\n", resultSLOObjective(slo))
+		fmt.Fprintf(&b, "| Compliance | %.3f%% (%d good, %d bad) |\n", slo.Compliance*100, slo.GoodRequests, slo.BadRequests)
+		fmt.Fprintf(&b, "| Burn rate | %.2fx |\n", slo.BurnRate)
+		fmt.Fprintf(&b, "| Error budget remaining | %.4f%% |\n", slo.BudgetRemaining*100)
+	}
+
+	if len(result.Errors) > 0 {
+		b.WriteString("\n## Errors\n\n| Count | Error |\n|---|---|\n")
+		messages := make([]string, 0, len(result.Errors))
+		for msg := range result.Errors {
+			messages = append(messages, msg)
+		}
+		sort.Slice(messages, func(i, j int) bool { return result.Errors[messages[i]] > result.Errors[messages[j]] })
+		for _, msg := range messages {
+			fmt.Fprintf(&b, "| %d | %s |\n", result.Errors[msg], markdownCell(msg))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(text)
+}
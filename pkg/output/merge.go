@@ -0,0 +1,60 @@
+package output
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// MergeResults combines JSON result files written with --mergeable, e.g. by
+// several load generator hosts, into one set of stats. Counters, latency
+// histograms and per-endpoint stats are summed; the duration is the longest of
+// the runs, since they ran side by side. The returned config carries the name
+// and percentiles of the first result, to report the merged stats like it.
+func MergeResults(filenames []string) (*benchmark.Stats, *config.Config, error) {
+	if len(filenames) == 0 {
+		return nil, nil, fmt.Errorf("no result files to merge")
+	}
+
+	var stats *benchmark.Stats
+	cfg := &config.Config{}
+	for _, filename := range filenames {
+		result, err := LoadResult(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		if result.Raw == nil {
+			return nil, nil, fmt.Errorf("result %s has no raw stats; write it with --mergeable", filename)
+		}
+
+		useHdr := result.Raw.Histogram != nil
+		if stats == nil {
+			stats = benchmark.NewStatsWithOptions(useHdr, false)
+			cfg.Name = result.Name
+			cfg.Metadata = result.Metadata
+			cfg.Tags = result.Tags
+			cfg.Settings.Percentiles = resultPercentiles(result.Latency.Percentiles)
+		} else if useHdr != stats.IsUsingHdr() {
+			return nil, nil, fmt.Errorf("result %s was recorded with a different --no-hdr setting than %s", filename, filenames[0])
+		}
+		stats.Merge(result.Raw)
+	}
+	return stats, cfg, nil
+}
+
+// resultPercentiles returns the percentiles reported in a result's latency
+// map (keys like "p99.9"), in ascending order
+func resultPercentiles(latencies map[string]string) []float64 {
+	var percentiles []float64
+	for key := range latencies {
+		if p, err := strconv.ParseFloat(strings.TrimPrefix(key, "p"), 64); err == nil {
+			percentiles = append(percentiles, p)
+		}
+	}
+	slices.Sort(percentiles)
+	return percentiles
+}
@@ -0,0 +1,63 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+	"github.com/benchmarking_go/pkg/plugin"
+)
+
+// sinkTimeout is how long a sink plugin gets to accept the results
+const sinkTimeout = time.Minute
+
+// WritePlugins hands the results, as in the JSON report, to the sink plugins
+// named in the config. Every sink is tried; their errors are returned together.
+func WritePlugins(stats *benchmark.Stats, cfg *config.Config, thresholds *benchmark.ThresholdResults) error {
+	if len(cfg.Output.Plugins) == 0 {
+		return nil
+	}
+	registry, err := cfg.Plugins()
+	if err != nil {
+		return err
+	}
+
+	result := ToJSONResult(stats, cfg)
+	result.Thresholds = ToThresholdSummary(thresholds)
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	var errs []error
+	for _, name := range cfg.Output.Plugins {
+		if err := writePlugin(registry, name, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writePlugin runs one sink plugin and sends it the results
+func writePlugin(registry plugin.Registry, name string, results json.RawMessage) error {
+	manifest, err := registry.Get(name, plugin.KindSink)
+	if err != nil {
+		return err
+	}
+	client, err := plugin.Start(manifest, nil)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sinkTimeout)
+	defer cancel()
+	_, err = client.Call(ctx, &plugin.Message{Type: plugin.TypeResults, Results: results})
+	if closeErr := client.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
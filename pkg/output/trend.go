@@ -0,0 +1,471 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// TrendFormats are the formats a trend report can be rendered in
+var TrendFormats = []string{"html", "markdown"}
+
+// LoadHistory reads the run summaries of a history directory (or of a
+// history file directly), oldest first
+func LoadHistory(path string) ([]HistoryEntry, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, HistoryFile)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse %s line %d: %w", path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+// SelectTrend returns the last entries of the history, only those of the
+// named benchmark unless name is empty (last <= 0 keeps all)
+func SelectTrend(entries []HistoryEntry, name string, last int) []HistoryEntry {
+	var selected []HistoryEntry
+	for _, entry := range entries {
+		if name == "" || entry.Name == name {
+			selected = append(selected, entry)
+		}
+	}
+	if last > 0 && len(selected) > last {
+		selected = selected[len(selected)-last:]
+	}
+	return selected
+}
+
+// WriteTrend renders how requests/sec and p99 latency developed over the
+// entries, for lightweight tracking across releases
+func WriteTrend(w io.Writer, entries []HistoryEntry, format string) error {
+	if len(entries) == 0 {
+		return fmt.Errorf("no runs in the history")
+	}
+	switch format {
+	case "html":
+		return renderTrendHTML(w, buildTrendReport(entries))
+	case "markdown":
+		return writeTrendMarkdown(w, buildTrendReport(entries))
+	}
+	return fmt.Errorf("unknown format %q (expected %s)", format, strings.Join(TrendFormats, ", "))
+}
+
+// TrendReport represents data for the trend report templates
+type TrendReport struct {
+	Title      string
+	From, To   string // Timestamps of the first and last run
+	Runs       []TrendRun
+	HasLabels  bool // Whether any run has metadata or tags
+	RPSChange  string
+	P99Change  string
+	RPSChart   TrendChart
+	P99Chart   TrendChart
+	LatestRPS  float64
+	LatestP99  string
+	FirstRPS   float64
+	FirstP99   string
+	TotalRuns  int
+	FailedRuns int // Runs whose thresholds failed
+}
+
+// TrendRun is one run of a trend report
+type TrendRun struct {
+	Number    int
+	Timestamp string
+	Name      string
+	Labels    string // Metadata and tags, e.g. "gitSha=abc123, nightly"
+	RPS       float64
+	RPSChange string // Change from the previous run
+	P99       string
+	P99Change string
+	Failures  int64
+	Result    string // "pass", "fail" or "" without thresholds
+}
+
+// TrendChart is an SVG line chart of one metric over the runs
+type TrendChart struct {
+	Width, Height int
+	Left, Right   int    // x of the ends of the axes
+	Top, Bottom   int    // y of the top of the scale and of the x axis
+	Line          string // Points of the polyline
+	Points        []TrendPoint
+	Max           string // Label of the top of the y axis (the bottom is 0)
+}
+
+// TrendPoint is one run in a trend chart
+type TrendPoint struct {
+	X, Y  float64
+	Label string
+}
+
+// buildTrendReport builds the trend report data from history entries
+func buildTrendReport(entries []HistoryEntry) TrendReport {
+	first, latest := entries[0], entries[len(entries)-1]
+	report := TrendReport{
+		Title:     trendTitle(entries),
+		From:      first.Timestamp,
+		To:        latest.Timestamp,
+		RPSChange: formatChange(relativeChange(first.RequestsPerSec, latest.RequestsPerSec)),
+		P99Change: formatChange(relativeChange(float64(first.P99LatencyUs), float64(latest.P99LatencyUs))),
+		LatestRPS: latest.RequestsPerSec,
+		LatestP99: FormatLatency(float64(latest.P99LatencyUs)),
+		FirstRPS:  first.RequestsPerSec,
+		FirstP99:  FormatLatency(float64(first.P99LatencyUs)),
+		TotalRuns: len(entries),
+	}
+
+	rps := make([]float64, len(entries))
+	p99 := make([]float64, len(entries))
+	for i, entry := range entries {
+		run := TrendRun{
+			Number:    i + 1,
+			Timestamp: entry.Timestamp,
+			Name:      entry.Name,
+			Labels:    trendLabels(entry),
+			RPS:       entry.RequestsPerSec,
+			P99:       FormatLatency(float64(entry.P99LatencyUs)),
+			Failures:  entry.FailureCount,
+		}
+		if i > 0 {
+			previous := entries[i-1]
+			run.RPSChange = formatChange(relativeChange(previous.RequestsPerSec, entry.RequestsPerSec))
+			run.P99Change = formatChange(relativeChange(float64(previous.P99LatencyUs), float64(entry.P99LatencyUs)))
+		}
+		if entry.Passed != nil {
+			run.Result = "pass"
+			if !*entry.Passed {
+				run.Result = "fail"
+				report.FailedRuns++
+			}
+		}
+		if run.Labels != "" {
+			report.HasLabels = true
+		}
+		report.Runs = append(report.Runs, run)
+		rps[i] = entry.RequestsPerSec
+		p99[i] = float64(entry.P99LatencyUs)
+	}
+
+	report.RPSChart = trendChart(rps, func(v float64) string { return fmt.Sprintf("%.1f req/s", v) })
+	report.P99Chart = trendChart(p99, FormatLatency)
+	return report
+}
+
+// trendTitle names the report after the benchmark when all runs share a name
+func trendTitle(entries []HistoryEntry) string {
+	name := entries[0].Name
+	for _, entry := range entries[1:] {
+		if entry.Name != name {
+			return "Benchmark Trend"
+		}
+	}
+	if name == "" {
+		return "Benchmark Trend"
+	}
+	return "Benchmark Trend: " + name
+}
+
+// trendLabels formats a run's metadata and tags for the run table
+func trendLabels(entry HistoryEntry) string {
+	var labels []string
+	for _, key := range config.SortedKeys(entry.Metadata) {
+		labels = append(labels, key+"="+entry.Metadata[key])
+	}
+	labels = append(labels, entry.Tags...)
+	return strings.Join(labels, ", ")
+}
+
+// trendChart lays the values out as a line chart with the y axis starting at
+// 0, so small changes don't look dramatic
+func trendChart(values []float64, format func(float64) string) TrendChart {
+	const width, height, padding = 800, 220, 20
+	chart := TrendChart{Width: width, Height: height, Left: padding, Right: width - padding, Top: padding, Bottom: height - padding}
+
+	max := 0.0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	top := max * 1.1
+	if top == 0 {
+		top = 1
+	}
+	chart.Max = format(top)
+
+	var line []string
+	for i, v := range values {
+		x := float64(width) / 2
+		if len(values) > 1 {
+			x = padding + float64(i)*float64(width-2*padding)/float64(len(values)-1)
+		}
+		y := height - padding - v/top*float64(height-2*padding)
+		line = append(line, fmt.Sprintf("%.1f,%.1f", x, y))
+		chart.Points = append(chart.Points, TrendPoint{X: x, Y: y, Label: fmt.Sprintf("Run %d: %s", i+1, format(v))})
+	}
+	chart.Line = strings.Join(line, " ")
+	return chart
+}
+
+// writeTrendMarkdown renders the trend report as Markdown, e.g. for wiki pages
+func writeTrendMarkdown(w io.Writer, report TrendReport) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", markdownCell(report.Title))
+	fmt.Fprintf(&b, "%d runs from %s to %s.\n\n", report.TotalRuns, report.From, report.To)
+
+	b.WriteString("| Metric | First run | Latest run | Change |\n|---|---|---|---|\n")
+	fmt.Fprintf(&b, "| Requests/sec | %.2f | %.2f | %s |\n", report.FirstRPS, report.LatestRPS, report.RPSChange)
+	fmt.Fprintf(&b, "| p99 latency | %s | %s | %s |\n", report.FirstP99, report.LatestP99, report.P99Change)
+	if report.FailedRuns > 0 {
+		fmt.Fprintf(&b, "| Failed runs | %d of %d | | |\n", report.FailedRuns, report.TotalRuns)
+	}
+
+	b.WriteString("\n## Runs\n\n| # | Time | Requests/sec | p99 | Failures | Result |")
+	if report.HasLabels {
+		b.WriteString(" Labels |")
+	}
+	b.WriteString("\n|---|---|---|---|---|---|")
+	if report.HasLabels {
+		b.WriteString("---|")
+	}
+	b.WriteString("\n")
+	for _, run := range report.Runs {
+		rps := strings.TrimSpace(fmt.Sprintf("%.2f %s", run.RPS, run.RPSChange))
+		p99 := strings.TrimSpace(run.P99 + " " + run.P99Change)
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %d | %s |", run.Number, run.Timestamp, rps, p99, run.Failures, run.Result)
+		if report.HasLabels {
+			fmt.Fprintf(&b, " %s |", markdownCell(run.Labels))
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// renderTrendHTML executes the trend template into w
+func renderTrendHTML(w io.Writer, report TrendReport) error {
+	tmpl, err := template.New("trend").Parse(trendTemplate)
+	if err != nil {
+		return fmt.Errorf("error parsing trend template: %w", err)
+	}
+	if err := tmpl.Execute(w, report); err != nil {
+		return fmt.Errorf("error executing trend template: %w", err)
+	}
+	return nil
+}
+
+const trendTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Title}}</title>
+    <style>
+        :root {
+            --bg-primary: #0d1117;
+            --bg-secondary: #161b22;
+            --bg-tertiary: #21262d;
+            --text-primary: #c9d1d9;
+            --text-secondary: #8b949e;
+            --accent: #58a6ff;
+            --success: #3fb950;
+            --error: #f85149;
+            --border: #30363d;
+        }
+
+        * {
+            margin: 0;
+            padding: 0;
+            box-sizing: border-box;
+        }
+
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', 'Noto Sans', Helvetica, Arial, sans-serif;
+            background: var(--bg-primary);
+            color: var(--text-primary);
+            line-height: 1.6;
+            padding: 2rem;
+        }
+
+        .container {
+            max-width: 1200px;
+            margin: 0 auto;
+        }
+
+        header {
+            text-align: center;
+            margin-bottom: 2rem;
+        }
+
+        h1 {
+            font-size: 2rem;
+            font-weight: 600;
+            margin-bottom: 0.5rem;
+        }
+
+        .timestamp {
+            color: var(--text-secondary);
+            font-size: 0.9rem;
+        }
+
+        .section {
+            background: var(--bg-secondary);
+            border: 1px solid var(--border);
+            border-radius: 8px;
+            padding: 1.5rem;
+            margin-bottom: 1.5rem;
+        }
+
+        .section h2 {
+            font-size: 1.1rem;
+            margin-bottom: 1rem;
+        }
+
+        .section h2 .change {
+            color: var(--text-secondary);
+            font-weight: normal;
+            font-size: 0.9rem;
+        }
+
+        svg {
+            width: 100%;
+            height: auto;
+        }
+
+        svg .axis {
+            stroke: var(--border);
+        }
+
+        svg .line {
+            fill: none;
+            stroke: var(--accent);
+            stroke-width: 2;
+        }
+
+        svg .point {
+            fill: var(--accent);
+        }
+
+        svg text {
+            fill: var(--text-secondary);
+            font-size: 12px;
+        }
+
+        table {
+            width: 100%;
+            border-collapse: collapse;
+        }
+
+        th, td {
+            padding: 0.5rem;
+            text-align: left;
+            border-bottom: 1px solid var(--border);
+        }
+
+        th {
+            color: var(--text-secondary);
+            font-weight: 500;
+        }
+
+        .sub {
+            color: var(--text-secondary);
+            font-size: 0.85rem;
+        }
+
+        .pass {
+            color: var(--success);
+        }
+
+        .fail {
+            color: var(--error);
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <header>
+            <h1>{{.Title}}</h1>
+            <p class="timestamp">{{.TotalRuns}} runs from {{.From}} to {{.To}}{{if .FailedRuns}} &middot; <span class="fail">{{.FailedRuns}} failed</span>{{end}}</p>
+        </header>
+
+        {{define "chart"}}
+        <svg viewBox="0 0 {{.Width}} {{.Height}}" role="img">
+            <line class="axis" x1="{{.Left}}" y1="{{.Top}}" x2="{{.Right}}" y2="{{.Top}}" stroke-dasharray="4"/>
+            <line class="axis" x1="{{.Left}}" y1="{{.Bottom}}" x2="{{.Right}}" y2="{{.Bottom}}"/>
+            <text x="{{.Left}}" y="14">{{.Max}}</text>
+            <polyline class="line" points="{{.Line}}"/>
+            {{range .Points}}<circle class="point" cx="{{.X}}" cy="{{.Y}}" r="4"><title>{{.Label}}</title></circle>{{end}}
+        </svg>
+        {{end}}
+
+        <div class="section">
+            <h2>Requests/sec <span class="change">{{printf "%.2f" .FirstRPS}} &rarr; {{printf "%.2f" .LatestRPS}} {{.RPSChange}}</span></h2>
+            {{template "chart" .RPSChart}}
+        </div>
+
+        <div class="section">
+            <h2>p99 Latency <span class="change">{{.FirstP99}} &rarr; {{.LatestP99}} {{.P99Change}}</span></h2>
+            {{template "chart" .P99Chart}}
+        </div>
+
+        <div class="section">
+            <h2>Runs</h2>
+            <table>
+                <thead>
+                    <tr>
+                        <th>#</th>
+                        <th>Time</th>
+                        <th>Requests/sec</th>
+                        <th>p99</th>
+                        <th>Failures</th>
+                        <th>Result</th>
+                        {{if .HasLabels}}<th>Labels</th>{{end}}
+                    </tr>
+                </thead>
+                <tbody>
+                    {{$labels := .HasLabels}}
+                    {{range .Runs}}
+                    <tr>
+                        <td>{{.Number}}</td>
+                        <td>{{.Timestamp}}{{if .Name}} <span class="sub">{{.Name}}</span>{{end}}</td>
+                        <td>{{printf "%.2f" .RPS}} <span class="sub">{{.RPSChange}}</span></td>
+                        <td>{{.P99}} <span class="sub">{{.P99Change}}</span></td>
+                        <td>{{.Failures}}</td>
+                        <td class="{{.Result}}">{{.Result}}</td>
+                        {{if $labels}}<td class="sub">{{.Labels}}</td>{{end}}
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+    </div>
+</body>
+</html>
+`
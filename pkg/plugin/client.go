@@ -0,0 +1,219 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Message types sent to plugins
+const (
+	TypeAuth    = "auth"    // Ask an auth plugin for headers
+	TypeRequest = "request" // Ask a protocol plugin to send a request
+	TypeResults = "results" // Hand a sink plugin the results
+)
+
+// closeTimeout is how long a plugin gets to exit after its stdin is closed
+const closeTimeout = 5 * time.Second
+
+// Message is one line sent to a plugin. Replies carry the same ID, so a
+// plugin may answer several requests concurrently and out of order.
+type Message struct {
+	ID      int64           `json:"id"`
+	Type    string          `json:"type"`
+	Request *Request        `json:"request,omitempty"` // For "request"
+	Results json.RawMessage `json:"results,omitempty"` // For "results": the JSON report
+}
+
+// Request is a request for a protocol plugin to send
+type Request struct {
+	Method  string              `json:"method"`
+	URL     string              `json:"url"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    []byte              `json:"body,omitempty"` // Base64 in JSON
+}
+
+// Reply is one line a plugin answers with
+type Reply struct {
+	ID       int64             `json:"id"`
+	Error    string            `json:"error,omitempty"`    // The call failed
+	Headers  map[string]string `json:"headers,omitempty"`  // Auth: headers to add to requests
+	TTL      string            `json:"ttl,omitempty"`      // Auth: how long the headers stay valid (empty = for the whole run)
+	Response *Response         `json:"response,omitempty"` // Request: what the target answered
+}
+
+// Response is what a protocol plugin received for a request
+type Response struct {
+	Status  int                 `json:"status"` // HTTP-like status deciding success (2xx unless expectedStatus says otherwise)
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    []byte              `json:"body,omitempty"` // Base64 in JSON
+}
+
+// Client is a running plugin process
+type Client struct {
+	name  string
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+	encoder *json.Encoder
+
+	mu      sync.Mutex
+	pending map[int64]chan *Reply
+	nextID  int64
+	err     error         // Why the plugin stopped answering
+	done    chan struct{} // Closed once the plugin's output ends
+}
+
+// Start runs a plugin. Its stderr goes to stderr (os.Stderr if nil).
+func Start(m *Manifest, stderr io.Writer) (*Client, error) {
+	command := m.Command
+	if !filepath.IsAbs(command) {
+		if path := filepath.Join(m.Dir, command); fileExists(path) {
+			command, _ = filepath.Abs(path)
+		}
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	cmd := exec.Command(command, m.Args...)
+	cmd.Dir = m.Dir
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", m.Name, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", m.Name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("plugin %s: failed to start: %w", m.Name, err)
+	}
+
+	c := &Client{
+		name:    m.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		encoder: json.NewEncoder(stdin),
+		pending: make(map[int64]chan *Reply),
+		done:    make(chan struct{}),
+	}
+	go c.readReplies(stdout)
+	return c, nil
+}
+
+// Name returns the plugin's name
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Call sends a message and waits for its reply. A reply with an error is
+// returned as an error.
+func (c *Client) Call(ctx context.Context, msg *Message) (*Reply, error) {
+	replies := make(chan *Reply, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	c.nextID++
+	msg.ID = c.nextID
+	c.pending[msg.ID] = replies
+	c.mu.Unlock()
+
+	c.writeMu.Lock()
+	err := c.encoder.Encode(msg)
+	c.writeMu.Unlock()
+	if err != nil {
+		c.forget(msg.ID)
+		return nil, fmt.Errorf("plugin %s: failed to send: %w", c.name, err)
+	}
+
+	select {
+	case reply := <-replies:
+		if reply.Error != "" {
+			return nil, fmt.Errorf("plugin %s: %s", c.name, reply.Error)
+		}
+		return reply, nil
+	case <-c.done:
+		c.forget(msg.ID)
+		return nil, c.stopped()
+	case <-ctx.Done():
+		c.forget(msg.ID)
+		return nil, ctx.Err()
+	}
+}
+
+// Close closes the plugin's stdin and waits for it to exit, killing it if it
+// takes longer than a few seconds
+func (c *Client) Close() error {
+	c.stdin.Close()
+	select {
+	case <-c.done:
+	case <-time.After(closeTimeout):
+		c.cmd.Process.Kill()
+		<-c.done
+	}
+	if err := c.cmd.Wait(); err != nil {
+		return fmt.Errorf("plugin %s: %w", c.name, err)
+	}
+	return nil
+}
+
+// readReplies hands each reply to the call waiting for it until the plugin's output ends
+func (c *Client) readReplies(stdout io.Reader) {
+	decoder := json.NewDecoder(stdout)
+	var err error
+	for {
+		reply := &Reply{}
+		if err = decoder.Decode(reply); err != nil {
+			break
+		}
+		c.mu.Lock()
+		replies, ok := c.pending[reply.ID]
+		delete(c.pending, reply.ID)
+		c.mu.Unlock()
+		if ok {
+			replies <- reply
+		}
+	}
+
+	if errors.Is(err, io.EOF) {
+		err = fmt.Errorf("plugin %s exited", c.name)
+	} else {
+		err = fmt.Errorf("plugin %s: invalid reply: %w", c.name, err)
+	}
+	c.mu.Lock()
+	c.err = err
+	c.mu.Unlock()
+	close(c.done)
+}
+
+// stopped returns why the plugin stopped answering
+func (c *Client) stopped() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// forget drops a call that no longer waits for its reply
+func (c *Client) forget(id int64) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+// fileExists reports whether path is an existing file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
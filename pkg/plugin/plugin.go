@@ -0,0 +1,159 @@
+// Package plugin runs external plugins: executables that add custom protocols,
+// authenticators and output sinks without living in this repository. Plugins
+// are discovered from the JSON manifests in a plugins directory and talk to
+// the benchmark over stdin and stdout, one JSON message per line, so they can
+// be written in any language.
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Plugin kinds
+const (
+	KindProtocol = "protocol" // Sends requests for URL schemes other than HTTP
+	KindAuth     = "auth"     // Supplies headers (e.g. tokens) added to every request
+	KindSink     = "sink"     // Receives the results when the run is finished
+)
+
+// manifestSuffix marks the plugin manifests in a plugins directory, which may
+// also hold the plugins' own files
+const manifestSuffix = ".plugin.json"
+
+// Manifest describes a plugin. It is a <name>.plugin.json file in the plugins directory:
+//
+//	{"kinds": ["protocol"], "schemes": ["grpc"], "command": "./grpc-plugin", "args": ["--tls"]}
+type Manifest struct {
+	Name    string   `json:"name,omitempty"`    // Defaults to the file name without .plugin.json
+	Kinds   []string `json:"kinds"`             // What the plugin provides
+	Schemes []string `json:"schemes,omitempty"` // URL schemes a protocol plugin sends requests for
+	Command string   `json:"command"`           // Executable in the plugins directory, or an absolute path or one looked up in PATH
+	Args    []string `json:"args,omitempty"`
+	Dir     string   `json:"-"` // Directory the manifest was found in
+}
+
+// Has reports whether the plugin provides a kind
+func (m *Manifest) Has(kind string) bool {
+	for _, k := range m.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry holds the plugins found in a directory, by name
+type Registry map[string]*Manifest
+
+// Discover reads the plugin manifests in dir
+func Discover(dir string) (Registry, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("failed to read plugins directory: %w", err)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+manifestSuffix))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plugins: %w", err)
+	}
+
+	registry := make(Registry)
+	schemes := make(map[string]string)
+	for _, path := range paths {
+		manifest, err := loadManifest(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := registry[manifest.Name]; ok {
+			return nil, fmt.Errorf("duplicate plugin name %q", manifest.Name)
+		}
+		for _, scheme := range manifest.Schemes {
+			if other, ok := schemes[scheme]; ok {
+				return nil, fmt.Errorf("plugins %q and %q both handle the %s scheme", other, manifest.Name, scheme)
+			}
+			schemes[scheme] = manifest.Name
+		}
+		registry[manifest.Name] = manifest
+	}
+	return registry, nil
+}
+
+// loadManifest reads and checks one manifest
+func loadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin manifest: %w", err)
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid plugin manifest %s: %w", path, err)
+	}
+	if manifest.Name == "" {
+		manifest.Name = strings.TrimSuffix(filepath.Base(path), manifestSuffix)
+	}
+	manifest.Dir = filepath.Dir(path)
+
+	if manifest.Command == "" {
+		return nil, fmt.Errorf("plugin %q has no command", manifest.Name)
+	}
+	if len(manifest.Kinds) == 0 {
+		return nil, fmt.Errorf("plugin %q has no kinds", manifest.Name)
+	}
+	for _, kind := range manifest.Kinds {
+		switch kind {
+		case KindProtocol, KindAuth, KindSink:
+		default:
+			return nil, fmt.Errorf("plugin %q has unknown kind %q (use protocol, auth or sink)", manifest.Name, kind)
+		}
+	}
+	if manifest.Has(KindProtocol) != (len(manifest.Schemes) > 0) {
+		return nil, fmt.Errorf("plugin %q needs schemes exactly when it is a protocol plugin", manifest.Name)
+	}
+	for i, scheme := range manifest.Schemes {
+		manifest.Schemes[i] = strings.ToLower(scheme)
+		switch manifest.Schemes[i] {
+		case "http", "https", "ws", "wss":
+			return nil, fmt.Errorf("plugin %q cannot take over the %s scheme", manifest.Name, scheme)
+		}
+	}
+	return manifest, nil
+}
+
+// Get returns the named plugin, which must provide kind
+func (r Registry) Get(name, kind string) (*Manifest, error) {
+	manifest, ok := r[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown plugin %q (found: %s)", name, r.names())
+	}
+	if !manifest.Has(kind) {
+		return nil, fmt.Errorf("plugin %q does not provide the %s kind", name, kind)
+	}
+	return manifest, nil
+}
+
+// Protocols returns the protocol plugins by URL scheme
+func (r Registry) Protocols() map[string]*Manifest {
+	protocols := make(map[string]*Manifest)
+	for _, manifest := range r {
+		for _, scheme := range manifest.Schemes {
+			protocols[scheme] = manifest
+		}
+	}
+	return protocols
+}
+
+// names lists the plugin names for error messages
+func (r Registry) names() string {
+	if len(r) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -4,14 +4,18 @@ package progress
 import (
 	"fmt"
 	"math"
+	"os"
 	"strings"
 	"sync"
 	"time"
 )
 
-// Bar displays and updates a progress bar
+// Bar displays and updates a progress bar. On a terminal it redraws one
+// line fitted to the terminal width; when stdout is not a terminal (CI logs,
+// files) it prints a plain status line every plainInterval instead.
 type Bar struct {
-	blockCount      int
+	width           int // Terminal width, re-read every widthRefresh
+	widthCheckedAt  time.Time
 	currentProgress float64
 	startTime       time.Time
 	currentText     string
@@ -20,8 +24,21 @@ type Bar struct {
 	done            bool
 	quiet           bool
 	showLiveStats   bool
+	plain           bool      // stdout is not a terminal
+	lastLine        time.Time // When the last plain line was printed
 }
 
+const (
+	maxBlockCount = 50 // Width of the bar on wide terminals
+	minBlockCount = 10 // Narrowest bar before stats are dropped from the line
+
+	// plainInterval is the time between status lines when stdout is not a terminal
+	plainInterval = 10 * time.Second
+
+	// widthRefresh is how often the terminal width is re-read, to follow resizes
+	widthRefresh = 2 * time.Second
+)
+
 // NewBar creates a new progress bar
 func NewBar(durationMode bool, quiet bool) *Bar {
 	return NewBarWithOptions(durationMode, quiet, false)
@@ -30,14 +47,15 @@ func NewBar(durationMode bool, quiet bool) *Bar {
 // NewBarWithOptions creates a new progress bar with additional options
 func NewBarWithOptions(durationMode bool, quiet bool, showLiveStats bool) *Bar {
 	p := &Bar{
-		blockCount:    50,
 		startTime:     time.Now(),
 		durationMode:  durationMode,
 		quiet:         quiet,
 		showLiveStats: showLiveStats,
+		plain:         !isTerminal(os.Stdout),
 	}
+	p.lastLine = p.startTime
 
-	if !quiet {
+	if !quiet && !p.plain {
 		fmt.Print("\033[?25l") // Hide cursor
 		p.resetBar()
 	}
@@ -45,17 +63,42 @@ func NewBarWithOptions(durationMode bool, quiet bool, showLiveStats bool) *Bar {
 	return p
 }
 
+// isTerminal reports whether f is a terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 // Report updates the progress bar
 func (p *Bar) Report(value float64, requestCount int) {
 	p.ReportWithStats(value, requestCount, nil)
 }
 
+// Display shows benchmark progress: the single-line Bar or the full-screen TUI
+type Display interface {
+	ReportWithStats(value float64, requestCount int, stats *LiveStats)
+	ForceComplete(elapsed time.Duration, requestCount int)
+	Message(text string)
+	Close()
+}
+
 // LiveStats holds real-time statistics for display
 type LiveStats struct {
 	RequestsPerSec float64
 	AvgLatencyUs   float64
 	ErrorCount     int64
 	SuccessCount   int64
+	Elapsed        time.Duration // Benchmark time so far, without pauses
+	P99Us          float64
+
+	// Only filled for the TUI
+	ActiveWorkers int
+	TotalWorkers  int
+	StatusCodes   [6]int64  // 1xx, 2xx, 3xx, 4xx, 5xx, other
+	RPSHistory    []float64 // Requests/sec per second, oldest first
+	P99History    []float64 // p99 latency per second in microseconds, oldest first
+	RecentErrors  []string  // Newest errors, oldest first
+	Paused        bool
 }
 
 // ReportWithStats updates the progress bar with optional live stats
@@ -69,38 +112,161 @@ func (p *Bar) ReportWithStats(value float64, requestCount int, stats *LiveStats)
 	}
 
 	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	p.currentProgress = math.Max(0, math.Min(1, value))
-	p.mutex.Unlock()
 
-	progressBlockCount := int(p.currentProgress * float64(p.blockCount))
+	if p.plain {
+		if time.Since(p.lastLine) < plainInterval {
+			return
+		}
+		p.lastLine = time.Now()
+		fmt.Println(p.plainLine(requestCount, stats))
+		return
+	}
+	p.redraw(p.barLine(requestCount, stats))
+}
+
+// barField is one statistic after the bar; the lowest priority fields are
+// left out first when the terminal is too narrow
+type barField struct {
+	text     string
+	priority int
+}
+
+// barLine formats the progress bar and as many statistics as fit the
+// terminal. The caller must hold p.mutex.
+func (p *Bar) barLine(requestCount int, stats *LiveStats) string {
+	var fields []barField
+	if requestCount > 0 {
+		fields = append(fields, barField{fmt.Sprintf("Reqs: %d", requestCount), 4})
+	}
+	if stats != nil {
+		if p.showLiveStats {
+			fields = append(fields,
+				barField{fmt.Sprintf("Rate: %.1f/s", stats.RequestsPerSec), 2},
+				barField{"Avg: " + formatLatencyCompact(stats.AvgLatencyUs), 1})
+		}
+		if stats.P99Us > 0 {
+			fields = append(fields, barField{"p99: " + formatLatencyCompact(stats.P99Us), 3})
+		}
+		if p.showLiveStats {
+			fields = append(fields, barField{fmt.Sprintf("Err: %d", stats.ErrorCount), 3})
+		}
+	}
+	if eta := p.eta(stats); eta != "" {
+		fields = append(fields, barField{"ETA: " + eta, 5})
+	}
+
 	percent := int(p.currentProgress * 100)
+	width := p.terminalWidth() - 1 // Writing the last column would wrap on some terminals
+	var suffix string
+	blocks := 0
+	for {
+		texts := make([]string, len(fields))
+		for i, field := range fields {
+			texts[i] = field.text
+		}
+		suffix = strings.Join(texts, " | ")
+		blocks = width - len(fmt.Sprintf(" %3d%% [] ", percent)) - len(suffix)
+		if blocks >= minBlockCount || len(fields) == 0 {
+			break
+		}
+		lowest := 0
+		for i, field := range fields {
+			if field.priority < fields[lowest].priority {
+				lowest = i
+			}
+		}
+		fields = append(fields[:lowest], fields[lowest+1:]...)
+	}
+	return p.fitLine(p.formatBar(percent, blocks)+" "+suffix, width)
+}
 
-	var text string
-	if p.showLiveStats && stats != nil {
-		// Live stats mode: show compact stats
-		latencyStr := formatLatencyCompact(stats.AvgLatencyUs)
-		text = fmt.Sprintf(" %3d%% [%s%s] Reqs: %d | Rate: %.1f/s | Avg: %s | Err: %d",
-			percent,
-			strings.Repeat("=", progressBlockCount),
-			strings.Repeat(" ", p.blockCount-progressBlockCount),
-			requestCount,
-			stats.RequestsPerSec,
-			latencyStr,
-			stats.ErrorCount)
-	} else if requestCount > 0 {
-		text = fmt.Sprintf(" %3d%% [%s%s] (%d requests)",
-			percent,
-			strings.Repeat("=", progressBlockCount),
-			strings.Repeat(" ", p.blockCount-progressBlockCount),
-			requestCount)
-	} else {
-		text = fmt.Sprintf(" %3d%% [%s%s]",
-			percent,
-			strings.Repeat("=", progressBlockCount),
-			strings.Repeat(" ", p.blockCount-progressBlockCount))
+// formatBar formats the percentage and a bar of up to blocks characters
+func (p *Bar) formatBar(percent, blocks int) string {
+	blocks = max(1, min(blocks, maxBlockCount))
+	filled := int(p.currentProgress * float64(blocks))
+	return fmt.Sprintf(" %3d%% [%s%s]", percent, strings.Repeat("=", filled), strings.Repeat(" ", blocks-filled))
+}
+
+// fitLine cuts a line to the terminal width
+func (p *Bar) fitLine(text string, width int) string {
+	text = strings.TrimRight(text, " ")
+	if width > 0 && len(text) > width {
+		text = text[:width]
+	}
+	return text
+}
+
+// plainLine formats a status line for output that is not a terminal. The
+// caller must hold p.mutex.
+func (p *Bar) plainLine(requestCount int, stats *LiveStats) string {
+	parts := []string{fmt.Sprintf("[%6s] %3d%%", p.elapsed(stats).Round(time.Second), int(p.currentProgress*100))}
+	parts = append(parts, fmt.Sprintf("%d requests", requestCount))
+	if stats != nil {
+		parts = append(parts, fmt.Sprintf("%.1f req/s", stats.RequestsPerSec))
+		if stats.P99Us > 0 {
+			parts = append(parts, "p99 "+formatLatencyCompact(stats.P99Us))
+		}
+		parts = append(parts, fmt.Sprintf("%d errors", stats.ErrorCount))
+	}
+	if eta := p.eta(stats); eta != "" {
+		parts = append(parts, "ETA "+eta)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// elapsed returns the benchmark time so far, which leaves out pauses when the
+// runner reports it
+func (p *Bar) elapsed(stats *LiveStats) time.Duration {
+	if stats != nil && stats.Elapsed > 0 {
+		return stats.Elapsed
+	}
+	return time.Since(p.startTime)
+}
+
+// eta estimates the time left from the progress so far, or returns "" while
+// there is too little progress for an estimate
+func (p *Bar) eta(stats *LiveStats) string {
+	elapsed := p.elapsed(stats)
+	if p.currentProgress <= 0 || p.currentProgress >= 1 || elapsed < time.Second {
+		return ""
+	}
+	remaining := time.Duration(float64(elapsed) * (1 - p.currentProgress) / p.currentProgress)
+	return remaining.Round(time.Second).String()
+}
+
+// terminalWidth returns the width of the terminal, re-reading it every
+// widthRefresh. The caller must hold p.mutex.
+func (p *Bar) terminalWidth() int {
+	if p.width == 0 || time.Since(p.widthCheckedAt) > widthRefresh {
+		p.width = terminalWidth()
+		p.widthCheckedAt = time.Now()
 	}
+	return p.width
+}
+
+// IntervalReport holds the stats of one reporting interval
+type IntervalReport struct {
+	Elapsed        time.Duration // Benchmark time at the end of the interval
+	Requests       int64
+	Errors         int64
+	RequestsPerSec float64
+	P50Us          int64
+	P95Us          int64
+	P99Us          int64
+}
 
-	p.updateText(text)
+// FormatIntervalReport formats an interval report as a single compact line
+func FormatIntervalReport(r *IntervalReport) string {
+	errorRate := 0.0
+	if r.Requests > 0 {
+		errorRate = float64(r.Errors) / float64(r.Requests) * 100
+	}
+	return fmt.Sprintf("[%6s] %8.1f req/s  p50 %-7s p95 %-7s p99 %-7s errors %d/%d (%.2f%%)",
+		r.Elapsed.Round(time.Second), r.RequestsPerSec,
+		formatLatencyCompact(float64(r.P50Us)), formatLatencyCompact(float64(r.P95Us)), formatLatencyCompact(float64(r.P99Us)),
+		r.Errors, r.Requests, errorRate)
 }
 
 // formatLatencyCompact formats latency in microseconds to a compact string
@@ -114,36 +280,32 @@ func formatLatencyCompact(us float64) string {
 	}
 }
 
-func (p *Bar) updateText(text string) {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-
-	commonPrefixLength := 0
-	commonLength := int(math.Min(float64(len(p.currentText)), float64(len(text))))
-
-	for commonPrefixLength < commonLength && text[commonPrefixLength] == p.currentText[commonPrefixLength] {
-		commonPrefixLength++
-	}
+// redraw replaces the bar's line with text. The caller must hold p.mutex.
+func (p *Bar) redraw(text string) {
+	fmt.Print("\r" + text + "\033[K")
+	p.currentText = text
+}
 
-	var outputBuilder strings.Builder
-	for i := 0; i < len(p.currentText)-commonPrefixLength; i++ {
-		outputBuilder.WriteRune('\b')
+// Message prints text on its own line above the progress bar
+func (p *Bar) Message(text string) {
+	if p.quiet {
+		return
 	}
 
-	outputBuilder.WriteString(text[commonPrefixLength:])
-
-	overlapCount := len(p.currentText) - len(text)
-	if overlapCount > 0 {
-		outputBuilder.WriteString(strings.Repeat(" ", overlapCount))
-		outputBuilder.WriteString(strings.Repeat("\b", overlapCount))
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.plain {
+		fmt.Println(text)
+		return
 	}
-
-	fmt.Print(outputBuilder.String())
-	p.currentText = text
+	fmt.Print("\r\033[K" + text + "\n" + p.currentText) // Clear the bar, print, redraw the bar below
 }
 
+// resetBar draws the empty bar. The caller must not hold p.mutex.
 func (p *Bar) resetBar() {
-	p.updateText(fmt.Sprintf(" %3d%% [%s]", 0, strings.Repeat(" ", p.blockCount)))
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.redraw(p.barLine(0, nil))
 }
 
 // Close cleans up the progress bar
@@ -157,7 +319,9 @@ func (p *Bar) Close() {
 
 	if !p.done {
 		p.done = true
-		fmt.Print("\033[?25h") // Show cursor
+		if !p.plain {
+			fmt.Print("\033[?25h") // Show cursor
+		}
 	}
 }
 
@@ -168,17 +332,17 @@ func (p *Bar) ForceComplete(elapsed time.Duration, requestCount int) {
 	}
 
 	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	p.currentProgress = 1.0
-	p.mutex.Unlock()
 
-	progressBlockCount := p.blockCount
-
-	text := fmt.Sprintf(" 100%% [%s] %.0fs (%d requests)",
-		strings.Repeat("=", progressBlockCount),
-		elapsed.Seconds(),
-		requestCount)
+	if p.plain {
+		fmt.Printf("[%6s] 100%%, %d requests, done\n", elapsed.Round(time.Second), requestCount)
+		return
+	}
 
-	p.updateText(text)
+	suffix := fmt.Sprintf("%.0fs (%d requests)", elapsed.Seconds(), requestCount)
+	width := p.terminalWidth() - 1
+	blocks := width - len(" 100% [] ") - len(suffix)
+	p.redraw(p.fitLine(p.formatBar(100, blocks)+" "+suffix, width))
 	fmt.Println()
 }
-
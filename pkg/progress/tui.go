@@ -0,0 +1,213 @@
+package progress
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sparkBlocks are the bar heights used by sparklines, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// TUI is a full-screen terminal dashboard redrawn on every report. It uses the
+// terminal's alternate screen, so the console output before and after the run
+// stays intact.
+type TUI struct {
+	title        string
+	durationMode bool
+	width        int
+	mutex        sync.Mutex
+	done         bool
+	restore      func() // Restores the terminal's input mode (nil when keys aren't read)
+}
+
+// NewTUI switches the terminal to the alternate screen and returns the dashboard.
+// Pressing p calls togglePause.
+func NewTUI(title string, durationMode bool, togglePause func()) *TUI {
+	t := &TUI{
+		title:        title,
+		durationMode: durationMode,
+		width:        terminalWidth(),
+	}
+	fmt.Print("\033[?1049h\033[?25l\033[2J") // Alternate screen, hide cursor, clear
+	t.readKeys(func(key byte) {
+		if key == 'p' || key == 'P' {
+			togglePause()
+		}
+	})
+	return t
+}
+
+// readKeys switches stdin to unbuffered input and calls onKey for every key
+// pressed until the TUI closes. It does nothing when stdin is not a terminal.
+func (t *TUI) readKeys(onKey func(byte)) {
+	state, err := stty("-g")
+	if err != nil {
+		return
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return
+	}
+	t.restore = func() { stty(strings.TrimSpace(state)) }
+
+	go func() {
+		key := make([]byte, 1)
+		for {
+			if _, err := os.Stdin.Read(key); err != nil {
+				return
+			}
+			t.mutex.Lock()
+			done := t.done
+			t.mutex.Unlock()
+			if done {
+				return
+			}
+			onKey(key[0])
+		}
+	}()
+}
+
+// stty runs stty on the terminal attached to stdin
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// terminalWidth returns $COLUMNS, else the width stty reports for the
+// terminal, or 80 when neither is known
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns >= 40 {
+		return columns
+	}
+	if size, err := stty("size"); err == nil {
+		if fields := strings.Fields(size); len(fields) == 2 {
+			if columns, err := strconv.Atoi(fields[1]); err == nil && columns >= 40 {
+				return columns
+			}
+		}
+	}
+	return 80
+}
+
+// ReportWithStats redraws the dashboard
+func (t *TUI) ReportWithStats(value float64, requestCount int, stats *LiveStats) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.done || stats == nil {
+		return
+	}
+
+	value = max(0, min(1, value))
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(&b, format, args...)
+		b.WriteString("\033[K\n") // Clear what is left of the previous frame's line
+	}
+
+	title := t.title
+	if title == "" {
+		title = "Benchmark"
+	}
+	state := "p pause"
+	if stats.Paused {
+		state = "\033[7m PAUSED \033[0m  p resume"
+	}
+	line("\033[1m%s\033[0m  elapsed %s  %s  Ctrl+C stop", title, stats.Elapsed.Round(time.Second), state)
+	barWidth := max(10, t.width-20)
+	filled := int(value * float64(barWidth))
+	label := fmt.Sprintf("%d requests", requestCount)
+	if t.durationMode {
+		label = "of duration"
+	}
+	line("%3d%% [%s%s] %s", int(value*100), strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled), label)
+	line("")
+
+	total := stats.SuccessCount + stats.ErrorCount
+	errorRate := 0.0
+	if total > 0 {
+		errorRate = float64(stats.ErrorCount) / float64(total) * 100
+	}
+	line("Requests  %-12d Success %-12d Errors %d (%.2f%%)", total, stats.SuccessCount, stats.ErrorCount, errorRate)
+	line("Workers   %d/%d active", stats.ActiveWorkers, stats.TotalWorkers)
+	line("")
+
+	sparkWidth := max(10, t.width-32)
+	line("Req/sec   %-10.1f %s", lastOr(stats.RPSHistory, stats.RequestsPerSec), sparkline(stats.RPSHistory, sparkWidth))
+	line("p99       %-10s %s", formatLatencyCompact(lastOr(stats.P99History, stats.P99Us)), sparkline(stats.P99History, sparkWidth))
+	line("Avg       %-10s overall p99 %s", formatLatencyCompact(stats.AvgLatencyUs), formatLatencyCompact(stats.P99Us))
+	line("")
+
+	codes := stats.StatusCodes
+	line("Status    1xx %-8d 2xx %-8d 3xx %-8d", codes[0], codes[1], codes[2])
+	line("          4xx %-8d 5xx %-8d other %d", codes[3], codes[4], codes[5])
+	line("")
+
+	line("Recent errors")
+	if len(stats.RecentErrors) == 0 {
+		line("  none")
+	}
+	for _, msg := range stats.RecentErrors {
+		if len(msg) > t.width-4 {
+			msg = msg[:t.width-7] + "..."
+		}
+		line("  %s", msg)
+	}
+
+	fmt.Print("\033[H" + b.String() + "\033[J") // Home, frame, clear the rest of the screen
+}
+
+// ForceComplete leaves the dashboard so the summary prints on the normal screen
+func (t *TUI) ForceComplete(elapsed time.Duration, requestCount int) {
+	t.Close()
+	fmt.Printf("Completed %d requests in %.0fs\n", requestCount, elapsed.Seconds())
+}
+
+// Message is ignored: the dashboard already shows the live numbers
+func (t *TUI) Message(text string) {}
+
+// Close restores the terminal
+func (t *TUI) Close() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if !t.done {
+		t.done = true
+		if t.restore != nil {
+			t.restore()
+		}
+		fmt.Print("\033[?25h\033[?1049l") // Show cursor, leave alternate screen
+	}
+}
+
+// sparkline draws the last width values as block characters scaled to their maximum
+func sparkline(values []float64, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	peak := 0.0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if peak > 0 {
+			level = int(v / peak * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// lastOr returns the newest value, or fallback when there is none yet
+func lastOr(values []float64, fallback float64) float64 {
+	if len(values) == 0 {
+		return fallback
+	}
+	return values[len(values)-1]
+}
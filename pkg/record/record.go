@@ -0,0 +1,296 @@
+// Package record implements a recording HTTP(S) proxy that turns captured
+// traffic into a scenario configuration
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// hopHeaders are connection-specific headers that are neither forwarded nor recorded
+var hopHeaders = []string{
+	"Connection", "Proxy-Connection", "Keep-Alive", "Proxy-Authenticate",
+	"Proxy-Authorization", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// skippedHeaders are request headers that are not recorded because the benchmark sets them itself
+var skippedHeaders = []string{"Host", "Content-Length", "Accept-Encoding"}
+
+// Recorder is a forward proxy that records every request passing through it.
+// HTTPS traffic is intercepted with certificates signed by its CA; without a
+// CA, CONNECT requests are tunneled and not recorded.
+type Recorder struct {
+	Log io.Writer // Receives verbose output (discarded by default)
+
+	ca      *CA
+	filter  *regexp.Regexp // Only URLs matching this are recorded (nil = all)
+	verbose bool
+	client  *http.Client
+
+	mu       sync.Mutex
+	steps    []config.StepConfig
+	lastSeen time.Time
+}
+
+// NewRecorder creates a recorder. ca may be nil to tunnel HTTPS without recording it.
+// insecure skips certificate verification of upstream servers.
+func NewRecorder(ca *CA, filter *regexp.Regexp, insecure, verbose bool) *Recorder {
+	return &Recorder{
+		Log:     io.Discard,
+		ca:      ca,
+		filter:  filter,
+		verbose: verbose,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           nil,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
+			},
+			// Redirects are passed back to the client, which follows (and records) them
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// ServeHTTP handles proxied requests
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodConnect {
+		r.handleConnect(w, req)
+		return
+	}
+	if !req.URL.IsAbs() {
+		http.Error(w, "recording proxy: configure this address as your HTTP proxy", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := r.forward(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for key, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// forward sends a request upstream and records it with the response status
+func (r *Recorder) forward(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body.Close()
+
+	out, err := http.NewRequestWithContext(req.Context(), req.Method, req.URL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	out.Header = req.Header.Clone()
+	for _, h := range hopHeaders {
+		out.Header.Del(h)
+	}
+
+	start := time.Now()
+	resp, err := r.client.Do(out)
+	if err != nil {
+		return nil, err
+	}
+	r.record(req, body, resp.StatusCode, start)
+	return resp, nil
+}
+
+// handleConnect intercepts (with a CA) or tunnels (without) an HTTPS connection
+func (r *Recorder) handleConnect(w http.ResponseWriter, req *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+	clientConn, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	defer clientConn.Close()
+	clientConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+
+	if r.ca == nil {
+		r.tunnel(clientConn, req.Host)
+		return
+	}
+
+	host, _, err := net.SplitHostPort(req.Host)
+	if err != nil {
+		host = req.Host
+	}
+	tlsConn := tls.Server(clientConn, &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			name := hello.ServerName
+			if name == "" {
+				name = host
+			}
+			return r.ca.CertificateFor(name)
+		},
+		NextProtos: []string{"http/1.1"},
+	})
+	if err := tlsConn.Handshake(); err != nil {
+		if r.verbose {
+			fmt.Fprintf(r.Log, "[record] TLS handshake with client failed for %s: %v\n", req.Host, err)
+		}
+		return
+	}
+	defer tlsConn.Close()
+
+	reader := bufio.NewReader(tlsConn)
+	for {
+		inner, err := http.ReadRequest(reader)
+		if err != nil {
+			return
+		}
+		inner.URL.Scheme = "https"
+		inner.URL.Host = req.Host
+		inner.RequestURI = ""
+
+		resp, err := r.forward(inner)
+		if err != nil {
+			errResp := &http.Response{
+				StatusCode: http.StatusBadGateway,
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Body:       io.NopCloser(strings.NewReader(err.Error())),
+			}
+			errResp.Write(tlsConn)
+			return
+		}
+		err = resp.Write(tlsConn)
+		resp.Body.Close()
+		if err != nil || inner.Close {
+			return
+		}
+	}
+}
+
+// tunnel copies bytes between the client and the target without recording
+func (r *Recorder) tunnel(clientConn net.Conn, target string) {
+	upstream, err := net.DialTimeout("tcp", target, 10*time.Second)
+	if err != nil {
+		return
+	}
+	defer upstream.Close()
+	if r.verbose {
+		fmt.Fprintf(r.Log, "[record] Tunneling %s (not recorded, no CA configured)\n", target)
+	}
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(upstream, clientConn)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(clientConn, upstream)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// record adds a step for the request, including the think time since the previous one
+func (r *Recorder) record(req *http.Request, body []byte, status int, start time.Time) {
+	url := req.URL.String()
+	if r.filter != nil && !r.filter.MatchString(url) {
+		return
+	}
+
+	step := config.StepConfig{
+		Method:   req.Method,
+		URL:      url,
+		Validate: &config.ValidateConfig{Status: status},
+	}
+
+	headers := req.Header.Clone()
+	for _, h := range append(hopHeaders, skippedHeaders...) {
+		headers.Del(h)
+	}
+	if len(headers) > 0 {
+		step.Headers = make(map[string]string, len(headers))
+		for key := range headers {
+			step.Headers[key] = headers.Get(key)
+		}
+	}
+
+	if len(body) > 0 {
+		var parsed interface{}
+		if json.Unmarshal(body, &parsed) == nil {
+			step.Body = parsed
+		} else {
+			step.Body = string(body)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.lastSeen.IsZero() {
+		if think := start.Sub(r.lastSeen).Round(time.Millisecond); think > 0 {
+			step.Delay = think.String()
+		}
+	}
+	r.lastSeen = time.Now()
+	step.Name = fmt.Sprintf("%d. %s %s", len(r.steps)+1, req.Method, req.URL.Path)
+	r.steps = append(r.steps, step)
+
+	if r.verbose {
+		fmt.Fprintf(r.Log, "[record] %s %s -> %d\n", req.Method, url, status)
+	}
+}
+
+// Count returns the number of recorded steps
+func (r *Recorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.steps)
+}
+
+// Config builds a scenario configuration from the recorded steps
+func (r *Recorder) Config(name string) *config.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &config.Config{
+		Name: name,
+		Settings: config.Settings{
+			ConcurrentUsers: 1,
+			RequestsPerUser: 1,
+		},
+		Steps: append([]config.StepConfig(nil), r.steps...),
+	}
+}
+
+// WriteConfig writes the recorded scenario as indented JSON
+func (r *Recorder) WriteConfig(filename, name string) error {
+	data, err := json.MarshalIndent(r.Config(name), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recorded config: %w", err)
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write recorded config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/benchmarking_go/pkg/benchmark"
+	"github.com/benchmarking_go/pkg/config"
+	"github.com/benchmarking_go/pkg/output"
+)
+
+// Daemon runs the jobs of a schedule file, one at a time, until stopped
+type Daemon struct {
+	Log io.Writer // Receives the timestamped job log (discarded by default)
+
+	file      *File
+	quietMode bool
+	client    *http.Client // Sends notifications
+}
+
+// Notification is the JSON body POSTed to a job's webhook. Text makes it
+// usable with Slack-style incoming webhooks as is.
+type Notification struct {
+	Text    string               `json:"text"`
+	Job     string               `json:"job"`
+	Passed  bool                 `json:"passed"`
+	Error   string               `json:"error,omitempty"` // Why the benchmark could not run
+	Summary *output.HistoryEntry `json:"summary,omitempty"`
+}
+
+// NewDaemon creates a daemon for the jobs in file
+func NewDaemon(file *File, quietMode bool) *Daemon {
+	return &Daemon{
+		Log:       io.Discard,
+		file:      file,
+		quietMode: quietMode,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Run waits for each job's next scheduled time and runs it, until ctx is
+// cancelled. Jobs run one at a time so they don't skew each other's results; a
+// job that comes due while another runs starts right after it.
+func (d *Daemon) Run(ctx context.Context) error {
+	now := time.Now()
+	next := make([]time.Time, len(d.file.Jobs))
+	for i := range d.file.Jobs {
+		next[i] = d.file.Jobs[i].cron.Next(now)
+		d.logf("job %s scheduled for %s", d.file.Jobs[i].Name, formatNext(next[i]))
+	}
+
+	for {
+		due := -1
+		for i, t := range next {
+			if !t.IsZero() && (due < 0 || t.Before(next[due])) {
+				due = i
+			}
+		}
+		if due < 0 {
+			return fmt.Errorf("no job has an upcoming run")
+		}
+
+		timer := time.NewTimer(time.Until(next[due]))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		job := &d.file.Jobs[due]
+		d.runJob(ctx, job)
+		if ctx.Err() != nil {
+			return nil
+		}
+		next[due] = job.cron.Next(time.Now())
+		d.logf("job %s next run at %s", job.Name, formatNext(next[due]))
+	}
+}
+
+// runJob runs one benchmark, appends its summary to the history and sends the notification
+func (d *Daemon) runJob(ctx context.Context, job *Job) {
+	d.logf("job %s starting (%s)", job.Name, job.Config)
+	entry, thresholds, err := d.execute(ctx, job)
+	if err != nil {
+		d.logf("job %s failed: %v", job.Name, err)
+		d.notify(job, &Notification{
+			Text:  fmt.Sprintf("Benchmark %s could not run: %v", job.Name, err),
+			Job:   job.Name,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	entry.Name = job.Name
+	if err := output.AppendHistory(d.file.HistoryDir, *entry); err != nil {
+		d.logf("job %s: %v", job.Name, err)
+	}
+
+	passed := thresholds == nil || thresholds.Passed
+	verdict := "passed"
+	if !passed {
+		verdict = fmt.Sprintf("FAILED %d threshold(s)", thresholds.FailedCount())
+	}
+	text := fmt.Sprintf("Benchmark %s %s: %d requests, %.1f req/s, p99 %s, %d failures",
+		job.Name, verdict, entry.TotalRequests, entry.RequestsPerSec,
+		output.FormatLatency(float64(entry.P99LatencyUs)), entry.FailureCount)
+	d.logf("%s", text)
+	d.notify(job, &Notification{Text: text, Job: job.Name, Passed: passed, Summary: entry})
+}
+
+// execute loads the job's config and runs the benchmark. thresholds is nil when the config has none.
+func (d *Daemon) execute(ctx context.Context, job *Job) (*output.HistoryEntry, *benchmark.ThresholdResults, error) {
+	cfg, err := config.Load(job.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cfg.Requests) == 0 && !cfg.IsScenarioMode() {
+		return nil, nil, fmt.Errorf("config has no requests or steps")
+	}
+	durationSec, err := cfg.GetDurationSeconds()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cfg.ValidateSLO(); err != nil {
+		return nil, nil, err
+	}
+	if _, err := cfg.GetGracePeriod(0); err != nil {
+		return nil, nil, err
+	}
+	cfg.ResolveRequestVariables()
+
+	runner := benchmark.NewRunner(cfg, durationSec, cfg.GetTimeout(), cfg.GetRampUpSeconds(), true, false)
+	runner.Log = d.Log
+	stats := runner.Run(ctx)
+
+	var thresholds *benchmark.ThresholdResults
+	if cfg.HasThresholds() {
+		if thresholds, err = benchmark.EvaluateConfigThresholds(stats, cfg); err != nil {
+			return nil, nil, fmt.Errorf("threshold evaluation failed: %w", err)
+		}
+	}
+	if reason := runner.AbortReason(); reason != "" {
+		if thresholds == nil {
+			thresholds = &benchmark.ThresholdResults{Passed: true}
+		}
+		thresholds.RecordAbort(reason)
+	}
+
+	entry := output.NewHistoryEntry(stats, cfg, thresholds)
+	return &entry, thresholds, nil
+}
+
+// notify POSTs the notification to the job's webhook, if any
+func (d *Daemon) notify(job *Job, n *Notification) {
+	if job.Notify == nil || job.Notify.Webhook == "" || (job.Notify.OnFailure && n.Passed) {
+		return
+	}
+	body, err := json.Marshal(n)
+	if err != nil {
+		d.logf("job %s: failed to encode notification: %v", job.Name, err)
+		return
+	}
+	resp, err := d.client.Post(job.Notify.Webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		d.logf("job %s: notification failed: %v", job.Name, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		d.logf("job %s: notification failed: webhook returned %s", job.Name, resp.Status)
+	}
+}
+
+// logf prints a timestamped line unless in quiet mode
+func (d *Daemon) logf(format string, args ...interface{}) {
+	if !d.quietMode {
+		fmt.Fprintf(d.Log, "[%s] %s\n", time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf(format, args...))
+	}
+}
+
+// formatNext formats a job's next run time
+func formatNext(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format("2006-01-02 15:04")
+}
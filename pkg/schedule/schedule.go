@@ -0,0 +1,87 @@
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DefaultHistoryDir is where run summaries go when the schedule file names no directory
+const DefaultHistoryDir = "bench-history"
+
+// File is a schedule file listing the benchmarks the daemon runs
+type File struct {
+	HistoryDir string        `json:"historyDir,omitempty"` // Directory run summaries are appended to
+	Notify     *NotifyConfig `json:"notify,omitempty"`     // Notification for every job
+	Jobs       []Job         `json:"jobs"`
+}
+
+// Job is a benchmark run on a schedule
+type Job struct {
+	Name     string        `json:"name"`
+	Schedule string        `json:"schedule"`         // Cron expression, e.g. "0 2 * * *"
+	Config   string        `json:"config"`           // Benchmark config file, re-read before every run
+	Notify   *NotifyConfig `json:"notify,omitempty"` // Overrides the file-level notification
+
+	cron *Cron
+}
+
+// NotifyConfig describes where to report finished runs
+type NotifyConfig struct {
+	Webhook   string `json:"webhook"`             // URL the run summary is POSTed to
+	OnFailure bool   `json:"onFailure,omitempty"` // Only notify when a run fails its thresholds or cannot run
+}
+
+// Load reads and validates a schedule file. Relative config paths and the
+// history directory are resolved against the schedule file's directory.
+func Load(filename string) (*File, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule file: %w", err)
+	}
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule file: %w", err)
+	}
+	if len(file.Jobs) == 0 {
+		return nil, fmt.Errorf("schedule file %s has no jobs", filename)
+	}
+
+	base := filepath.Dir(filename)
+	if file.HistoryDir == "" {
+		file.HistoryDir = DefaultHistoryDir
+	}
+	file.HistoryDir = resolvePath(base, file.HistoryDir)
+
+	names := make(map[string]bool)
+	for i := range file.Jobs {
+		job := &file.Jobs[i]
+		if job.Name == "" {
+			return nil, fmt.Errorf("job %d has no name", i+1)
+		}
+		if names[job.Name] {
+			return nil, fmt.Errorf("duplicate job name %q", job.Name)
+		}
+		names[job.Name] = true
+		if job.Config == "" {
+			return nil, fmt.Errorf("job %q has no config", job.Name)
+		}
+		job.Config = resolvePath(base, job.Config)
+		if job.cron, err = ParseCron(job.Schedule); err != nil {
+			return nil, fmt.Errorf("job %q: %w", job.Name, err)
+		}
+		if job.Notify == nil {
+			job.Notify = file.Notify
+		}
+	}
+	return &file, nil
+}
+
+// resolvePath makes a relative path relative to base
+func resolvePath(base, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
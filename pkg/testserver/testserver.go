@@ -0,0 +1,177 @@
+// Package testserver implements a local HTTP server with configurable latency,
+// status codes and payload sizes, to try the benchmark against without an
+// external endpoint
+package testserver
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/benchmarking_go/pkg/config"
+)
+
+// StatusMix picks response statuses by weight
+type StatusMix struct {
+	codes   []int
+	weights []int // Cumulative
+}
+
+// ParseStatusMix parses weighted statuses such as "200:95,500:4,503:1". A
+// status without a weight counts as 1.
+func ParseStatusMix(spec string) (StatusMix, error) {
+	var mix StatusMix
+	total := 0
+	for _, part := range strings.Split(spec, ",") {
+		code, weight, found := strings.Cut(strings.TrimSpace(part), ":")
+		status, err := strconv.Atoi(code)
+		if err != nil || status < 200 || status > 599 {
+			return StatusMix{}, fmt.Errorf("invalid status %q", code)
+		}
+		w := 1
+		if found {
+			if w, err = strconv.Atoi(weight); err != nil || w < 0 {
+				return StatusMix{}, fmt.Errorf("invalid weight %q for status %d", weight, status)
+			}
+		}
+		total += w
+		mix.codes = append(mix.codes, status)
+		mix.weights = append(mix.weights, total)
+	}
+	if total == 0 {
+		return StatusMix{}, fmt.Errorf("status weights must not all be 0")
+	}
+	return mix, nil
+}
+
+// Pick returns a status drawn by weight (200 for an empty mix)
+func (m StatusMix) Pick() int {
+	if len(m.codes) == 0 {
+		return http.StatusOK
+	}
+	n := rand.Intn(m.weights[len(m.weights)-1])
+	for i, cumulative := range m.weights {
+		if n < cumulative {
+			return m.codes[i]
+		}
+	}
+	return m.codes[len(m.codes)-1]
+}
+
+// SizeRange is a range of payload sizes
+type SizeRange struct {
+	min, max int64
+}
+
+// ParseSizeRange parses a payload size such as "1KB" or a range such as "1KB-64KB"
+func ParseSizeRange(spec string) (SizeRange, error) {
+	first, second, isRange := strings.Cut(spec, "-")
+	low, err := config.ParseByteSize(strings.TrimSpace(first))
+	if err != nil {
+		return SizeRange{}, err
+	}
+	r := SizeRange{min: int64(low), max: int64(low)}
+	if isRange {
+		high, err := config.ParseByteSize(strings.TrimSpace(second))
+		if err != nil {
+			return SizeRange{}, err
+		}
+		if int64(high) < r.min {
+			return SizeRange{}, fmt.Errorf("invalid size range %q: the maximum is below the minimum", spec)
+		}
+		r.max = int64(high)
+	}
+	return r, nil
+}
+
+// Sample returns one size from the range
+func (r SizeRange) Sample() int64 {
+	if r.max == r.min {
+		return r.min
+	}
+	return r.min + rand.Int63n(r.max-r.min+1)
+}
+
+// filler is written repeatedly as the payload
+var filler = []byte(strings.Repeat("benchmarking_go test payload\n", 2260))
+
+// Server answers every request with a delay, status and payload drawn from
+// its settings. The query parameters latency, status and size override them
+// per request, e.g. /slow?latency=2s or /missing?status=404.
+type Server struct {
+	Latency  config.Delay
+	Statuses StatusMix
+	Size     SizeRange // Zero for a small JSON body
+	requests atomic.Int64
+}
+
+// Requests returns how many requests were served
+func (s *Server) Requests() int64 {
+	return s.requests.Load()
+}
+
+// ServeHTTP answers a request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+	io.Copy(io.Discard, r.Body)
+
+	latency, statuses, size := s.Latency, s.Statuses, s.Size
+	query := r.URL.Query()
+	var err error
+	if spec := query.Get("latency"); spec != "" {
+		if latency, err = config.ParseDelay(spec); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if spec := query.Get("status"); spec != "" {
+		if statuses, err = ParseStatusMix(spec); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if spec := query.Get("size"); spec != "" {
+		if size, err = ParseSizeRange(spec); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if delay := latency.Sample(); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		}
+	}
+
+	status := statuses.Pick()
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		w.WriteHeader(status) // No body allowed
+		return
+	}
+	n := size.Sample()
+	if n == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "{\"status\":%d}\n", status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.Header().Set("Content-Length", strconv.FormatInt(n, 10))
+	w.WriteHeader(status)
+	for n > 0 {
+		chunk := filler[:min(n, int64(len(filler)))]
+		if _, err := w.Write(chunk); err != nil {
+			return
+		}
+		n -= int64(len(chunk))
+	}
+}